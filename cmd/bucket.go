@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	bucketListFormat        string
+	bucketInfoFormat        string
+	bucketDeleteForce       bool
+	bucketDeleteConcurrency int
+)
+
+// bucketCmd represents the bucket command
+var bucketCmd = &cobra.Command{
+	Use:   "bucket",
+	Short: "Manage buckets",
+	Long: `List, inspect, create and delete buckets.
+
+Examples:
+  r2s3-cli bucket list                    # Show all buckets
+  r2s3-cli bucket info media              # Creation date, location, policy and website
+  r2s3-cli bucket info media --format json
+  r2s3-cli bucket create media-archive    # Create a bucket
+  r2s3-cli bucket delete media-archive    # Delete an empty bucket
+  r2s3-cli bucket delete old-logs --force # Delete every object, then the bucket`,
+}
+
+var bucketListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List buckets",
+	Long: `List every bucket the credentials can access. The bucket other commands use
+by default is marked with "*".`,
+	Args: cobra.NoArgs,
+	RunE: showBuckets,
+}
+
+var bucketInfoCmd = &cobra.Command{
+	Use:   "info <name>",
+	Short: "Show a bucket's creation date, location, policy and website configuration",
+	Long: `Show what is known about a bucket: its creation date, location, whether
+it has a bucket policy and whether it serves a static website.
+
+Servers that do not support policies or website configurations (R2 among
+them) report those parts as unavailable.`,
+	Args: cobra.ExactArgs(1),
+	RunE: showBucketInfo,
+}
+
+var bucketCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a bucket",
+	Args:  cobra.ExactArgs(1),
+	RunE:  createBucket,
+}
+
+var bucketDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a bucket",
+	Long: `Delete a bucket. Only empty buckets can be deleted; asks for confirmation
+unless --force is used.
+
+With --force every object in the bucket is deleted first, without
+confirmation. This cannot be undone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: removeBucket,
+}
+
+func init() {
+	rootCmd.AddCommand(bucketCmd)
+	bucketCmd.AddCommand(bucketListCmd)
+	bucketCmd.AddCommand(bucketInfoCmd)
+	bucketCmd.AddCommand(bucketCreateCmd)
+	bucketCmd.AddCommand(bucketDeleteCmd)
+
+	bucketListCmd.Flags().StringVar(&bucketListFormat, "format", "text", "output format: text, json")
+	bucketInfoCmd.Flags().StringVar(&bucketInfoFormat, "format", "text", "output format: text, json")
+	bucketDeleteCmd.Flags().BoolVarP(&bucketDeleteForce, "force", "f", false, "delete all objects in the bucket first, without confirmation")
+	bucketDeleteCmd.Flags().IntVar(&bucketDeleteConcurrency, "concurrency", 4, "number of delete batches sent in parallel (with --force)")
+}
+
+// newBucketClient creates the client used by the bucket commands
+func newBucketClient() (*r2.Client, error) {
+	cfg := GetConfig()
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create R2 client: %w", err)
+	}
+	return client, nil
+}
+
+func showBuckets(cmd *cobra.Command, args []string) error {
+	if bucketListFormat != "text" && bucketListFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", bucketListFormat)
+	}
+
+	client, err := newBucketClient()
+	if err != nil {
+		return err
+	}
+
+	buckets, err := client.ListBuckets(context.Background())
+	if err != nil {
+		return err
+	}
+
+	infos := make([]*r2.BucketInfo, 0, len(buckets))
+	for _, bucket := range buckets {
+		infos = append(infos, r2.NewBucketInfo(bucket))
+	}
+
+	if bucketListFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(infos)
+	}
+
+	defaultBucket := GetConfig().GetEffectiveBucket()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tCREATED")
+	for _, info := range infos {
+		marker := " "
+		if info.Name == defaultBucket {
+			marker = "*"
+		}
+		created := ""
+		if !info.CreationDate.IsZero() {
+			created = info.CreationDate.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s %s\t%s\n", marker, info.Name, created)
+	}
+	return w.Flush()
+}
+
+func showBucketInfo(cmd *cobra.Command, args []string) error {
+	if bucketInfoFormat != "text" && bucketInfoFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", bucketInfoFormat)
+	}
+
+	client, err := newBucketClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	info, err := client.GetBucketInfo(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if bucketInfoFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	for _, field := range info.Fields() {
+		fmt.Printf("%-9s %s\n", field[0]+":", field[1])
+	}
+	return nil
+}
+
+func createBucket(cmd *cobra.Command, args []string) error {
+	client, err := newBucketClient()
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	if err := client.CreateBucket(context.Background(), name); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("Created bucket %s\n", name)
+	}
+	return nil
+}
+
+func removeBucket(cmd *cobra.Command, args []string) error {
+	if bucketDeleteConcurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", bucketDeleteConcurrency)
+	}
+
+	client, err := newBucketClient()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := client.HeadBucket(ctx, name); err != nil {
+		return err
+	}
+
+	objects, totalSize, err := collectRemoteObjects(ctx, client.Storage(), name, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if len(objects) > 0 {
+		if !bucketDeleteForce {
+			return fmt.Errorf("bucket %s is not empty (%d files, %s); use --force to delete them first",
+				name, len(objects), utils.FormatBytes(totalSize))
+		}
+		if err := emptyBucket(ctx, client, name, objects, totalSize); err != nil {
+			return err
+		}
+	} else if !bucketDeleteForce {
+		fmt.Printf("Are you sure you want to delete bucket '%s'? (y/N): ", name)
+		var response string
+		fmt.Scanln(&response)
+
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Delete cancelled.")
+			return nil
+		}
+	}
+
+	logrus.Infof("Deleting bucket: %s", name)
+	if err := client.DeleteBucket(ctx, name); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("Deleted bucket %s\n", name)
+	}
+	return nil
+}
+
+// emptyBucket deletes the listed objects so that the bucket can be removed
+func emptyBucket(ctx context.Context, client *r2.Client, name string, objects []remoteObject, totalSize int64) error {
+	if !quiet {
+		fmt.Printf("Deleting %d files (%s) from bucket %s\n", len(objects), utils.FormatBytes(totalSize), name)
+	}
+
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = object.key
+	}
+	deleted, errs := deleteKeys(ctx, client.Storage(), name, keys, bucketDeleteConcurrency)
+
+	if ctx.Err() != nil {
+		fmt.Printf("Delete cancelled after deleting %d of %d files\n", deleted, len(keys))
+		return fmt.Errorf("delete cancelled: %w", ctx.Err())
+	}
+	if len(errs) > 0 {
+		fmt.Printf("Deleted %d files successfully, %d failed:\n", deleted, len(errs))
+		for _, err := range errs {
+			fmt.Printf("  Error: %v\n", err)
+		}
+		return fmt.Errorf("bucket %s could not be emptied", name)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/image"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var cacheStatsFormat string
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the local preview cache",
+	Long: `Inspect and maintain the cache of downloaded previews and thumbnails used
+by the TUI browser.
+
+Entries are keyed on bucket, object key and ETag, so a changed object is
+downloaded again. The directory, size limit and cleanup policy are set in
+the [cache] section of the configuration file.
+
+Examples:
+  r2s3-cli cache stats                 # Size, usage and hit rate
+  r2s3-cli cache stats --format json   # Machine-readable output
+  r2s3-cli cache compact               # Drop broken entries and shrink to the limit
+  r2s3-cli cache clear                 # Remove every cached file`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache size, usage and hit rate",
+	Args:  cobra.NoArgs,
+	RunE:  showCacheStats,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached file",
+	Args:  cobra.NoArgs,
+	RunE:  clearCache,
+}
+
+var cacheCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Remove broken entries and shrink the cache to its size limit",
+	Long: `Remove entries whose files are missing or fail their checksum, delete
+files the cache index does not know about, then apply the cleanup policy
+until the cache fits within max_size_mb.`,
+	Args: cobra.NoArgs,
+	RunE: compactCache,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheCompactCmd)
+
+	cacheStatsCmd.Flags().StringVar(&cacheStatsFormat, "format", "text", "output format: text, json")
+}
+
+// cacheStats is the JSON form of the cache metrics
+type cacheStats struct {
+	Dir           string     `json:"dir"`
+	CleanupPolicy string     `json:"cleanup_policy"`
+	Files         int        `json:"files"`
+	Size          int64      `json:"size"`
+	MaxSize       int64      `json:"max_size"`
+	UsagePercent  float64    `json:"usage_percent"`
+	Hits          int64      `json:"hits"`
+	Misses        int64      `json:"misses"`
+	HitRate       float64    `json:"hit_rate"`
+	LastCleanup   *time.Time `json:"last_cleanup,omitempty"`
+}
+
+// openCache opens the cache configured in the [cache] section; callers must
+// call StopAutoCleanup when done
+func openCache() (*image.CacheManager, error) {
+	cfg := GetConfig()
+	policy, err := image.ParseCleanupPolicy(cfg.Cache.CleanupPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := image.NewCacheManager(cfg.Cache.Directory(), cfg.Cache.MaxSizeBytes())
+	cache.SetCleanupPolicy(policy, cfg.Cache.MaxAge())
+	return cache, nil
+}
+
+func showCacheStats(cmd *cobra.Command, args []string) error {
+	if cacheStatsFormat != "text" && cacheStatsFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", cacheStatsFormat)
+	}
+
+	cache, err := openCache()
+	if err != nil {
+		return err
+	}
+	defer cache.StopAutoCleanup()
+
+	metrics := cache.GetCacheMetrics()
+
+	if cacheStatsFormat == "json" {
+		stats := cacheStats{
+			Dir:           metrics.CacheDir,
+			CleanupPolicy: metrics.CleanupPolicy,
+			Files:         metrics.TotalFiles,
+			Size:          metrics.TotalSize,
+			MaxSize:       metrics.MaxSize,
+			UsagePercent:  metrics.UsagePercent,
+			Hits:          metrics.Hits,
+			Misses:        metrics.Misses,
+			HitRate:       metrics.HitRate,
+		}
+		if !metrics.LastCleanupTime.IsZero() {
+			stats.LastCleanup = &metrics.LastCleanupTime
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	lastCleanup := "never"
+	if !metrics.LastCleanupTime.IsZero() {
+		lastCleanup = metrics.LastCleanupTime.Local().Format("2006-01-02 15:04:05")
+	}
+
+	fmt.Printf("%-13s %s\n", "Dir:", metrics.CacheDir)
+	fmt.Printf("%-13s %s\n", "Policy:", metrics.CleanupPolicy)
+	fmt.Printf("%-13s %d\n", "Files:", metrics.TotalFiles)
+	fmt.Printf("%-13s %s / %s (%.1f%%)\n", "Size:", utils.FormatBytes(metrics.TotalSize), utils.FormatBytes(metrics.MaxSize), metrics.UsagePercent)
+	fmt.Printf("%-13s %.1f%% (%d hits, %d misses)\n", "Hit rate:", metrics.HitRate, metrics.Hits, metrics.Misses)
+	fmt.Printf("%-13s %s\n", "Last cleanup:", lastCleanup)
+	return nil
+}
+
+func clearCache(cmd *cobra.Command, args []string) error {
+	cache, err := openCache()
+	if err != nil {
+		return err
+	}
+	defer cache.StopAutoCleanup()
+
+	files, size, err := cache.Clear()
+	if err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	if !quiet {
+		fmt.Printf("Removed %d files (%s)\n", files, utils.FormatBytes(size))
+	}
+	return nil
+}
+
+func compactCache(cmd *cobra.Command, args []string) error {
+	cache, err := openCache()
+	if err != nil {
+		return err
+	}
+	defer cache.StopAutoCleanup()
+
+	before := cache.GetCacheMetrics()
+	if err := cache.CompactCache(); err != nil {
+		return fmt.Errorf("failed to compact cache: %w", err)
+	}
+	after := cache.GetCacheMetrics()
+
+	if !quiet {
+		fmt.Printf("Compacted cache: %d files (%s) -> %d files (%s)\n",
+			before.TotalFiles, utils.FormatBytes(before.TotalSize),
+			after.TotalFiles, utils.FormatBytes(after.TotalSize))
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// transferFlags holds the flags shared by cp and mv
+type transferFlags struct {
+	bucket      string
+	destBucket  string
+	recursive   bool
+	include     []string
+	exclude     []string
+	metadata    map[string]string
+	contentType string
+	dryRun      bool
+	concurrency int
+}
+
+var copyFlags transferFlags
+
+// cpCmd represents the cp command
+var cpCmd = &cobra.Command{
+	Use:   "cp <source> <destination>",
+	Short: "Copy objects within R2 without downloading them",
+	Long: `Copy an object or a whole prefix on the server side. Objects larger than
+5GB are copied in parts with UploadPartCopy.
+
+A destination ending with "/" keeps the source file name. With --recursive,
+every object below the source prefix is copied below the destination prefix.
+Metadata is preserved unless --metadata or --content-type is given.
+
+Examples:
+  r2s3-cli cp photos/cat.jpg backup/                     # Copy into a folder
+  r2s3-cli cp photos/cat.jpg photos/kitty.jpg            # Copy under a new name
+  r2s3-cli cp photos/ archive/photos/ -r                 # Copy a whole prefix
+  r2s3-cli cp photos/ photos/ -r --dest-bucket backups   # Copy to another bucket
+  r2s3-cli cp report.pdf report.pdf --content-type application/pdf  # Fix metadata in place
+  r2s3-cli cp logs/ old-logs/ -r --include "*.gz" --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTransfer(cmd, args, &copyFlags, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+	addTransferFlags(cpCmd, &copyFlags)
+}
+
+// addTransferFlags registers the flags shared by cp and mv
+func addTransferFlags(cmd *cobra.Command, flags *transferFlags) {
+	cmd.Flags().StringVarP(&flags.bucket, "bucket", "b", "", "source bucket name (overrides config)")
+	cmd.Flags().StringVar(&flags.destBucket, "dest-bucket", "", "destination bucket (defaults to the source bucket)")
+	cmd.Flags().BoolVarP(&flags.recursive, "recursive", "r", false, "treat source and destination as prefixes")
+	cmd.Flags().StringSliceVar(&flags.include, "include", nil, "only transfer keys matching these glob patterns (with --recursive)")
+	cmd.Flags().StringSliceVar(&flags.exclude, "exclude", nil, "skip keys matching these glob patterns (with --recursive)")
+	cmd.Flags().StringToStringVar(&flags.metadata, "metadata", nil, "replace user metadata (key=value,...)")
+	cmd.Flags().StringVar(&flags.contentType, "content-type", "", "replace the content type")
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "show what would be transferred without doing it")
+	cmd.Flags().IntVar(&flags.concurrency, "concurrency", 4, "number of objects transferred in parallel")
+}
+
+// transferPair is one source key and its destination key
+type transferPair struct {
+	source      string
+	destination string
+	size        int64
+}
+
+// runTransfer copies (or moves, when move is true) objects according to args
+func runTransfer(cmd *cobra.Command, args []string, flags *transferFlags, move bool) error {
+	cfg := GetConfig()
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+	storage := client.Storage()
+
+	// Determine bucket names with priority: --bucket flag > effective bucket from config
+	srcBucket := cfg.GetEffectiveBucket()
+	if flags.bucket != "" {
+		srcBucket = flags.bucket
+	}
+	dstBucket := srcBucket
+	if flags.destBucket != "" {
+		dstBucket = flags.destBucket
+	}
+
+	if !flags.recursive && (len(flags.include) > 0 || len(flags.exclude) > 0) {
+		return fmt.Errorf("--include and --exclude require --recursive")
+	}
+
+	options := &utils.CopyOptions{
+		ContentType: flags.contentType,
+		Concurrency: cfg.Upload.MultipartConcurrency,
+	}
+	if cmd.Flags().Changed("metadata") {
+		options.Metadata = flags.metadata
+		if options.Metadata == nil {
+			options.Metadata = map[string]string{}
+		}
+	}
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pairs, err := planTransfer(ctx, storage, srcBucket, dstBucket, args[0], args[1], flags, move, options)
+	if err != nil {
+		return err
+	}
+
+	verb, past := "COPY", "Copied"
+	if move {
+		verb, past = "MOVE", "Moved"
+	}
+
+	if flags.dryRun {
+		var totalSize int64
+		for _, pair := range pairs {
+			fmt.Printf("%s %s/%s -> %s/%s (%s)\n", verb, srcBucket, pair.source, dstBucket, pair.destination, utils.FormatBytes(pair.size))
+			totalSize += pair.size
+		}
+		fmt.Printf("\nDry run: %d files (%s) would be %s\n", len(pairs), utils.FormatBytes(totalSize), strings.ToLower(past))
+		return nil
+	}
+
+	copier := utils.NewObjectCopier(storage)
+
+	var (
+		mu         sync.Mutex
+		copied     = make([]bool, len(pairs))
+		copiedSize int64
+	)
+	failures := runWorkers(ctx, flags.concurrency, len(pairs), func(worker, index int) error {
+		pair := pairs[index]
+		size, err := copier.Copy(ctx, srcBucket, pair.source, dstBucket, pair.destination, options)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		copied[index] = true
+		copiedSize += size
+		mu.Unlock()
+
+		if !flags.recursive {
+			fmt.Printf("%s %s/%s -> %s/%s (%s)\n", past, srcBucket, pair.source, dstBucket, pair.destination, utils.FormatBytes(size))
+		}
+		return nil
+	})
+
+	// Only remove sources whose copy succeeded
+	var deleteErrors []error
+	if move {
+		var sources []string
+		for i, pair := range pairs {
+			if copied[i] {
+				sources = append(sources, pair.source)
+			}
+		}
+		if len(sources) > 0 {
+			_, deleteErrors = deleteKeys(ctx, storage, srcBucket, sources, flags.concurrency)
+		}
+	}
+
+	succeeded := 0
+	for _, ok := range copied {
+		if ok {
+			succeeded++
+		}
+	}
+
+	if ctx.Err() != nil {
+		fmt.Printf("Cancelled after %s %d of %d files\n", strings.ToLower(past), succeeded, len(pairs))
+		return fmt.Errorf("transfer cancelled: %w", ctx.Err())
+	}
+
+	if len(failures) > 0 || len(deleteErrors) > 0 {
+		fmt.Printf("%s %d files, %d failed:\n", past, succeeded, len(failures)+len(deleteErrors))
+		for _, failure := range failures {
+			fmt.Printf("  Error: %v\n", failure.err)
+		}
+		for _, err := range deleteErrors {
+			fmt.Printf("  Error: %v\n", err)
+		}
+		return fmt.Errorf("some files could not be %s", strings.ToLower(past))
+	}
+
+	if flags.recursive {
+		fmt.Printf("%s %d files (%s) from %s/%s to %s/%s\n", past, succeeded, utils.FormatBytes(copiedSize), srcBucket, args[0], dstBucket, args[1])
+	}
+	logrus.Infof("%s %d files from %s to %s", past, succeeded, srcBucket, dstBucket)
+	return nil
+}
+
+// planTransfer resolves the source/destination key pairs for a transfer
+func planTransfer(ctx context.Context, storage r2.Storage, srcBucket, dstBucket, source, destination string, flags *transferFlags, move bool, options *utils.CopyOptions) ([]transferPair, error) {
+	sameBucket := srcBucket == dstBucket
+
+	if !flags.recursive {
+		if source == "" || strings.HasSuffix(source, "/") {
+			return nil, fmt.Errorf("source %q is a prefix, use --recursive", source)
+		}
+		if destination == "" || strings.HasSuffix(destination, "/") {
+			destination += path.Base(source)
+		}
+		// Copying onto itself is only useful to replace metadata
+		if sameBucket && source == destination && (move || options.Metadata == nil && options.ContentType == "") {
+			return nil, fmt.Errorf("source and destination are the same: %s", source)
+		}
+
+		head, err := storage.Head(ctx, srcBucket, source)
+		if err != nil {
+			return nil, fmt.Errorf("source %s/%s not found: %w", srcBucket, source, err)
+		}
+		return []transferPair{{source: source, destination: destination, size: head.Size}}, nil
+	}
+
+	// Treat both sides as folders so relative keys join cleanly
+	if source != "" && !strings.HasSuffix(source, "/") {
+		source += "/"
+	}
+	if destination != "" && !strings.HasSuffix(destination, "/") {
+		destination += "/"
+	}
+	if move && sameBucket && strings.HasPrefix(destination, source) {
+		return nil, fmt.Errorf("cannot move %s into itself (%s)", source, destination)
+	}
+
+	filter, err := utils.NewKeyFilter(flags.include, flags.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, _, err := collectRemoteObjects(ctx, storage, srcBucket, source, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no files found with prefix: %s", source)
+	}
+
+	pairs := make([]transferPair, 0, len(objects))
+	for _, object := range objects {
+		// Skip "folder/" marker objects
+		if strings.HasSuffix(object.key, "/") {
+			continue
+		}
+		pairs = append(pairs, transferPair{
+			source:      object.key,
+			destination: destination + strings.TrimPrefix(object.key, source),
+			size:        object.size,
+		})
+	}
+	return pairs, nil
+}
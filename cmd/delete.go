@@ -3,21 +3,27 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
 )
 
 var (
-	deleteBucket    string
-	deleteForce     bool
-	deleteRecursive bool
+	deleteBucket      string
+	deleteForce       bool
+	deleteRecursive   bool
+	deleteInclude     []string
+	deleteExclude     []string
+	deleteDryRun      bool
+	deleteConcurrency int
 )
 
 // deleteCmd represents the delete command
@@ -30,7 +36,13 @@ Examples:
   r2s3-cli delete image.jpg                  # Delete a single file
   r2s3-cli delete photos/old-image.jpg      # Delete from specific path
   r2s3-cli delete photos/ --recursive       # Delete all files with prefix
-  r2s3-cli delete image.jpg --force         # Delete without confirmation`,
+  r2s3-cli delete image.jpg --force         # Delete without confirmation
+  r2s3-cli delete photos/ -r --dry-run      # List files that would be deleted
+  r2s3-cli delete photos/ -r --include "*.tmp" --exclude "keep/**"
+
+Filters (--include/--exclude) are glob patterns matched against the key
+relative to the prefix. Patterns without "/" match the file name only, and
+"**" matches any number of directories.`,
 	Args: cobra.ExactArgs(1),
 	RunE: deleteFile,
 }
@@ -41,13 +53,17 @@ func init() {
 	deleteCmd.Flags().StringVarP(&deleteBucket, "bucket", "b", "", "bucket name (overrides config)")
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "force delete without confirmation")
 	deleteCmd.Flags().BoolVarP(&deleteRecursive, "recursive", "r", false, "delete all files with prefix (use with caution)")
+	deleteCmd.Flags().StringSliceVar(&deleteInclude, "include", nil, "only delete keys matching these glob patterns (with --recursive)")
+	deleteCmd.Flags().StringSliceVar(&deleteExclude, "exclude", nil, "skip keys matching these glob patterns (with --recursive)")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "list files that would be deleted without deleting them (with --recursive)")
+	deleteCmd.Flags().IntVar(&deleteConcurrency, "concurrency", 4, "number of delete batches sent in parallel")
 }
 
 func deleteFile(cmd *cobra.Command, args []string) error {
 	cfg := GetConfig()
 
 	// Create R2 client
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	if err != nil {
 		return fmt.Errorf("failed to create R2 client: %w", err)
 	}
@@ -60,16 +76,24 @@ func deleteFile(cmd *cobra.Command, args []string) error {
 
 	remotePath := args[0]
 
+	if !deleteRecursive && (len(deleteInclude) > 0 || len(deleteExclude) > 0 || deleteDryRun) {
+		return fmt.Errorf("--include, --exclude and --dry-run require --recursive")
+	}
+
 	if deleteRecursive {
 		return deletePrefix(client, bucketName, remotePath)
 	}
 
-	return deleteSingleFile(client, bucketName, remotePath)
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return deleteSingleFile(ctx, client, bucketName, remotePath)
 }
 
-func deleteSingleFile(client *r2.Client, bucketName, key string) error {
+func deleteSingleFile(ctx context.Context, client *r2.Client, bucketName, key string) error {
 	// Check if file exists first
-	exists, err := checkFileExists(client, bucketName, key)
+	exists, err := checkFileExists(ctx, client, bucketName, key)
 	if err != nil {
 		return fmt.Errorf("failed to check if file exists: %w", err)
 	}
@@ -94,10 +118,7 @@ func deleteSingleFile(client *r2.Client, bucketName, key string) error {
 	// Delete the file
 	logrus.Infof("Deleting file: %s", key)
 
-	_, err = client.GetS3Client().(*s3.Client).DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(key),
-	})
+	err = client.Storage().Delete(ctx, bucketName, key)
 
 	if err != nil {
 		return fmt.Errorf("failed to delete file %s: %w", key, err)
@@ -107,28 +128,79 @@ func deleteSingleFile(client *r2.Client, bucketName, key string) error {
 	return nil
 }
 
+// remoteObject is an object listed below a prefix
+type remoteObject struct {
+	key  string
+	size int64
+}
+
+// collectRemoteObjects lists every object below prefix (all pages) and keeps
+// the ones accepted by filter, matched on the key relative to the prefix. A
+// leading "/" is dropped, so "keep/**" matches photos/keep/a.jpg below "photos".
+func collectRemoteObjects(ctx context.Context, storage r2.Storage, bucketName, prefix string, filter *utils.KeyFilter) ([]remoteObject, int64, error) {
+	paginator := r2.NewListPaginator(storage, bucketName, r2.ListOptions{Prefix: prefix})
+
+	var targets []remoteObject
+	var totalSize int64
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		}
+
+		for _, obj := range page.Objects {
+			relative := strings.TrimPrefix(strings.TrimPrefix(obj.Key, prefix), "/")
+			if !filter.Match(relative) {
+				continue
+			}
+
+			targets = append(targets, remoteObject{key: obj.Key, size: obj.Size})
+			totalSize += obj.Size
+		}
+	}
+
+	return targets, totalSize, nil
+}
+
 func deletePrefix(client *r2.Client, bucketName, prefix string) error {
-	// List all files with the prefix
-	s3Client := client.GetS3Client().(*s3.Client)
+	storage := client.Storage()
 
-	listInput := &s3.ListObjectsV2Input{
-		Bucket: aws.String(bucketName),
-		Prefix: aws.String(prefix),
+	filter, err := utils.NewKeyFilter(deleteInclude, deleteExclude)
+	if err != nil {
+		return err
 	}
 
-	result, err := s3Client.ListObjectsV2(context.TODO(), listInput)
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// List all files with the prefix
+	targets, totalSize, err := collectRemoteObjects(ctx, storage, bucketName, prefix, filter)
 	if err != nil {
-		return fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		return err
 	}
 
-	if len(result.Contents) == 0 {
+	if len(targets) == 0 {
 		return fmt.Errorf("no files found with prefix: %s", prefix)
 	}
 
-	// Show what will be deleted
-	fmt.Printf("The following %d files will be deleted:\n", len(result.Contents))
-	for _, obj := range result.Contents {
-		fmt.Printf("  - %s\n", aws.ToString(obj.Key))
+	// Dry run lists every key that would be deleted
+	if deleteDryRun {
+		for _, target := range targets {
+			fmt.Printf("DELETE %s (%s)\n", target.key, utils.FormatBytes(target.size))
+		}
+		fmt.Printf("\nDry run: %d files (%s) would be deleted from %s/%s\n", len(targets), utils.FormatBytes(totalSize), bucketName, prefix)
+		return nil
+	}
+
+	// Show a summary of what will be deleted
+	fmt.Printf("%d files (%s) will be deleted from %s/%s\n", len(targets), utils.FormatBytes(totalSize), bucketName, prefix)
+	const previewCount = 5
+	for _, target := range targets[:min(previewCount, len(targets))] {
+		fmt.Printf("  - %s\n", target.key)
+	}
+	if len(targets) > previewCount {
+		fmt.Printf("  ... and %d more (use --dry-run to list all)\n", len(targets)-previewCount)
 	}
 
 	// Ask for confirmation unless --force is used
@@ -144,78 +216,89 @@ func deletePrefix(client *r2.Client, bucketName, prefix string) error {
 		}
 	}
 
-	// Delete all files using batch deletion
-	logrus.Infof("Deleting %d files with prefix: %s", len(result.Contents), prefix)
+	// Delete all files using parallel batch deletion
+	logrus.Infof("Deleting %d files with prefix: %s", len(targets), prefix)
 
-	const maxDeleteBatchSize = 1000
-	totalErrors := []error{}
-	totalDeleted := 0
-
-	// Process objects in batches of up to 1000
-	for i := 0; i < len(result.Contents); i += maxDeleteBatchSize {
-		end := min(i+maxDeleteBatchSize, len(result.Contents))
-		batch := result.Contents[i:end]
-
-		// Build delete objects input
-		objects := make([]types.ObjectIdentifier, len(batch))
-		for j, obj := range batch {
-			objects[j] = types.ObjectIdentifier{
-				Key: obj.Key,
-			}
-		}
+	keys := make([]string, len(targets))
+	for i, target := range targets {
+		keys[i] = target.key
+	}
+	totalDeleted, totalErrors := deleteKeys(ctx, storage, bucketName, keys, deleteConcurrency)
+
+	if ctx.Err() != nil {
+		fmt.Printf("Delete cancelled after deleting %d of %d files\n", totalDeleted, len(keys))
+		return fmt.Errorf("delete cancelled: %w", ctx.Err())
+	}
 
-		deleteInput := &s3.DeleteObjectsInput{
-			Bucket: aws.String(bucketName),
-			Delete: &types.Delete{
-				Objects: objects,
-			},
+	// Report final results
+	if len(totalErrors) > 0 {
+		fmt.Printf("Deleted %d files successfully, %d failed:\n", totalDeleted, len(totalErrors))
+		for _, err := range totalErrors {
+			fmt.Printf("  Error: %v\n", err)
 		}
+		return fmt.Errorf("some files could not be deleted")
+	}
+
+	fmt.Printf("Deleted %d files (%s)\n", totalDeleted, utils.FormatBytes(totalSize))
+	logrus.Infof("Successfully deleted %d files", totalDeleted)
+	return nil
+}
+
+// deleteKeys deletes the given keys using DeleteObjects batches of up to 1000
+// keys, running up to concurrency batches in parallel. It returns the number of
+// deleted objects and one error per failed key (or per failed batch request).
+func deleteKeys(ctx context.Context, storage r2.Storage, bucketName string, keys []string, concurrency int) (int, []error) {
+	const maxDeleteBatchSize = 1000
+
+	// Split keys into batches of up to 1000
+	var batches [][]string
+	for i := 0; i < len(keys); i += maxDeleteBatchSize {
+		batches = append(batches, keys[i:min(i+maxDeleteBatchSize, len(keys))])
+	}
+
+	var (
+		mu           sync.Mutex
+		totalDeleted int
+		keyErrors    []error
+	)
+
+	batchFailures := runWorkers(ctx, concurrency, len(batches), func(worker, index int) error {
+		batch := batches[index]
 
 		// Execute batch deletion
-		deleteResult, err := s3Client.DeleteObjects(context.TODO(), deleteInput)
+		deleteErrors, err := storage.DeleteMany(ctx, bucketName, batch)
 		if err != nil {
 			logrus.Errorf("Failed to execute batch delete: %v", err)
-			totalErrors = append(totalErrors, fmt.Errorf("batch delete failed: %w", err))
-			continue
+			return fmt.Errorf("batch delete of %d keys starting at %s failed: %w", len(batch), batch[0], err)
 		}
 
-		// Process successful deletions
-		if deleteResult.Deleted != nil {
-			for _, deleted := range deleteResult.Deleted {
-				logrus.Debugf("Deleted: %s", aws.ToString(deleted.Key))
-				totalDeleted++
-			}
+		// Only failed keys are reported back
+		failed := make(map[string]bool, len(deleteErrors))
+		var batchErrors []error
+		for _, deleteError := range deleteErrors {
+			logrus.Errorf("Failed to delete %s: %s - %s", deleteError.Key, deleteError.Code, deleteError.Message)
+			failed[deleteError.Key] = true
+			batchErrors = append(batchErrors, fmt.Errorf("failed to delete %s: %s - %s", deleteError.Key, deleteError.Code, deleteError.Message))
 		}
 
-		// Process failed deletions
-		if deleteResult.Errors != nil {
-			for _, deleteError := range deleteResult.Errors {
-				key := aws.ToString(deleteError.Key)
-				code := aws.ToString(deleteError.Code)
-				message := aws.ToString(deleteError.Message)
-				logrus.Errorf("Failed to delete %s: %s - %s", key, code, message)
-				totalErrors = append(totalErrors, fmt.Errorf("failed to delete %s: %s - %s", key, code, message))
-			}
-		}
+		mu.Lock()
+		totalDeleted += len(batch) - len(failed)
+		keyErrors = append(keyErrors, batchErrors...)
+		deletedSoFar := totalDeleted
+		mu.Unlock()
 
 		// Log batch progress for large operations
-		if len(result.Contents) > maxDeleteBatchSize {
-			logrus.Infof("Processed batch %d/%d (deleted %d files so far)",
-				(i/maxDeleteBatchSize)+1,
-				(len(result.Contents)+maxDeleteBatchSize-1)/maxDeleteBatchSize,
-				totalDeleted)
+		if len(batches) > 1 {
+			logrus.Infof("Processed batch %d/%d (deleted %d files so far)", index+1, len(batches), deletedSoFar)
 		}
-	}
+		return nil
+	})
 
-	// Report final results
-	if len(totalErrors) > 0 {
-		fmt.Printf("Deleted %d files successfully, %d failed:\n", totalDeleted, len(totalErrors))
-		for _, err := range totalErrors {
-			fmt.Printf("  Error: %v\n", err)
-		}
-		return fmt.Errorf("some files could not be deleted")
+	totalErrors := make([]error, 0, len(batchFailures)+len(keyErrors))
+	for _, failure := range batchFailures {
+		totalErrors = append(totalErrors, failure.err)
 	}
+	totalErrors = append(totalErrors, keyErrors...)
 
-	logrus.Infof("Successfully deleted %d files", totalDeleted)
-	return nil
+	return totalDeleted, totalErrors
 }
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	downloadBucket     string
+	downloadRecursive  bool
+	downloadOverwrite  bool
+	downloadConflict   string
+	downloadNoProgress bool
+)
+
+// downloadCmd represents the download command
+var downloadCmd = &cobra.Command{
+	Use:   "download <remote-path|prefix> [local-path]",
+	Short: "Download a file or prefix from R2 storage",
+	Long: `Download a file or every file under a prefix from the specified R2 bucket.
+
+Local Path Logic:
+  - Empty local-path defaults to the current directory
+  - If local-path is an existing directory or ends with "/", the file keeps its name
+  - Otherwise local-path is used as the new file name
+  - With --recursive, keys are recreated below local-path relative to the prefix
+
+Conflict Policies (when a local file already exists):
+  rename     write to "name (1).ext" instead (default)
+  skip       leave the existing file untouched
+  overwrite  replace the existing file (same as --overwrite)
+
+Examples:
+  r2s3-cli download image.jpg                       # Download to ./image.jpg
+  r2s3-cli download photos/cat.jpg ~/Pictures/      # Download to ~/Pictures/cat.jpg
+  r2s3-cli download photos/cat.jpg kitty.jpg        # Download as ./kitty.jpg
+  r2s3-cli download photos/ ./backup --recursive    # Download prefix into ./backup
+  r2s3-cli download photos/ -r --conflict skip      # Only fetch files missing locally
+  r2s3-cli download image.jpg --overwrite           # Replace existing local file`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: downloadFile,
+}
+
+func init() {
+	rootCmd.AddCommand(downloadCmd)
+
+	downloadCmd.Flags().StringVarP(&downloadBucket, "bucket", "b", "", "bucket name (overrides config)")
+	downloadCmd.Flags().BoolVarP(&downloadRecursive, "recursive", "r", false, "download all files with prefix")
+	downloadCmd.Flags().BoolVar(&downloadOverwrite, "overwrite", false, "overwrite existing local files")
+	downloadCmd.Flags().StringVar(&downloadConflict, "conflict", "rename", "policy for existing local files (rename, skip, overwrite)")
+	downloadCmd.Flags().BoolVar(&downloadNoProgress, "no-progress", false, "disable progress bar")
+}
+
+// downloadItem is a single object scheduled for download
+type downloadItem struct {
+	key       string
+	localPath string
+	size      int64
+}
+
+func downloadFile(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+
+	// Create R2 client
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if downloadBucket != "" {
+		bucketName = downloadBucket
+	}
+
+	// Determine conflict policy (--overwrite wins over --conflict)
+	policy, err := utils.ParseConflictPolicy(downloadConflict)
+	if err != nil {
+		return err
+	}
+	if downloadOverwrite {
+		policy = utils.ConflictOverwrite
+	}
+
+	remotePath := args[0]
+	localPath := ""
+	if len(args) > 1 {
+		localPath = args[1]
+	}
+
+	downloader := utils.NewFileDownloader(client.Storage(), bucketName)
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var items []downloadItem
+	if downloadRecursive {
+		items, err = collectPrefixDownloads(ctx, client, bucketName, remotePath, localPath)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return fmt.Errorf("no files found with prefix: %s", remotePath)
+		}
+	} else {
+		head, err := client.Storage().Head(ctx, bucketName, remotePath)
+		if err != nil {
+			return fmt.Errorf("failed to get object info for %s: %w", remotePath, err)
+		}
+		items = []downloadItem{{
+			key:       remotePath,
+			localPath: processLocalPath(localPath, remotePath),
+			size:      head.Size,
+		}}
+	}
+
+	return runDownloads(ctx, downloader, items, policy)
+}
+
+// processLocalPath resolves the local destination for a single object:
+// - Empty path defaults to the current directory with the original name
+// - Existing directories and paths ending with a separator keep the original name
+// - Anything else is treated as the new file name
+func processLocalPath(localPath, key string) string {
+	name := filepath.Base(key)
+	if localPath == "" {
+		return name
+	}
+
+	if strings.HasSuffix(localPath, "/") || strings.HasSuffix(localPath, string(os.PathSeparator)) {
+		return filepath.Join(localPath, name)
+	}
+
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		return filepath.Join(localPath, name)
+	}
+
+	return localPath
+}
+
+// collectPrefixDownloads lists every object below prefix and maps each key to a
+// local path that mirrors the key hierarchy relative to the prefix
+func collectPrefixDownloads(ctx context.Context, client *r2.Client, bucketName, prefix, localDir string) ([]downloadItem, error) {
+	if localDir == "" {
+		localDir = "."
+	}
+
+	baseDir, err := filepath.Abs(localDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve local path %s: %w", localDir, err)
+	}
+
+	paginator := r2.NewListPaginator(client.Storage(), bucketName, r2.ListOptions{Prefix: prefix})
+
+	var items []downloadItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		}
+
+		for _, obj := range page.Objects {
+			key := obj.Key
+
+			// Skip "folder" placeholder objects
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+
+			relPath := strings.TrimPrefix(key, prefix)
+			relPath = strings.TrimPrefix(relPath, "/")
+			if relPath == "" {
+				relPath = filepath.Base(key)
+			}
+
+			target := filepath.Join(baseDir, filepath.FromSlash(relPath))
+
+			// Refuse keys that would escape the destination directory (e.g. "../x")
+			if rel, err := filepath.Rel(baseDir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				logrus.Warnf("Skipping %s: resolves outside of %s", key, baseDir)
+				continue
+			}
+
+			items = append(items, downloadItem{
+				key:       key,
+				localPath: target,
+				size:      obj.Size,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// runDownloads downloads the given items sequentially with a shared progress bar
+func runDownloads(ctx context.Context, downloader *utils.FileDownloader, items []downloadItem, policy utils.ConflictPolicy) error {
+	var totalBytes int64
+	for _, item := range items {
+		totalBytes += item.size
+	}
+
+	logrus.Infof("Downloading %d files (%d bytes)", len(items), totalBytes)
+
+	// Create multi-file progress tracker
+	var progress *utils.MultiFileProgress
+	if !downloadNoProgress && !quiet {
+		progress = utils.NewMultiFileProgress(len(items), totalBytes)
+		progress.SetAction("Downloading")
+	}
+
+	downloadedCount := 0
+	skippedCount := 0
+	var failures []string
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var callback utils.ProgressCallback
+		if progress != nil {
+			progress.StartFile(filepath.Base(item.key), item.size)
+			callback = func(transferred, total int64, percentage float64) {
+				progress.UpdateFile(transferred)
+			}
+		}
+
+		_, skipped, err := downloader.DownloadToFile(ctx, item.key, item.localPath, policy, callback)
+		if progress != nil {
+			// Failed files count no bytes towards the total
+			var done int64
+			if err == nil {
+				done = item.size
+			}
+			progress.FinishFile(done)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to download %s: %v", item.key, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", item.key, err))
+			continue
+		}
+
+		if skipped {
+			skippedCount++
+		} else {
+			downloadedCount++
+		}
+	}
+
+	// Finish progress display
+	if progress != nil {
+		progress.Finish()
+	}
+
+	if ctx.Err() != nil {
+		fmt.Printf("Cancelled after downloading %d of %d files\n", downloadedCount, len(items))
+		return fmt.Errorf("download cancelled: %w", ctx.Err())
+	}
+
+	if len(failures) > 0 {
+		fmt.Printf("Downloaded %d files, %d skipped, %d failed:\n", downloadedCount, skippedCount, len(failures))
+		for _, failure := range failures {
+			fmt.Printf("  Error: %s\n", failure)
+		}
+		return fmt.Errorf("%d files failed to download", len(failures))
+	}
+
+	if !quiet {
+		fmt.Printf("Downloaded %d files, %d skipped\n", downloadedCount, skippedCount)
+	}
+	logrus.Infof("Download completed: %d downloaded, %d skipped", downloadedCount, skippedCount)
+	return nil
+}
@@ -0,0 +1,675 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2/r2test"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/image"
+)
+
+const e2eBucket = "e2e-bucket"
+
+// e2eEnv is a fake S3 server plus a config file pointing at it
+type e2eEnv struct {
+	server     *r2test.Server
+	configPath string
+}
+
+func newE2EEnv(t *testing.T) *e2eEnv {
+	t.Helper()
+
+	// The multipart journal and user data live under the home directory
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("R2CLI_PROFILE", "")
+
+	server := r2test.NewServer(t, e2eBucket)
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	config := fmt.Sprintf(`[r2]
+access_key_id = "test-access-key"
+access_key_secret = "test-secret-key"
+bucket_name = %q
+endpoint = %q
+region = "auto"
+use_path_style = true
+
+[general]
+default_timeout = 10
+max_retries = 3
+
+[upload]
+multipart_threshold = 5
+multipart_part_size = 5
+`, e2eBucket, server.URL)
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+	return &e2eEnv{server: server, configPath: configPath}
+}
+
+// appendConfig adds TOML to the end of the config file, after the [upload] section
+func (e *e2eEnv) appendConfig(t *testing.T, toml string) {
+	t.Helper()
+
+	file, err := os.OpenFile(e.configPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString("\n" + toml)
+	require.NoError(t, err)
+}
+
+// run executes the root command with args and returns what it printed to stdout
+func (e *e2eEnv) run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	resetCommandFlags(rootCmd)
+	rootCmd.SetArgs(append([]string{"--config", e.configPath}, args...))
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, reader)
+		output <- buf.String()
+	}()
+
+	runErr := rootCmd.Execute()
+
+	writer.Close()
+	os.Stdout = stdout
+	return <-output, runErr
+}
+
+// mapFlagVariables are the variables behind key=value flags. pflag cannot
+// reset those through the flag, so resetCommandFlags replaces them instead.
+var mapFlagVariables = []*map[string]string{
+	&uploadMeta,
+	&metaSet,
+	&copyFlags.metadata,
+	&moveFlags.metadata,
+}
+
+// resetCommandFlags restores every flag to its default so that one run does
+// not leak into the next; cobra keeps flag values in package variables
+func resetCommandFlags(cmd *cobra.Command) {
+	// Once set, a map flag merges later values into the current map, so an
+	// empty map makes the next run start clean
+	for _, variable := range mapFlagVariables {
+		*variable = map[string]string{}
+	}
+	resetFlags(cmd)
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		switch value := f.Value.(type) {
+		case pflag.SliceValue:
+			value.Replace(nil)
+		default:
+			if f.Value.Type() == "stringToString" {
+				if f.Value.String() != "[]" {
+					panic(fmt.Sprintf("flag --%s of %s is not reset: add its variable to mapFlagVariables", f.Name, cmd.Name()))
+				}
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
+
+func writeLocalFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, content, 0644))
+}
+
+func TestE2E_UploadSingleFile(t *testing.T) {
+	env := newE2EEnv(t)
+	content := []byte("hello from the end-to-end test")
+	localPath := filepath.Join(t.TempDir(), "hello.txt")
+	writeLocalFile(t, localPath, content)
+
+	_, err := env.run(t, "upload", localPath, "docs/", "--no-progress", "--cache-control", "max-age=60")
+	require.NoError(t, err)
+
+	object, ok := env.server.Object(e2eBucket, "docs/hello.txt")
+	require.True(t, ok, "uploaded object should exist")
+	assert.Equal(t, content, object.Body)
+	assert.Equal(t, "max-age=60", object.CacheControl)
+	assert.True(t, strings.HasPrefix(object.ContentType, "text/plain"), "content type %q", object.ContentType)
+
+	sum := md5.Sum(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), object.ETag)
+
+	// A second upload without --overwrite leaves the object alone
+	writeLocalFile(t, localPath, []byte("changed"))
+	_, err = env.run(t, "upload", localPath, "docs/", "--no-progress")
+	assert.Error(t, err)
+	object, _ = env.server.Object(e2eBucket, "docs/hello.txt")
+	assert.Equal(t, content, object.Body)
+
+	_, err = env.run(t, "upload", localPath, "docs/", "--no-progress", "--overwrite")
+	require.NoError(t, err)
+	object, _ = env.server.Object(e2eBucket, "docs/hello.txt")
+	assert.Equal(t, []byte("changed"), object.Body)
+}
+
+func TestE2E_UploadMultipart(t *testing.T) {
+	env := newE2EEnv(t)
+	content := bytes.Repeat([]byte("0123456789abcdef"), 6*1024*1024/16)
+	localPath := filepath.Join(t.TempDir(), "large.bin")
+	writeLocalFile(t, localPath, content)
+
+	_, err := env.run(t, "upload", localPath, "large.bin", "--no-progress")
+	require.NoError(t, err)
+
+	object, ok := env.server.Object(e2eBucket, "large.bin")
+	require.True(t, ok)
+	assert.Equal(t, len(content), len(object.Body))
+	assert.True(t, bytes.Equal(content, object.Body), "multipart content should be reassembled in order")
+	assert.True(t, strings.HasSuffix(object.ETag, "-2"), "expected a two part ETag, got %s", object.ETag)
+	assert.Equal(t, 2, env.server.CountOperation("UploadPart"))
+	assert.Equal(t, 0, env.server.PendingUploads())
+
+	// verify reproduces the multipart ETag with the configured part size
+	output, err := env.run(t, "verify", localPath, "large.bin")
+	require.NoError(t, err)
+	assert.Contains(t, output, "OK")
+}
+
+func TestE2E_UploadMultipartInterruptAborts(t *testing.T) {
+	env := newE2EEnv(t)
+	content := bytes.Repeat([]byte("0123456789abcdef"), 6*1024*1024/16)
+	localPath := filepath.Join(t.TempDir(), "large.bin")
+	writeLocalFile(t, localPath, content)
+
+	// Ctrl-C while the first part is in flight
+	var once sync.Once
+	env.server.OnOperation("UploadPart", func() {
+		once.Do(func() {
+			assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+			time.Sleep(200 * time.Millisecond)
+		})
+	})
+
+	_, err := env.run(t, "upload", localPath, "large.bin", "--no-progress")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, ok := env.server.Object(e2eBucket, "large.bin")
+	assert.False(t, ok)
+	assert.Equal(t, 1, env.server.CountOperation("AbortMultipartUpload"))
+	assert.Equal(t, 0, env.server.PendingUploads())
+}
+
+func TestE2E_UploadFolderAndList(t *testing.T) {
+	env := newE2EEnv(t)
+	dir := t.TempDir()
+	writeLocalFile(t, filepath.Join(dir, "a.txt"), []byte("a"))
+	writeLocalFile(t, filepath.Join(dir, "sub", "b.txt"), []byte("bb"))
+	writeLocalFile(t, filepath.Join(dir, "sub", "c.txt"), []byte("ccc"))
+
+	_, err := env.run(t, "upload", dir, "site/", "--no-progress")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"site/a.txt", "site/sub/b.txt", "site/sub/c.txt"}, env.server.Keys(e2eBucket))
+
+	output, err := env.run(t, "list", "site/", "--format", "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "site/a.txt\nsite/sub/\n", output)
+
+	output, err = env.run(t, "list", "site/", "--format", "plain", "--recursive")
+	require.NoError(t, err)
+	assert.Equal(t, "site/a.txt\nsite/sub/b.txt\nsite/sub/c.txt\n", output)
+
+	// Pagination: --limit stops after the requested number of entries
+	output, err = env.run(t, "list", "site/", "--format", "plain", "--recursive", "--limit", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "site/a.txt\nsite/sub/b.txt\n", output)
+
+	// Directories and objects are merged in name order before the limit
+	output, err = env.run(t, "list", "site/", "--format", "plain", "--limit", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "site/a.txt\n", output)
+
+	// The summary only mentions the limit when it left entries out
+	output, err = env.run(t, "list", "site/", "--format", "table", "--limit", "1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "limited to 1 entries")
+	output, err = env.run(t, "list", "site/", "--format", "table", "--limit", "2")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "limited")
+
+	// Other sort orders see every key before the limit is applied
+	output, err = env.run(t, "list", "site/", "--format", "plain", "--recursive", "--sort", "size", "--reverse", "--limit", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "site/sub/c.txt\n", output)
+}
+
+func TestE2E_Download(t *testing.T) {
+	env := newE2EEnv(t)
+	env.server.PutObject(e2eBucket, "photos/one.jpg", []byte("first"))
+	env.server.PutObject(e2eBucket, "photos/2024/two.jpg", []byte("second"))
+	env.server.PutObject(e2eBucket, "other/three.jpg", []byte("third"))
+	env.server.PutObject(e2eBucket, "photos/..hidden/four.jpg", []byte("fourth"))
+	env.server.PutObject(e2eBucket, "photos/../escape.jpg", []byte("escape"))
+
+	dir := t.TempDir()
+	_, err := env.run(t, "download", "photos/one.jpg", filepath.Join(dir, "single.jpg"), "--no-progress")
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "single.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	target := filepath.Join(dir, "all")
+	_, err = env.run(t, "download", "photos/", target, "--recursive", "--no-progress")
+	require.NoError(t, err)
+
+	content, err = os.ReadFile(filepath.Join(target, "2024", "two.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+	_, err = os.Stat(filepath.Join(target, "three.jpg"))
+	assert.True(t, os.IsNotExist(err), "objects outside the prefix should not be downloaded")
+
+	// Names starting with ".." are fine, keys escaping the target are skipped
+	content, err = os.ReadFile(filepath.Join(target, "..hidden", "four.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "fourth", string(content))
+	_, err = os.Stat(filepath.Join(dir, "escape.jpg"))
+	assert.True(t, os.IsNotExist(err), "keys resolving outside the target should be skipped")
+}
+
+func TestE2E_Delete(t *testing.T) {
+	env := newE2EEnv(t)
+	env.server.PutObject(e2eBucket, "keep.txt", []byte("keep"))
+	env.server.PutObject(e2eBucket, "single.txt", []byte("single"))
+	for i := 0; i < 5; i++ {
+		env.server.PutObject(e2eBucket, fmt.Sprintf("logs/%d.log", i), []byte("log"))
+	}
+	env.server.PutObject(e2eBucket, "logs/keep.txt", []byte("keep"))
+
+	_, err := env.run(t, "delete", "single.txt", "--force")
+	require.NoError(t, err)
+	_, exists := env.server.Object(e2eBucket, "single.txt")
+	assert.False(t, exists)
+
+	// Dry run reports without deleting
+	output, err := env.run(t, "delete", "logs/", "--recursive", "--include", "*.log", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "5 files")
+	assert.Len(t, env.server.Keys(e2eBucket), 7)
+
+	output, err = env.run(t, "delete", "logs/", "--recursive", "--include", "*.log", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Deleted 5 files")
+	assert.Equal(t, []string{"keep.txt", "logs/keep.txt"}, env.server.Keys(e2eBucket))
+	assert.Equal(t, 1, env.server.CountOperation("DeleteObject"), "recursive delete should batch with DeleteObjects")
+	assert.Equal(t, 1, env.server.CountOperation("DeleteObjects"))
+
+	// Filters match below a prefix given without the trailing slash
+	env.server.PutObject(e2eBucket, "photos/keep/a.jpg", []byte("a"))
+	env.server.PutObject(e2eBucket, "photos/drop/b.jpg", []byte("b"))
+	output, err = env.run(t, "delete", "photos", "--recursive", "--exclude", "keep/**", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Deleted 1 files")
+	assert.Equal(t, []string{"keep.txt", "logs/keep.txt", "photos/keep/a.jpg"}, env.server.Keys(e2eBucket))
+}
+
+func TestE2E_CopyPreservesMetadata(t *testing.T) {
+	env := newE2EEnv(t)
+	localPath := filepath.Join(t.TempDir(), "report.txt")
+	writeLocalFile(t, localPath, []byte("report"))
+
+	_, err := env.run(t, "upload", localPath, "reports/report.txt", "--no-progress", "--meta", "owner=alice")
+	require.NoError(t, err)
+
+	_, err = env.run(t, "cp", "reports/report.txt", "archive/report.txt")
+	require.NoError(t, err)
+
+	object, ok := env.server.Object(e2eBucket, "archive/report.txt")
+	require.True(t, ok)
+	assert.Equal(t, []byte("report"), object.Body)
+	assert.Equal(t, "alice", object.Metadata["owner"])
+}
+
+func TestE2E_MapFlagsDoNotLeak(t *testing.T) {
+	env := newE2EEnv(t)
+	dir := t.TempDir()
+	writeLocalFile(t, filepath.Join(dir, "a.txt"), []byte("a"))
+	writeLocalFile(t, filepath.Join(dir, "b.txt"), []byte("b"))
+
+	_, err := env.run(t, "upload", filepath.Join(dir, "a.txt"), "a.txt", "--no-progress", "--meta", "owner=alice")
+	require.NoError(t, err)
+	_, err = env.run(t, "upload", filepath.Join(dir, "b.txt"), "b.txt", "--no-progress", "--meta", "team=web")
+	require.NoError(t, err)
+
+	object, _ := env.server.Object(e2eBucket, "a.txt")
+	assert.Equal(t, map[string]string{"owner": "alice"}, object.Metadata)
+	object, _ = env.server.Object(e2eBucket, "b.txt")
+	assert.Equal(t, map[string]string{"team": "web"}, object.Metadata, "--meta from the previous run must not carry over")
+}
+
+func TestE2E_Meta(t *testing.T) {
+	env := newE2EEnv(t)
+	env.server.PutObject(e2eBucket, "index.html", []byte("<html></html>"))
+
+	output, err := env.run(t, "meta", "index.html", "--cache-control", "no-cache", "--meta", "owner=bob")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Updated metadata of "+e2eBucket+"/index.html")
+	assert.Regexp(t, `Cache-Control:\s+no-cache`, output)
+	assert.Contains(t, output, "x-amz-meta-owner: bob")
+	assert.NotContains(t, output, "ETag:", "the pre-copy ETag is not reported")
+
+	object, ok := env.server.Object(e2eBucket, "index.html")
+	require.True(t, ok)
+	assert.Equal(t, []byte("<html></html>"), object.Body)
+	assert.Equal(t, "no-cache", object.CacheControl)
+	assert.Equal(t, "bob", object.Metadata["owner"])
+	// One stat before the copy and one inside it, none afterwards
+	assert.Equal(t, 2, env.server.CountOperation("HeadObject"))
+
+	_, err = env.run(t, "meta", "index.html")
+	assert.ErrorContains(t, err, "nothing to change")
+}
+
+func TestE2E_RetriesTransientFailures(t *testing.T) {
+	env := newE2EEnv(t)
+	env.server.PutObject(e2eBucket, "flaky.txt", []byte("eventually"))
+
+	// Two failures are within the configured three retries
+	env.server.FailNext(2, http.StatusServiceUnavailable)
+	output, err := env.run(t, "list", "--format", "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "flaky.txt\n", output)
+
+	// Client errors are not retried
+	env.server.FailNext(1, http.StatusForbidden)
+	_, err = env.run(t, "list", "--format", "plain")
+	assert.Error(t, err)
+	assert.Equal(t, 1, env.server.CountOperation("ListObjectsV2"))
+}
+
+func TestE2E_BucketCommands(t *testing.T) {
+	env := newE2EEnv(t)
+
+	_, err := env.run(t, "bucket", "create", "archive")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive", e2eBucket}, env.server.Buckets())
+
+	output, err := env.run(t, "bucket", "list", "--format", "json")
+	require.NoError(t, err)
+	var buckets []r2.BucketInfo
+	require.NoError(t, json.Unmarshal([]byte(output), &buckets))
+	require.Len(t, buckets, 2)
+	assert.Equal(t, "archive", buckets[0].Name)
+
+	// The default bucket is marked in the text listing
+	output, err = env.run(t, "bucket", "list")
+	require.NoError(t, err)
+	assert.Contains(t, output, "* "+e2eBucket)
+
+	output, err = env.run(t, "bucket", "info", "archive", "--format", "json")
+	require.NoError(t, err)
+	var info r2.BucketInfo
+	require.NoError(t, json.Unmarshal([]byte(output), &info))
+	assert.Equal(t, "archive", info.Name)
+	require.NotNil(t, info.Policy)
+	assert.False(t, info.Policy.HasPolicy)
+	require.NotNil(t, info.Website)
+	assert.False(t, info.Website.Enabled)
+	assert.Empty(t, info.Error)
+
+	output, err = env.run(t, "bucket", "info", "archive")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Policy:   none")
+	assert.Contains(t, output, "Website:  disabled")
+
+	_, err = env.run(t, "bucket", "info", "missing")
+	assert.Error(t, err)
+}
+
+func TestE2E_BucketDelete(t *testing.T) {
+	env := newE2EEnv(t)
+	env.server.CreateBucket("old-logs")
+	for i := 0; i < 3; i++ {
+		env.server.PutObject("old-logs", fmt.Sprintf("%d.log", i), []byte("log"))
+	}
+
+	// A bucket with objects is only deleted with --force
+	_, err := env.run(t, "bucket", "delete", "old-logs")
+	assert.Error(t, err)
+	assert.Len(t, env.server.Keys("old-logs"), 3)
+
+	output, err := env.run(t, "bucket", "delete", "old-logs", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Deleted bucket old-logs")
+	assert.Equal(t, []string{e2eBucket}, env.server.Buckets())
+	assert.Equal(t, 1, env.server.CountOperation("DeleteObjects"))
+}
+
+func TestE2E_PeekArchive(t *testing.T) {
+	env := newE2EEnv(t)
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, body := range map[string]string{"docs/readme.txt": "read me", "main.go": "package main"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	env.server.PutObject(e2eBucket, "backups/site.zip", zipBuf.Bytes())
+
+	var tarBuf bytes.Buffer
+	gz := gzip.NewWriter(&tarBuf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "app.log", Size: 5, Mode: 0644}))
+	_, err := tw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	env.server.PutObject(e2eBucket, "logs/app.tar.gz", tarBuf.Bytes())
+
+	output, err := env.run(t, "peek", "backups/site.zip", "--format", "json")
+	require.NoError(t, err)
+	var entries []struct {
+		Name string `json:"name"`
+		Size int64  `json:"size"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &entries))
+	require.Len(t, entries, 2)
+	names := []string{entries[0].Name, entries[1].Name}
+	assert.ElementsMatch(t, []string{"docs/readme.txt", "main.go"}, names)
+	assert.Equal(t, 1, env.server.CountOperation("GetObject"), "a small zip is listed with a single ranged read")
+
+	output, err = env.run(t, "peek", "logs/app.tar.gz")
+	require.NoError(t, err)
+	assert.Contains(t, output, "app.log")
+	assert.Contains(t, output, "1 files, 5B uncompressed")
+
+	// Extracting into a directory keeps the entry's base name
+	dir := t.TempDir()
+	output, err = env.run(t, "peek", "backups/site.zip", "--extract", "docs/readme.txt", "-o", dir)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Extracted docs/readme.txt")
+	content, err := os.ReadFile(filepath.Join(dir, "readme.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "read me", string(content))
+
+	// Extracting again keeps the existing file unless asked otherwise
+	output, err = env.run(t, "peek", "backups/site.zip", "--extract", "docs/readme.txt", "-o", dir)
+	require.NoError(t, err)
+	assert.Contains(t, output, filepath.Join(dir, "readme (1).txt"))
+	output, err = env.run(t, "peek", "backups/site.zip", "--extract", "docs/readme.txt", "-o", dir, "--conflict", "skip")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Skipped docs/readme.txt")
+
+	output, err = env.run(t, "peek", "logs/app.tar.gz", "--extract", "app.log", "-o", "-")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", output)
+
+	_, err = env.run(t, "peek", "backups/site.zip", "--extract", "missing.txt", "-o", dir)
+	assert.Error(t, err)
+	_, err = env.run(t, "peek", "photos/cat.jpg")
+	assert.Error(t, err)
+}
+
+func TestE2E_CacheCommands(t *testing.T) {
+	env := newE2EEnv(t)
+	dir := t.TempDir()
+	t.Setenv("R2CLI_CACHE_DIR", dir)
+
+	// Populate the cache the way the TUI does, plus a file the index does not know
+	cache := image.NewCacheManager(dir, 100*1024*1024)
+	for i, size := range []int{100, 200} {
+		source := filepath.Join(t.TempDir(), fmt.Sprintf("photo%d.jpg", i))
+		writeLocalFile(t, source, make([]byte, size))
+		_, err := cache.Put(fmt.Sprintf("%s/photo%d.jpg@etag", e2eBucket, i), source)
+		require.NoError(t, err)
+	}
+	_, _, err := cache.Get(e2eBucket + "/photo0.jpg@etag")
+	require.NoError(t, err)
+	cache.StopAutoCleanup()
+	// A file named like a cache entry but missing from the index, and a user file
+	writeLocalFile(t, filepath.Join(dir, "0123456789abcdef0123456789abcdef.bin"), make([]byte, 50))
+	writeLocalFile(t, filepath.Join(dir, "notes.txt"), []byte("keep me"))
+
+	output, err := env.run(t, "cache", "stats", "--format", "json")
+	require.NoError(t, err)
+	var stats struct {
+		Dir           string  `json:"dir"`
+		CleanupPolicy string  `json:"cleanup_policy"`
+		Files         int     `json:"files"`
+		Size          int64   `json:"size"`
+		HitRate       float64 `json:"hit_rate"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &stats))
+	assert.Equal(t, dir, stats.Dir)
+	assert.Equal(t, "lru", stats.CleanupPolicy)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, int64(300), stats.Size)
+	assert.Equal(t, float64(100), stats.HitRate)
+
+	output, err = env.run(t, "cache", "stats")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Files:        2")
+	assert.Contains(t, output, "300B / 100.0MB")
+
+	output, err = env.run(t, "cache", "compact")
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 files (300B) -> 2 files (300B)")
+	_, statErr := os.Stat(filepath.Join(dir, "0123456789abcdef0123456789abcdef.bin"))
+	assert.True(t, os.IsNotExist(statErr), "compact removes cache files outside the index")
+
+	output, err = env.run(t, "cache", "clear")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Removed 2 files (300B)")
+
+	output, err = env.run(t, "cache", "stats", "--format", "json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), &stats))
+	assert.Zero(t, stats.Files)
+
+	// Files the cache did not create are never removed
+	content, err := os.ReadFile(filepath.Join(dir, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(content))
+
+	// The home directory and the filesystem root are rejected as cache dirs
+	for _, unsafeDir := range []string{"~", "~/", "/"} {
+		t.Setenv("R2CLI_CACHE_DIR", unsafeDir)
+		_, err = env.run(t, "cache", "clear")
+		assert.ErrorContains(t, err, "cache config validation failed", "dir %q", unsafeDir)
+	}
+	t.Setenv("R2CLI_CACHE_DIR", dir)
+
+	t.Setenv("R2CLI_CACHE_CLEANUP_POLICY", "fifo")
+	_, err = env.run(t, "cache", "stats")
+	assert.Error(t, err)
+}
+
+func TestE2E_Sync(t *testing.T) {
+	env := newE2EEnv(t)
+	localDir := t.TempDir()
+	writeLocalFile(t, filepath.Join(localDir, "index.html"), []byte("<html></html>"))
+	writeLocalFile(t, filepath.Join(localDir, "css", "site.css"), []byte("body {}"))
+	env.server.PutObject(e2eBucket, "site/stale.txt", []byte("stale"))
+	env.appendConfig(t, `[[upload.header_rules]]
+pattern = "*.html"
+cache_control = "no-cache"
+metadata = { deploy = "sync" }
+`)
+
+	// Dry run plans without touching the bucket
+	output, err := env.run(t, "sync", localDir, "site", "--delete", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 to upload, 1 to delete")
+	assert.Equal(t, []string{"site/stale.txt"}, env.server.Keys(e2eBucket))
+
+	output, err = env.run(t, "sync", localDir, "site", "--delete", "--no-progress")
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 uploaded, 1 deleted, 0 unchanged")
+	assert.Equal(t, []string{"site/css/site.css", "site/index.html"}, env.server.Keys(e2eBucket))
+
+	// Synced objects get the configured header rules
+	object, _ := env.server.Object(e2eBucket, "site/index.html")
+	assert.Equal(t, "no-cache", object.CacheControl)
+	assert.Equal(t, "sync", object.Metadata["deploy"])
+	object, _ = env.server.Object(e2eBucket, "site/css/site.css")
+	assert.Empty(t, object.CacheControl)
+
+	// Only the changed file is uploaded again
+	writeLocalFile(t, filepath.Join(localDir, "index.html"), []byte("<html>!</html>"))
+	output, err = env.run(t, "sync", localDir, "site/", "--no-progress")
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 uploaded, 0 deleted, 1 unchanged")
+	object, _ = env.server.Object(e2eBucket, "site/index.html")
+	assert.Equal(t, "<html>!</html>", string(object.Body))
+
+	// Download mirrors the prefix and removes local extras with --delete
+	backupDir := t.TempDir()
+	writeLocalFile(t, filepath.Join(backupDir, "old.txt"), []byte("old"))
+	output, err = env.run(t, "sync", "--download", "site/", backupDir, "--delete", "--no-progress")
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 downloaded, 1 deleted, 0 unchanged")
+	content, err := os.ReadFile(filepath.Join(backupDir, "css", "site.css"))
+	require.NoError(t, err)
+	assert.Equal(t, "body {}", string(content))
+	_, statErr := os.Stat(filepath.Join(backupDir, "old.txt"))
+	assert.True(t, os.IsNotExist(statErr))
+
+	output, err = env.run(t, "sync", "--download", "site/", backupDir)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Everything up to date (2 files)")
+}
@@ -1,19 +1,38 @@
 package cmd
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
 	"github.com/HaiFongPan/r2s3-cli/internal/config"
 	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 	"github.com/HaiFongPan/r2s3-cli/internal/tui"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
 )
 
 var (
-	listBucket string
-	listLimit  int64
+	listBucket      string
+	listLimit       int64
+	listInteractive bool
+	listFormat      string
+	listRecursive   bool
+	listBytes       bool
+	listSort        string
+	listReverse     bool
 )
 
 // listCmd represents the list command
@@ -22,11 +41,25 @@ var listCmd = &cobra.Command{
 	Short: "List files in the R2 bucket",
 	Long: `List files in the specified R2 bucket with optional prefix filtering.
 By default, launches interactive TUI browser. Use --interactive=false for table output.
+When stdout is not a terminal (e.g. piped to another command), plain output is used.
+
+Without --recursive, keys are grouped into "directories" at the next "/" after
+the prefix, like ls. With --recursive every object below the prefix is listed.
+
+Output formats:
+  table   aligned columns with size, modification time and key
+  plain   one key per line
+  json    array of objects with key, size, last_modified and is_dir
+  csv     key,size,last_modified,is_dir with a header row
 
 Examples:
-  r2s3-cli list                    # Launch interactive browser
-  r2s3-cli list photos/            # Browse files with 'photos/' prefix  
-  r2s3-cli list --interactive=false # Show table output`,
+  r2s3-cli list                              # Launch interactive browser
+  r2s3-cli list photos/                      # Browse files with 'photos/' prefix
+  r2s3-cli list --interactive=false          # Show table output
+  r2s3-cli list photos/ -r --format json     # All objects below photos/ as JSON
+  r2s3-cli list --format csv --bytes         # CSV with exact byte sizes
+  r2s3-cli list -r --sort size --reverse     # Largest files first
+  r2s3-cli list -r | grep .jpg               # Plain keys when piped`,
 	RunE: listFiles,
 }
 
@@ -34,7 +67,21 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().StringVarP(&listBucket, "bucket", "b", "", "bucket name (overrides config)")
-	listCmd.Flags().Int64VarP(&listLimit, "limit", "l", 1000, "maximum number of files to list")
+	listCmd.Flags().Int64VarP(&listLimit, "limit", "l", 1000, "maximum number of entries to list (0 for no limit)")
+	listCmd.Flags().BoolVarP(&listInteractive, "interactive", "i", true, "launch the interactive browser when stdout is a terminal")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "output format: table, plain, json, csv (implies --interactive=false)")
+	listCmd.Flags().BoolVarP(&listRecursive, "recursive", "r", false, "list all objects below the prefix instead of grouping by directory")
+	listCmd.Flags().BoolVar(&listBytes, "bytes", false, "show sizes in bytes instead of human readable units")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "sort by: name, size, time")
+	listCmd.Flags().BoolVar(&listReverse, "reverse", false, "reverse the sort order")
+}
+
+// listEntry is a single object or common prefix in the listing output
+type listEntry struct {
+	Key          string     `json:"key"`
+	Size         int64      `json:"size"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	IsDir        bool       `json:"is_dir"`
 }
 
 func listFiles(cmd *cobra.Command, args []string) error {
@@ -53,12 +100,236 @@ func listFiles(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create R2 client
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	if err != nil {
 		return fmt.Errorf("failed to create R2 client: %w", err)
 	}
-	// Launch interactive mode if requested
-	return runInteractiveBrowser(client, cfg, bucketName, prefix)
+
+	format := listFormat
+	if format == "" {
+		if listInteractive && isTerminal(os.Stdout) {
+			// Launch interactive mode if requested
+			return runInteractiveBrowser(client, cfg, bucketName, prefix)
+		}
+
+		format = "table"
+		if !isTerminal(os.Stdout) {
+			format = "plain"
+		}
+	}
+
+	if format != "table" && format != "plain" && format != "json" && format != "csv" {
+		return fmt.Errorf("invalid format: %s (use: table, plain, json, csv)", format)
+	}
+	if listSort != "name" && listSort != "size" && listSort != "time" {
+		return fmt.Errorf("invalid sort: %s (use: name, size, time)", listSort)
+	}
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Entries arrive in name order, so any other order needs the full listing
+	// before --limit can be applied. One extra entry shows whether it cut anything.
+	fetchLimit := int64(0)
+	if listLimit > 0 && listSort == "name" && !listReverse {
+		fetchLimit = listLimit + 1
+	}
+
+	entries, err := listEntries(ctx, client, bucketName, prefix, listRecursive, fetchLimit)
+	if err != nil {
+		return err
+	}
+	truncated := listLimit > 0 && int64(len(entries)) > listLimit
+
+	entries, err = sortAndLimitEntries(entries, listSort, listReverse, listLimit)
+	if err != nil {
+		return err
+	}
+
+	return printEntries(os.Stdout, entries, format, truncated)
+}
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// listEntries pages through the bucket until limit entries are collected (0 for
+// no limit), in name order. Unless recursive, common prefixes are returned as
+// directory entries.
+func listEntries(ctx context.Context, client *r2.Client, bucketName, prefix string, recursive bool, limit int64) ([]listEntry, error) {
+	options := r2.ListOptions{Prefix: prefix}
+	if !recursive {
+		options.Delimiter = "/"
+	}
+
+	var entries []listEntry
+	limitReached := func() bool {
+		return limit > 0 && int64(len(entries)) >= limit
+	}
+
+	paginator := r2.NewListPaginator(client.Storage(), bucketName, options)
+	for paginator.HasMorePages() && !limitReached() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		}
+
+		for _, entry := range pageEntries(page) {
+			if limitReached() {
+				break
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// pageEntries merges the directories and objects of a page in key order.
+// Both are sorted already and pages follow each other in key order.
+func pageEntries(page *r2.ListPage) []listEntry {
+	entries := make([]listEntry, 0, len(page.Prefixes)+len(page.Objects))
+	prefixes, objects := page.Prefixes, page.Objects
+	for len(prefixes) > 0 || len(objects) > 0 {
+		if len(objects) == 0 || len(prefixes) > 0 && prefixes[0] < objects[0].Key {
+			entries = append(entries, listEntry{Key: prefixes[0], IsDir: true})
+			prefixes = prefixes[1:]
+			continue
+		}
+		obj := objects[0]
+		entries = append(entries, listEntry{
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: &obj.LastModified,
+		})
+		objects = objects[1:]
+	}
+	return entries
+}
+
+// sortAndLimitEntries sorts entries and keeps the first limit of them (0 for no limit)
+func sortAndLimitEntries(entries []listEntry, by string, reverse bool, limit int64) ([]listEntry, error) {
+	if err := sortEntries(entries, by, reverse); err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(entries)) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// sortEntries sorts entries in place by name, size or time
+func sortEntries(entries []listEntry, by string, reverse bool) error {
+	var less func(a, b listEntry) bool
+	switch by {
+	case "name":
+		less = func(a, b listEntry) bool { return a.Key < b.Key }
+	case "size":
+		less = func(a, b listEntry) bool {
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+			return a.Key < b.Key
+		}
+	case "time":
+		less = func(a, b listEntry) bool {
+			at, bt := aws.ToTime(a.LastModified), aws.ToTime(b.LastModified)
+			if !at.Equal(bt) {
+				return at.Before(bt)
+			}
+			return a.Key < b.Key
+		}
+	default:
+		return fmt.Errorf("invalid sort: %s (use: name, size, time)", by)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if reverse {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+	return nil
+}
+
+// formatEntrySize formats a size according to --bytes
+func formatEntrySize(size int64) string {
+	if listBytes {
+		return strconv.FormatInt(size, 10)
+	}
+	return utils.FormatBytes(size)
+}
+
+// printEntries writes entries to w in the given format. truncated reports that
+// --limit left out further entries.
+func printEntries(w io.Writer, entries []listEntry, format string, truncated bool) error {
+	switch format {
+	case "plain":
+		for _, entry := range entries {
+			fmt.Fprintln(w, entry.Key)
+		}
+		return nil
+
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if entries == nil {
+			entries = []listEntry{}
+		}
+		return encoder.Encode(entries)
+
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"key", "size", "last_modified", "is_dir"})
+		for _, entry := range entries {
+			modified := ""
+			if entry.LastModified != nil {
+				modified = entry.LastModified.UTC().Format(time.RFC3339)
+			}
+			writer.Write([]string{entry.Key, formatEntrySize(entry.Size), modified, strconv.FormatBool(entry.IsDir)})
+		}
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SIZE\tMODIFIED\tKEY")
+
+		var objects, dirs int
+		var totalSize int64
+		for _, entry := range entries {
+			if entry.IsDir {
+				dirs++
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", "DIR", "-", entry.Key)
+				continue
+			}
+			objects++
+			totalSize += entry.Size
+			fmt.Fprintf(tw, "%s\t%s\t%s\n",
+				formatEntrySize(entry.Size),
+				aws.ToTime(entry.LastModified).Local().Format("2006-01-02 15:04"),
+				entry.Key)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		summary := fmt.Sprintf("\n%d objects (%s)", objects, formatEntrySize(totalSize))
+		if dirs > 0 {
+			summary += fmt.Sprintf(", %d directories", dirs)
+		}
+		if truncated {
+			summary += fmt.Sprintf(" - limited to %d entries, use --limit to change", listLimit)
+		}
+		fmt.Fprintln(w, summary)
+		return nil
+	}
 }
 
 func runInteractiveBrowser(client *r2.Client, cfg *config.Config, bucketName, prefix string) error {
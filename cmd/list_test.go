@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortAndLimitEntries(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Sizes and times grow with the index, keys grow in reverse
+	newEntries := func() []listEntry {
+		entries := make([]listEntry, 10)
+		for i := range entries {
+			modified := base.Add(time.Duration(i) * time.Hour)
+			entries[i] = listEntry{Key: fmt.Sprintf("file-%02d", 9-i), Size: int64(i * 100), LastModified: &modified}
+		}
+		return entries
+	}
+	keys := func(entries []listEntry) []string {
+		result := make([]string, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.Key
+		}
+		return result
+	}
+
+	tests := []struct {
+		by      string
+		reverse bool
+		limit   int64
+		want    []string
+	}{
+		{"name", false, 3, []string{"file-00", "file-01", "file-02"}},
+		{"name", true, 2, []string{"file-09", "file-08"}},
+		{"size", true, 3, []string{"file-00", "file-01", "file-02"}},
+		{"size", false, 2, []string{"file-09", "file-08"}},
+		{"time", true, 1, []string{"file-00"}},
+		{"size", false, 0, keys(newEntries())},
+		{"size", false, 50, keys(newEntries())},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_reverse=%t_limit=%d", tt.by, tt.reverse, tt.limit), func(t *testing.T) {
+			entries, err := sortAndLimitEntries(newEntries(), tt.by, tt.reverse, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, keys(entries))
+		})
+	}
+
+	_, err := sortAndLimitEntries(newEntries(), "owner", false, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	metaBucket             string
+	metaContentType        string
+	metaCacheControl       string
+	metaContentDisposition string
+	metaSet                map[string]string
+	metaRemove             []string
+	metaClear              bool
+)
+
+// metaCmd represents the meta command
+var metaCmd = &cobra.Command{
+	Use:   "meta <remote-path>",
+	Short: "Change an object's headers and user metadata",
+	Long: `Change the headers and user metadata of an object in place. The object is
+copied onto itself with MetadataDirective=REPLACE, so its content stays the
+same. The ETag may change: objects uploaded in parts, and objects over 5GB
+(which are copied in parts), get a new multipart ETag. Headers that are not
+given keep their current value; pass an empty string to remove one.
+
+Examples:
+  r2s3-cli meta index.html --cache-control no-cache
+  r2s3-cli meta report.pdf --content-disposition 'attachment; filename="report.pdf"'
+  r2s3-cli meta logo.svg --content-type image/svg+xml
+  r2s3-cli meta photo.jpg --meta owner=alice,album=2024   # Add or update metadata
+  r2s3-cli meta photo.jpg --remove-meta album             # Remove one key
+  r2s3-cli meta photo.jpg --clear-meta --meta owner=bob   # Replace all metadata`,
+	Args: cobra.ExactArgs(1),
+	RunE: editObjectMetadata,
+}
+
+func init() {
+	rootCmd.AddCommand(metaCmd)
+
+	metaCmd.Flags().StringVarP(&metaBucket, "bucket", "b", "", "bucket name (overrides config)")
+	metaCmd.Flags().StringVar(&metaContentType, "content-type", "", "set the Content-Type")
+	metaCmd.Flags().StringVar(&metaCacheControl, "cache-control", "", "set the Cache-Control header (empty removes it)")
+	metaCmd.Flags().StringVar(&metaContentDisposition, "content-disposition", "", "set the Content-Disposition header (empty removes it)")
+	metaCmd.Flags().StringToStringVar(&metaSet, "meta", nil, "add or update x-amz-meta-* values (key=value,...)")
+	metaCmd.Flags().StringSliceVar(&metaRemove, "remove-meta", nil, "remove x-amz-meta-* keys")
+	metaCmd.Flags().BoolVar(&metaClear, "clear-meta", false, "remove all existing user metadata before applying --meta")
+}
+
+func editObjectMetadata(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	if !flags.Changed("content-type") && !flags.Changed("cache-control") && !flags.Changed("content-disposition") &&
+		!flags.Changed("meta") && !flags.Changed("remove-meta") && !metaClear {
+		return fmt.Errorf("nothing to change: use --content-type, --cache-control, --content-disposition, --meta, --remove-meta or --clear-meta")
+	}
+
+	cfg := GetConfig()
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+	storage := client.Storage()
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if metaBucket != "" {
+		bucketName = metaBucket
+	}
+
+	ctx := context.Background()
+	remotePath := args[0]
+
+	info, err := utils.StatObject(ctx, storage, bucketName, remotePath)
+	if err != nil {
+		return err
+	}
+
+	options := &utils.CopyOptions{
+		ContentType: metaContentType,
+		Concurrency: cfg.Upload.MultipartConcurrency,
+	}
+	if flags.Changed("cache-control") {
+		options.CacheControl = aws.String(metaCacheControl)
+	}
+	if flags.Changed("content-disposition") {
+		options.ContentDisposition = aws.String(metaContentDisposition)
+	}
+
+	// Metadata is always sent in full, so start from the current values
+	metadata := info.CopyMetadata()
+	if metaClear {
+		metadata = map[string]string{}
+	}
+	for _, key := range metaRemove {
+		key = utils.NormalizeMetadataKey(key)
+		if _, ok := metadata[key]; !ok {
+			return fmt.Errorf("metadata key %q not found on %s", key, remotePath)
+		}
+		delete(metadata, key)
+	}
+	for key, value := range metaSet {
+		metadata[utils.NormalizeMetadataKey(key)] = value
+	}
+	options.Metadata = metadata
+
+	if err := utils.NewObjectCopier(storage).UpdateMetadata(ctx, bucketName, remotePath, options); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated metadata of %s/%s\n\n", bucketName, remotePath)
+
+	// Show the values that were applied; the copy gives the object a new
+	// modification time and possibly a new ETag, so those are left out
+	updated := *info
+	updated.LastModified = time.Time{}
+	updated.ETag = ""
+	if metaContentType != "" {
+		updated.ContentType = metaContentType
+	}
+	if options.CacheControl != nil {
+		updated.CacheControl = *options.CacheControl
+	}
+	if options.ContentDisposition != nil {
+		updated.ContentDisposition = *options.ContentDisposition
+	}
+	updated.Metadata = metadata
+	return printObjectInfo(os.Stdout, &updated, "text")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var moveFlags transferFlags
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:   "mv <source> <destination>",
+	Short: "Move or rename objects within R2",
+	Long: `Move an object or a whole prefix by copying it on the server side and then
+deleting the source. Sources are only deleted after their copy succeeded.
+
+Examples:
+  r2s3-cli mv photos/cat.jpg photos/kitty.jpg            # Rename a file
+  r2s3-cli mv inbox/report.pdf reports/                  # Move into a folder
+  r2s3-cli mv photos/2023/ archive/2023/ -r              # Move a whole prefix
+  r2s3-cli mv tmp/ tmp/ -r --dest-bucket scratch         # Move to another bucket
+  r2s3-cli mv uploads/ done/ -r --exclude "*.part" --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTransfer(cmd, args, &moveFlags, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+	addTransferFlags(mvCmd, &moveFlags)
+}
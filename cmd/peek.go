@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	peekBucket   string
+	peekFormat   string
+	peekExtract  string
+	peekOutput   string
+	peekConflict string
+)
+
+// peekCmd represents the peek command
+var peekCmd = &cobra.Command{
+	Use:   "peek <remote-path>",
+	Short: "List the entries of a remote archive",
+	Long: `List the files inside a .zip, .tar or .tar.gz object without downloading it.
+
+Zip archives are read with ranged requests: only the central directory at the
+end of the archive is fetched. Tar archives are streamed and only their
+headers are parsed.
+
+A single entry can be extracted with --extract. It is written to --output, or
+to its base name in the current directory; use "-o -" to write to stdout.
+An existing local file is kept and the entry is written to "name (N).ext"
+unless --conflict says otherwise.
+
+Examples:
+  r2s3-cli peek backups/site.zip                              # List entries
+  r2s3-cli peek logs/2024-05.tar.gz --format json             # Machine-readable output
+  r2s3-cli peek backups/site.zip --extract docs/readme.txt    # Extract one entry
+  r2s3-cli peek backups/site.zip --extract a.txt -o -         # Extract to stdout`,
+	Args: cobra.ExactArgs(1),
+	RunE: peekArchive,
+}
+
+func init() {
+	rootCmd.AddCommand(peekCmd)
+
+	peekCmd.Flags().StringVarP(&peekBucket, "bucket", "b", "", "bucket name (overrides config)")
+	peekCmd.Flags().StringVar(&peekFormat, "format", "text", "output format: text, json")
+	peekCmd.Flags().StringVar(&peekExtract, "extract", "", "extract a single entry instead of listing")
+	peekCmd.Flags().StringVarP(&peekOutput, "output", "o", "", "local path for --extract (\"-\" for stdout)")
+	peekCmd.Flags().StringVar(&peekConflict, "conflict", "rename", "policy for an existing local file (rename, skip, overwrite)")
+}
+
+func peekArchive(cmd *cobra.Command, args []string) error {
+	if peekFormat != "text" && peekFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", peekFormat)
+	}
+	if peekOutput != "" && peekExtract == "" {
+		return fmt.Errorf("--output requires --extract")
+	}
+
+	key := args[0]
+	if _, err := utils.DetectArchiveFormat(key); err != nil {
+		return err
+	}
+
+	cfg := GetConfig()
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if peekBucket != "" {
+		bucketName = peekBucket
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reader := utils.NewArchiveReader(client.Storage(), bucketName)
+
+	if peekExtract != "" {
+		policy, err := utils.ParseConflictPolicy(peekConflict)
+		if err != nil {
+			return err
+		}
+		return extractArchiveEntry(ctx, reader, key, peekExtract, peekOutput, policy)
+	}
+
+	entries, err := reader.List(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if peekFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	var total int64
+	files := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SIZE\tMODIFIED\tNAME")
+	for _, entry := range entries {
+		size := utils.FormatBytes(entry.Size)
+		if entry.IsDir {
+			size = "-"
+		} else {
+			total += entry.Size
+			files++
+		}
+		modified := ""
+		if !entry.Modified.IsZero() {
+			modified = entry.Modified.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", size, modified, entry.Name)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("\n%d files, %s uncompressed\n", files, utils.FormatBytes(total))
+	}
+	return nil
+}
+
+// extractArchiveEntry writes one entry of an archive to output, or to the
+// entry's base name in the current directory when output is empty
+func extractArchiveEntry(ctx context.Context, reader *utils.ArchiveReader, key, entry, output string, policy utils.ConflictPolicy) error {
+	if output == "-" {
+		_, err := reader.Extract(ctx, key, entry, os.Stdout)
+		return err
+	}
+
+	if output == "" {
+		output = path.Base(entry)
+	}
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		output = filepath.Join(output, path.Base(entry))
+	}
+
+	output, written, err := reader.ExtractToFile(ctx, key, entry, output, policy)
+	if errors.Is(err, os.ErrExist) {
+		if !quiet {
+			fmt.Printf("Skipped %s: %s already exists\n", entry, output)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Printf("Extracted %s (%s) to %s\n", entry, utils.FormatBytes(written), output)
+	}
+	return nil
+}
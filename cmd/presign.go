@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	presignBucket             string
+	presignExpires            string
+	presignMethod             string
+	presignContentDisposition string
+	presignFormat             string
+)
+
+// presignCmd represents the presign command
+var presignCmd = &cobra.Command{
+	Use:   "presign <remote-path>",
+	Short: "Create a presigned download or upload URL",
+	Long: `Create a presigned URL that grants temporary access to a single object
+without sharing credentials. GET URLs download the object; PUT URLs let
+someone else upload to that key until the URL expires.
+
+The URL is printed on stdout; --format json also reports the method, bucket,
+key and expiry time.
+
+Examples:
+  r2s3-cli presign report.pdf                         # Download URL valid for 1 hour
+  r2s3-cli presign report.pdf --expires 7d            # Valid for 7 days (the maximum)
+  r2s3-cli presign report.pdf --content-disposition 'attachment; filename="Q3.pdf"'
+  r2s3-cli presign inbox/upload.zip --method PUT --expires 30m
+  r2s3-cli presign inbox/photo.jpg --method PUT --format json
+
+Upload with a presigned PUT URL:
+  curl -X PUT -T photo.jpg -H "Content-Type: image/jpeg" "<url>"`,
+	Args: cobra.ExactArgs(1),
+	RunE: presignObject,
+}
+
+func init() {
+	rootCmd.AddCommand(presignCmd)
+
+	presignCmd.Flags().StringVarP(&presignBucket, "bucket", "b", "", "bucket name (overrides config)")
+	presignCmd.Flags().StringVarP(&presignExpires, "expires", "e", "1h", "URL lifetime, e.g. 15m, 12h, 7d (max 7d)")
+	presignCmd.Flags().StringVarP(&presignMethod, "method", "m", http.MethodGet, "HTTP method the URL is valid for: GET or PUT")
+	presignCmd.Flags().StringVar(&presignContentDisposition, "content-disposition", "", "override response-content-disposition (GET only)")
+	presignCmd.Flags().StringVar(&presignFormat, "format", "text", "output format: text, json")
+}
+
+func presignObject(cmd *cobra.Command, args []string) error {
+	if presignFormat != "text" && presignFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", presignFormat)
+	}
+
+	expires, err := utils.ParseExpiry(presignExpires)
+	if err != nil {
+		return err
+	}
+
+	method := strings.ToUpper(presignMethod)
+	switch {
+	case method != http.MethodGet && method != http.MethodPut:
+		return fmt.Errorf("invalid method %q: must be GET or PUT", presignMethod)
+	case method == http.MethodPut && presignContentDisposition != "":
+		return fmt.Errorf("--content-disposition only applies to GET URLs")
+	}
+
+	cfg := GetConfig()
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if presignBucket != "" {
+		bucketName = presignBucket
+	}
+
+	generator := utils.NewURLGenerator(client.Storage(), cfg, bucketName)
+	presigned, err := generator.Presign(context.Background(), args[0], utils.PresignOptions{
+		Method:             method,
+		Expires:            expires,
+		ContentDisposition: presignContentDisposition,
+	})
+	if err != nil {
+		return err
+	}
+
+	if presignFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(presigned)
+	}
+
+	fmt.Println(presigned.URL)
+	if !quiet {
+		// Keep stdout to the URL alone so it can be piped
+		fmt.Fprintf(os.Stderr, "%s %s/%s, expires %s (in %s)\n", presigned.Method, bucketName, args[0],
+			presigned.ExpiresAt.Local().Format("2006-01-02 15:04:05"), utils.FormatExpiry(expires))
+	}
+	return nil
+}
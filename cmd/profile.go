@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage connection profiles",
+	Long: `Manage connection profiles defined as [profiles.<name>] sections in the config file.
+
+Each profile holds its own R2 settings (credentials, endpoint, default bucket,
+custom domains); settings it leaves out are taken from the [r2] section, which
+is also available as the "default" profile. The main and last used bucket are
+remembered per profile.
+
+The active profile is chosen by --profile, then R2CLI_PROFILE, then the
+profile saved with "profile use".
+
+Examples:
+  r2s3-cli profile list          # Show all profiles
+  r2s3-cli profile use work      # Use the "work" profile from now on
+  r2s3-cli profile use default   # Go back to the [r2] section
+  r2s3-cli --profile work list   # Use a profile for a single command`,
+	// Profiles must stay manageable even when the active one fails validation
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List connection profiles",
+	Args:  cobra.NoArgs,
+	RunE:  listProfiles,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the default connection profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  useProfile,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+}
+
+func listProfiles(cmd *cobra.Command, args []string) error {
+	profiles, err := config.ListProfiles(cfgFile, profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tBUCKET\tENDPOINT")
+	for _, profile := range profiles {
+		marker := " "
+		if profile.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\n", marker, profile.Name, profile.R2.BucketName, profile.R2.EndpointURL())
+	}
+	return w.Flush()
+}
+
+func useProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	profiles, err := config.ListProfiles(cfgFile, profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+
+	found := false
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Name, name) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("profile %q not found in config", name)
+	}
+
+	if err := config.SaveActiveProfile(name); err != nil {
+		return fmt.Errorf("failed to save active profile: %w", err)
+	}
+
+	fmt.Printf("Switched to profile: %s\n", name)
+	return nil
+}
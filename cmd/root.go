@@ -15,6 +15,7 @@ import (
 
 var (
 	cfgFile      string
+	profileName  string
 	verbose      bool
 	quiet        bool
 	globalConfig *config.Config
@@ -31,7 +32,9 @@ configuration management using TOML files, environment variables, and CLI flags.
 Example usage:
   r2s3-cli # Interactive file browser
   r2s3-cli upload image.jpg
+  r2s3-cli download photos/ --recursive
   r2s3-cli list photos/
+  r2s3-cli mv photos/a.jpg photos/b.jpg
   r2s3-cli delete old-file.jpg`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		return initConfig()
@@ -53,6 +56,7 @@ func Execute() {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is ~/.r2s3-cli/config.toml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "connection profile from [profiles.<name>] (env: R2CLI_PROFILE)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "enable quiet mode")
 }
@@ -60,7 +64,7 @@ func init() {
 // initConfig reads in config file and ENV variables if set.
 func initConfig() error {
 	var err error
-	globalConfig, err = config.Load(cfgFile)
+	globalConfig, err = config.Load(cfgFile, profileName)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -124,7 +128,7 @@ func runDefaultInteractiveList() error {
 	cfg := globalConfig
 
 	// Create R2 client
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	if err != nil {
 		return fmt.Errorf("failed to create R2 client: %w", err)
 	}
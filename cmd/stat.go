@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	statBucket string
+	statFormat string
+)
+
+// statCmd represents the stat command
+var statCmd = &cobra.Command{
+	Use:   "stat <remote-path>",
+	Short: "Show an object's headers and metadata",
+	Long: `Show the headers and user metadata stored on an object, as returned by
+HeadObject: size, ETag, Content-Type, Cache-Control, Content-Disposition,
+Content-Encoding and every x-amz-meta-* value.
+
+Examples:
+  r2s3-cli stat index.html                  # Show headers of a file
+  r2s3-cli stat photos/cat.jpg -b media     # From a specific bucket
+  r2s3-cli stat index.html --format json    # Machine-readable output
+
+Use "r2s3-cli meta" to change headers or metadata.`,
+	Args: cobra.ExactArgs(1),
+	RunE: statObject,
+}
+
+func init() {
+	rootCmd.AddCommand(statCmd)
+
+	statCmd.Flags().StringVarP(&statBucket, "bucket", "b", "", "bucket name (overrides config)")
+	statCmd.Flags().StringVar(&statFormat, "format", "text", "output format: text, json")
+}
+
+func statObject(cmd *cobra.Command, args []string) error {
+	if statFormat != "text" && statFormat != "json" {
+		return fmt.Errorf("invalid format %q: must be text or json", statFormat)
+	}
+
+	cfg := GetConfig()
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+	storage := client.Storage()
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if statBucket != "" {
+		bucketName = statBucket
+	}
+
+	info, err := utils.StatObject(context.Background(), storage, bucketName, args[0])
+	if err != nil {
+		return err
+	}
+
+	return printObjectInfo(os.Stdout, info, statFormat)
+}
+
+// printObjectInfo writes info to w as aligned text or JSON
+func printObjectInfo(w io.Writer, info *utils.ObjectInfo, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%-21s %s\n", name+":", value)
+		}
+	}
+	field("Key", info.Key)
+	field("Size", fmt.Sprintf("%s (%d bytes)", utils.FormatBytes(info.Size), info.Size))
+	if !info.LastModified.IsZero() {
+		field("Last-Modified", info.LastModified.Local().Format(time.RFC3339))
+	}
+	field("ETag", info.ETag)
+	field("Content-Type", info.ContentType)
+	field("Cache-Control", info.CacheControl)
+	field("Content-Disposition", info.ContentDisposition)
+	field("Content-Encoding", info.ContentEncoding)
+	field("Storage-Class", info.StorageClass)
+
+	if len(info.Metadata) > 0 {
+		fmt.Fprintln(w, "Metadata:")
+		for _, key := range info.MetadataKeys() {
+			fmt.Fprintf(w, "  x-amz-meta-%s: %s\n", key, info.Metadata[key])
+		}
+	}
+	return nil
+}
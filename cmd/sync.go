@@ -0,0 +1,509 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	syncBucket      string
+	syncDownload    bool
+	syncDelete      bool
+	syncDryRun      bool
+	syncSizeOnly    bool
+	syncConcurrency int
+	syncNoProgress  bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync <source> <destination>",
+	Short: "Mirror a local directory to a bucket prefix (or the reverse)",
+	Long: `Mirror a local directory to a bucket prefix, transferring only changed files.
+
+By default the source is a local directory and the destination is a remote
+prefix. With --download the source is a remote prefix and the destination a
+local directory.
+
+A file is transferred when it is missing on the destination or when:
+  - the sizes differ, or
+  - the object ETag is a plain MD5 and it differs from the local file's MD5, or
+  - the ETag is not a plain MD5 (multipart uploads) and the source is newer
+
+Examples:
+  r2s3-cli sync ./dist site/                       # Upload changed files to site/
+  r2s3-cli sync ./dist site/ --delete              # Also delete remote files missing locally
+  r2s3-cli sync ./dist site/ --dry-run             # Show planned PUT/DELETE operations
+  r2s3-cli sync --download site/ ./backup          # Download changed files from site/
+  r2s3-cli sync --download site/ ./backup --delete # Also delete local files missing remotely`,
+	Args: cobra.ExactArgs(2),
+	RunE: syncFiles,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVarP(&syncBucket, "bucket", "b", "", "bucket name (overrides config)")
+	syncCmd.Flags().BoolVar(&syncDownload, "download", false, "sync from a remote prefix to a local directory")
+	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "delete destination files that do not exist in the source")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "print planned operations without executing them")
+	syncCmd.Flags().BoolVar(&syncSizeOnly, "size-only", false, "compare file sizes only")
+	syncCmd.Flags().IntVar(&syncConcurrency, "concurrency", 4, "number of files transferred in parallel")
+	syncCmd.Flags().BoolVar(&syncNoProgress, "no-progress", false, "disable progress bar")
+}
+
+// syncEntry describes a file on one side of a sync, keyed by its path relative
+// to the sync root (always with forward slashes)
+type syncEntry struct {
+	relPath string
+	size    int64
+	modTime time.Time
+	etag    string // remote only
+}
+
+// syncPlanOptions controls how both sides of a sync are compared
+type syncPlanOptions struct {
+	download bool // the remote prefix is the source
+	delete   bool // delete destination files missing from the source
+	sizeOnly bool // compare sizes only
+}
+
+// syncOp is the kind of operation planned for a file
+type syncOp string
+
+const (
+	syncOpPut    syncOp = "PUT"
+	syncOpGet    syncOp = "GET"
+	syncOpDelete syncOp = "DELETE"
+)
+
+// syncAction is a single planned operation
+type syncAction struct {
+	op      syncOp
+	relPath string
+	size    int64
+	modTime time.Time // source modification time, applied to downloaded files
+	reason  string
+}
+
+func syncFiles(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+
+	if syncConcurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", syncConcurrency)
+	}
+
+	// Create R2 client
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if syncBucket != "" {
+		bucketName = syncBucket
+	}
+
+	localDir, prefix := args[0], args[1]
+	if syncDownload {
+		prefix, localDir = args[0], args[1]
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	localFiles, err := scanLocalFiles(localDir, !syncDownload)
+	if err != nil {
+		return err
+	}
+
+	remoteFiles, err := scanRemoteFiles(ctx, client, bucketName, prefix)
+	if err != nil {
+		return err
+	}
+
+	plan := syncPlanOptions{download: syncDownload, delete: syncDelete, sizeOnly: syncSizeOnly}
+	actions, unchanged := planSync(localDir, localFiles, remoteFiles, plan)
+
+	if len(actions) == 0 {
+		if !quiet {
+			fmt.Printf("Everything up to date (%d files)\n", unchanged)
+		}
+		return nil
+	}
+
+	if syncDryRun {
+		for _, action := range actions {
+			fmt.Printf("%-6s %s (%s)\n", action.op, syncTarget(action, localDir, prefix, syncDownload), action.reason)
+		}
+		fmt.Printf("\nDry run: %s, %d unchanged\n", summarizeActions(actions, syncDownload), unchanged)
+		return nil
+	}
+
+	return executeSync(ctx, client, cfg, bucketName, localDir, prefix, actions, unchanged)
+}
+
+// scanLocalFiles walks localDir and returns its regular files keyed by relative path.
+// A missing directory is an error only when it is the sync source.
+func scanLocalFiles(localDir string, mustExist bool) (map[string]syncEntry, error) {
+	files := make(map[string]syncEntry)
+
+	info, err := os.Stat(localDir)
+	if err != nil {
+		if os.IsNotExist(err) && !mustExist {
+			return files, nil
+		}
+		return nil, fmt.Errorf("failed to access %s: %w", localDir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", localDir)
+	}
+
+	err = filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.Warnf("Error accessing %s: %v", path, err)
+			return nil // Continue walking
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(localDir, path)
+		if err != nil {
+			logrus.Warnf("Error calculating relative path for %s: %v", path, err)
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		files[relPath] = syncEntry{
+			relPath: relPath,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %w", localDir, err)
+	}
+
+	return files, nil
+}
+
+// scanRemoteFiles lists every object below prefix keyed by path relative to the prefix
+func scanRemoteFiles(ctx context.Context, client *r2.Client, bucketName, prefix string) (map[string]syncEntry, error) {
+	files := make(map[string]syncEntry)
+
+	paginator := r2.NewListPaginator(client.Storage(), bucketName, r2.ListOptions{Prefix: prefix})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects with prefix %s: %w", prefix, err)
+		}
+
+		for _, obj := range page.Objects {
+			key := obj.Key
+
+			// Skip "folder" placeholder objects
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+
+			relPath := strings.TrimPrefix(key, prefix)
+			if !isSafeRelPath(relPath) {
+				logrus.Warnf("Skipping %s: cannot be mapped to a local path", key)
+				continue
+			}
+
+			files[relPath] = syncEntry{
+				relPath: relPath,
+				size:    obj.Size,
+				modTime: obj.LastModified,
+				etag:    obj.ETag,
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// isSafeRelPath reports whether a key suffix stays inside the sync root when
+// used as a local path
+func isSafeRelPath(relPath string) bool {
+	if relPath == "" || strings.HasPrefix(relPath, "/") {
+		return false
+	}
+	for _, part := range strings.Split(relPath, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// planSync compares both sides and returns the sorted list of operations together
+// with the number of files that are already up to date
+func planSync(localDir string, localFiles, remoteFiles map[string]syncEntry, opts syncPlanOptions) ([]syncAction, int) {
+	source, destination := localFiles, remoteFiles
+	transferOp := syncOpPut
+	if opts.download {
+		source, destination = remoteFiles, localFiles
+		transferOp = syncOpGet
+	}
+
+	var actions []syncAction
+	unchanged := 0
+
+	for relPath, src := range source {
+		dst, exists := destination[relPath]
+		if !exists {
+			actions = append(actions, syncAction{op: transferOp, relPath: relPath, size: src.size, modTime: src.modTime, reason: "new"})
+			continue
+		}
+
+		local, remote := src, dst
+		if opts.download {
+			local, remote = dst, src
+		}
+
+		if reason := syncChangeReason(localDir, local, remote, opts); reason != "" {
+			actions = append(actions, syncAction{op: transferOp, relPath: relPath, size: src.size, modTime: src.modTime, reason: reason})
+		} else {
+			unchanged++
+		}
+	}
+
+	if opts.delete {
+		for relPath, dst := range destination {
+			if _, exists := source[relPath]; !exists {
+				actions = append(actions, syncAction{op: syncOpDelete, relPath: relPath, size: dst.size, reason: "not in source"})
+			}
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].op != actions[j].op {
+			// Transfers first, deletes last
+			return actions[j].op == syncOpDelete
+		}
+		return actions[i].relPath < actions[j].relPath
+	})
+
+	return actions, unchanged
+}
+
+// syncChangeReason returns why a file present on both sides needs a transfer,
+// or "" when both sides match
+func syncChangeReason(localDir string, local, remote syncEntry, opts syncPlanOptions) string {
+	if local.size != remote.size {
+		return "size differs"
+	}
+	if opts.sizeOnly {
+		return ""
+	}
+
+	// A plain MD5 ETag can be compared with the local content directly
+	if utils.IsMD5ETag(remote.etag) {
+		sum, err := utils.FileMD5(filepath.Join(localDir, filepath.FromSlash(local.relPath)))
+		if err != nil {
+			logrus.Warnf("Failed to hash %s: %v", local.relPath, err)
+			return "checksum unavailable"
+		}
+		if sum != remote.etag {
+			return "content differs"
+		}
+		return ""
+	}
+
+	// Multipart ETags are not content hashes, fall back to modification time
+	if opts.download {
+		if remote.modTime.After(local.modTime) {
+			return "remote is newer"
+		}
+	} else if local.modTime.After(remote.modTime) {
+		return "local is newer"
+	}
+	return ""
+}
+
+// syncTarget describes the destination of an action for display
+func syncTarget(action syncAction, localDir, prefix string, download bool) string {
+	localPath := filepath.Join(localDir, filepath.FromSlash(action.relPath))
+	remoteKey := prefix + action.relPath
+
+	switch action.op {
+	case syncOpPut:
+		return fmt.Sprintf("%s -> %s", localPath, remoteKey)
+	case syncOpGet:
+		return fmt.Sprintf("%s -> %s", remoteKey, localPath)
+	default:
+		if download {
+			return localPath
+		}
+		return remoteKey
+	}
+}
+
+// summarizeActions counts planned operations by kind
+func summarizeActions(actions []syncAction, download bool) string {
+	counts := make(map[syncOp]int)
+	for _, action := range actions {
+		counts[action.op]++
+	}
+
+	if download {
+		return fmt.Sprintf("%d to download, %d to delete", counts[syncOpGet], counts[syncOpDelete])
+	}
+	return fmt.Sprintf("%d to upload, %d to delete", counts[syncOpPut], counts[syncOpDelete])
+}
+
+// executeSync runs the planned transfers in parallel and then applies deletions
+func executeSync(ctx context.Context, client *r2.Client, cfg *config.Config, bucketName, localDir, prefix string, actions []syncAction, unchanged int) error {
+	var transfers, deletes []syncAction
+	var totalBytes int64
+	for _, action := range actions {
+		if action.op == syncOpDelete {
+			deletes = append(deletes, action)
+		} else {
+			transfers = append(transfers, action)
+			totalBytes += action.size
+		}
+	}
+
+	var failures []string
+	transferred := 0
+
+	if len(transfers) > 0 {
+		var progress *utils.MultiFileProgress
+		if !syncNoProgress && !quiet {
+			progress = utils.NewMultiFileProgress(len(transfers), totalBytes)
+			if syncDownload {
+				progress.SetAction("Downloading")
+			}
+		}
+
+		uploader := utils.NewFileUploader(client.Storage(), cfg, bucketName)
+		downloader := utils.NewFileDownloader(client.Storage(), bucketName)
+
+		// Uploads get the configured headers, metadata and header rules
+		uploadOptions := utils.UploadOptionsFromConfig(&cfg.Upload)
+		uploadOptions.Overwrite = true
+
+		transferFailures := runWorkers(ctx, syncConcurrency, len(transfers), func(worker, index int) error {
+			action := transfers[index]
+			localPath := filepath.Join(localDir, filepath.FromSlash(action.relPath))
+			remoteKey := prefix + action.relPath
+
+			var callback utils.ProgressCallback
+			if progress != nil {
+				progress.StartWorkerFile(worker, filepath.Base(action.relPath), action.size)
+				callback = func(transferred, total int64, percentage float64) {
+					progress.UpdateWorkerFile(worker, transferred)
+				}
+			}
+
+			var err error
+			if action.op == syncOpPut {
+				err = uploader.UploadFileWithProgress(ctx, localPath, remoteKey, uploadOptions, callback)
+			} else {
+				_, _, err = downloader.DownloadToFile(ctx, remoteKey, localPath, utils.ConflictOverwrite, callback)
+				if err == nil {
+					// Keep local mtime in line with the object so later runs compare correctly
+					if chErr := os.Chtimes(localPath, action.modTime, action.modTime); chErr != nil {
+						logrus.Warnf("Failed to set modification time on %s: %v", localPath, chErr)
+					}
+				}
+			}
+
+			if progress != nil {
+				if err != nil {
+					progress.FinishWorkerFile(worker, 0)
+				} else {
+					progress.FinishWorkerFile(worker, action.size)
+				}
+			}
+			if err != nil {
+				logrus.Errorf("Failed to sync %s: %v", action.relPath, err)
+			}
+			return err
+		})
+
+		if progress != nil {
+			progress.Finish()
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("sync cancelled: %w", ctx.Err())
+		}
+
+		transferred = len(transfers) - len(transferFailures)
+		for _, failure := range transferFailures {
+			failures = append(failures, fmt.Sprintf("%s: %v", transfers[failure.index].relPath, failure.err))
+		}
+	}
+
+	deleted := 0
+	if len(deletes) > 0 {
+		if syncDownload {
+			for _, action := range deletes {
+				localPath := filepath.Join(localDir, filepath.FromSlash(action.relPath))
+				if err := os.Remove(localPath); err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", action.relPath, err))
+					continue
+				}
+				logrus.Infof("Deleted local file: %s", localPath)
+				deleted++
+			}
+		} else {
+			keys := make([]string, len(deletes))
+			for i, action := range deletes {
+				keys[i] = prefix + action.relPath
+			}
+
+			var deleteErrors []error
+			deleted, deleteErrors = deleteKeys(ctx, client.Storage(), bucketName, keys, syncConcurrency)
+			for _, err := range deleteErrors {
+				failures = append(failures, err.Error())
+			}
+		}
+	}
+
+	verb := "uploaded"
+	if syncDownload {
+		verb = "downloaded"
+	}
+
+	if len(failures) > 0 {
+		fmt.Printf("Sync finished with errors: %d %s, %d deleted, %d unchanged, %d failed:\n", transferred, verb, deleted, unchanged, len(failures))
+		for _, failure := range failures {
+			fmt.Printf("  Error: %s\n", failure)
+		}
+		return fmt.Errorf("%d operations failed", len(failures))
+	}
+
+	if !quiet {
+		fmt.Printf("Sync complete: %d %s, %d deleted, %d unchanged\n", transferred, verb, deleted, unchanged)
+	}
+	logrus.Infof("Sync complete: %d %s, %d deleted, %d unchanged", transferred, verb, deleted, unchanged)
+	return nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func md5Hex(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestSyncChangeReason(t *testing.T) {
+	localDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "index.html"), []byte("<html></html>"), 0644))
+
+	older := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	local := syncEntry{relPath: "index.html", size: 13, modTime: older}
+	multipartETag := md5Hex("parts") + "-2"
+
+	tests := []struct {
+		name   string
+		remote syncEntry
+		opts   syncPlanOptions
+		want   string
+	}{
+		{"size differs", syncEntry{size: 14, etag: md5Hex("<html></html>")}, syncPlanOptions{}, "size differs"},
+		{"md5 matches", syncEntry{size: 13, modTime: newer, etag: md5Hex("<html></html>")}, syncPlanOptions{}, ""},
+		{"md5 matches despite older remote", syncEntry{size: 13, modTime: older.Add(-24 * time.Hour), etag: md5Hex("<html></html>")}, syncPlanOptions{}, ""},
+		{"md5 differs", syncEntry{size: 13, modTime: newer, etag: md5Hex("<body></body>")}, syncPlanOptions{}, "content differs"},
+		{"size only ignores content", syncEntry{size: 13, etag: md5Hex("<body></body>")}, syncPlanOptions{sizeOnly: true}, ""},
+		{"size only still compares size", syncEntry{size: 1, etag: md5Hex("x")}, syncPlanOptions{sizeOnly: true}, "size differs"},
+		{"multipart local newer", syncEntry{size: 13, modTime: older.Add(-time.Hour), etag: multipartETag}, syncPlanOptions{}, "local is newer"},
+		{"multipart remote newer on upload", syncEntry{size: 13, modTime: newer, etag: multipartETag}, syncPlanOptions{}, ""},
+		{"multipart remote newer on download", syncEntry{size: 13, modTime: newer, etag: multipartETag}, syncPlanOptions{download: true}, "remote is newer"},
+		{"multipart local newer on download", syncEntry{size: 13, modTime: older.Add(-time.Hour), etag: multipartETag}, syncPlanOptions{download: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := tt.remote
+			remote.relPath = local.relPath
+			assert.Equal(t, tt.want, syncChangeReason(localDir, local, remote, tt.opts))
+		})
+	}
+
+	// A local file that cannot be hashed is transferred again
+	missing := syncEntry{relPath: "missing.html", size: 13}
+	assert.Equal(t, "checksum unavailable", syncChangeReason(localDir, missing, syncEntry{size: 13, etag: md5Hex("x")}, syncPlanOptions{}))
+}
+
+func TestPlanSync(t *testing.T) {
+	localDir := t.TempDir()
+	for name, content := range map[string]string{"same.txt": "same", "changed.txt": "new!", "added.txt": "added"} {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, name), []byte(content), 0644))
+	}
+
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	localFiles := map[string]syncEntry{
+		"same.txt":    {relPath: "same.txt", size: 4, modTime: modTime},
+		"changed.txt": {relPath: "changed.txt", size: 4, modTime: modTime},
+		"added.txt":   {relPath: "added.txt", size: 5, modTime: modTime},
+	}
+	remoteFiles := map[string]syncEntry{
+		"same.txt":    {relPath: "same.txt", size: 4, modTime: modTime, etag: md5Hex("same")},
+		"changed.txt": {relPath: "changed.txt", size: 4, modTime: modTime, etag: md5Hex("old!")},
+		"stale.txt":   {relPath: "stale.txt", size: 9, modTime: modTime, etag: md5Hex("stale.txt")},
+	}
+
+	type planned struct {
+		op      syncOp
+		relPath string
+		reason  string
+	}
+	plan := func(opts syncPlanOptions) ([]planned, int) {
+		actions, unchanged := planSync(localDir, localFiles, remoteFiles, opts)
+		result := make([]planned, len(actions))
+		for i, action := range actions {
+			result[i] = planned{action.op, action.relPath, action.reason}
+		}
+		return result, unchanged
+	}
+
+	tests := []struct {
+		name      string
+		opts      syncPlanOptions
+		want      []planned
+		unchanged int
+	}{
+		{
+			name: "upload",
+			want: []planned{
+				{syncOpPut, "added.txt", "new"},
+				{syncOpPut, "changed.txt", "content differs"},
+			},
+			unchanged: 1,
+		},
+		{
+			name: "upload with delete",
+			opts: syncPlanOptions{delete: true},
+			want: []planned{
+				{syncOpPut, "added.txt", "new"},
+				{syncOpPut, "changed.txt", "content differs"},
+				{syncOpDelete, "stale.txt", "not in source"},
+			},
+			unchanged: 1,
+		},
+		{
+			name:      "size only",
+			opts:      syncPlanOptions{sizeOnly: true},
+			want:      []planned{{syncOpPut, "added.txt", "new"}},
+			unchanged: 2,
+		},
+		{
+			name: "download",
+			opts: syncPlanOptions{download: true},
+			want: []planned{
+				{syncOpGet, "changed.txt", "content differs"},
+				{syncOpGet, "stale.txt", "new"},
+			},
+			unchanged: 1,
+		},
+		{
+			name: "download with delete",
+			opts: syncPlanOptions{download: true, delete: true},
+			want: []planned{
+				{syncOpGet, "changed.txt", "content differs"},
+				{syncOpGet, "stale.txt", "new"},
+				{syncOpDelete, "added.txt", "not in source"},
+			},
+			unchanged: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions, unchanged := plan(tt.opts)
+			assert.Equal(t, tt.want, actions)
+			assert.Equal(t, tt.unchanged, unchanged)
+		})
+	}
+}
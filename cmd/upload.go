@@ -10,12 +10,11 @@ import (
 	_ "image/png"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/disintegration/imaging"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -31,6 +30,14 @@ var (
 	uploadOverwrite   bool
 	uploadCompress    string
 	uploadNoProgress  bool
+	uploadPartSize    int
+	uploadPartWorkers int
+	uploadConcurrency int
+
+	uploadCacheControl       string
+	uploadContentDisposition string
+	uploadContentEncoding    string
+	uploadMeta               map[string]string
 )
 
 // uploadCmd represents the upload command
@@ -51,8 +58,27 @@ Examples:
   r2s3-cli upload image.jpg photos/new.jpg    # Upload as: photos/new.jpg
   r2s3-cli upload ./photos                    # Upload folder as: photos/
   r2s3-cli upload ./photos images/            # Upload folder to: images/
+  r2s3-cli upload ./site --concurrency 16     # Upload folder with 16 parallel workers
   r2s3-cli upload image.jpg --compress high   # Upload with high compression
-  r2s3-cli upload image.jpg --no-progress     # Upload without progress bar`,
+  r2s3-cli upload image.jpg --no-progress     # Upload without progress bar
+  r2s3-cli upload backup.tar --part-size 64   # Multipart upload with 64MB parts
+  r2s3-cli upload ./dist site/ --cache-control "public, max-age=3600"
+  r2s3-cli upload report.pdf --content-disposition attachment --meta owner=alice,team=ops
+
+Files at least upload.multipart_threshold MB in size are uploaded in parts.
+If such an upload is interrupted, running the same command again resumes it
+from the parts that were already uploaded.
+
+Headers and metadata can also be set in the [upload] config section, together
+with per-glob rules that override them for matching keys:
+
+  [[upload.header_rules]]
+  pattern = "*.html"
+  cache_control = "no-cache"
+
+  [[upload.header_rules]]
+  pattern = "*.woff2"
+  cache_control = "public, max-age=31536000, immutable"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: uploadFile,
 }
@@ -65,6 +91,13 @@ func init() {
 	uploadCmd.Flags().BoolVar(&uploadOverwrite, "overwrite", false, "overwrite existing files")
 	uploadCmd.Flags().StringVarP(&uploadCompress, "compress", "z", "", "image compression level (high, fine, normal, low)")
 	uploadCmd.Flags().BoolVar(&uploadNoProgress, "no-progress", false, "disable progress bar")
+	uploadCmd.Flags().IntVar(&uploadPartSize, "part-size", 0, "multipart upload part size in MB (overrides config)")
+	uploadCmd.Flags().IntVar(&uploadPartWorkers, "part-concurrency", 0, "number of parts uploaded in parallel (overrides config)")
+	uploadCmd.Flags().IntVar(&uploadConcurrency, "concurrency", 4, "number of files uploaded in parallel for folder uploads")
+	uploadCmd.Flags().StringVar(&uploadCacheControl, "cache-control", "", "Cache-Control header (overrides config)")
+	uploadCmd.Flags().StringVar(&uploadContentDisposition, "content-disposition", "", "Content-Disposition header (overrides config)")
+	uploadCmd.Flags().StringVar(&uploadContentEncoding, "content-encoding", "", "Content-Encoding header (overrides config)")
+	uploadCmd.Flags().StringToStringVar(&uploadMeta, "meta", nil, "x-amz-meta-* values added to the configured metadata (key=value,...)")
 }
 
 // processRemotePath processes the remote path based on upload logic:
@@ -106,7 +139,7 @@ func uploadFile(cmd *cobra.Command, args []string) error {
 	cfg := GetConfig()
 
 	// Create R2 client
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	if err != nil {
 		return fmt.Errorf("failed to create R2 client: %w", err)
 	}
@@ -140,31 +173,23 @@ func uploadFile(cmd *cobra.Command, args []string) error {
 	// Process remote path with enhanced logic
 	remotePath = processRemotePath(remotePath, localPath, fileInfo.IsDir())
 
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if fileInfo.IsDir() {
-		return uploadDirectory(client, bucketName, localPath, remotePath, cfg, cmd)
+		return uploadDirectory(ctx, client, bucketName, localPath, remotePath, cfg, cmd)
 	} else {
-		return uploadSingleFile(client, bucketName, localPath, remotePath, cfg, cmd, false)
+		return uploadSingleFile(ctx, client, bucketName, localPath, remotePath, cfg, cmd, nil)
 	}
 }
 
-func checkFileExists(client *r2.Client, bucket, key string) (bool, error) {
-	_, err := client.GetS3Client().(*s3.Client).HeadObject(context.TODO(), &s3.HeadObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-	})
+func checkFileExists(ctx context.Context, client *r2.Client, bucket, key string) (bool, error) {
+	_, err := client.Storage().Head(ctx, bucket, key)
 	if err != nil {
-		// Check for various "not found" error types
-		var nsk *types.NoSuchKey
-		var nf *types.NotFound
-		if errors.As(err, &nsk) || errors.As(err, &nf) {
-			return false, nil
-		}
-
-		// Also check for HTTP 404 status code in error message
-		if strings.Contains(err.Error(), "StatusCode: 404") || strings.Contains(err.Error(), "NotFound") {
+		if errors.Is(err, r2.ErrNotFound) {
 			return false, nil
 		}
-
 		return false, err
 	}
 
@@ -235,8 +260,9 @@ func compressImage(file *os.File, quality string) ([]byte, int64, error) {
 	return compressedData, int64(len(compressedData)), nil
 }
 
-// uploadSingleFile uploads a single file
-func uploadSingleFile(client *r2.Client, bucketName, filePath, remotePath string, cfg *config.Config, cmd *cobra.Command, suppressProgress bool) error {
+// uploadSingleFile uploads a single file. When onProgress is set, progress is
+// reported through it instead of the built-in progress bar.
+func uploadSingleFile(ctx context.Context, client *r2.Client, bucketName, filePath, remotePath string, cfg *config.Config, cmd *cobra.Command, onProgress utils.ProgressCallback) error {
 	// Determine overwrite behavior (CLI flag > config > default)
 	shouldOverwrite := uploadOverwrite
 	if !cmd.Flags().Changed("overwrite") {
@@ -251,7 +277,7 @@ func uploadSingleFile(client *r2.Client, bucketName, filePath, remotePath string
 
 	// Check if file exists
 	if !shouldOverwrite {
-		exists, err := checkFileExists(client, bucketName, remotePath)
+		exists, err := checkFileExists(ctx, client, bucketName, remotePath)
 		if err != nil {
 			return fmt.Errorf("failed to check if file exists: %w", err)
 		}
@@ -294,12 +320,10 @@ func uploadSingleFile(client *r2.Client, bucketName, filePath, remotePath string
 
 	logrus.Infof("Uploading %s (%d bytes) to %s", filePath, finalSize, remotePath)
 
-	// Determine content type
-	var contentType string
-	if uploadContentType != "" {
-		// Use explicitly specified content type
-		contentType = uploadContentType
-	} else if cfg.Upload.AutoDetectContentType {
+	// Resolve headers; --content-type or a matching rule wins over detection
+	headers := getUploadOptions(cfg, cmd).HeadersFor(remotePath)
+	contentType := headers.ContentType
+	if contentType == "" && cfg.Upload.AutoDetectContentType {
 		// Auto-detect content type
 		detectedType, err := utils.DetectContentType(filePath, file)
 		if err != nil {
@@ -313,55 +337,138 @@ func uploadSingleFile(client *r2.Client, bucketName, filePath, remotePath string
 			file.Seek(0, 0)
 		}
 	}
+	headers.ContentType = contentType
 
-	// Reset upload body position before wrapping with progress bar
-	if seeker, ok := uploadBody.(io.Seeker); ok {
-		seeker.Seek(0, io.SeekStart)
+	// Large uncompressed files go through the resumable multipart uploader
+	multipartOptions := getMultipartOptions(cfg, cmd)
+	compressed := compressionLevel != "" && isImageFile(filePath)
+	if !compressed && multipartOptions.ShouldUseMultipart(finalSize) {
+		return uploadMultipartFile(ctx, client, bucketName, filePath, remotePath, headers, finalSize, multipartOptions, onProgress)
 	}
 
-	// Wrap upload body with progress bar (if enabled, not in quiet mode, file is large enough, and not suppressed)
-	if !uploadNoProgress && !quiet && !suppressProgress && finalSize > 1024*10 { // Show progress bar for files larger than 10KB
+	// Hash the body (this also rewinds it) so the server can reject corrupted uploads
+	var checksums utils.Checksums
+	if seeker, ok := uploadBody.(io.ReadSeeker); ok {
+		checksums, err = utils.ComputeChecksums(seeker)
+		if err != nil {
+			return fmt.Errorf("failed to compute checksum for %s: %w", filePath, err)
+		}
+	}
+
+	// Wrap upload body with progress bar (if enabled, not in quiet mode, file is large enough, and not reported by the caller)
+	if onProgress != nil {
+		uploadBody = utils.NewProgressCallbackReader(uploadBody, finalSize, onProgress)
+	} else if !uploadNoProgress && !quiet && finalSize > 1024*10 { // Show progress bar for files larger than 10KB
 		description := fmt.Sprintf("Uploading %s", filepath.Base(filePath))
 		progressReader := utils.NewProgressReader(uploadBody, finalSize, description)
 		uploadBody = progressReader
 		defer progressReader.Close()
 	}
 
-	// Prepare upload input
-	input := &s3.PutObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(remotePath),
-		Body:   uploadBody,
+	// Set content type, caching headers, metadata and checksums
+	var options r2.PutOptions
+	headers.ApplyToPut(&options)
+	logrus.Debugf("Setting headers for %s: %+v", remotePath, headers)
+	checksums.ApplyToPut(&options)
+
+	// Upload file
+	err = client.Storage().Put(ctx, bucketName, remotePath, uploadBody, options)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// Set content type if determined
-	if contentType != "" {
-		input.ContentType = aws.String(contentType)
-		logrus.Debugf("Setting content type: %s", contentType)
+	logrus.Infof("Successfully uploaded %s to %s", filePath, remotePath)
+	return nil
+}
+
+// getUploadOptions resolves headers and metadata (CLI flag > header rule >
+// config) and the per-glob header rules from config
+func getUploadOptions(cfg *config.Config, cmd *cobra.Command) *utils.UploadOptions {
+	options := utils.UploadOptionsFromConfig(&cfg.Upload)
+
+	// Flags are applied after the header rules, so they win over both
+	if uploadContentType != "" {
+		options.Overrides.ContentType = &uploadContentType
+	}
+	if cmd.Flags().Changed("cache-control") {
+		options.Overrides.CacheControl = &uploadCacheControl
+	}
+	if cmd.Flags().Changed("content-disposition") {
+		options.Overrides.ContentDisposition = &uploadContentDisposition
+	}
+	if cmd.Flags().Changed("content-encoding") {
+		options.Overrides.ContentEncoding = &uploadContentEncoding
 	}
 
-	// Upload file
-	_, err = client.GetS3Client().(*s3.Client).PutObject(context.TODO(), input)
+	// --meta adds to the configured metadata, overriding keys it repeats
+	options.Overrides.Metadata = uploadMeta
+	return options
+}
+
+// getMultipartOptions resolves multipart settings (CLI flag > config > default)
+func getMultipartOptions(cfg *config.Config, cmd *cobra.Command) *utils.MultipartOptions {
+	options := utils.MultipartOptionsFromConfig(&cfg.Upload)
+	if cmd.Flags().Changed("part-size") {
+		options.PartSize = int64(uploadPartSize) * 1024 * 1024
+	}
+	if cmd.Flags().Changed("part-concurrency") {
+		options.Concurrency = uploadPartWorkers
+	}
+	return options
+}
+
+// uploadMultipartFile uploads a large file in parts, resuming a previous attempt if possible
+func uploadMultipartFile(ctx context.Context, client *r2.Client, bucketName, filePath, remotePath string, headers utils.ObjectHeaders, fileSize int64, options *utils.MultipartOptions, onProgress utils.ProgressCallback) error {
+	if options.PartSize < utils.MinPartSize {
+		return fmt.Errorf("part size must be at least %d MB", utils.MinPartSize/1024/1024)
+	}
+
+	var progress *utils.MultiFileProgress
+	callback := onProgress
+	if callback == nil && !uploadNoProgress && !quiet {
+		progress = utils.NewMultiFileProgress(1, fileSize)
+		progress.StartFile(filepath.Base(filePath), fileSize)
+		callback = func(uploaded, total int64, percentage float64) {
+			progress.UpdateFile(uploaded)
+		}
+	}
+
+	uploader := utils.NewMultipartUploader(client.Storage(), bucketName, options)
+	err := uploader.Upload(ctx, filePath, remotePath, headers, false, callback)
+	if progress != nil {
+		if err == nil {
+			progress.FinishFile(fileSize)
+		}
+		progress.Finish()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	logrus.Infof("Successfully uploaded %s to %s", filePath, remotePath)
 	return nil
 }
 
-// uploadDirectory uploads an entire directory recursively
-func uploadDirectory(client *r2.Client, bucketName, localPath, remotePath string, cfg *config.Config, cmd *cobra.Command) error {
+// uploadJob is a single file scheduled for a directory upload
+type uploadJob struct {
+	localPath  string
+	remotePath string
+	size       int64
+}
+
+// uploadDirectory uploads an entire directory recursively using a pool of workers
+func uploadDirectory(ctx context.Context, client *r2.Client, bucketName, localPath, remotePath string, cfg *config.Config, cmd *cobra.Command) error {
+	if uploadConcurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", uploadConcurrency)
+	}
+
 	// Ensure remote path ends with /
 	if !strings.HasSuffix(remotePath, "/") {
 		remotePath += "/"
 	}
 
 	// Collect all files to upload
-	var filesToUpload []struct {
-		localPath  string
-		remotePath string
-	}
+	var filesToUpload []uploadJob
+	var totalBytes int64
 
 	err := filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -384,16 +491,12 @@ func uploadDirectory(client *r2.Client, bucketName, localPath, remotePath string
 		// Convert file separators to forward slashes for S3
 		relPath = filepath.ToSlash(relPath)
 
-		// Build remote path
-		remoteFilePath := remotePath + relPath
-
-		filesToUpload = append(filesToUpload, struct {
-			localPath  string
-			remotePath string
-		}{
+		filesToUpload = append(filesToUpload, uploadJob{
 			localPath:  path,
-			remotePath: remoteFilePath,
+			remotePath: remotePath + relPath,
+			size:       info.Size(),
 		})
+		totalBytes += info.Size()
 
 		return nil
 	})
@@ -406,15 +509,7 @@ func uploadDirectory(client *r2.Client, bucketName, localPath, remotePath string
 		return nil
 	}
 
-	logrus.Infof("Found %d files to upload from directory %s", len(filesToUpload), localPath)
-
-	// Calculate total size for progress tracking
-	var totalBytes int64
-	for _, file := range filesToUpload {
-		if fileInfo, err := os.Stat(file.localPath); err == nil {
-			totalBytes += fileInfo.Size()
-		}
-	}
+	logrus.Infof("Found %d files to upload from directory %s (%d workers)", len(filesToUpload), localPath, uploadConcurrency)
 
 	// Create multi-file progress tracker
 	var progress *utils.MultiFileProgress
@@ -422,44 +517,52 @@ func uploadDirectory(client *r2.Client, bucketName, localPath, remotePath string
 		progress = utils.NewMultiFileProgress(len(filesToUpload), totalBytes)
 	}
 
-	// Upload files with progress tracking
-	uploadedCount := 0
-	errorCount := 0
-
-	for _, file := range filesToUpload {
-		// Get file size for progress tracking
-		var fileSize int64
-		if fileInfo, err := os.Stat(file.localPath); err == nil {
-			fileSize = fileInfo.Size()
-		}
+	failures := runWorkers(ctx, uploadConcurrency, len(filesToUpload), func(worker, index int) error {
+		job := filesToUpload[index]
 
-		// Update progress - start file
+		// A non-nil callback keeps uploadSingleFile from drawing its own progress bar
+		var onProgress utils.ProgressCallback = func(uploaded, total int64, percentage float64) {}
 		if progress != nil {
-			progress.StartFile(filepath.Base(file.localPath), fileSize)
+			progress.StartWorkerFile(worker, filepath.Base(job.localPath), job.size)
+			onProgress = func(uploaded, total int64, percentage float64) {
+				progress.UpdateWorkerFile(worker, uploaded)
+			}
 		}
 
-		err := uploadSingleFile(client, bucketName, file.localPath, file.remotePath, cfg, cmd, true)
+		err := uploadSingleFile(ctx, client, bucketName, job.localPath, job.remotePath, cfg, cmd, onProgress)
 		if err != nil {
-			logrus.Errorf("Failed to upload %s: %v", file.localPath, err)
-			errorCount++
-			continue
+			logrus.Errorf("Failed to upload %s: %v", job.localPath, err)
 		}
-		uploadedCount++
 
-		// Update progress - finish file
 		if progress != nil {
-			progress.FinishFile(fileSize)
+			if err != nil {
+				progress.FinishWorkerFile(worker, 0)
+			} else {
+				progress.FinishWorkerFile(worker, job.size)
+			}
 		}
-	}
+		return err
+	})
 
 	// Finish progress display
 	if progress != nil {
 		progress.Finish()
 	}
 
-	if errorCount > 0 {
-		logrus.Warnf("Directory upload completed with errors: %d succeeded, %d failed", uploadedCount, errorCount)
-		return fmt.Errorf("%d files failed to upload", errorCount)
+	if ctx.Err() != nil {
+		logrus.Warnf("Directory upload cancelled")
+		return fmt.Errorf("upload cancelled: %w", ctx.Err())
+	}
+
+	uploadedCount := len(filesToUpload) - len(failures)
+	if len(failures) > 0 {
+		// Report errors in the same order the files were found
+		for _, failure := range failures {
+			fmt.Printf("  Error: %s: %v\n", filesToUpload[failure.index].localPath, failure.err)
+		}
+
+		logrus.Warnf("Directory upload completed with errors: %d succeeded, %d failed", uploadedCount, len(failures))
+		return fmt.Errorf("%d files failed to upload", len(failures))
 	}
 
 	logrus.Infof("Successfully uploaded directory %s: %d files uploaded to %s", localPath, uploadedCount, remotePath)
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+var (
+	verifyBucket      string
+	verifyConcurrency int
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify <local-path> <remote-path>",
+	Short: "Check that local files match the objects in a bucket",
+	Long: `Compare a local file, or every file in a local directory tree, with the
+objects stored in the bucket and report which ones match.
+
+A file matches when its size is equal and its MD5 equals the object's ETag.
+Objects uploaded in several parts have a multipart ETag; these are checked by
+recomputing the ETag with the configured multipart part size, and reported as
+UNVERIFIED when the object was uploaded with a different part size.
+
+Each file is reported as one of:
+  OK          size and content match
+  MISMATCH    size or content differs
+  MISSING     the object does not exist in the bucket
+  EXTRA       the object exists only in the bucket (directories only)
+  UNVERIFIED  sizes match but the multipart ETag could not be reproduced
+
+The command exits with an error when any file is MISMATCH or MISSING.
+
+Examples:
+  r2s3-cli verify photo.jpg images/photo.jpg     # Verify a single file
+  r2s3-cli verify photo.jpg images/              # Same key, derived from the file name
+  r2s3-cli verify ./dist site/                   # Verify a whole directory tree
+  r2s3-cli verify ./dist site/ -q                # Only print differences`,
+	Args: cobra.ExactArgs(2),
+	RunE: verifyFiles,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&verifyBucket, "bucket", "b", "", "bucket name (overrides config)")
+	verifyCmd.Flags().IntVar(&verifyConcurrency, "concurrency", 4, "number of files hashed in parallel")
+}
+
+// verifyStatus is the outcome of comparing one local file with its object
+type verifyStatus string
+
+const (
+	verifyOK         verifyStatus = "OK"
+	verifyMismatch   verifyStatus = "MISMATCH"
+	verifyMissing    verifyStatus = "MISSING"
+	verifyExtra      verifyStatus = "EXTRA"
+	verifyUnverified verifyStatus = "UNVERIFIED"
+)
+
+// verifyResult is the comparison of a single file
+type verifyResult struct {
+	relPath string
+	status  verifyStatus
+	reason  string
+}
+
+func verifyFiles(cmd *cobra.Command, args []string) error {
+	cfg := GetConfig()
+
+	if verifyConcurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", verifyConcurrency)
+	}
+
+	localPath, remotePath := args[0], args[1]
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", localPath, err)
+	}
+
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
+	if err != nil {
+		return fmt.Errorf("failed to create R2 client: %w", err)
+	}
+
+	// Determine bucket name with priority: --bucket flag > effective bucket from config
+	bucketName := cfg.GetEffectiveBucket()
+	if verifyBucket != "" {
+		bucketName = verifyBucket
+	}
+
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	multipartOptions := utils.MultipartOptionsFromConfig(&cfg.Upload)
+
+	var results []verifyResult
+	if info.IsDir() {
+		prefix := remotePath
+		if prefix != "" && !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		results, err = verifyDirectory(ctx, client, bucketName, localPath, prefix, multipartOptions)
+	} else {
+		key := processRemotePath(remotePath, localPath, false)
+		results, err = verifySingleFile(ctx, client, bucketName, localPath, key, info.Size(), multipartOptions)
+	}
+	if err != nil {
+		return err
+	}
+
+	return reportVerifyResults(results)
+}
+
+// verifySingleFile compares one local file with the object at key
+func verifySingleFile(ctx context.Context, client *r2.Client, bucketName, localPath, key string, size int64, options *utils.MultipartOptions) ([]verifyResult, error) {
+	object, err := utils.StatObject(ctx, client.Storage(), bucketName, key)
+	if err != nil {
+		if errors.Is(err, r2.ErrNotFound) {
+			return []verifyResult{{relPath: key, status: verifyMissing, reason: "not in bucket"}}, nil
+		}
+		return nil, err
+	}
+
+	result := compareWithObject(localPath, size, object.Size, object.ETag, options)
+	result.relPath = key
+	return []verifyResult{result}, nil
+}
+
+// verifyDirectory compares every file below localDir with the objects below prefix
+func verifyDirectory(ctx context.Context, client *r2.Client, bucketName, localDir, prefix string, options *utils.MultipartOptions) ([]verifyResult, error) {
+	localFiles, err := scanLocalFiles(localDir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteFiles, err := scanRemoteFiles(ctx, client, bucketName, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []verifyResult
+	var toCompare []string
+	for relPath := range localFiles {
+		if _, exists := remoteFiles[relPath]; exists {
+			toCompare = append(toCompare, relPath)
+		} else {
+			results = append(results, verifyResult{relPath: relPath, status: verifyMissing, reason: "not in bucket"})
+		}
+	}
+	for relPath := range remoteFiles {
+		if _, exists := localFiles[relPath]; !exists {
+			results = append(results, verifyResult{relPath: relPath, status: verifyExtra, reason: "not in " + localDir})
+		}
+	}
+
+	compared := make([]verifyResult, len(toCompare))
+	runWorkers(ctx, verifyConcurrency, len(toCompare), func(worker, index int) error {
+		relPath := toCompare[index]
+		local, remote := localFiles[relPath], remoteFiles[relPath]
+
+		compared[index] = compareWithObject(filepath.Join(localDir, filepath.FromSlash(relPath)), local.size, remote.size, remote.etag, options)
+		compared[index].relPath = relPath
+		return nil
+	})
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("verify cancelled: %w", ctx.Err())
+	}
+
+	results = append(results, compared...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].relPath < results[j].relPath
+	})
+	return results, nil
+}
+
+// compareWithObject checks a local file against an object's size and ETag
+func compareWithObject(localPath string, localSize, remoteSize int64, etag string, options *utils.MultipartOptions) verifyResult {
+	if localSize != remoteSize {
+		return verifyResult{status: verifyMismatch, reason: fmt.Sprintf("size differs: local %s, remote %s",
+			utils.FormatBytes(localSize), utils.FormatBytes(remoteSize))}
+	}
+
+	if utils.IsMD5ETag(etag) {
+		sum, err := utils.FileMD5(localPath)
+		if err != nil {
+			return verifyResult{status: verifyUnverified, reason: fmt.Sprintf("failed to hash: %v", err)}
+		}
+		if sum != strings.ToLower(utils.NormalizeETag(etag)) {
+			return verifyResult{status: verifyMismatch, reason: "content differs"}
+		}
+		return verifyResult{status: verifyOK}
+	}
+
+	// Multipart ETags can only be reproduced with the part size used for the upload
+	parts := utils.MultipartETagParts(etag)
+	if parts == 0 {
+		return verifyResult{status: verifyUnverified, reason: "ETag is not an MD5"}
+	}
+	partSize := options.PartSizeFor(localSize)
+	if int64(parts) != max(1, (localSize+partSize-1)/partSize) {
+		return verifyResult{status: verifyUnverified, reason: fmt.Sprintf("multipart ETag with %d parts, uploaded with another part size", parts)}
+	}
+
+	multipartETag, err := utils.FileMultipartETag(localPath, partSize)
+	if err != nil {
+		return verifyResult{status: verifyUnverified, reason: fmt.Sprintf("failed to hash: %v", err)}
+	}
+	if multipartETag != strings.ToLower(utils.NormalizeETag(etag)) {
+		return verifyResult{status: verifyUnverified, reason: fmt.Sprintf("multipart ETag with %d parts does not match the configured part size", parts)}
+	}
+	return verifyResult{status: verifyOK}
+}
+
+// reportVerifyResults prints the results and fails when any file differs
+func reportVerifyResults(results []verifyResult) error {
+	counts := make(map[verifyStatus]int)
+	for _, result := range results {
+		counts[result.status]++
+
+		if result.status == verifyOK {
+			if !quiet {
+				fmt.Printf("%-10s %s\n", result.status, result.relPath)
+			}
+			continue
+		}
+		fmt.Printf("%-10s %s (%s)\n", result.status, result.relPath, result.reason)
+	}
+
+	if !quiet {
+		fmt.Printf("\nVerified %d files: %d OK, %d mismatched, %d missing, %d extra, %d unverified\n",
+			len(results), counts[verifyOK], counts[verifyMismatch], counts[verifyMissing], counts[verifyExtra], counts[verifyUnverified])
+	}
+
+	if failed := counts[verifyMismatch] + counts[verifyMissing]; failed > 0 {
+		return fmt.Errorf("verification failed: %d of %d files differ", failed, len(results))
+	}
+	return nil
+}
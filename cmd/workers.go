@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// indexedError is a failure of the job at the given position
+type indexedError struct {
+	index int
+	err   error
+}
+
+// runWorkers calls fn for every index in [0, count) using a fixed number of
+// workers. Jobs are handed out one at a time, so memory stays bounded by the
+// number of workers. Dispatching stops once ctx is cancelled; jobs already
+// running are expected to observe ctx themselves. Failures are returned
+// ordered by index.
+func runWorkers(ctx context.Context, workers, count int, fn func(worker, index int) error) []indexedError {
+	workers = max(1, min(workers, count))
+
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []indexedError
+	)
+
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for index := range jobs {
+				if err := fn(worker, index); err != nil {
+					mu.Lock()
+					failures = append(failures, indexedError{index: index, err: err})
+					mu.Unlock()
+				}
+			}
+		}(worker)
+	}
+
+dispatch:
+	for index := 0; index < count; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].index < failures[j].index
+	})
+	return failures
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -16,8 +18,13 @@ type Config struct {
 	General GeneralConfig `mapstructure:"general"`
 	Upload  UploadConfig  `mapstructure:"upload"`
 	UI      UIConfig      `mapstructure:"ui"`
+	Cache   CacheConfig   `mapstructure:"cache"`
+
+	// Named connection profiles, each overriding the [r2] section
+	Profiles map[string]R2Config `mapstructure:"profiles"`
 
 	// Runtime state (not persisted in config file)
+	Profile    string    // Active profile name ("" for the [r2] section)
 	TempBucket string    // Temporary bucket for current session
 	UserData   *UserData // User data loaded from user.data file
 }
@@ -31,6 +38,11 @@ type R2Config struct {
 	Endpoint        string            `mapstructure:"endpoint"`
 	Region          string            `mapstructure:"region"`
 	CustomDomains   map[string]string `mapstructure:"custom_domains"` // bucket -> domain mapping
+
+	// Options for S3-compatible servers other than Cloudflare R2
+	UsePathStyle bool   `mapstructure:"use_path_style"` // use https://host/bucket/key instead of https://bucket.host/key
+	Insecure     bool   `mapstructure:"insecure"`       // skip TLS certificate verification
+	CACertFile   string `mapstructure:"ca_cert_file"`   // PEM file with additional trusted CA certificates
 }
 
 // LogConfig holds logging configuration
@@ -52,11 +64,45 @@ type UploadConfig struct {
 	DefaultPublic         bool   `mapstructure:"default_public"`
 	AutoDetectContentType bool   `mapstructure:"auto_detect_content_type"`
 	DefaultCompress       string `mapstructure:"default_compress"`
+
+	// Multipart upload settings (sizes in MB)
+	MultipartThreshold   int `mapstructure:"multipart_threshold"`
+	MultipartPartSize    int `mapstructure:"multipart_part_size"`
+	MultipartConcurrency int `mapstructure:"multipart_concurrency"`
+
+	// Headers and user metadata set on every uploaded object
+	CacheControl       string            `mapstructure:"cache_control"`
+	ContentDisposition string            `mapstructure:"content_disposition"`
+	ContentEncoding    string            `mapstructure:"content_encoding"`
+	Metadata           map[string]string `mapstructure:"metadata"`
+
+	// Per-glob header rules, applied in order on top of the values above
+	HeaderRules []HeaderRule `mapstructure:"header_rules"`
+}
+
+// HeaderRule sets headers on uploaded keys matching a glob pattern. Patterns
+// without "/" match the file name only, e.g. "*.html" or "assets/**/*.woff2".
+type HeaderRule struct {
+	Pattern            string            `mapstructure:"pattern"`
+	ContentType        string            `mapstructure:"content_type"`
+	CacheControl       string            `mapstructure:"cache_control"`
+	ContentDisposition string            `mapstructure:"content_disposition"`
+	ContentEncoding    string            `mapstructure:"content_encoding"`
+	Metadata           map[string]string `mapstructure:"metadata"`
 }
 
 // UIConfig holds user interface configuration
 type UIConfig struct {
-	PageSize int `mapstructure:"page_size"`
+	PageSize      int `mapstructure:"page_size"`
+	TextPreviewKB int `mapstructure:"text_preview_kb"`
+}
+
+// CacheConfig holds the local preview cache configuration
+type CacheConfig struct {
+	Dir           string `mapstructure:"dir"`            // Defaults to r2s3-cli-cache in the system temp directory
+	MaxSizeMB     int    `mapstructure:"max_size_mb"`    // Size the cache is shrunk to by the cleanup policy
+	CleanupPolicy string `mapstructure:"cleanup_policy"` // lru, size or age
+	MaxAgeHours   int    `mapstructure:"max_age_hours"`  // Entry lifetime for the age policy
 }
 
 // Load loads configuration from multiple sources with priority:
@@ -64,7 +110,47 @@ type UIConfig struct {
 // 2. Environment variables
 // 3. Configuration file
 // 4. Defaults (lowest)
-func Load(configPath string) (*Config, error) {
+//
+// profile selects a [profiles.<name>] section; when empty, R2CLI_PROFILE and
+// then the profile saved by "profile use" are tried.
+func Load(configPath, profile string) (*Config, error) {
+	v, err := newViper(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the active profile on top of the [r2] section
+	profile = ResolveProfile(profile)
+	if err := applyProfile(v, profile); err != nil {
+		return nil, err
+	}
+
+	// Unmarshal configuration
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	config.Profile = profile
+
+	// Load user data
+	userData, err := LoadUserData(config.Profile)
+	if err != nil {
+		// Non-fatal error, continue with default user data
+		userData = createDefaultUserData(config.Profile)
+	}
+	config.UserData = userData
+
+	// Validate configuration
+	if err := Validate(&config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &config, nil
+}
+
+// newViper creates a viper instance with defaults, environment bindings and
+// the configuration file (if any) loaded
+func newViper(configPath string) (*viper.Viper, error) {
 	v := viper.New()
 
 	// Set defaults
@@ -82,12 +168,27 @@ func Load(configPath string) (*Config, error) {
 	v.BindEnv("r2.endpoint", "R2CLI_ENDPOINT")
 	v.BindEnv("r2.region", "R2CLI_REGION")
 	v.BindEnv("r2.custom_domains", "R2CLI_CUSTOM_DOMAINS")
+	v.BindEnv("r2.use_path_style", "R2CLI_USE_PATH_STYLE")
+	v.BindEnv("r2.insecure", "R2CLI_INSECURE")
+	v.BindEnv("r2.ca_cert_file", "R2CLI_CA_CERT_FILE")
 	v.BindEnv("log.level", "R2CLI_LOG_LEVEL")
 	v.BindEnv("log.format", "R2CLI_LOG_FORMAT")
+	v.BindEnv("general.default_timeout", "R2CLI_DEFAULT_TIMEOUT")
+	v.BindEnv("general.max_retries", "R2CLI_MAX_RETRIES")
 	v.BindEnv("upload.default_overwrite", "R2CLI_UPLOAD_DEFAULT_OVERWRITE")
 	v.BindEnv("upload.default_public", "R2CLI_UPLOAD_DEFAULT_PUBLIC")
 	v.BindEnv("upload.auto_detect_content_type", "R2CLI_UPLOAD_AUTO_DETECT_CONTENT_TYPE")
 	v.BindEnv("upload.default_compress", "R2CLI_UPLOAD_DEFAULT_COMPRESS")
+	v.BindEnv("upload.multipart_threshold", "R2CLI_UPLOAD_MULTIPART_THRESHOLD")
+	v.BindEnv("upload.multipart_part_size", "R2CLI_UPLOAD_MULTIPART_PART_SIZE")
+	v.BindEnv("upload.multipart_concurrency", "R2CLI_UPLOAD_MULTIPART_CONCURRENCY")
+	v.BindEnv("upload.cache_control", "R2CLI_UPLOAD_CACHE_CONTROL")
+	v.BindEnv("upload.content_disposition", "R2CLI_UPLOAD_CONTENT_DISPOSITION")
+	v.BindEnv("upload.content_encoding", "R2CLI_UPLOAD_CONTENT_ENCODING")
+	v.BindEnv("cache.dir", "R2CLI_CACHE_DIR")
+	v.BindEnv("cache.max_size_mb", "R2CLI_CACHE_MAX_SIZE_MB")
+	v.BindEnv("cache.cleanup_policy", "R2CLI_CACHE_CLEANUP_POLICY")
+	v.BindEnv("cache.max_age_hours", "R2CLI_CACHE_MAX_AGE_HOURS")
 
 	// Configuration file handling
 	if configPath != "" {
@@ -111,26 +212,7 @@ func Load(configPath string) (*Config, error) {
 		// Config file not found is not an error - we can use defaults and env vars
 	}
 
-	// Unmarshal configuration
-	var config Config
-	if err := v.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
-	}
-
-	// Load user data
-	userData, err := LoadUserData()
-	if err != nil {
-		// Non-fatal error, continue with default user data
-		userData = &UserData{}
-	}
-	config.UserData = userData
-
-	// Validate configuration
-	if err := Validate(&config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
-	}
-
-	return &config, nil
+	return v, nil
 }
 
 // setDefaults sets default values for configuration
@@ -138,6 +220,8 @@ func setDefaults(v *viper.Viper) {
 	// R2 defaults
 	v.SetDefault("r2.endpoint", "auto")
 	v.SetDefault("r2.region", "auto")
+	v.SetDefault("r2.use_path_style", false)
+	v.SetDefault("r2.insecure", false)
 
 	// Log defaults
 	v.SetDefault("log.level", "info")
@@ -152,9 +236,19 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("upload.default_public", false)
 	v.SetDefault("upload.auto_detect_content_type", true)
 	v.SetDefault("upload.default_compress", "")
+	v.SetDefault("upload.multipart_threshold", 100)
+	v.SetDefault("upload.multipart_part_size", 16)
+	v.SetDefault("upload.multipart_concurrency", 4)
 
 	// UI defaults
 	v.SetDefault("ui.page_size", 50)
+	v.SetDefault("ui.text_preview_kb", 64)
+
+	// Cache defaults
+	v.SetDefault("cache.dir", "")
+	v.SetDefault("cache.max_size_mb", 100)
+	v.SetDefault("cache.cleanup_policy", "lru")
+	v.SetDefault("cache.max_age_hours", 24)
 }
 
 // GetDefaultConfigPath returns the default configuration file path
@@ -221,7 +315,7 @@ func (c *Config) SetTempBucket(bucket string) {
 // SetMainBucket sets the main bucket in user data and saves it
 func (c *Config) SetMainBucket(bucket string) error {
 	if c.UserData == nil {
-		c.UserData = &UserData{}
+		c.UserData = createDefaultUserData(c.Profile)
 	}
 
 	return c.UserData.SetMainBucket(bucket)
@@ -240,6 +334,65 @@ func (c *Config) IsMainBucket(bucket string) bool {
 	return c.GetMainBucket() == bucket
 }
 
+// HasCustomEndpoint reports whether an explicit endpoint overrides the Cloudflare R2 default
+func (r *R2Config) HasCustomEndpoint() bool {
+	endpoint := strings.TrimSpace(r.Endpoint)
+	return endpoint != "" && endpoint != "auto"
+}
+
+// EndpointURL returns the base URL of the S3 API. An explicit endpoint wins,
+// otherwise the Cloudflare R2 endpoint for the account is used. Endpoints
+// without a scheme default to https.
+func (r *R2Config) EndpointURL() string {
+	if !r.HasCustomEndpoint() {
+		return fmt.Sprintf("https://%s.r2.cloudflarestorage.com", r.AccountID)
+	}
+
+	endpoint := strings.TrimSuffix(strings.TrimSpace(r.Endpoint), "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return endpoint
+}
+
+// Timeout returns default_timeout as a duration; zero means no timeout
+func (g *GeneralConfig) Timeout() time.Duration {
+	if g.DefaultTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(g.DefaultTimeout) * time.Second
+}
+
+// Directory returns the cache directory, expanding a leading "~"
+func (c *CacheConfig) Directory() string {
+	dir := strings.TrimSpace(c.Dir)
+	if dir == "" {
+		return filepath.Join(os.TempDir(), "r2s3-cli-cache")
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, dir[1:])
+		}
+	}
+	return dir
+}
+
+// MaxSizeBytes returns max_size_mb in bytes, 100MB when unset
+func (c *CacheConfig) MaxSizeBytes() int64 {
+	if c.MaxSizeMB <= 0 {
+		return 100 * 1024 * 1024
+	}
+	return int64(c.MaxSizeMB) * 1024 * 1024
+}
+
+// MaxAge returns max_age_hours as a duration; zero means the cache default
+func (c *CacheConfig) MaxAge() time.Duration {
+	if c.MaxAgeHours <= 0 {
+		return 0
+	}
+	return time.Duration(c.MaxAgeHours) * time.Hour
+}
+
 // GetCustomDomain returns the custom domain for a specific bucket
 func (c *Config) GetCustomDomain(bucket string) string {
 	if c.R2.CustomDomains == nil {
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfileName names the connection defined by the top-level [r2] section
+const DefaultProfileName = "default"
+
+// ProfileInfo describes a connection profile for display
+type ProfileInfo struct {
+	Name   string
+	R2     R2Config
+	Active bool
+}
+
+// ResolveProfile returns the profile to use with priority:
+// 1. Explicit name (--profile flag)
+// 2. R2CLI_PROFILE environment variable
+// 3. Profile saved with "profile use"
+// The default profile is returned as ""
+func ResolveProfile(name string) string {
+	if name == "" {
+		name = os.Getenv("R2CLI_PROFILE")
+	}
+	if name == "" {
+		name = LoadActiveProfile()
+	}
+	return normalizeProfileName(name)
+}
+
+// normalizeProfileName lowercases the name (viper keys are case-insensitive)
+// and maps the default profile to ""
+func normalizeProfileName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == DefaultProfileName {
+		return ""
+	}
+	return name
+}
+
+// applyProfile merges the named profile over the [r2] section. The profile is
+// merged at the configuration file level, so environment variables still take
+// precedence and keys left out of the profile keep the values from [r2].
+func applyProfile(v *viper.Viper, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if !isValidProfileName(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+
+	key := "profiles." + name
+	if !v.IsSet(key) {
+		var names []string
+		for profile := range v.GetStringMap("profiles") {
+			names = append(names, profile)
+		}
+		sort.Strings(names)
+		names = append([]string{DefaultProfileName}, names...)
+		return fmt.Errorf("profile %q not found in config (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	return v.MergeConfigMap(map[string]any{"r2": v.GetStringMap(key)})
+}
+
+// isValidProfileName checks that a profile name is safe to use in file names
+func isValidProfileName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		case char == '-' || char == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ProfileNames returns all profile names including the default profile, sorted
+func (c *Config) ProfileNames() []string {
+	names := []string{DefaultProfileName}
+	for name := range c.Profiles {
+		if name != DefaultProfileName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ActiveProfileName returns the display name of the active profile
+func (c *Config) ActiveProfileName() string {
+	if c.Profile == "" {
+		return DefaultProfileName
+	}
+	return c.Profile
+}
+
+// ListProfiles reads the configuration file and returns every profile without
+// validating credentials, so a broken profile can still be listed and switched away from
+func ListProfiles(configPath, active string) ([]ProfileInfo, error) {
+	v, err := newViper(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	active = ResolveProfile(active)
+	var profiles []ProfileInfo
+	for _, name := range config.ProfileNames() {
+		r2 := config.R2
+		if name != DefaultProfileName {
+			// Each profile is merged into a fresh copy of the configuration
+			if r2, err = loadProfileR2(configPath, name); err != nil {
+				return nil, err
+			}
+		}
+		profiles = append(profiles, ProfileInfo{
+			Name:   name,
+			R2:     r2,
+			Active: normalizeProfileName(name) == active,
+		})
+	}
+
+	return profiles, nil
+}
+
+// loadProfileR2 returns the [r2] section with the named profile applied
+func loadProfileR2(configPath, name string) (R2Config, error) {
+	v, err := newViper(configPath)
+	if err != nil {
+		return R2Config{}, err
+	}
+	if err := applyProfile(v, name); err != nil {
+		return R2Config{}, err
+	}
+
+	var r2 R2Config
+	if err := v.UnmarshalKey("r2", &r2); err != nil {
+		return R2Config{}, fmt.Errorf("failed to unmarshal profile %s: %w", name, err)
+	}
+	return r2, nil
+}
+
+// LoadActiveProfile returns the profile saved with "profile use", or "" if none
+func LoadActiveProfile() string {
+	path, err := getActiveProfilePath()
+	if err != nil {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SaveActiveProfile persists the profile used when no --profile flag or
+// R2CLI_PROFILE is given. Saving the default profile clears the setting.
+func SaveActiveProfile(name string) error {
+	path, err := getActiveProfilePath()
+	if err != nil {
+		return err
+	}
+
+	name = normalizeProfileName(name)
+	if name == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return os.WriteFile(path, []byte(name+"\n"), 0644)
+}
+
+// getActiveProfilePath returns the path to the file storing the active profile
+func getActiveProfilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	configDir := filepath.Join(homeDir, ".r2s3-cli")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "profile"), nil
+}
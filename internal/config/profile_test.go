@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profileTestConfig = `[r2]
+access_key_id = "default-key"
+access_key_secret = "default-secret"
+bucket_name = "default-bucket"
+endpoint = "https://s3.example.com"
+use_path_style = true
+insecure = true
+
+[profiles.minio]
+access_key_id = "minio-key"
+endpoint = "http://localhost:9000"
+
+[profiles.r2]
+account_id = "abc123"
+endpoint = "auto"
+bucket_name = "r2-bucket"
+use_path_style = false
+insecure = false
+`
+
+// writeProfileConfig writes a config file and isolates the saved active profile
+func writeProfileConfig(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("R2CLI_PROFILE", "")
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(profileTestConfig), 0644))
+	return path
+}
+
+func TestIsValidProfileName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"work":    true,
+		"dev-1":   true,
+		"Prod_EU": true,
+		"":        false,
+		"a b":     false,
+		"../etc":  false,
+		"café":    false,
+		// U+0167 truncated to a byte is 'g'
+		"ŧest": false,
+	} {
+		assert.Equal(t, want, isValidProfileName(name), "name %q", name)
+	}
+}
+
+func TestLoad_ProfileMerge(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	cfg, err := Load(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Profile)
+	assert.Equal(t, "default-key", cfg.R2.AccessKeyID)
+
+	// Keys missing from the profile keep the [r2] values
+	cfg, err = Load(path, "minio")
+	require.NoError(t, err)
+	assert.Equal(t, "minio", cfg.Profile)
+	assert.Equal(t, "minio-key", cfg.R2.AccessKeyID)
+	assert.Equal(t, "default-secret", cfg.R2.AccessKeySecret)
+	assert.Equal(t, "default-bucket", cfg.R2.BucketName)
+	assert.Equal(t, "http://localhost:9000", cfg.R2.Endpoint)
+	assert.True(t, cfg.R2.UsePathStyle)
+	assert.True(t, cfg.R2.Insecure)
+
+	// A profile can switch booleans back off
+	cfg, err = Load(path, "R2")
+	require.NoError(t, err)
+	assert.Equal(t, "r2-bucket", cfg.R2.BucketName)
+	assert.False(t, cfg.R2.UsePathStyle)
+	assert.False(t, cfg.R2.Insecure)
+
+	_, err = Load(path, "missing")
+	assert.ErrorContains(t, err, "available: default, minio, r2")
+	_, err = Load(path, "../minio")
+	assert.ErrorContains(t, err, "invalid profile name")
+}
+
+func TestLoad_EnvOverridesProfile(t *testing.T) {
+	path := writeProfileConfig(t)
+	t.Setenv("R2CLI_ENDPOINT", "http://env.example.com")
+	t.Setenv("R2CLI_USE_PATH_STYLE", "true")
+
+	cfg, err := Load(path, "minio")
+	require.NoError(t, err)
+	assert.Equal(t, "http://env.example.com", cfg.R2.Endpoint)
+	assert.Equal(t, "minio-key", cfg.R2.AccessKeyID)
+
+	cfg, err = Load(path, "r2")
+	require.NoError(t, err)
+	assert.True(t, cfg.R2.UsePathStyle)
+}
+
+func TestResolveProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("R2CLI_PROFILE", "")
+
+	assert.Equal(t, "", ResolveProfile(""))
+	assert.Equal(t, "", ResolveProfile("Default"))
+
+	require.NoError(t, SaveActiveProfile("saved"))
+	assert.Equal(t, "saved", ResolveProfile(""))
+
+	// The environment beats the saved profile, the flag beats both
+	t.Setenv("R2CLI_PROFILE", "Env")
+	assert.Equal(t, "env", ResolveProfile(""))
+	assert.Equal(t, "flag", ResolveProfile("flag"))
+
+	// Saving the default profile clears the setting
+	t.Setenv("R2CLI_PROFILE", "")
+	require.NoError(t, SaveActiveProfile(DefaultProfileName))
+	assert.Equal(t, "", ResolveProfile(""))
+}
+
+func TestListProfiles(t *testing.T) {
+	path := writeProfileConfig(t)
+
+	profiles, err := ListProfiles(path, "minio")
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	assert.Equal(t, DefaultProfileName, profiles[0].Name)
+	assert.Equal(t, "default-key", profiles[0].R2.AccessKeyID)
+	assert.Equal(t, "minio", profiles[1].Name)
+	assert.True(t, profiles[1].Active)
+	assert.Equal(t, "default-bucket", profiles[1].R2.BucketName)
+	assert.Equal(t, "r2", profiles[2].Name)
+	assert.False(t, profiles[2].R2.UsePathStyle)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,40 +14,43 @@ type UserData struct {
 	LastUsed   string    `json:"last_used"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+
+	profile string // profile this data belongs to ("" for the default profile)
 }
 
-// LoadUserData loads user data from the user.data file in the executable directory
-func LoadUserData() (*UserData, error) {
-	userDataPath, err := getUserDataPath()
+// LoadUserData loads the user data of the given profile ("" for the default profile)
+func LoadUserData(profile string) (*UserData, error) {
+	userDataPath, err := getUserDataPath(profile)
 	if err != nil {
-		return createDefaultUserData(), nil
+		return createDefaultUserData(profile), nil
 	}
 
 	// Check if file exists
 	if _, err := os.Stat(userDataPath); os.IsNotExist(err) {
 		// File doesn't exist, return default
-		return createDefaultUserData(), nil
+		return createDefaultUserData(profile), nil
 	}
 
 	// Read the file
 	data, err := os.ReadFile(userDataPath)
 	if err != nil {
-		return createDefaultUserData(), nil
+		return createDefaultUserData(profile), nil
 	}
 
 	// Parse JSON
 	var userData UserData
 	if err := json.Unmarshal(data, &userData); err != nil {
 		// Invalid JSON, return default
-		return createDefaultUserData(), nil
+		return createDefaultUserData(profile), nil
 	}
+	userData.profile = profile
 
 	return &userData, nil
 }
 
-// SaveUserData saves user data to the user.data file in the executable directory
+// SaveUserData saves user data to the user data file of its profile
 func (ud *UserData) SaveUserData() error {
-	userDataPath, err := getUserDataPath()
+	userDataPath, err := getUserDataPath(ud.profile)
 	if err != nil {
 		return err
 	}
@@ -82,18 +86,20 @@ func (ud *UserData) SetLastUsed(bucket string) error {
 }
 
 // createDefaultUserData creates a new UserData with default values
-func createDefaultUserData() *UserData {
+func createDefaultUserData(profile string) *UserData {
 	now := time.Now()
 	return &UserData{
 		MainBucket: "",
 		LastUsed:   "",
 		CreatedAt:  now,
 		UpdatedAt:  now,
+		profile:    profile,
 	}
 }
 
-// getUserDataPath returns the path to the user.data file
-func getUserDataPath() (string, error) {
+// getUserDataPath returns the path to the user data file of a profile:
+// user.data for the default profile, user.<profile>.data otherwise
+func getUserDataPath(profile string) (string, error) {
 	// Get user home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -108,5 +114,8 @@ func getUserDataPath() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(configDir, "user.data"), nil
+	if profile == "" {
+		return filepath.Join(configDir, "user.data"), nil
+	}
+	return filepath.Join(configDir, fmt.Sprintf("user.%s.data", profile)), nil
 }
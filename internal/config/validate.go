@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -23,13 +27,26 @@ func Validate(config *Config) error {
 		return fmt.Errorf("upload config validation failed: %w", err)
 	}
 
+	if err := validateCacheConfig(&config.Cache); err != nil {
+		return fmt.Errorf("cache config validation failed: %w", err)
+	}
+
 	return nil
 }
 
 // validateR2Config validates R2 specific configuration
 func validateR2Config(config *R2Config) error {
-	if strings.TrimSpace(config.AccountID) == "" {
-		return fmt.Errorf("account_id is required")
+	// account_id is only needed to build the Cloudflare R2 endpoint
+	if config.HasCustomEndpoint() {
+		endpoint, err := url.Parse(config.EndpointURL())
+		if err != nil || endpoint.Host == "" {
+			return fmt.Errorf("invalid endpoint: %s", config.Endpoint)
+		}
+		if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			return fmt.Errorf("invalid endpoint scheme %q: use http or https", endpoint.Scheme)
+		}
+	} else if strings.TrimSpace(config.AccountID) == "" {
+		return fmt.Errorf("account_id is required (or set an explicit endpoint)")
 	}
 
 	if strings.TrimSpace(config.AccessKeyID) == "" {
@@ -128,11 +145,64 @@ func isValidBucketName(name string) bool {
 
 // validateUploadConfig validates upload configuration
 func validateUploadConfig(config *UploadConfig) error {
-	// Upload config fields are all boolean or boolean-like, no specific validation needed
-	// Just ensure the config struct is not nil
 	if config == nil {
 		return fmt.Errorf("upload config cannot be nil")
 	}
+
+	// Zero values fall back to the uploader defaults
+	if config.MultipartThreshold < 0 {
+		return fmt.Errorf("multipart_threshold must be non-negative, got %d", config.MultipartThreshold)
+	}
+
+	// S3 requires every part except the last to be at least 5MB, and a single part at most 5GB
+	if config.MultipartPartSize != 0 && (config.MultipartPartSize < 5 || config.MultipartPartSize > 5120) {
+		return fmt.Errorf("multipart_part_size must be between 5 and 5120 MB, got %d", config.MultipartPartSize)
+	}
+
+	if config.MultipartConcurrency < 0 || config.MultipartConcurrency > 64 {
+		return fmt.Errorf("multipart_concurrency must be between 0 and 64, got %d", config.MultipartConcurrency)
+	}
+
+	for i, rule := range config.HeaderRules {
+		if rule.Pattern == "" {
+			return fmt.Errorf("header_rules[%d]: pattern is required", i)
+		}
+		// "**" is not valid path.Match syntax, so check the remaining segments only
+		if _, err := path.Match(strings.ReplaceAll(rule.Pattern, "**", "*"), ""); err != nil {
+			return fmt.Errorf("header_rules[%d]: invalid pattern %q: %w", i, rule.Pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCacheConfig validates preview cache configuration
+func validateCacheConfig(config *CacheConfig) error {
+	// Zero values fall back to the cache defaults
+	if config.MaxSizeMB < 0 {
+		return fmt.Errorf("max_size_mb must be non-negative, got %d", config.MaxSizeMB)
+	}
+
+	if config.MaxAgeHours < 0 {
+		return fmt.Errorf("max_age_hours must be non-negative, got %d", config.MaxAgeHours)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(config.CleanupPolicy)) {
+	case "", "lru", "size", "age":
+	default:
+		return fmt.Errorf("invalid cleanup_policy %q: must be lru, size or age", config.CleanupPolicy)
+	}
+
+	// The cache removes files it does not know about, so it must not share a
+	// directory with the user's own files
+	dir := filepath.Clean(config.Directory())
+	if filepath.Dir(dir) == dir {
+		return fmt.Errorf("dir must not be the filesystem root: %s", config.Dir)
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil && dir == filepath.Clean(homeDir) {
+		return fmt.Errorf("dir must not be the home directory: %s", config.Dir)
+	}
+
 	return nil
 }
 
@@ -2,50 +2,132 @@ package r2
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	appconfig "github.com/HaiFongPan/r2s3-cli/internal/config"
 )
 
-// Client wraps the S3 client for R2 operations
+// Client wraps the storage backend for R2 operations
 type Client struct {
-	s3Client *s3.Client
-	config   *appconfig.R2Config
+	storage Storage
+	config  *appconfig.R2Config
 }
 
-// NewClient creates a new R2 client from configuration
-func NewClient(cfg *appconfig.R2Config) (*Client, error) {
-	awsCfg, err := config.LoadDefaultConfig(context.TODO(),
+// NewClient creates a new R2 client from configuration. The general settings
+// provide the retry policy (max_retries) and the per-operation timeout
+// (default_timeout); a nil general keeps the SDK defaults.
+func NewClient(cfg *appconfig.R2Config, general *appconfig.GeneralConfig) (*Client, error) {
+	loadOptions := []func(*config.LoadOptions) error{
 		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKeyID,
 			cfg.AccessKeySecret,
 			"",
 		)),
 		config.WithRegion(cfg.Region),
-	)
+	}
+
+	var timeout time.Duration
+	if general != nil {
+		timeout = general.Timeout()
+		maxRetries := general.MaxRetries
+		loadOptions = append(loadOptions, config.WithRetryer(func() aws.Retryer {
+			return NewRetryer(maxRetries)
+		}))
+	}
+
+	// Custom TLS settings for self-hosted S3-compatible servers
+	var tlsConfig *tls.Config
+	if cfg.Insecure || cfg.CACertFile != "" {
+		var err error
+		tlsConfig, err = newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tlsConfig != nil || timeout > 0 {
+		httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			if tlsConfig != nil {
+				tr.TLSClientConfig = tlsConfig
+			}
+			// Bound connecting and waiting for a response, but not the body
+			// transfer, which grows with the object size
+			if timeout > 0 {
+				tr.TLSHandshakeTimeout = timeout
+				tr.ResponseHeaderTimeout = timeout
+			}
+		}).WithDialerOptions(func(dialer *net.Dialer) {
+			if timeout > 0 {
+				dialer.Timeout = timeout
+			}
+		})
+		loadOptions = append(loadOptions, config.WithHTTPClient(httpClient))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(), loadOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", cfg.AccountID))
+		o.BaseEndpoint = aws.String(cfg.EndpointURL())
+		o.UsePathStyle = cfg.UsePathStyle
+		if timeout > 0 {
+			o.APIOptions = append(o.APIOptions, addOperationTimeout(timeout))
+		}
 	})
 
+	return NewClientWithStorage(NewS3Storage(s3Client), cfg), nil
+}
+
+// NewClientWithStorage creates a client on top of any Storage backend
+func NewClientWithStorage(storage Storage, cfg *appconfig.R2Config) *Client {
 	return &Client{
-		s3Client: s3Client,
-		config:   cfg,
-	}, nil
+		storage: storage,
+		config:  cfg,
+	}
+}
+
+// newTLSConfig builds the TLS configuration from the insecure and CA certificate options
+func newTLSConfig(cfg *appconfig.R2Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.Insecure,
+	}
+
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate file %s: %w", cfg.CACertFile, err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
 }
 
-// GetS3Client returns the underlying S3 client
-func (c *Client) GetS3Client() interface{} {
-	return c.s3Client
+// Storage returns the storage backend
+func (c *Client) Storage() Storage {
+	return c.storage
 }
 
 // GetBucketName returns the configured bucket name
@@ -54,74 +136,125 @@ func (c *Client) GetBucketName() string {
 }
 
 // ListBuckets lists all buckets in the R2 account
-func (c *Client) ListBuckets(ctx context.Context) ([]types.Bucket, error) {
-	input := &s3.ListBucketsInput{}
-
-	result, err := c.s3Client.ListBuckets(ctx, input)
+func (c *Client) ListBuckets(ctx context.Context) ([]Bucket, error) {
+	buckets, err := c.storage.ListBuckets(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list buckets: %w", err)
 	}
 
-	return result.Buckets, nil
+	return buckets, nil
 }
 
 // GetBucketLocation gets the region/location of a specific bucket
 func (c *Client) GetBucketLocation(ctx context.Context, bucketName string) (string, error) {
-	input := &s3.GetBucketLocationInput{
-		Bucket: aws.String(bucketName),
-	}
-
-	result, err := c.s3Client.GetBucketLocation(ctx, input)
+	location, err := c.storage.BucketLocation(ctx, bucketName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get bucket location for %s: %w", bucketName, err)
 	}
 
 	// Handle empty location constraint (default region)
-	if result.LocationConstraint == "" {
+	if location == "" {
 		return "us-east-1", nil
 	}
 
-	return string(result.LocationConstraint), nil
+	return location, nil
 }
 
 // HeadBucket checks if a bucket exists and is accessible
 func (c *Client) HeadBucket(ctx context.Context, bucketName string) error {
-	input := &s3.HeadBucketInput{
-		Bucket: aws.String(bucketName),
+	if err := c.storage.HeadBucket(ctx, bucketName); err != nil {
+		return fmt.Errorf("failed to head bucket %s: %w", bucketName, err)
+	}
+
+	return nil
+}
+
+// GetBucketPolicy retrieves the bucket policy for a specific bucket, empty
+// when the bucket has none
+func (c *Client) GetBucketPolicy(ctx context.Context, bucketName string) (string, error) {
+	policy, err := c.storage.BucketPolicy(ctx, bucketName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket policy for %s: %w", bucketName, err)
 	}
 
-	_, err := c.s3Client.HeadBucket(ctx, input)
+	return policy, nil
+}
+
+// GetBucketWebsite retrieves the website configuration for a specific bucket,
+// nil when website hosting is disabled
+func (c *Client) GetBucketWebsite(ctx context.Context, bucketName string) (*WebsiteConfig, error) {
+	website, err := c.storage.BucketWebsite(ctx, bucketName)
 	if err != nil {
-		return fmt.Errorf("failed to head bucket %s: %w", bucketName, err)
+		return nil, fmt.Errorf("failed to get bucket website configuration for %s: %w", bucketName, err)
+	}
+
+	return website, nil
+}
+
+// CreateBucket creates a new bucket
+func (c *Client) CreateBucket(ctx context.Context, bucketName string) error {
+	if err := c.storage.CreateBucket(ctx, bucketName); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucketName, err)
 	}
 
 	return nil
 }
 
-// GetBucketPolicy retrieves the bucket policy for a specific bucket
-func (c *Client) GetBucketPolicy(ctx context.Context, bucketName string) (*s3.GetBucketPolicyOutput, error) {
-	input := &s3.GetBucketPolicyInput{
-		Bucket: aws.String(bucketName),
+// DeleteBucket deletes an empty bucket
+func (c *Client) DeleteBucket(ctx context.Context, bucketName string) error {
+	if err := c.storage.DeleteBucket(ctx, bucketName); err != nil {
+		return fmt.Errorf("failed to delete bucket %s: %w", bucketName, err)
 	}
 
-	result, err := c.s3Client.GetBucketPolicy(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get bucket policy for %s: %w", bucketName, err)
+	return nil
+}
+
+// GetBucketInfo collects the creation date, location, policy and website
+// configuration of a bucket. It fails only when the bucket cannot be
+// accessed; the optional parts record their own errors instead.
+func (c *Client) GetBucketInfo(ctx context.Context, bucketName string) (*BucketInfo, error) {
+	bucket := Bucket{Name: bucketName}
+
+	// HeadBucket does not report the creation date, the listing does
+	if buckets, err := c.ListBuckets(ctx); err == nil {
+		for _, listed := range buckets {
+			if listed.Name == bucketName {
+				bucket = listed
+				break
+			}
+		}
 	}
 
-	return result, nil
+	return c.GetBucketDetails(ctx, bucket)
 }
 
-// GetBucketWebsite retrieves the website configuration for a specific bucket
-func (c *Client) GetBucketWebsite(ctx context.Context, bucketName string) (*s3.GetBucketWebsiteOutput, error) {
-	input := &s3.GetBucketWebsiteInput{
-		Bucket: aws.String(bucketName),
+// GetBucketDetails is GetBucketInfo for a bucket that was already listed, so
+// the creation date does not need another ListBuckets call
+func (c *Client) GetBucketDetails(ctx context.Context, bucket Bucket) (*BucketInfo, error) {
+	bucketName := bucket.Name
+	if err := c.HeadBucket(ctx, bucketName); err != nil {
+		return nil, err
 	}
 
-	result, err := c.s3Client.GetBucketWebsite(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get bucket website configuration for %s: %w", bucketName, err)
+	info := NewBucketInfo(bucket)
+
+	if region, err := c.GetBucketLocation(ctx, bucketName); err != nil {
+		info.SetError(err)
+	} else {
+		info.SetRegion(region)
+	}
+
+	if policy, err := c.GetBucketPolicy(ctx, bucketName); err != nil {
+		info.SetPolicy(NewBucketPolicyInfoWithError(err))
+	} else {
+		info.SetPolicy(NewBucketPolicyInfo(policy))
+	}
+
+	if website, err := c.GetBucketWebsite(ctx, bucketName); err != nil {
+		info.SetWebsite(NewBucketWebsiteInfoWithError(err))
+	} else {
+		info.SetWebsite(NewBucketWebsiteInfo(website))
 	}
 
-	return result, nil
+	return info, nil
 }
@@ -0,0 +1,920 @@
+package r2test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const s3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// s3Error is an error response in the S3 XML format
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func noSuchBucket(name string) *s3Error {
+	return &s3Error{http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist: " + name}
+}
+
+func noSuchKey(key string) *s3Error {
+	return &s3Error{http.StatusNotFound, "NoSuchKey", "The specified key does not exist: " + key}
+}
+
+func noSuchUpload(id string) *s3Error {
+	return &s3Error{http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist: " + id}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		writeError(w, r, &s3Error{status, http.StatusText(status), "injected failure"})
+		return
+	}
+	s.mu.Unlock()
+
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	var err *s3Error
+	switch {
+	case bucketName == "":
+		err = s.route(r, "ListBuckets", http.MethodGet, func() *s3Error { return s.listBuckets(w) })
+	case key == "":
+		err = s.serveBucket(w, r, bucketName, query)
+	default:
+		err = s.serveObject(w, r, bucketName, key, query)
+	}
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// route records the operation and runs handler when the method matches
+func (s *Server) route(r *http.Request, operation, method string, handler func() *s3Error) *s3Error {
+	if r.Method != method {
+		return &s3Error{http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource."}
+	}
+	s.mu.Lock()
+	s.operations = append(s.operations, operation)
+	hook := s.hooks[operation]
+	s.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	return handler()
+}
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucketName string, query url.Values) *s3Error {
+	switch {
+	case r.Method == http.MethodGet && query.Has("policy"):
+		return s.route(r, "GetBucketPolicy", http.MethodGet, func() *s3Error {
+			return s.missingBucketConfig(bucketName, "NoSuchBucketPolicy", "The bucket policy does not exist")
+		})
+	case r.Method == http.MethodGet && query.Has("website"):
+		return s.route(r, "GetBucketWebsite", http.MethodGet, func() *s3Error {
+			return s.missingBucketConfig(bucketName, "NoSuchWebsiteConfiguration", "The specified bucket does not have a website configuration")
+		})
+	case r.Method == http.MethodGet && query.Has("location"):
+		return s.route(r, "GetBucketLocation", http.MethodGet, func() *s3Error { return s.getBucketLocation(w, bucketName) })
+	case r.Method == http.MethodGet:
+		return s.route(r, "ListObjectsV2", http.MethodGet, func() *s3Error { return s.listObjects(w, bucketName, query) })
+	case r.Method == http.MethodHead:
+		return s.route(r, "HeadBucket", http.MethodHead, func() *s3Error { return s.headBucket(w, bucketName) })
+	case r.Method == http.MethodPut:
+		return s.route(r, "CreateBucket", http.MethodPut, func() *s3Error { return s.createBucket(w, bucketName) })
+	case r.Method == http.MethodDelete:
+		return s.route(r, "DeleteBucket", http.MethodDelete, func() *s3Error { return s.deleteBucket(w, bucketName) })
+	case r.Method == http.MethodPost && query.Has("delete"):
+		return s.route(r, "DeleteObjects", http.MethodPost, func() *s3Error { return s.deleteObjects(w, r, bucketName) })
+	}
+	return &s3Error{http.StatusNotImplemented, "NotImplemented", "Bucket operation not supported by the fake server"}
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucketName, key string, query url.Values) *s3Error {
+	uploadID := query.Get("uploadId")
+	copySource := r.Header.Get("X-Amz-Copy-Source")
+
+	switch {
+	case r.Method == http.MethodPut && uploadID != "" && copySource != "":
+		return s.route(r, "UploadPartCopy", http.MethodPut, func() *s3Error { return s.uploadPartCopy(w, r, uploadID, query, copySource) })
+	case r.Method == http.MethodPut && uploadID != "":
+		return s.route(r, "UploadPart", http.MethodPut, func() *s3Error { return s.uploadPart(w, r, uploadID, query) })
+	case r.Method == http.MethodPut && copySource != "":
+		return s.route(r, "CopyObject", http.MethodPut, func() *s3Error { return s.copyObject(w, r, bucketName, key, copySource) })
+	case r.Method == http.MethodPut:
+		return s.route(r, "PutObject", http.MethodPut, func() *s3Error { return s.putObject(w, r, bucketName, key) })
+	case r.Method == http.MethodGet && uploadID != "":
+		return s.route(r, "ListParts", http.MethodGet, func() *s3Error { return s.listParts(w, uploadID) })
+	case r.Method == http.MethodGet:
+		return s.route(r, "GetObject", http.MethodGet, func() *s3Error { return s.getObject(w, r, bucketName, key, query) })
+	case r.Method == http.MethodHead:
+		return s.route(r, "HeadObject", http.MethodHead, func() *s3Error { return s.headObject(w, bucketName, key) })
+	case r.Method == http.MethodDelete && uploadID != "":
+		return s.route(r, "AbortMultipartUpload", http.MethodDelete, func() *s3Error { return s.abortUpload(w, uploadID) })
+	case r.Method == http.MethodDelete:
+		return s.route(r, "DeleteObject", http.MethodDelete, func() *s3Error { return s.deleteObject(w, bucketName, key) })
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		return s.route(r, "CreateMultipartUpload", http.MethodPost, func() *s3Error { return s.createUpload(w, r, bucketName, key) })
+	case r.Method == http.MethodPost && uploadID != "":
+		return s.route(r, "CompleteMultipartUpload", http.MethodPost, func() *s3Error { return s.completeUpload(w, r, uploadID) })
+	}
+	return &s3Error{http.StatusNotImplemented, "NotImplemented", "Object operation not supported by the fake server"}
+}
+
+// --- Buckets ---
+
+type listBucketsResult struct {
+	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Owner   struct {
+		ID          string `xml:"ID"`
+		DisplayName string `xml:"DisplayName"`
+	} `xml:"Owner"`
+	Buckets []bucketEntry `xml:"Buckets>Bucket"`
+}
+
+type bucketEntry struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) *s3Error {
+	s.mu.Lock()
+	result := listBucketsResult{Xmlns: s3Namespace}
+	result.Owner.ID = "r2test"
+	result.Owner.DisplayName = "r2test"
+	for name, b := range s.buckets {
+		result.Buckets = append(result.Buckets, bucketEntry{Name: name, CreationDate: formatTime(b.created)})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result.Buckets, func(i, j int) bool { return result.Buckets[i].Name < result.Buckets[j].Name })
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) headBucket(w http.ResponseWriter, bucketName string) *s3Error {
+	s.mu.Lock()
+	_, exists := s.buckets[bucketName]
+	s.mu.Unlock()
+
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getBucketLocation(w http.ResponseWriter, bucketName string) *s3Error {
+	s.mu.Lock()
+	_, exists := s.buckets[bucketName]
+	s.mu.Unlock()
+
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+	writeXML(w, http.StatusOK, struct {
+		XMLName xml.Name `xml:"LocationConstraint"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Value   string   `xml:",chardata"`
+	}{Xmlns: s3Namespace, Value: "auto"})
+	return nil
+}
+
+// missingBucketConfig answers requests for bucket settings the fake server
+// never stores, such as policies and website configurations
+func (s *Server) missingBucketConfig(bucketName, code, message string) *s3Error {
+	s.mu.Lock()
+	_, exists := s.buckets[bucketName]
+	s.mu.Unlock()
+
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+	return &s3Error{http.StatusNotFound, code, message}
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, bucketName string) *s3Error {
+	s.mu.Lock()
+	_, exists := s.buckets[bucketName]
+	s.mu.Unlock()
+
+	if exists {
+		return &s3Error{http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it."}
+	}
+	s.CreateBucket(bucketName)
+	w.Header().Set("Location", "/"+bucketName)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, bucketName string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+	if len(b.objects) > 0 {
+		return &s3Error{http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty."}
+	}
+	delete(s.buckets, bucketName)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// --- Listing ---
+
+type listObjectsResult struct {
+	XMLName               xml.Name      `xml:"ListBucketResult"`
+	Xmlns                 string        `xml:"xmlns,attr"`
+	Name                  string        `xml:"Name"`
+	Prefix                string        `xml:"Prefix"`
+	Delimiter             string        `xml:"Delimiter,omitempty"`
+	StartAfter            string        `xml:"StartAfter,omitempty"`
+	ContinuationToken     string        `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string        `xml:"NextContinuationToken,omitempty"`
+	MaxKeys               int           `xml:"MaxKeys"`
+	KeyCount              int           `xml:"KeyCount"`
+	IsTruncated           bool          `xml:"IsTruncated"`
+	Contents              []objectEntry `xml:"Contents"`
+	CommonPrefixes        []prefixEntry `xml:"CommonPrefixes"`
+}
+
+type objectEntry struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type prefixEntry struct {
+	Prefix string `xml:"Prefix"`
+}
+
+func (s *Server) listObjects(w http.ResponseWriter, bucketName string, query url.Values) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	maxKeys := 1000
+	if value := query.Get("max-keys"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return &s3Error{http.StatusBadRequest, "InvalidArgument", "Invalid max-keys: " + value}
+		}
+		maxKeys = min(parsed, 1000)
+	}
+
+	// The continuation token is simply the last key or common prefix returned
+	marker := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		marker = token
+	}
+
+	result := listObjectsResult{
+		Xmlns:             s3Namespace,
+		Name:              bucketName,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		StartAfter:        query.Get("start-after"),
+		ContinuationToken: query.Get("continuation-token"),
+		MaxKeys:           maxKeys,
+	}
+
+	last := ""
+	for _, key := range sortedKeys(b.objects) {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		// Keys grouped under a common prefix that was already returned
+		if delimiter != "" && strings.HasSuffix(marker, delimiter) && strings.HasPrefix(key, marker) {
+			continue
+		}
+
+		entry := key
+		isPrefix := false
+		if delimiter != "" {
+			if index := strings.Index(key[len(prefix):], delimiter); index >= 0 {
+				entry = key[:len(prefix)+index+len(delimiter)]
+				isPrefix = true
+			}
+		}
+		if entry == last {
+			continue
+		}
+
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = last
+			break
+		}
+
+		if isPrefix {
+			result.CommonPrefixes = append(result.CommonPrefixes, prefixEntry{Prefix: entry})
+		} else {
+			object := b.objects[key]
+			result.Contents = append(result.Contents, objectEntry{
+				Key:          key,
+				LastModified: formatTime(object.LastModified),
+				ETag:         `"` + object.ETag + `"`,
+				Size:         int64(len(object.Body)),
+				StorageClass: "STANDARD",
+			})
+		}
+		result.KeyCount++
+		last = entry
+	}
+
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+// --- Objects ---
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, bucketName, key string) *s3Error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(r, body); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+
+	object := newObject(key, body, headersFromRequest(r))
+	b.objects[key] = object
+
+	w.Header().Set("ETag", `"`+object.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// lookup returns a stored object; the caller must hold s.mu
+func (s *Server) lookup(bucketName, key string) (*Object, *s3Error) {
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return nil, noSuchBucket(bucketName)
+	}
+	object, exists := b.objects[key]
+	if !exists {
+		return nil, noSuchKey(key)
+	}
+	return object, nil
+}
+
+func (s *Server) headObject(w http.ResponseWriter, bucketName, key string) *s3Error {
+	s.mu.Lock()
+	object, err := s.lookup(bucketName, key)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	writeObjectHeaders(w, object)
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.Body)))
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, bucketName, key string, query url.Values) *s3Error {
+	s.mu.Lock()
+	object, err := s.lookup(bucketName, key)
+	var snapshot Object
+	if err == nil {
+		snapshot = object.clone()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	writeObjectHeaders(w, &snapshot)
+	if disposition := query.Get("response-content-disposition"); disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+
+	body := snapshot.Body
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		start, end, ok := parseRange(rangeHeader, int64(len(body)))
+		if !ok {
+			return &s3Error{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable"}
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(body)))
+		body = body[start : end+1]
+		status = http.StatusPartialContent
+	} else {
+		// Lets the SDK validate the payload instead of warning that it cannot
+		w.Header().Set("X-Amz-Checksum-Crc32", crc32Checksum(body))
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+	return nil
+}
+
+func (s *Server) deleteObject(w http.ResponseWriter, bucketName, key string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+	// Deleting a missing key succeeds, as on S3
+	delete(b.objects, key)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type deleteRequest struct {
+	Quiet   bool `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name `xml:"DeleteResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Deleted []struct {
+		Key string `xml:"Key"`
+	} `xml:"Deleted"`
+}
+
+func (s *Server) deleteObjects(w http.ResponseWriter, r *http.Request, bucketName string) *s3Error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	var request deleteRequest
+	if decodeErr := xml.Unmarshal(body, &request); decodeErr != nil {
+		return &s3Error{http.StatusBadRequest, "MalformedXML", decodeErr.Error()}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+
+	result := deleteResult{Xmlns: s3Namespace}
+	for _, object := range request.Objects {
+		delete(b.objects, object.Key)
+		if !request.Quiet {
+			result.Deleted = append(result.Deleted, struct {
+				Key string `xml:"Key"`
+			}{object.Key})
+		}
+	}
+
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+type copyResult struct {
+	XMLName      xml.Name
+	Xmlns        string `xml:"xmlns,attr"`
+	ETag         string `xml:"ETag"`
+	LastModified string `xml:"LastModified"`
+}
+
+// copySourceObject resolves an x-amz-copy-source header; the caller must hold s.mu
+func (s *Server) copySourceObject(copySource string) (*Object, *s3Error) {
+	source, err := url.PathUnescape(copySource)
+	if err != nil {
+		return nil, &s3Error{http.StatusBadRequest, "InvalidArgument", "Invalid copy source: " + copySource}
+	}
+	source, _, _ = strings.Cut(source, "?versionId=")
+	sourceBucket, sourceKey, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || sourceKey == "" {
+		return nil, &s3Error{http.StatusBadRequest, "InvalidArgument", "Invalid copy source: " + copySource}
+	}
+	return s.lookup(sourceBucket, sourceKey)
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request, bucketName, key, copySource string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, err := s.copySourceObject(copySource)
+	if err != nil {
+		return err
+	}
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return noSuchBucket(bucketName)
+	}
+
+	// COPY keeps the source headers; REPLACE takes them from the request
+	headers := source
+	if strings.EqualFold(r.Header.Get("X-Amz-Metadata-Directive"), "REPLACE") {
+		headers = headersFromRequest(r)
+	}
+
+	object := newObject(key, source.Body, headers)
+	b.objects[key] = object
+
+	writeXML(w, http.StatusOK, copyResult{
+		XMLName:      xml.Name{Local: "CopyObjectResult"},
+		Xmlns:        s3Namespace,
+		ETag:         `"` + object.ETag + `"`,
+		LastModified: formatTime(object.LastModified),
+	})
+	return nil
+}
+
+// --- Multipart uploads ---
+
+type initiateResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request, bucketName, key string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.buckets[bucketName]; !exists {
+		return noSuchBucket(bucketName)
+	}
+
+	s.nextUploadID++
+	uploadID := fmt.Sprintf("upload-%d", s.nextUploadID)
+	s.uploads[uploadID] = &multipartUpload{
+		bucket:  bucketName,
+		key:     key,
+		headers: headersFromRequest(r),
+		parts:   make(map[int]*uploadPart),
+	}
+
+	writeXML(w, http.StatusOK, initiateResult{Xmlns: s3Namespace, Bucket: bucketName, Key: key, UploadID: uploadID})
+	return nil
+}
+
+func parsePartNumber(query url.Values) (int, *s3Error) {
+	number, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || number < 1 || number > 10000 {
+		return 0, &s3Error{http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000"}
+	}
+	return number, nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, uploadID string, query url.Values) *s3Error {
+	number, err := parsePartNumber(query)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(r, body); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, exists := s.uploads[uploadID]
+	if !exists {
+		return noSuchUpload(uploadID)
+	}
+	part := newPart(body)
+	upload.parts[number] = part
+
+	w.Header().Set("ETag", `"`+part.etag+`"`)
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) uploadPartCopy(w http.ResponseWriter, r *http.Request, uploadID string, query url.Values, copySource string) *s3Error {
+	number, err := parsePartNumber(query)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, exists := s.uploads[uploadID]
+	if !exists {
+		return noSuchUpload(uploadID)
+	}
+	source, err := s.copySourceObject(copySource)
+	if err != nil {
+		return err
+	}
+
+	body := source.Body
+	if sourceRange := r.Header.Get("X-Amz-Copy-Source-Range"); sourceRange != "" {
+		start, end, ok := parseRange(sourceRange, int64(len(body)))
+		if !ok {
+			return &s3Error{http.StatusBadRequest, "InvalidArgument", "Invalid copy source range: " + sourceRange}
+		}
+		body = body[start : end+1]
+	}
+	part := newPart(body)
+	upload.parts[number] = part
+
+	writeXML(w, http.StatusOK, copyResult{
+		XMLName:      xml.Name{Local: "CopyPartResult"},
+		Xmlns:        s3Namespace,
+		ETag:         `"` + part.etag + `"`,
+		LastModified: formatTime(part.lastModified),
+	})
+	return nil
+}
+
+type listPartsResult struct {
+	XMLName     xml.Name    `xml:"ListPartsResult"`
+	Xmlns       string      `xml:"xmlns,attr"`
+	Bucket      string      `xml:"Bucket"`
+	Key         string      `xml:"Key"`
+	UploadID    string      `xml:"UploadId"`
+	MaxParts    int         `xml:"MaxParts"`
+	IsTruncated bool        `xml:"IsTruncated"`
+	Parts       []partEntry `xml:"Part"`
+}
+
+type partEntry struct {
+	PartNumber   int    `xml:"PartNumber"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	LastModified string `xml:"LastModified"`
+}
+
+func (s *Server) listParts(w http.ResponseWriter, uploadID string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, exists := s.uploads[uploadID]
+	if !exists {
+		return noSuchUpload(uploadID)
+	}
+
+	result := listPartsResult{Xmlns: s3Namespace, Bucket: upload.bucket, Key: upload.key, UploadID: uploadID, MaxParts: 10000}
+	for number, part := range upload.parts {
+		result.Parts = append(result.Parts, partEntry{
+			PartNumber:   number,
+			ETag:         `"` + part.etag + `"`,
+			Size:         int64(len(part.body)),
+			LastModified: formatTime(part.lastModified),
+		})
+	}
+	sort.Slice(result.Parts, func(i, j int) bool { return result.Parts[i].PartNumber < result.Parts[j].PartNumber })
+
+	writeXML(w, http.StatusOK, result)
+	return nil
+}
+
+type completeRequest struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, r *http.Request, uploadID string) *s3Error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	var request completeRequest
+	if decodeErr := xml.Unmarshal(body, &request); decodeErr != nil {
+		return &s3Error{http.StatusBadRequest, "MalformedXML", decodeErr.Error()}
+	}
+	if len(request.Parts) == 0 {
+		return &s3Error{http.StatusBadRequest, "MalformedXML", "The upload must contain at least one part"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, exists := s.uploads[uploadID]
+	if !exists {
+		return noSuchUpload(uploadID)
+	}
+	b, exists := s.buckets[upload.bucket]
+	if !exists {
+		return noSuchBucket(upload.bucket)
+	}
+
+	var parts []*uploadPart
+	var content bytes.Buffer
+	previous := 0
+	for _, requested := range request.Parts {
+		if requested.PartNumber <= previous {
+			return &s3Error{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+		}
+		previous = requested.PartNumber
+
+		part, exists := upload.parts[requested.PartNumber]
+		if !exists || strings.Trim(requested.ETag, `"`) != part.etag {
+			return &s3Error{http.StatusBadRequest, "InvalidPart", fmt.Sprintf("Part %d could not be found or its ETag does not match.", requested.PartNumber)}
+		}
+		parts = append(parts, part)
+		content.Write(part.body)
+	}
+
+	object := newObject(upload.key, content.Bytes(), upload.headers)
+	object.ETag = multipartETag(parts)
+	b.objects[upload.key] = object
+	delete(s.uploads, uploadID)
+
+	writeXML(w, http.StatusOK, completeResult{
+		Xmlns:    s3Namespace,
+		Location: "/" + upload.bucket + "/" + upload.key,
+		Bucket:   upload.bucket,
+		Key:      upload.key,
+		ETag:     `"` + object.ETag + `"`,
+	})
+	return nil
+}
+
+func (s *Server) abortUpload(w http.ResponseWriter, uploadID string) *s3Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.uploads[uploadID]; !exists {
+		return noSuchUpload(uploadID)
+	}
+	delete(s.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func newPart(body []byte) *uploadPart {
+	object := newObject("", body, &Object{})
+	return &uploadPart{body: object.Body, etag: object.ETag, lastModified: object.LastModified}
+}
+
+// --- Encoding helpers ---
+
+// crc32Checksum returns the base64 big-endian CRC32 used by x-amz-checksum-crc32
+func crc32Checksum(body []byte) string {
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.ChecksumIEEE(body))
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func writeXML(w http.ResponseWriter, status int, value any) {
+	body, err := xml.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Length", strconv.Itoa(len(xml.Header)+len(body)))
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err *s3Error) {
+	// HEAD responses carry no body, clients only see the status code
+	if r.Method == http.MethodHead {
+		w.WriteHeader(err.status)
+		return
+	}
+	writeXML(w, err.status, struct {
+		XMLName  xml.Name `xml:"Error"`
+		Code     string   `xml:"Code"`
+		Message  string   `xml:"Message"`
+		Resource string   `xml:"Resource"`
+	}{Code: err.code, Message: err.message, Resource: r.URL.Path})
+}
+
+// readBody reads a request body, decoding the aws-chunked framing the SDK
+// uses when it streams a trailing checksum
+func readBody(r *http.Request) ([]byte, *s3Error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, &s3Error{http.StatusBadRequest, "IncompleteBody", err.Error()}
+	}
+
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return body, nil
+	}
+
+	decoded, err := decodeAWSChunked(body)
+	if err != nil {
+		return nil, &s3Error{http.StatusBadRequest, "IncompleteBody", err.Error()}
+	}
+	return decoded, nil
+}
+
+// decodeAWSChunked strips the "<hex size>[;extensions]\r\n<data>\r\n" framing
+// and ignores the trailing headers
+func decodeAWSChunked(body []byte) ([]byte, error) {
+	reader := bufio.NewReader(bytes.NewReader(body))
+	var decoded bytes.Buffer
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("malformed aws-chunked body: %w", err)
+		}
+		sizeField, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeField, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed aws-chunked size %q", sizeField)
+		}
+		if size == 0 {
+			return decoded.Bytes(), nil
+		}
+		if _, err := io.CopyN(&decoded, reader, size); err != nil {
+			return nil, fmt.Errorf("truncated aws-chunked body: %w", err)
+		}
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, fmt.Errorf("malformed aws-chunked body: %w", err)
+		}
+	}
+}
+
+// parseRange parses a single "bytes=start-end" range against size
+func parseRange(header string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	startField, endField, ok := strings.Cut(spec, "-")
+	if !ok || size == 0 {
+		return 0, 0, false
+	}
+
+	// "bytes=-n" asks for the last n bytes
+	if startField == "" {
+		n, err := strconv.ParseInt(endField, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		return max(0, size-n), size - 1, true
+	}
+
+	start, err := strconv.ParseInt(startField, 10, 64)
+	if err != nil || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if endField != "" {
+		end, err = strconv.ParseInt(endField, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true
+}
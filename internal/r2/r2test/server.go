@@ -0,0 +1,328 @@
+// Package r2test provides an in-process S3-compatible server for end-to-end
+// tests. It speaks enough of the S3 REST protocol (path-style addressing only)
+// for the commands and the file browser: bucket listing, creation and
+// deletion, ListObjectsV2,
+// Put/Get/Head/Delete/Copy object, DeleteObjects and multipart uploads.
+// Request signatures are not checked.
+package r2test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// Object is a stored object as seen by the server
+type Object struct {
+	Key                string
+	Body               []byte
+	ETag               string // without quotes
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Metadata           map[string]string
+	LastModified       time.Time
+}
+
+type bucket struct {
+	created time.Time
+	objects map[string]*Object
+}
+
+type uploadPart struct {
+	body         []byte
+	etag         string
+	lastModified time.Time
+}
+
+type multipartUpload struct {
+	bucket  string
+	key     string
+	headers *Object // headers and metadata given when the upload was created
+	parts   map[int]*uploadPart
+}
+
+// Server is a fake S3 endpoint backed by memory
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	buckets      map[string]*bucket
+	uploads      map[string]*multipartUpload
+	nextUploadID int
+	operations   []string
+	failures     []int // status codes returned by the next requests
+	hooks        map[string]func()
+}
+
+// NewServer starts a server with the given buckets already created. It is
+// closed when the test finishes.
+func NewServer(tb testing.TB, buckets ...string) *Server {
+	tb.Helper()
+
+	s := &Server{
+		buckets: make(map[string]*bucket),
+		uploads: make(map[string]*multipartUpload),
+		hooks:   make(map[string]func()),
+	}
+	for _, name := range buckets {
+		s.CreateBucket(name)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// R2Config returns connection settings that point the client at this server
+func (s *Server) R2Config(bucketName string) config.R2Config {
+	return config.R2Config{
+		AccessKeyID:     "test-access-key",
+		AccessKeySecret: "test-secret-key",
+		BucketName:      bucketName,
+		Endpoint:        s.URL,
+		Region:          "auto",
+		UsePathStyle:    true,
+	}
+}
+
+// Client creates an r2.Client connected to this server
+func (s *Server) Client(tb testing.TB, bucketName string) *r2.Client {
+	tb.Helper()
+
+	cfg := s.R2Config(bucketName)
+	client, err := r2.NewClient(&cfg, &config.GeneralConfig{DefaultTimeout: 10, MaxRetries: 3})
+	if err != nil {
+		tb.Fatalf("failed to create client for fake S3 server: %v", err)
+	}
+	return client
+}
+
+// CreateBucket adds an empty bucket; existing buckets are left untouched
+func (s *Server) CreateBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.buckets[name]; !exists {
+		s.buckets[name] = &bucket{created: time.Now().UTC(), objects: make(map[string]*Object)}
+	}
+}
+
+// PutObject stores an object directly, creating the bucket if needed
+func (s *Server) PutObject(bucketName, key string, body []byte) {
+	s.CreateBucket(bucketName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buckets[bucketName].objects[key] = newObject(key, body, &Object{ContentType: "application/octet-stream"})
+}
+
+// Object returns a copy of a stored object
+func (s *Server) Object(bucketName, key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return Object{}, false
+	}
+	object, exists := b.objects[key]
+	if !exists {
+		return Object{}, false
+	}
+	return object.clone(), true
+}
+
+// Keys returns the sorted keys stored in a bucket
+func (s *Server) Keys(bucketName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, exists := s.buckets[bucketName]
+	if !exists {
+		return nil
+	}
+	return sortedKeys(b.objects)
+}
+
+// Buckets returns the sorted bucket names
+func (s *Server) Buckets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PendingUploads returns the number of multipart uploads neither completed nor aborted
+func (s *Server) PendingUploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+// Operations returns the S3 operations served so far, e.g. "PutObject", in order
+func (s *Server) Operations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.operations...)
+}
+
+// CountOperation returns how many times an operation was served
+func (s *Server) CountOperation(name string) int {
+	count := 0
+	for _, operation := range s.Operations() {
+		if operation == name {
+			count++
+		}
+	}
+	return count
+}
+
+// FailNext makes the next count requests fail with the given status code,
+// e.g. http.StatusServiceUnavailable to exercise retries
+func (s *Server) FailNext(count, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// OnOperation runs fn whenever operation is served, before the request is
+// handled, e.g. to interrupt a command halfway through an upload
+func (s *Server) OnOperation(operation string, fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks[operation] = fn
+}
+
+// newObject builds an object from its body and the headers in meta
+func newObject(key string, body []byte, meta *Object) *Object {
+	sum := md5.Sum(body)
+	object := meta.clone()
+	object.Key = key
+	object.Body = append([]byte(nil), body...)
+	object.ETag = hex.EncodeToString(sum[:])
+	object.LastModified = time.Now().UTC().Truncate(time.Second)
+	if object.ContentType == "" {
+		object.ContentType = "binary/octet-stream"
+	}
+	return &object
+}
+
+func (o *Object) clone() Object {
+	c := *o
+	c.Body = append([]byte(nil), o.Body...)
+	c.Metadata = make(map[string]string, len(o.Metadata))
+	for k, v := range o.Metadata {
+		c.Metadata[k] = v
+	}
+	return c
+}
+
+func sortedKeys(objects map[string]*Object) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// verifyChecksums rejects bodies that do not match the Content-MD5 or
+// x-amz-checksum-sha256 headers sent with them
+func verifyChecksums(r *http.Request, body []byte) *s3Error {
+	if expected := r.Header.Get("Content-MD5"); expected != "" {
+		sum := md5.Sum(body)
+		if base64.StdEncoding.EncodeToString(sum[:]) != expected {
+			return &s3Error{http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what we received."}
+		}
+	}
+	if expected := r.Header.Get("X-Amz-Checksum-Sha256"); expected != "" {
+		sum := sha256.Sum256(body)
+		if base64.StdEncoding.EncodeToString(sum[:]) != expected {
+			return &s3Error{http.StatusBadRequest, "BadDigest", "The SHA256 you specified did not match the calculated checksum."}
+		}
+	}
+	return nil
+}
+
+// headersFromRequest reads the object headers and x-amz-meta-* values of a request
+func headersFromRequest(r *http.Request) *Object {
+	object := &Object{
+		ContentType:        r.Header.Get("Content-Type"),
+		CacheControl:       r.Header.Get("Cache-Control"),
+		ContentDisposition: r.Header.Get("Content-Disposition"),
+		ContentEncoding:    stripAWSChunked(r.Header.Get("Content-Encoding")),
+		Metadata:           make(map[string]string),
+	}
+	for name, values := range r.Header {
+		if len(name) > len("X-Amz-Meta-") && strings.EqualFold(name[:len("X-Amz-Meta-")], "X-Amz-Meta-") {
+			object.Metadata[strings.ToLower(name[len("X-Amz-Meta-"):])] = strings.Join(values, ",")
+		}
+	}
+	return object
+}
+
+// stripAWSChunked removes the transfer-only aws-chunked coding from Content-Encoding
+func stripAWSChunked(encoding string) string {
+	var kept []string
+	for _, part := range strings.Split(encoding, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" && part != "aws-chunked" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// writeObjectHeaders sets the response headers describing an object
+func writeObjectHeaders(w http.ResponseWriter, object *Object) {
+	header := w.Header()
+	header.Set("ETag", `"`+object.ETag+`"`)
+	header.Set("Last-Modified", object.LastModified.Format(http.TimeFormat))
+	header.Set("Content-Type", object.ContentType)
+	header.Set("Accept-Ranges", "bytes")
+	if object.CacheControl != "" {
+		header.Set("Cache-Control", object.CacheControl)
+	}
+	if object.ContentDisposition != "" {
+		header.Set("Content-Disposition", object.ContentDisposition)
+	}
+	if object.ContentEncoding != "" {
+		header.Set("Content-Encoding", object.ContentEncoding)
+	}
+	for key, value := range object.Metadata {
+		header.Set("X-Amz-Meta-"+key, value)
+	}
+}
+
+// multipartETag computes the ETag of an object assembled from parts
+func multipartETag(parts []*uploadPart) string {
+	combined := md5.New()
+	for _, part := range parts {
+		sum, _ := hex.DecodeString(part.etag)
+		combined.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(combined.Sum(nil)), len(parts))
+}
@@ -0,0 +1,89 @@
+package r2
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// MaxRetryBackoff caps the delay between two attempts of the same request
+const MaxRetryBackoff = 20 * time.Second
+
+// retryableMessages are error fragments of transient network and server
+// failures that do not surface as a typed error
+var retryableMessages = []string{
+	"timeout",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"temporary failure",
+	"service unavailable",
+	"internal server error",
+	"bad gateway",
+	"gateway timeout",
+	"too many requests",
+	"slowdown",
+}
+
+// IsRetryableError reports whether a failed request is worth repeating:
+// throttling, 5xx responses and transient network errors are; cancellation
+// and client errors such as 403 or 404 are not
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) {
+		status := responseErr.HTTPStatusCode()
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, fragment := range retryableMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRetryer returns the SDK retryer used for every request: up to maxRetries
+// retries with exponential backoff and full jitter, classified by IsRetryableError
+func NewRetryer(maxRetries int) aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = max(0, maxRetries) + 1
+		o.MaxBackoff = MaxRetryBackoff
+		o.Backoff = retry.NewExponentialJitterBackoff(MaxRetryBackoff)
+		// The SDK's own checks run first so its explicit "do not retry" answers win
+		o.Retryables = append(o.Retryables, retry.IsErrorRetryableFunc(func(err error) aws.Ternary {
+			if IsRetryableError(err) {
+				return aws.TrueTernary
+			}
+			return aws.UnknownTernary
+		}))
+		// Many files are transferred in parallel, so do not let a burst of
+		// failures exhaust a shared retry budget
+		o.RateLimiter = ratelimit.None
+	})
+}
@@ -0,0 +1,61 @@
+package r2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func responseError(status int) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      errors.New("api error"),
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"cancelled", context.Canceled, false},
+		{"wrapped cancel", fmt.Errorf("list: %w", context.Canceled), false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"throttled", responseError(http.StatusTooManyRequests), true},
+		{"server error", responseError(http.StatusServiceUnavailable), true},
+		{"not found", responseError(http.StatusNotFound), false},
+		{"forbidden", responseError(http.StatusForbidden), false},
+		{"connection reset", errors.New("read tcp: connection reset by peer"), true},
+		{"unrelated", errors.New("invalid bucket name"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryableError(tt.err))
+		})
+	}
+}
+
+func TestNewRetryer(t *testing.T) {
+	retryer := NewRetryer(5)
+	assert.Equal(t, 6, retryer.MaxAttempts())
+	assert.True(t, retryer.IsErrorRetryable(errors.New("connection refused")))
+	assert.False(t, retryer.IsErrorRetryable(context.Canceled))
+
+	// Backoff grows with the attempt but never exceeds the cap
+	for attempt := 1; attempt < 20; attempt++ {
+		delay, err := retryer.RetryDelay(attempt, errors.New("timeout"))
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, delay, MaxRetryBackoff)
+	}
+
+	// Zero retries means a single attempt
+	assert.Equal(t, 1, NewRetryer(0).MaxAttempts())
+}
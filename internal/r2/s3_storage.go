@@ -0,0 +1,557 @@
+package r2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// s3Storage is the Storage backed by the S3 SDK
+type s3Storage struct {
+	client    *s3.Client
+	presigner *s3.PresignClient
+}
+
+// NewS3Storage wraps an S3 SDK client as a Storage
+func NewS3Storage(client *s3.Client) Storage {
+	return &s3Storage{
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+	}
+}
+
+// storageError keeps the SDK error and its message while matching a sentinel
+// such as ErrNotFound with errors.Is
+type storageError struct {
+	err  error
+	kind error
+}
+
+func (e *storageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *storageError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// translateError marks SDK errors for missing resources and unsatisfiable ranges
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound", "NoSuchBucket", "NoSuchUpload":
+			return &storageError{err: err, kind: ErrNotFound}
+		case "InvalidRange":
+			return &storageError{err: err, kind: ErrInvalidRange}
+		}
+	}
+
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound {
+		return &storageError{err: err, kind: ErrNotFound}
+	}
+	return err
+}
+
+// hasErrorCode reports whether err is an API error with the given code
+func hasErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// optional converts an empty string to nil so no empty header is sent
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// normalizeETag strips the quotes S3 puts around ETag values
+func normalizeETag(etag *string) string {
+	return strings.Trim(aws.ToString(etag), `"`)
+}
+
+// copySource builds the CopySource parameter (bucket/key with the key URL-encoded)
+func copySource(bucket, key string) string {
+	return url.PathEscape(bucket) + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// ListBuckets lists all buckets of the account
+func (s *s3Storage) ListBuckets(ctx context.Context) ([]Bucket, error) {
+	output, err := s.client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	buckets := make([]Bucket, 0, len(output.Buckets))
+	for _, bucket := range output.Buckets {
+		buckets = append(buckets, Bucket{
+			Name:         aws.ToString(bucket.Name),
+			CreationDate: aws.ToTime(bucket.CreationDate),
+		})
+	}
+	return buckets, nil
+}
+
+// HeadBucket checks that a bucket exists and is accessible
+func (s *s3Storage) HeadBucket(ctx context.Context, bucket string) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	return translateError(err)
+}
+
+// CreateBucket creates a bucket
+func (s *s3Storage) CreateBucket(ctx context.Context, bucket string) error {
+	_, err := s.client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+	return translateError(err)
+}
+
+// DeleteBucket deletes an empty bucket
+func (s *s3Storage) DeleteBucket(ctx context.Context, bucket string) error {
+	_, err := s.client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	return translateError(err)
+}
+
+// BucketLocation returns the location constraint of a bucket
+func (s *s3Storage) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	output, err := s.client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", translateError(err)
+	}
+	return string(output.LocationConstraint), nil
+}
+
+// BucketPolicy returns the policy document of a bucket
+func (s *s3Storage) BucketPolicy(ctx context.Context, bucket string) (string, error) {
+	output, err := s.client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if hasErrorCode(err, "NoSuchBucketPolicy") {
+		return "", nil
+	}
+	if err != nil {
+		return "", translateError(err)
+	}
+	return aws.ToString(output.Policy), nil
+}
+
+// BucketWebsite returns the website configuration of a bucket
+func (s *s3Storage) BucketWebsite(ctx context.Context, bucket string) (*WebsiteConfig, error) {
+	output, err := s.client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{Bucket: aws.String(bucket)})
+	if hasErrorCode(err, "NoSuchWebsiteConfiguration") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	website := &WebsiteConfig{}
+	if output.IndexDocument != nil {
+		website.IndexDocument = aws.ToString(output.IndexDocument.Suffix)
+	}
+	if output.ErrorDocument != nil {
+		website.ErrorDocument = aws.ToString(output.ErrorDocument.Key)
+	}
+	if output.RedirectAllRequestsTo != nil {
+		website.RedirectAllRequestsTo = aws.ToString(output.RedirectAllRequestsTo.HostName)
+	}
+	return website, nil
+}
+
+// List returns one page of objects with ListObjectsV2
+func (s *s3Storage) List(ctx context.Context, bucket string, options ListOptions) (*ListPage, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:            aws.String(bucket),
+		Prefix:            optional(options.Prefix),
+		Delimiter:         optional(options.Delimiter),
+		ContinuationToken: optional(options.ContinuationToken),
+	}
+	if options.MaxKeys > 0 {
+		input.MaxKeys = aws.Int32(options.MaxKeys)
+	}
+
+	output, err := s.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	page := &ListPage{
+		Objects:  make([]ObjectInfo, 0, len(output.Contents)),
+		Prefixes: make([]string, 0, len(output.CommonPrefixes)),
+	}
+	for _, object := range output.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:          aws.ToString(object.Key),
+			Size:         aws.ToInt64(object.Size),
+			LastModified: aws.ToTime(object.LastModified),
+			ETag:         normalizeETag(object.ETag),
+			StorageClass: string(object.StorageClass),
+		})
+	}
+	for _, prefix := range output.CommonPrefixes {
+		page.Prefixes = append(page.Prefixes, aws.ToString(prefix.Prefix))
+	}
+	if aws.ToBool(output.IsTruncated) {
+		page.NextToken = aws.ToString(output.NextContinuationToken)
+	}
+	return page, nil
+}
+
+// Head returns the headers of an object with HeadObject
+func (s *s3Storage) Head(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	output, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(output.ContentLength),
+		LastModified: aws.ToTime(output.LastModified),
+		ETag:         normalizeETag(output.ETag),
+		StorageClass: string(output.StorageClass),
+		Headers: Headers{
+			ContentType:        aws.ToString(output.ContentType),
+			CacheControl:       aws.ToString(output.CacheControl),
+			ContentDisposition: aws.ToString(output.ContentDisposition),
+			ContentEncoding:    aws.ToString(output.ContentEncoding),
+			ContentLanguage:    aws.ToString(output.ContentLanguage),
+			Metadata:           output.Metadata,
+		},
+	}, nil
+}
+
+// Get reads an object, or a range of it, with GetObject
+func (s *s3Storage) Get(ctx context.Context, bucket, key string, options GetOptions) (*Object, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	ranged := options.Offset > 0 || options.Length > 0
+	if ranged {
+		end := ""
+		if options.Length > 0 {
+			end = strconv.FormatInt(options.Offset+options.Length-1, 10)
+		}
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%s", options.Offset, end))
+	}
+
+	output, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	object := &Object{
+		ObjectInfo: ObjectInfo{
+			Key:          key,
+			Size:         aws.ToInt64(output.ContentLength),
+			LastModified: aws.ToTime(output.LastModified),
+			ETag:         normalizeETag(output.ETag),
+			StorageClass: string(output.StorageClass),
+			Headers: Headers{
+				ContentType:        aws.ToString(output.ContentType),
+				CacheControl:       aws.ToString(output.CacheControl),
+				ContentDisposition: aws.ToString(output.ContentDisposition),
+				ContentEncoding:    aws.ToString(output.ContentEncoding),
+				ContentLanguage:    aws.ToString(output.ContentLanguage),
+				Metadata:           output.Metadata,
+			},
+		},
+		Body:          output.Body,
+		ContentLength: aws.ToInt64(output.ContentLength),
+	}
+
+	if total := contentRangeTotal(aws.ToString(output.ContentRange)); total >= 0 {
+		object.Size = total
+	} else if ranged {
+		// The server ignored Range and sent the whole object
+		if err := skipToRange(object, options); err != nil {
+			output.Body.Close()
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+	}
+	return object, nil
+}
+
+// skipToRange trims a whole-object response down to the requested range
+func skipToRange(object *Object, options GetOptions) error {
+	if _, err := io.CopyN(io.Discard, object.Body, options.Offset); err != nil && err != io.EOF {
+		return err
+	}
+
+	remaining := max(0, object.Size-options.Offset)
+	if options.Length > 0 && options.Length < remaining {
+		remaining = options.Length
+		object.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(object.Body, remaining), object.Body}
+	}
+	object.ContentLength = remaining
+	return nil
+}
+
+// contentRangeTotal returns the object size from "bytes 0-99/1234", or -1 when unknown
+func contentRangeTotal(contentRange string) int64 {
+	slash := strings.LastIndex(contentRange, "/")
+	if slash < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[slash+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// Put uploads an object with PutObject
+func (s *s3Storage) Put(ctx context.Context, bucket, key string, body io.Reader, options PutOptions) error {
+	input := &s3.PutObjectInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		Body:               body,
+		ContentType:        optional(options.ContentType),
+		CacheControl:       optional(options.CacheControl),
+		ContentDisposition: optional(options.ContentDisposition),
+		ContentEncoding:    optional(options.ContentEncoding),
+		ContentLanguage:    optional(options.ContentLanguage),
+		Metadata:           options.Metadata,
+		ContentMD5:         optional(options.ContentMD5),
+		ChecksumSHA256:     optional(options.ChecksumSHA256),
+	}
+	if options.Public {
+		input.ACL = types.ObjectCannedACLPublicRead
+	}
+
+	_, err := s.client.PutObject(ctx, input)
+	return translateError(err)
+}
+
+// Delete deletes an object with DeleteObject
+func (s *s3Storage) Delete(ctx context.Context, bucket, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return translateError(err)
+}
+
+// DeleteMany deletes keys with a quiet DeleteObjects request
+func (s *s3Storage) DeleteMany(ctx context.Context, bucket string, keys []string) ([]DeleteError, error) {
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+
+	output, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &types.Delete{
+			Objects: objects,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	// In quiet mode only failed keys are reported back
+	var failed []DeleteError
+	for _, deleteError := range output.Errors {
+		failed = append(failed, DeleteError{
+			Key:     aws.ToString(deleteError.Key),
+			Code:    aws.ToString(deleteError.Code),
+			Message: aws.ToString(deleteError.Message),
+		})
+	}
+	return failed, nil
+}
+
+// Copy copies an object with CopyObject
+func (s *s3Storage) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, headers *Headers) error {
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(dstBucket),
+		Key:               aws.String(dstKey),
+		CopySource:        aws.String(copySource(srcBucket, srcKey)),
+		MetadataDirective: types.MetadataDirectiveCopy,
+	}
+
+	// Replacing drops every header that is not sent again
+	if headers != nil {
+		input.MetadataDirective = types.MetadataDirectiveReplace
+		input.ContentType = optional(headers.ContentType)
+		input.CacheControl = optional(headers.CacheControl)
+		input.ContentDisposition = optional(headers.ContentDisposition)
+		input.ContentEncoding = optional(headers.ContentEncoding)
+		input.ContentLanguage = optional(headers.ContentLanguage)
+		input.Metadata = headers.Metadata
+	}
+
+	_, err := s.client.CopyObject(ctx, input)
+	return translateError(err)
+}
+
+// CreateMultipart starts a multipart upload with CreateMultipartUpload
+func (s *s3Storage) CreateMultipart(ctx context.Context, bucket, key string, options PutOptions) (MultipartUpload, error) {
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		ContentType:        optional(options.ContentType),
+		CacheControl:       optional(options.CacheControl),
+		ContentDisposition: optional(options.ContentDisposition),
+		ContentEncoding:    optional(options.ContentEncoding),
+		ContentLanguage:    optional(options.ContentLanguage),
+		Metadata:           options.Metadata,
+	}
+	if options.Public {
+		input.ACL = types.ObjectCannedACLPublicRead
+	}
+
+	output, err := s.client.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return MultipartUpload{}, translateError(err)
+	}
+	return MultipartUpload{Bucket: bucket, Key: key, UploadID: aws.ToString(output.UploadId)}, nil
+}
+
+// UploadPart uploads one part with UploadPart
+func (s *s3Storage) UploadPart(ctx context.Context, upload MultipartUpload, number int32, body io.Reader, size int64, contentMD5 string) (string, error) {
+	output, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(upload.Bucket),
+		Key:           aws.String(upload.Key),
+		UploadId:      aws.String(upload.UploadID),
+		PartNumber:    aws.Int32(number),
+		ContentLength: aws.Int64(size),
+		ContentMD5:    optional(contentMD5),
+		Body:          body,
+	})
+	if err != nil {
+		return "", translateError(err)
+	}
+	return aws.ToString(output.ETag), nil
+}
+
+// UploadPartCopy copies one part with UploadPartCopy
+func (s *s3Storage) UploadPartCopy(ctx context.Context, upload MultipartUpload, number int32, srcBucket, srcKey string, start, end int64) (string, error) {
+	output, err := s.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+		Bucket:          aws.String(upload.Bucket),
+		Key:             aws.String(upload.Key),
+		UploadId:        aws.String(upload.UploadID),
+		PartNumber:      aws.Int32(number),
+		CopySource:      aws.String(copySource(srcBucket, srcKey)),
+		CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+	})
+	if err != nil {
+		return "", translateError(err)
+	}
+	if output.CopyPartResult == nil {
+		return "", nil
+	}
+	return aws.ToString(output.CopyPartResult.ETag), nil
+}
+
+// CompleteMultipart assembles the parts with CompleteMultipartUpload
+func (s *s3Storage) CompleteMultipart(ctx context.Context, upload MultipartUpload, parts []Part) error {
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, types.CompletedPart{
+			PartNumber: aws.Int32(part.Number),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(upload.Bucket),
+		Key:             aws.String(upload.Key),
+		UploadId:        aws.String(upload.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	return translateError(err)
+}
+
+// AbortMultipart discards the upload and its parts with AbortMultipartUpload
+func (s *s3Storage) AbortMultipart(ctx context.Context, upload MultipartUpload) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(upload.Bucket),
+		Key:      aws.String(upload.Key),
+		UploadId: aws.String(upload.UploadID),
+	})
+	return translateError(err)
+}
+
+// ListParts lists every uploaded part, following ListParts pagination
+func (s *s3Storage) ListParts(ctx context.Context, upload MultipartUpload) ([]Part, error) {
+	var parts []Part
+	var marker *string
+
+	for {
+		output, err := s.client.ListParts(ctx, &s3.ListPartsInput{
+			Bucket:           aws.String(upload.Bucket),
+			Key:              aws.String(upload.Key),
+			UploadId:         aws.String(upload.UploadID),
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			return nil, translateError(err)
+		}
+
+		for _, part := range output.Parts {
+			parts = append(parts, Part{
+				Number: aws.ToInt32(part.PartNumber),
+				ETag:   aws.ToString(part.ETag),
+				Size:   aws.ToInt64(part.Size),
+			})
+		}
+
+		if !aws.ToBool(output.IsTruncated) {
+			return parts, nil
+		}
+		marker = output.NextPartNumberMarker
+	}
+}
+
+// Presign creates a presigned GET or PUT URL
+func (s *s3Storage) Presign(ctx context.Context, request PresignRequest) (string, error) {
+	expires := func(opts *s3.PresignOptions) {
+		opts.Expires = request.Expires
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		output, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket:                     aws.String(request.Bucket),
+			Key:                        aws.String(request.Key),
+			ResponseContentDisposition: optional(request.ContentDisposition),
+		}, expires)
+		if err != nil {
+			return "", err
+		}
+		return output.URL, nil
+	case http.MethodPut:
+		output, err := s.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(request.Bucket),
+			Key:    aws.String(request.Key),
+		}, expires)
+		if err != nil {
+			return "", err
+		}
+		return output.URL, nil
+	}
+	return "", fmt.Errorf("unsupported presign method %q", request.Method)
+}
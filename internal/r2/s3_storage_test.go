@@ -0,0 +1,182 @@
+package r2_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2/r2test"
+)
+
+const testBucket = "test-bucket"
+
+func newTestStorage(t *testing.T) (r2.Storage, *r2test.Server) {
+	t.Helper()
+	server := r2test.NewServer(t, testBucket)
+	return server.Client(t, testBucket).Storage(), server
+}
+
+func TestS3Storage_NotFound(t *testing.T) {
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.Head(ctx, testBucket, "missing.txt")
+	assert.True(t, errors.Is(err, r2.ErrNotFound), "head: %v", err)
+
+	_, err = storage.Get(ctx, testBucket, "missing.txt", r2.GetOptions{})
+	assert.True(t, errors.Is(err, r2.ErrNotFound), "get: %v", err)
+
+	_, err = storage.List(ctx, "missing-bucket", r2.ListOptions{})
+	assert.True(t, errors.Is(err, r2.ErrNotFound), "list: %v", err)
+}
+
+func TestS3Storage_PutHeadGet(t *testing.T) {
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+
+	options := r2.PutOptions{Headers: r2.Headers{
+		ContentType:  "text/plain",
+		CacheControl: "no-cache",
+		Metadata:     map[string]string{"owner": "alice"},
+	}}
+	require.NoError(t, storage.Put(ctx, testBucket, "hello.txt", strings.NewReader("Hello, World!"), options))
+
+	head, err := storage.Head(ctx, testBucket, "hello.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), head.Size)
+	assert.Equal(t, "65a8e27d8879283831b664bd8b7f0ad4", head.ETag)
+	assert.Equal(t, "text/plain", head.ContentType)
+	assert.Equal(t, "no-cache", head.CacheControl)
+	assert.Equal(t, map[string]string{"owner": "alice"}, head.Metadata)
+
+	// A range reports the size of the whole object and the bytes in Body
+	object, err := storage.Get(ctx, testBucket, "hello.txt", r2.GetOptions{Offset: 7, Length: 5})
+	require.NoError(t, err)
+	defer object.Body.Close()
+	body, err := io.ReadAll(object.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "World", string(body))
+	assert.Equal(t, int64(13), object.Size)
+	assert.Equal(t, int64(5), object.ContentLength)
+
+	_, err = storage.Get(ctx, testBucket, "hello.txt", r2.GetOptions{Offset: 13})
+	assert.True(t, errors.Is(err, r2.ErrInvalidRange), "get past the end: %v", err)
+}
+
+func TestS3Storage_ListPaginator(t *testing.T) {
+	storage, server := newTestStorage(t)
+	for i := range 5 {
+		server.PutObject(testBucket, fmt.Sprintf("logs/%d.txt", i), []byte("x"))
+	}
+	server.PutObject(testBucket, "logs/2024/a.txt", []byte("x"))
+
+	paginator := r2.NewListPaginator(storage, testBucket, r2.ListOptions{Prefix: "logs/", Delimiter: "/", MaxKeys: 2})
+	var keys, prefixes []string
+	pages := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		require.NoError(t, err)
+		pages++
+		for _, object := range page.Objects {
+			keys = append(keys, object.Key)
+		}
+		prefixes = append(prefixes, page.Prefixes...)
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"logs/2024/"}, prefixes)
+	assert.Equal(t, []string{"logs/0.txt", "logs/1.txt", "logs/2.txt", "logs/3.txt", "logs/4.txt"}, keys)
+}
+
+func TestS3Storage_DeleteMany(t *testing.T) {
+	storage, server := newTestStorage(t)
+	server.PutObject(testBucket, "a.txt", []byte("a"))
+	server.PutObject(testBucket, "b.txt", []byte("b"))
+
+	failed, err := storage.DeleteMany(context.Background(), testBucket, []string{"a.txt", "b.txt"})
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+
+	_, exists := server.Object(testBucket, "a.txt")
+	assert.False(t, exists)
+	_, exists = server.Object(testBucket, "b.txt")
+	assert.False(t, exists)
+}
+
+func TestS3Storage_Copy(t *testing.T) {
+	storage, server := newTestStorage(t)
+	ctx := context.Background()
+	server.PutObject(testBucket, "photos/my cat.png", []byte("png"))
+
+	// Nil headers keep the source headers
+	require.NoError(t, storage.Copy(ctx, testBucket, "photos/my cat.png", testBucket, "copy.png", nil))
+	object, exists := server.Object(testBucket, "copy.png")
+	require.True(t, exists)
+	assert.Equal(t, "application/octet-stream", object.ContentType)
+
+	headers := &r2.Headers{ContentType: "image/png", Metadata: map[string]string{"owner": "bob"}}
+	require.NoError(t, storage.Copy(ctx, testBucket, "photos/my cat.png", testBucket, "replaced.png", headers))
+	object, exists = server.Object(testBucket, "replaced.png")
+	require.True(t, exists)
+	assert.Equal(t, "image/png", object.ContentType)
+	assert.Equal(t, map[string]string{"owner": "bob"}, object.Metadata)
+}
+
+func TestS3Storage_Multipart(t *testing.T) {
+	storage, server := newTestStorage(t)
+	ctx := context.Background()
+
+	upload, err := storage.CreateMultipart(ctx, testBucket, "large.bin", r2.PutOptions{})
+	require.NoError(t, err)
+
+	first := bytes.Repeat([]byte("a"), 5*1024*1024)
+	etag, err := storage.UploadPart(ctx, upload, 1, bytes.NewReader(first), int64(len(first)), "")
+	require.NoError(t, err)
+	parts := []r2.Part{{Number: 1, ETag: etag}}
+	etag, err = storage.UploadPart(ctx, upload, 2, strings.NewReader("tail"), 4, "")
+	require.NoError(t, err)
+	parts = append(parts, r2.Part{Number: 2, ETag: etag})
+
+	listed, err := storage.ListParts(ctx, upload)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, int64(4), listed[1].Size)
+
+	require.NoError(t, storage.CompleteMultipart(ctx, upload, parts))
+	object, exists := server.Object(testBucket, "large.bin")
+	require.True(t, exists)
+	assert.Equal(t, append(first, "tail"...), object.Body)
+
+	// The upload is gone once completed
+	_, err = storage.ListParts(ctx, upload)
+	assert.True(t, errors.Is(err, r2.ErrNotFound), "list parts: %v", err)
+}
+
+func TestS3Storage_Presign(t *testing.T) {
+	storage, _ := newTestStorage(t)
+
+	url, err := storage.Presign(context.Background(), r2.PresignRequest{
+		Method:             http.MethodGet,
+		Bucket:             testBucket,
+		Key:                "report.pdf",
+		Expires:            time.Hour,
+		ContentDisposition: "attachment",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, url, "/"+testBucket+"/report.pdf")
+	assert.Contains(t, url, "X-Amz-Expires=3600")
+	assert.Contains(t, url, "response-content-disposition=attachment")
+
+	_, err = storage.Presign(context.Background(), r2.PresignRequest{Method: http.MethodDelete, Bucket: testBucket, Key: "report.pdf"})
+	assert.Error(t, err)
+}
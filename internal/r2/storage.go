@@ -0,0 +1,208 @@
+package r2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound matches errors for buckets, objects and multipart uploads that
+// do not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidRange matches errors for ranged reads that start past the end of
+// the object, including any read of an empty object
+var ErrInvalidRange = errors.New("invalid range")
+
+// Headers are the content headers and user metadata stored with an object
+type Headers struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	Metadata           map[string]string
+}
+
+// ObjectInfo describes a stored object. List fills the key, size, time, ETag
+// and storage class; Head and Get fill the headers as well.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string // Without the surrounding quotes
+	StorageClass string
+	Headers
+}
+
+// Object is an object returned by Get. The caller must close Body.
+type Object struct {
+	ObjectInfo // Size is the size of the whole object
+	Body       io.ReadCloser
+	// ContentLength is the number of bytes in Body, less than Size for a range
+	ContentLength int64
+}
+
+// GetOptions selects the bytes returned by Get
+type GetOptions struct {
+	Offset int64 // First byte to return
+	Length int64 // Number of bytes to return, 0 reads to the end
+}
+
+// PutOptions controls how Put and CreateMultipart store an object
+type PutOptions struct {
+	Headers
+	ContentMD5     string // Base64 MD5 of the body, checked by the server
+	ChecksumSHA256 string // Base64 SHA-256 of the body, checked by the server
+	Public         bool   // Grant public read access
+}
+
+// ListOptions selects the objects returned by List
+type ListOptions struct {
+	Prefix            string
+	Delimiter         string // Groups keys up to the delimiter into Prefixes
+	MaxKeys           int32  // 0 uses the backend's page size
+	ContinuationToken string // NextToken of the previous page
+}
+
+// ListPage is one page of List results
+type ListPage struct {
+	Objects   []ObjectInfo
+	Prefixes  []string
+	NextToken string // Empty on the last page
+}
+
+// DeleteError is a key DeleteMany could not delete
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+// Bucket is a bucket returned by ListBuckets
+type Bucket struct {
+	Name         string
+	CreationDate time.Time
+}
+
+// WebsiteConfig is the static website configuration of a bucket
+type WebsiteConfig struct {
+	IndexDocument         string
+	ErrorDocument         string
+	RedirectAllRequestsTo string
+}
+
+// MultipartUpload identifies an upload created by CreateMultipart
+type MultipartUpload struct {
+	Bucket   string
+	Key      string
+	UploadID string
+}
+
+// Part is an uploaded part of a multipart upload
+type Part struct {
+	Number int32
+	ETag   string
+	Size   int64
+}
+
+// PresignRequest describes the request a presigned URL allows
+type PresignRequest struct {
+	Method             string // http.MethodGet or http.MethodPut
+	Bucket             string
+	Key                string
+	Expires            time.Duration
+	ContentDisposition string // GET only: overrides the response Content-Disposition
+}
+
+// BucketAPI covers the bucket level operations
+type BucketAPI interface {
+	ListBuckets(ctx context.Context) ([]Bucket, error)
+	HeadBucket(ctx context.Context, bucket string) error
+	CreateBucket(ctx context.Context, bucket string) error
+	DeleteBucket(ctx context.Context, bucket string) error
+	// BucketLocation returns the location constraint, empty for the default region
+	BucketLocation(ctx context.Context, bucket string) (string, error)
+	// BucketPolicy returns the policy document, empty when the bucket has none
+	BucketPolicy(ctx context.Context, bucket string) (string, error)
+	// BucketWebsite returns the website configuration, nil when it is disabled
+	BucketWebsite(ctx context.Context, bucket string) (*WebsiteConfig, error)
+}
+
+// ObjectAPI covers listing, reading, writing, copying and deleting objects
+type ObjectAPI interface {
+	List(ctx context.Context, bucket string, options ListOptions) (*ListPage, error)
+	Head(ctx context.Context, bucket, key string) (*ObjectInfo, error)
+	Get(ctx context.Context, bucket, key string, options GetOptions) (*Object, error)
+	Put(ctx context.Context, bucket, key string, body io.Reader, options PutOptions) error
+	Delete(ctx context.Context, bucket, key string) error
+	// DeleteMany deletes up to 1000 keys and returns the ones that failed
+	DeleteMany(ctx context.Context, bucket string, keys []string) ([]DeleteError, error)
+	// Copy copies an object of up to 5GB on the server. A nil headers keeps
+	// the source headers and metadata, otherwise they are all replaced.
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, headers *Headers) error
+}
+
+// MultipartAPI covers multipart uploads and multipart server-side copies
+type MultipartAPI interface {
+	// CreateMultipart starts an upload; the checksums in options are ignored
+	CreateMultipart(ctx context.Context, bucket, key string, options PutOptions) (MultipartUpload, error)
+	// UploadPart uploads size bytes from body as part number and returns its ETag
+	UploadPart(ctx context.Context, upload MultipartUpload, number int32, body io.Reader, size int64, contentMD5 string) (string, error)
+	// UploadPartCopy copies bytes start to end (inclusive) of another object
+	// as part number and returns its ETag
+	UploadPartCopy(ctx context.Context, upload MultipartUpload, number int32, srcBucket, srcKey string, start, end int64) (string, error)
+	CompleteMultipart(ctx context.Context, upload MultipartUpload, parts []Part) error
+	AbortMultipart(ctx context.Context, upload MultipartUpload) error
+	ListParts(ctx context.Context, upload MultipartUpload) ([]Part, error)
+}
+
+// PresignAPI creates presigned URLs
+type PresignAPI interface {
+	Presign(ctx context.Context, request PresignRequest) (string, error)
+}
+
+// Storage is the object storage backend used by the commands and the TUI.
+// NewS3Storage adapts the S3 SDK; other backends, such as a local directory
+// or a caching decorator, implement the same methods.
+type Storage interface {
+	BucketAPI
+	ObjectAPI
+	MultipartAPI
+	PresignAPI
+}
+
+// Lister is the part of the storage needed to page through a listing
+type Lister interface {
+	List(ctx context.Context, bucket string, options ListOptions) (*ListPage, error)
+}
+
+// ListPaginator pages through the results of List
+type ListPaginator struct {
+	lister  Lister
+	bucket  string
+	options ListOptions
+	done    bool
+}
+
+// NewListPaginator creates a paginator that starts at options.ContinuationToken
+func NewListPaginator(lister Lister, bucket string, options ListOptions) *ListPaginator {
+	return &ListPaginator{lister: lister, bucket: bucket, options: options}
+}
+
+// HasMorePages reports whether NextPage has another page to fetch
+func (p *ListPaginator) HasMorePages() bool {
+	return !p.done
+}
+
+// NextPage fetches the next page
+func (p *ListPaginator) NextPage(ctx context.Context) (*ListPage, error) {
+	page, err := p.lister.List(ctx, p.bucket, p.options)
+	if err != nil {
+		return nil, err
+	}
+	p.options.ContinuationToken = page.NextToken
+	p.done = page.NextToken == ""
+	return page, nil
+}
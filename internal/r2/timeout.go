@@ -0,0 +1,35 @@
+package r2
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// streamingOperations transfer object data, so their duration grows with the
+// object size. They are bounded by the transport's connect and response header
+// timeouts instead of a deadline for the whole call.
+var streamingOperations = map[string]bool{
+	"GetObject":      true,
+	"PutObject":      true,
+	"UploadPart":     true,
+	"UploadPartCopy": true,
+	"CopyObject":     true,
+}
+
+// addOperationTimeout limits every other operation, including its retries, to timeout
+func addOperationTimeout(timeout time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationTimeout",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				if streamingOperations[middleware.GetOperationName(ctx)] {
+					return next.HandleInitialize(ctx, in)
+				}
+
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return next.HandleInitialize(ctx, in)
+			}), middleware.Before)
+	}
+}
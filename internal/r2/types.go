@@ -1,10 +1,9 @@
 package r2
 
 import (
+	"fmt"
+	"strings"
 	"time"
-
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // BucketInfo represents detailed information about a bucket
@@ -34,36 +33,20 @@ type BucketWebsiteInfo struct {
 	Error               string `json:"error,omitempty"`
 }
 
-// NewBucketInfoFromAWS creates a BucketInfo from AWS SDK Bucket type
-func NewBucketInfoFromAWS(bucket types.Bucket) *BucketInfo {
-	info := &BucketInfo{
-		Name: *bucket.Name,
-	}
-
-	if bucket.CreationDate != nil {
-		info.CreationDate = *bucket.CreationDate
+// NewBucketInfo creates a BucketInfo from a listed bucket
+func NewBucketInfo(bucket Bucket) *BucketInfo {
+	return &BucketInfo{
+		Name:         bucket.Name,
+		CreationDate: bucket.CreationDate,
 	}
-
-	return info
 }
 
-// NewBucketPolicyInfoFromAWS creates a BucketPolicyInfo from AWS SDK GetBucketPolicyOutput
-func NewBucketPolicyInfoFromAWS(output *s3.GetBucketPolicyOutput) *BucketPolicyInfo {
-	if output == nil {
-		return &BucketPolicyInfo{
-			HasPolicy: false,
-		}
-	}
-
-	info := &BucketPolicyInfo{
-		HasPolicy: true,
-	}
-
-	if output.Policy != nil {
-		info.PolicySize = int64(len(*output.Policy))
+// NewBucketPolicyInfo creates a BucketPolicyInfo from a policy document, empty when there is none
+func NewBucketPolicyInfo(policy string) *BucketPolicyInfo {
+	return &BucketPolicyInfo{
+		HasPolicy:  policy != "",
+		PolicySize: int64(len(policy)),
 	}
-
-	return info
 }
 
 // NewBucketPolicyInfoWithError creates a BucketPolicyInfo with error information
@@ -74,31 +57,20 @@ func NewBucketPolicyInfoWithError(err error) *BucketPolicyInfo {
 	}
 }
 
-// NewBucketWebsiteInfoFromAWS creates a BucketWebsiteInfo from AWS SDK GetBucketWebsiteOutput
-func NewBucketWebsiteInfoFromAWS(output *s3.GetBucketWebsiteOutput) *BucketWebsiteInfo {
-	if output == nil {
+// NewBucketWebsiteInfo creates a BucketWebsiteInfo from a website configuration, nil when disabled
+func NewBucketWebsiteInfo(website *WebsiteConfig) *BucketWebsiteInfo {
+	if website == nil {
 		return &BucketWebsiteInfo{
 			Enabled: false,
 		}
 	}
 
-	info := &BucketWebsiteInfo{
-		Enabled: true,
-	}
-
-	if output.IndexDocument != nil && output.IndexDocument.Suffix != nil {
-		info.IndexDocument = *output.IndexDocument.Suffix
-	}
-
-	if output.ErrorDocument != nil && output.ErrorDocument.Key != nil {
-		info.ErrorDocument = *output.ErrorDocument.Key
-	}
-
-	if output.RedirectAllRequestsTo != nil && output.RedirectAllRequestsTo.HostName != nil {
-		info.RedirectAllRequests = *output.RedirectAllRequestsTo.HostName
+	return &BucketWebsiteInfo{
+		Enabled:             true,
+		IndexDocument:       website.IndexDocument,
+		ErrorDocument:       website.ErrorDocument,
+		RedirectAllRequests: website.RedirectAllRequestsTo,
 	}
-
-	return info
 }
 
 // NewBucketWebsiteInfoWithError creates a BucketWebsiteInfo with error information
@@ -129,3 +101,68 @@ func (b *BucketInfo) SetPolicy(policy *BucketPolicyInfo) {
 func (b *BucketInfo) SetWebsite(website *BucketWebsiteInfo) {
 	b.Website = website
 }
+
+// Summary describes the policy in a few words, e.g. "present (120 bytes)"
+func (p *BucketPolicyInfo) Summary() string {
+	switch {
+	case p == nil:
+		return "unknown"
+	case p.Error != "":
+		return "unavailable: " + p.Error
+	case p.HasPolicy:
+		return fmt.Sprintf("present (%d bytes)", p.PolicySize)
+	default:
+		return "none"
+	}
+}
+
+// Summary describes the website configuration in a few words, e.g.
+// "enabled (index: index.html, error: 404.html)"
+func (w *BucketWebsiteInfo) Summary() string {
+	switch {
+	case w == nil:
+		return "unknown"
+	case w.Error != "":
+		return "unavailable: " + w.Error
+	case !w.Enabled:
+		return "disabled"
+	case w.RedirectAllRequests != "":
+		return "redirects to " + w.RedirectAllRequests
+	}
+
+	var documents []string
+	if w.IndexDocument != "" {
+		documents = append(documents, "index: "+w.IndexDocument)
+	}
+	if w.ErrorDocument != "" {
+		documents = append(documents, "error: "+w.ErrorDocument)
+	}
+	if len(documents) == 0 {
+		return "enabled"
+	}
+	return "enabled (" + strings.Join(documents, ", ") + ")"
+}
+
+// Fields returns the bucket details as label/value pairs in display order
+func (b *BucketInfo) Fields() [][2]string {
+	created := "unknown"
+	if !b.CreationDate.IsZero() {
+		created = b.CreationDate.Local().Format(time.RFC3339)
+	}
+	region := b.Region
+	if region == "" {
+		region = "unknown"
+	}
+
+	fields := [][2]string{
+		{"Name", b.Name},
+		{"Created", created},
+		{"Region", region},
+		{"Policy", b.Policy.Summary()},
+		{"Website", b.Website.Summary()},
+	}
+	if b.Error != "" {
+		fields = append(fields, [2]string{"Error", b.Error})
+	}
+	return fields
+}
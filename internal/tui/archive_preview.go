@@ -0,0 +1,311 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// ArchivePreviewModel is a fullscreen modal listing the entries of an archive
+type ArchivePreviewModel struct {
+	width  int
+	height int
+	file   FileItem
+	reader *utils.ArchiveReader
+
+	entries []utils.ArchiveEntry
+	cursor  int
+	offset  int
+	loading bool
+	err     error
+
+	// extractDir is where extracted entries are written
+	extractDir string
+	extracting string
+	status     string
+
+	spin spinner.Model
+
+	// ctx is cancelled by Close so listing stops once the modal is gone
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// archiveListedMsg carries the entries of the archive key
+type archiveListedMsg struct {
+	key     string
+	entries []utils.ArchiveEntry
+	err     error
+}
+
+// archiveExtractedMsg reports the result of extracting one entry
+type archiveExtractedMsg struct {
+	key   string
+	entry string
+	path  string
+	size  int64
+	err   error
+}
+
+// Header (name, status, hint, separator), column titles and the footer line
+const archivePreviewChrome = 6
+
+func NewArchivePreviewModel(reader *utils.ArchiveReader, file FileItem, width, height int) *ArchivePreviewModel {
+	extractDir := "."
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		extractDir = filepath.Join(homeDir, "Downloads")
+	}
+
+	m := &ArchivePreviewModel{
+		width:      width,
+		height:     height,
+		file:       file,
+		reader:     reader,
+		loading:    true,
+		extractDir: extractDir,
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.spin = spinner.New()
+	m.spin.Spinner = spinner.Line
+	m.spin.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow))
+	return m
+}
+
+func (m *ArchivePreviewModel) Init() tea.Cmd {
+	return tea.Batch(m.list(), m.spin.Tick)
+}
+
+// list reads the archive directory in the background
+func (m *ArchivePreviewModel) list() tea.Cmd {
+	reader, key, parent := m.reader, m.file.Key, m.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, 60*time.Second)
+		defer cancel()
+
+		entries, err := reader.List(ctx, key)
+		return archiveListedMsg{key: key, entries: entries, err: err}
+	}
+}
+
+// Close cancels a listing that is still running
+func (m *ArchivePreviewModel) Close() {
+	m.cancel()
+}
+
+// extract writes the selected entry into the extract directory, renaming it
+// like downloads do when a file with that name already exists
+func (m *ArchivePreviewModel) extract(entry utils.ArchiveEntry) tea.Cmd {
+	reader, key := m.reader, m.file.Key
+	localPath := filepath.Join(m.extractDir, path.Base(entry.Name))
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		written, size, err := reader.ExtractToFile(ctx, key, entry.Name, localPath, utils.ConflictRename)
+		return archiveExtractedMsg{key: key, entry: entry.Name, path: written, size: size, err: err}
+	}
+}
+
+// SetSize resizes the modal
+func (m *ArchivePreviewModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clampOffset()
+}
+
+// listHeight is the number of entry rows that fit on screen
+func (m *ArchivePreviewModel) listHeight() int {
+	return max(1, m.height-archivePreviewChrome)
+}
+
+// clampOffset keeps the cursor inside the visible window
+func (m *ArchivePreviewModel) clampOffset() {
+	rows := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.entries)-rows))
+}
+
+func (m *ArchivePreviewModel) moveCursor(delta int) {
+	if len(m.entries) == 0 {
+		return
+	}
+	m.cursor = max(0, min(len(m.entries)-1, m.cursor+delta))
+	m.clampOffset()
+}
+
+func (m *ArchivePreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case archiveListedMsg:
+		// Drop results for an archive previewed earlier
+		if msg.key != m.file.Key {
+			return m, nil
+		}
+		m.loading = false
+		m.err = msg.err
+		m.entries = msg.entries
+		m.cursor = 0
+		m.offset = 0
+		return m, nil
+
+	case archiveExtractedMsg:
+		if msg.key != m.file.Key {
+			return m, nil
+		}
+		m.extracting = ""
+		if msg.err != nil {
+			m.status = theme.CreateErrorStyle().Render(fmt.Sprintf("Failed to extract %s: %v", msg.entry, msg.err))
+		} else {
+			m.status = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightGreen)).Render(fmt.Sprintf("Extracted %s (%s) to %s", msg.entry, formatFileSize(msg.size), msg.path))
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "p", "P":
+			return m, func() tea.Msg { return modalClosedMsg{} }
+		case "up", "k":
+			m.moveCursor(-1)
+		case "down", "j":
+			m.moveCursor(1)
+		case "pgup":
+			m.moveCursor(-m.listHeight())
+		case "pgdown":
+			m.moveCursor(m.listHeight())
+		case "g", "home":
+			m.moveCursor(-len(m.entries))
+		case "G", "end":
+			m.moveCursor(len(m.entries))
+		case "enter", "x":
+			if m.loading || m.extracting != "" || len(m.entries) == 0 {
+				return m, nil
+			}
+			entry := m.entries[m.cursor]
+			if entry.IsDir {
+				m.status = theme.CreateHintStyle().Render("Directories cannot be extracted")
+				return m, nil
+			}
+			m.extracting = entry.Name
+			m.status = ""
+			return m, tea.Batch(m.extract(entry), m.spin.Tick)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading || m.extracting != "" {
+			var cmd tea.Cmd
+			m.spin, cmd = m.spin.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m *ArchivePreviewModel) View() string {
+	nameLine := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Bold(true).
+		Foreground(lipgloss.Color(theme.ColorBrightCyan)).
+		Render("📦 " + filepath.Base(m.file.Key))
+
+	var statusText string
+	switch {
+	case m.err != nil:
+		statusText = theme.CreateErrorStyle().Render(fmt.Sprintf("Failed to read archive: %v", m.err))
+	case m.loading:
+		statusText = fmt.Sprintf("%s Reading archive directory…", m.spin.View())
+	case m.extracting != "":
+		statusText = fmt.Sprintf("%s Extracting %s…", m.spin.View(), m.extracting)
+	case m.status != "":
+		statusText = m.status
+	default:
+		statusText = m.summary()
+	}
+	statusLine := lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(statusText)
+
+	hint := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Foreground(lipgloss.Color(theme.ColorBrightBlack)).
+		Render(fmt.Sprintf("↑/↓ pgup/pgdn g/G move • enter/x extract to %s • q/esc close", m.extractDir))
+
+	separator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.ColorBrightBlue)).
+		Render(strings.Repeat("─", max(1, m.width)))
+
+	var b strings.Builder
+	b.WriteString(nameLine)
+	b.WriteString("\n")
+	b.WriteString(statusLine)
+	b.WriteString("\n")
+	b.WriteString(hint)
+	b.WriteString("\n")
+	b.WriteString(separator)
+	b.WriteString("\n")
+	if m.loading || m.err != nil {
+		return b.String()
+	}
+
+	b.WriteString(theme.CreateHintStyle().Render(fmt.Sprintf("  %10s  %-19s  %s", "SIZE", "MODIFIED", "NAME")))
+	b.WriteString("\n")
+
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightCyan)).Bold(true)
+	dir := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightBlue))
+	end := min(len(m.entries), m.offset+m.listHeight())
+	for i := m.offset; i < end; i++ {
+		entry := m.entries[i]
+		size := formatFileSize(entry.Size)
+		if entry.IsDir {
+			size = "-"
+		}
+		modified := ""
+		if !entry.Modified.IsZero() {
+			modified = entry.Modified.Local().Format("2006-01-02 15:04:05")
+		}
+		row := fmt.Sprintf("%10s  %-19s  %s", size, modified, entry.Name)
+		if m.width > 2 {
+			row = ansi.Truncate(row, m.width-2, "…")
+		}
+
+		switch {
+		case i == m.cursor:
+			b.WriteString(selected.Render("▶ " + row))
+		case entry.IsDir:
+			b.WriteString("  " + dir.Render(row))
+		default:
+			b.WriteString("  " + row)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// summary describes the archive contents
+func (m *ArchivePreviewModel) summary() string {
+	var total int64
+	files := 0
+	for _, entry := range m.entries {
+		if !entry.IsDir {
+			files++
+			total += entry.Size
+		}
+	}
+	return fmt.Sprintf("%d files  •  %s uncompressed  •  %s archive", files, formatFileSize(total), formatFileSize(m.file.Size))
+}
@@ -19,10 +19,11 @@ import (
 
 // BucketItem represents a bucket in the selector
 type BucketItem struct {
-	Name      string
-	IsMain    bool
-	IsCurrent bool
-	Error     string
+	Name         string
+	CreationDate time.Time
+	IsMain       bool
+	IsCurrent    bool
+	Error        string
 }
 
 // BucketSelectorModel represents the bucket selector TUI model
@@ -39,6 +40,12 @@ type BucketSelectorModel struct {
 	help          help.Model
 	windowWidth   int
 	windowHeight  int
+
+	// Details of the selected bucket, loaded on demand and cached by name
+	details        map[string]*r2.BucketInfo
+	loadingDetails string
+	// detailsGeneration changes on refresh so results loaded before it are dropped
+	detailsGeneration int
 }
 
 // BucketSelectorKeyMap defines keybindings for bucket selector
@@ -109,6 +116,7 @@ func NewBucketSelectorModel(client *r2.Client, cfg *config.Config) *BucketSelect
 		loading:      true,
 		windowWidth:  80,
 		windowHeight: 24,
+		details:      make(map[string]*r2.BucketInfo),
 	}
 }
 
@@ -135,7 +143,22 @@ func (m *BucketSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(m.buckets) > 0 && m.selectedIndex >= len(m.buckets) {
 			m.selectedIndex = 0
 		}
-		return m, nil
+		return m, m.loadSelectedDetails()
+
+	case bucketDetailsMsg:
+		if msg.generation != m.detailsGeneration {
+			return m, nil
+		}
+		if m.loadingDetails == msg.bucket {
+			m.loadingDetails = ""
+		}
+		if msg.err != nil {
+			m.details[msg.bucket] = &r2.BucketInfo{Name: msg.bucket, Error: msg.err.Error()}
+		} else {
+			m.details[msg.bucket] = msg.info
+		}
+		// The selection may have moved on while this bucket was loading
+		return m, m.loadSelectedDetails()
 
 	case bucketErrorMsg:
 		m.loading = false
@@ -177,13 +200,13 @@ func (m *BucketSelectorModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		if m.selectedIndex > 0 {
 			m.selectedIndex--
 		}
-		return m, nil
+		return m, m.loadSelectedDetails()
 
 	case key.Matches(msg, m.keyMap.Down):
 		if m.selectedIndex < len(m.buckets)-1 {
 			m.selectedIndex++
 		}
-		return m, nil
+		return m, m.loadSelectedDetails()
 
 	case key.Matches(msg, m.keyMap.Select):
 		if len(m.buckets) > 0 {
@@ -205,6 +228,9 @@ func (m *BucketSelectorModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd
 
 	case key.Matches(msg, m.keyMap.Refresh):
 		m.loading = true
+		m.details = make(map[string]*r2.BucketInfo)
+		m.loadingDetails = ""
+		m.detailsGeneration++
 		return m, m.loadBuckets()
 
 	case key.Matches(msg, m.keyMap.Quit):
@@ -354,6 +380,8 @@ func (m *BucketSelectorModel) renderBucketList() string {
 		"",
 		bucketList,
 		"",
+		m.renderDetails(),
+		"",
 	)
 
 	if messageView != "" {
@@ -374,6 +402,36 @@ func (m *BucketSelectorModel) renderBucketList() string {
 	)
 }
 
+// renderDetails renders the details panel of the selected bucket
+func (m *BucketSelectorModel) renderDetails() string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorText))
+
+	info, ok := m.selectedDetails()
+	if !ok {
+		return labelStyle.Render("Loading details...")
+	}
+
+	var lines []string
+	for _, field := range info.Fields() {
+		// The name is already highlighted in the list
+		if field[0] == "Name" {
+			continue
+		}
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%-9s", field[0]+":"))+" "+valueStyle.Render(field[1]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selectedDetails returns the loaded details of the selected bucket
+func (m *BucketSelectorModel) selectedDetails() (*r2.BucketInfo, bool) {
+	if len(m.buckets) == 0 {
+		return nil, false
+	}
+	info, ok := m.details[m.buckets[m.selectedIndex].Name]
+	return info, ok
+}
+
 // renderHelp renders the help view
 func (m *BucketSelectorModel) renderHelp() string {
 	title := lipgloss.NewStyle().
@@ -422,6 +480,13 @@ type mainBucketSetMsg struct {
 	err    error
 }
 
+type bucketDetailsMsg struct {
+	bucket     string
+	generation int
+	info       *r2.BucketInfo
+	err        error
+}
+
 // loadBuckets loads available buckets
 func (m *BucketSelectorModel) loadBuckets() tea.Cmd {
 	return func() tea.Msg {
@@ -442,15 +507,13 @@ func (m *BucketSelectorModel) loadBuckets() tea.Cmd {
 		currentBucket := m.config.GetEffectiveBucket()
 
 		for _, bucket := range buckets {
-			bucketName := ""
-			if bucket.Name != nil {
-				bucketName = *bucket.Name
-			}
+			bucketName := bucket.Name
 
 			item := BucketItem{
-				Name:      bucketName,
-				IsMain:    bucketName == mainBucket,
-				IsCurrent: bucketName == currentBucket,
+				Name:         bucketName,
+				CreationDate: bucket.CreationDate,
+				IsMain:       bucketName == mainBucket,
+				IsCurrent:    bucketName == currentBucket,
 			}
 
 			bucketItems = append(bucketItems, item)
@@ -491,3 +554,30 @@ func (m *BucketSelectorModel) setMainBucket(bucket string) tea.Cmd {
 		return mainBucketSetMsg{bucket: bucket, err: err}
 	}
 }
+
+// loadSelectedDetails loads the details of the selected bucket unless they
+// are cached or another bucket is still loading
+func (m *BucketSelectorModel) loadSelectedDetails() tea.Cmd {
+	if len(m.buckets) == 0 || m.loadingDetails != "" || m.client == nil {
+		return nil
+	}
+	item := m.buckets[m.selectedIndex]
+	bucket := item.Name
+	if _, ok := m.details[bucket]; ok {
+		return nil
+	}
+
+	m.loadingDetails = bucket
+	client, generation := m.client, m.detailsGeneration
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// The creation date comes from the bucket listing already loaded
+		info, err := client.GetBucketDetails(ctx, r2.Bucket{Name: bucket, CreationDate: item.CreationDate})
+		if err != nil {
+			logrus.Errorf("BucketSelector: failed to load details of %s: %v", bucket, err)
+		}
+		return bucketDetailsMsg{bucket: bucket, generation: generation, info: info, err: err}
+	}
+}
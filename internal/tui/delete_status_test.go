@@ -24,7 +24,7 @@ func TestFileBrowser_DeleteStatusIndication(t *testing.T) {
 	}
 
 	// 创建 R2 客户端（mock）
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	require.NoError(t, err)
 
 	// 创建 FileBrowser 模型
@@ -91,7 +91,7 @@ func TestFileBrowser_DeleteStatusBlocking(t *testing.T) {
 	}
 
 	// 创建 R2 客户端（mock）
-	client, err := r2.NewClient(&cfg.R2)
+	client, err := r2.NewClient(&cfg.R2, &cfg.General)
 	require.NoError(t, err)
 
 	// 创建 FileBrowser 模型
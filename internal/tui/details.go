@@ -0,0 +1,348 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	tuiconfig "github.com/HaiFongPan/r2s3-cli/internal/tui/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// Editable headers shown in the details pane
+const (
+	headerContentType        = "Content-Type"
+	headerCacheControl       = "Cache-Control"
+	headerContentDisposition = "Content-Disposition"
+)
+
+// detailsField is one editable row of the details pane
+type detailsField struct {
+	label   string
+	value   string
+	metaKey string // Set for x-amz-meta-* rows
+}
+
+// objectDetailsMsg carries the result of a HeadObject (after an edit when updated is set)
+type objectDetailsMsg struct {
+	key     string
+	info    *utils.ObjectInfo
+	err     error
+	updated bool
+}
+
+// detailsFields returns the editable rows for info: the headers first, then user metadata
+func detailsFields(info *utils.ObjectInfo) []detailsField {
+	if info == nil {
+		return nil
+	}
+	fields := []detailsField{
+		{label: headerContentType, value: info.ContentType},
+		{label: headerCacheControl, value: info.CacheControl},
+		{label: headerContentDisposition, value: info.ContentDisposition},
+	}
+	for _, metaKey := range info.MetadataKeys() {
+		fields = append(fields, detailsField{
+			label:   "x-amz-meta-" + metaKey,
+			value:   info.Metadata[metaKey],
+			metaKey: metaKey,
+		})
+	}
+	return fields
+}
+
+// metadataChange builds the copy options that set field to value. The full user
+// metadata is always sent because REPLACE drops anything that is omitted; an
+// empty value removes a metadata key or header.
+func metadataChange(info *utils.ObjectInfo, field detailsField, value string) (*utils.CopyOptions, error) {
+	options := &utils.CopyOptions{Metadata: info.CopyMetadata()}
+
+	switch {
+	case field.metaKey != "":
+		if value == "" {
+			delete(options.Metadata, field.metaKey)
+		} else {
+			options.Metadata[field.metaKey] = value
+		}
+	case field.label == headerContentType:
+		if value == "" {
+			return nil, fmt.Errorf("Content-Type cannot be empty")
+		}
+		options.ContentType = value
+	case field.label == headerCacheControl:
+		options.CacheControl = &value
+	case field.label == headerContentDisposition:
+		options.ContentDisposition = &value
+	default:
+		return nil, fmt.Errorf("unknown field %s", field.label)
+	}
+	return options, nil
+}
+
+// parseMetadataPair parses "key=value" entered for a new metadata row
+func parseMetadataPair(input string) (detailsField, string, error) {
+	name, value, ok := strings.Cut(input, "=")
+	name = utils.NormalizeMetadataKey(name)
+	if !ok || name == "" {
+		return detailsField{}, "", fmt.Errorf("expected key=value, got %q", input)
+	}
+	return detailsField{label: "x-amz-meta-" + name, metaKey: name}, strings.TrimSpace(value), nil
+}
+
+// openDetails shows the details pane for the selected file and loads its headers
+func (m *FileBrowserModel) openDetails() tea.Cmd {
+	file, ok := m.selectedFile()
+	if !ok {
+		m.setMessage("Select a file to show its details", messaging.MessageWarning)
+		return nil
+	}
+
+	m.showDetails = true
+	m.detailsFile = file.Key
+	m.detailsInfo = nil
+	m.detailsErr = nil
+	m.detailsCursor = 0
+	m.detailsLoading = true
+	return m.loadObjectDetails(file.Key)
+}
+
+// closeDetails hides the details pane
+func (m *FileBrowserModel) closeDetails() {
+	m.showDetails = false
+	m.detailsFile = ""
+	m.detailsInfo = nil
+	m.detailsErr = nil
+	m.detailsLoading = false
+}
+
+// loadObjectDetails fetches the object's headers with HeadObject
+func (m *FileBrowserModel) loadObjectDetails(objectKey string) tea.Cmd {
+	bucket := m.bucketName
+	return func() tea.Msg {
+		storage := m.client.Storage()
+		info, err := utils.StatObject(context.Background(), storage, bucket, objectKey)
+		return objectDetailsMsg{key: objectKey, info: info, err: err}
+	}
+}
+
+// updateObjectMetadata applies options by copying the object onto itself, then reloads its headers
+func (m *FileBrowserModel) updateObjectMetadata(objectKey string, options *utils.CopyOptions) tea.Cmd {
+	bucket := m.bucketName
+	return func() tea.Msg {
+		storage := m.client.Storage()
+		ctx := context.Background()
+
+		if err := utils.NewObjectCopier(storage).UpdateMetadata(ctx, bucket, objectKey, options); err != nil {
+			return objectDetailsMsg{key: objectKey, err: err, updated: true}
+		}
+		info, err := utils.StatObject(ctx, storage, bucket, objectKey)
+		return objectDetailsMsg{key: objectKey, info: info, err: err, updated: true}
+	}
+}
+
+// handleObjectDetails stores a HeadObject result if the pane still shows that object
+func (m *FileBrowserModel) handleObjectDetails(msg objectDetailsMsg) (tea.Model, tea.Cmd) {
+	if !m.showDetails || msg.key != m.detailsFile {
+		return m, nil
+	}
+	m.detailsLoading = false
+
+	if msg.err != nil {
+		if msg.updated {
+			// Keep showing the previous headers after a failed edit
+			m.setMessage(theme.FormatErrorMessage("Update metadata", msg.err), messaging.MessageError)
+			return m, nil
+		}
+		m.detailsErr = msg.err
+		return m, nil
+	}
+
+	m.detailsInfo = msg.info
+	m.detailsErr = nil
+	if fields := detailsFields(msg.info); m.detailsCursor >= len(fields) {
+		m.detailsCursor = max(0, len(fields)-1)
+	}
+	if msg.updated {
+		m.setMessage(fmt.Sprintf("Metadata of %s updated", msg.key), messaging.MessageSuccess)
+	}
+	return m, nil
+}
+
+// handleDetailsKey handles keys while the details pane is open
+func (m *FileBrowserModel) handleDetailsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	fields := detailsFields(m.detailsInfo)
+
+	switch {
+	case key.Matches(msg, m.keyMap.Quit), key.Matches(msg, m.keyMap.Details):
+		m.closeDetails()
+
+	case key.Matches(msg, m.keyMap.Up):
+		if m.detailsCursor > 0 {
+			m.detailsCursor--
+		}
+
+	case key.Matches(msg, m.keyMap.Down):
+		if m.detailsCursor < len(fields)-1 {
+			m.detailsCursor++
+		}
+
+	case key.Matches(msg, m.keyMap.Refresh):
+		m.detailsLoading = true
+		return m, m.loadObjectDetails(m.detailsFile)
+
+	case msg.String() == "enter", msg.String() == "e":
+		if m.detailsLoading || m.detailsCursor >= len(fields) {
+			return m, nil
+		}
+		m.showMetadataInput(fields[m.detailsCursor])
+
+	case msg.String() == "+":
+		if m.detailsLoading || m.detailsInfo == nil {
+			return m, nil
+		}
+		m.showMetadataInput(detailsField{})
+
+	case key.Matches(msg, m.keyMap.Delete):
+		if m.detailsLoading || m.detailsCursor >= len(fields) {
+			return m, nil
+		}
+		field := fields[m.detailsCursor]
+		if field.value == "" {
+			return m, nil
+		}
+		return m.applyMetadataChange(field, "")
+	}
+
+	return m, nil
+}
+
+// showMetadataInput opens the input popup for field; a zero field adds a new metadata key
+func (m *FileBrowserModel) showMetadataInput(field detailsField) {
+	m.detailsEdit = field
+	m.showInput = true
+	m.inputMode = InputModeEditMetadata
+	m.inputComponentMode = InputComponentText
+
+	if field.label == "" {
+		m.inputPrompt = "Add metadata to " + m.detailsFile + ":"
+		m.textInput.SetValue("")
+		m.textInput.Placeholder = "key=value"
+	} else {
+		m.inputPrompt = fmt.Sprintf("%s of %s:", field.label, m.detailsFile)
+		m.textInput.SetValue(field.value)
+		m.textInput.Placeholder = "Leave empty to remove"
+	}
+	m.textInput.Focus()
+}
+
+// processMetadataInput applies the value entered in the input popup
+func (m *FileBrowserModel) processMetadataInput() (tea.Model, tea.Cmd) {
+	m.showInput = false
+	m.inputMode = InputModeNone
+
+	value := strings.TrimSpace(m.textInput.Value())
+	m.textInput.SetValue("")
+	m.textInput.Blur()
+
+	field := m.detailsEdit
+	m.detailsEdit = detailsField{}
+	if field.label == "" {
+		var err error
+		field, value, err = parseMetadataPair(value)
+		if err != nil {
+			m.setMessage(err.Error(), messaging.MessageError)
+			return m, nil
+		}
+	} else if value == field.value {
+		return m, nil
+	}
+
+	return m.applyMetadataChange(field, value)
+}
+
+// applyMetadataChange starts the self-copy that sets field to value
+func (m *FileBrowserModel) applyMetadataChange(field detailsField, value string) (tea.Model, tea.Cmd) {
+	if m.detailsInfo == nil {
+		return m, nil
+	}
+	options, err := metadataChange(m.detailsInfo, field, value)
+	if err != nil {
+		m.setMessage(err.Error(), messaging.MessageError)
+		return m, nil
+	}
+	options.Concurrency = m.config.Upload.MultipartConcurrency
+
+	m.detailsLoading = true
+	m.setMessage(fmt.Sprintf("Updating %s of %s...", field.label, m.detailsFile), messaging.MessageWarning)
+	return m, m.updateObjectMetadata(m.detailsFile, options)
+}
+
+// renderDetailsDialog renders the object details pane
+func (m *FileBrowserModel) renderDetailsDialog() string {
+	dialogWidth := min(tuiconfig.DialogLargeWidth, m.windowWidth-10)
+	dialogStyle := theme.CreateDialogStyle(dialogWidth, theme.ColorBrightCyan)
+
+	titleStyle := theme.CreateSectionHeaderStyle().
+		Align(lipgloss.Center).
+		MarginBottom(1)
+	labelStyle := theme.CreateSecondaryTextStyle()
+	hintStyle := theme.CreateSecondaryTextStyle().MarginTop(1)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("📋 Object Details"))
+	b.WriteString("\n")
+	b.WriteString(m.detailsFile)
+	b.WriteString("\n\n")
+
+	switch {
+	case m.detailsErr != nil:
+		b.WriteString(theme.CreateErrorStyle().Render(theme.FormatErrorMessage("HeadObject", m.detailsErr)))
+		b.WriteString("\n")
+	case m.detailsInfo == nil:
+		b.WriteString(theme.CreateLoadingStyle().Render("Loading headers..."))
+		b.WriteString("\n")
+	default:
+		info := m.detailsInfo
+		row := func(label, value string) {
+			if value == "" {
+				value = "-"
+			}
+			b.WriteString(fmt.Sprintf("  %s %s\n", labelStyle.Render(fmt.Sprintf("%-21s", label)), value))
+		}
+		row("Size", fmt.Sprintf("%s (%d bytes)", formatFileSize(info.Size), info.Size))
+		row("Last-Modified", info.LastModified.Local().Format(time.DateTime))
+		row("ETag", info.ETag)
+		row("Content-Encoding", info.ContentEncoding)
+		row("Storage-Class", info.StorageClass)
+		b.WriteString("\n")
+
+		for i, field := range detailsFields(info) {
+			value := field.value
+			if value == "" {
+				value = "-"
+			}
+			line := fmt.Sprintf("%-21s %s", field.label, value)
+			if i == m.detailsCursor {
+				b.WriteString(theme.CreateHighlightStyle().Render("▶ " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		if m.detailsLoading {
+			b.WriteString("\n")
+			b.WriteString(theme.CreateLoadingStyle().Render("Updating..."))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(hintStyle.Render("↑/↓: select • enter: edit • +: add metadata • x: remove • r: reload • esc: close"))
+	return dialogStyle.Render(b.String())
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+func detailsTestInfo() *utils.ObjectInfo {
+	return &utils.ObjectInfo{
+		Key:          "index.html",
+		ContentType:  "text/html",
+		CacheControl: "max-age=60",
+		Metadata:     map[string]string{"owner": "alice", "build": "7"},
+	}
+}
+
+// TestDetailsFields 测试详情面板的可编辑字段顺序
+func TestDetailsFields(t *testing.T) {
+	fields := detailsFields(detailsTestInfo())
+	require.Len(t, fields, 5)
+	assert.Equal(t, headerContentType, fields[0].label)
+	assert.Equal(t, "max-age=60", fields[1].value)
+	assert.Equal(t, "x-amz-meta-build", fields[3].label)
+	assert.Equal(t, "owner", fields[4].metaKey)
+}
+
+// TestMetadataChange 测试修改请求头和元数据生成的复制选项
+func TestMetadataChange(t *testing.T) {
+	info := detailsTestInfo()
+	fields := detailsFields(info)
+
+	// 修改请求头时保留全部元数据
+	options, err := metadataChange(info, fields[1], "no-cache")
+	require.NoError(t, err)
+	assert.Equal(t, "no-cache", *options.CacheControl)
+	assert.Equal(t, info.Metadata, options.Metadata)
+
+	// 空值删除元数据，且不修改原对象信息
+	options, err = metadataChange(info, fields[4], "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"build": "7"}, options.Metadata)
+	assert.Equal(t, "alice", info.Metadata["owner"])
+
+	_, err = metadataChange(info, fields[0], "")
+	assert.Error(t, err)
+
+	// 新增元数据
+	field, value, err := parseMetadataPair("X-Amz-Meta-Album = 2024")
+	require.NoError(t, err)
+	assert.Equal(t, "album", field.metaKey)
+	assert.Equal(t, "2024", value)
+	_, _, err = parseMetadataPair("novalue")
+	assert.Error(t, err)
+}
+
+// TestFileBrowser_DetailsPane 测试详情面板的打开、导航和编辑输入
+func TestFileBrowser_DetailsPane(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.cursor = 1
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	fb := updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	require.True(t, fb.showDetails)
+	assert.Equal(t, "a.txt", fb.detailsFile)
+
+	// 过期的结果被忽略
+	fb.handleObjectDetails(objectDetailsMsg{key: "b.png", info: detailsTestInfo()})
+	assert.Nil(t, fb.detailsInfo)
+
+	fb.handleObjectDetails(objectDetailsMsg{key: "a.txt", info: detailsTestInfo()})
+	assert.False(t, fb.detailsLoading)
+	assert.Contains(t, fb.renderDetailsDialog(), "x-amz-meta-owner")
+
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyDown})
+	fb = updated.(*FileBrowserModel)
+	assert.Equal(t, 1, fb.detailsCursor)
+
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	fb = updated.(*FileBrowserModel)
+	require.True(t, fb.showInput)
+	assert.Equal(t, InputModeEditMetadata, fb.inputMode)
+	assert.Equal(t, "max-age=60", fb.textInput.Value())
+
+	// 未修改时不发起请求，详情面板保持打开
+	updated, cmd = fb.processMetadataInput()
+	fb = updated.(*FileBrowserModel)
+	assert.Nil(t, cmd)
+	assert.True(t, fb.showDetails)
+
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	fb = updated.(*FileBrowserModel)
+	assert.False(t, fb.showDetails)
+}
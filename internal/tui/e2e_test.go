@@ -0,0 +1,472 @@
+package tui
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2/r2test"
+)
+
+const e2eBucket = "tui-bucket"
+
+// newE2EFileBrowser 创建连接到内存 S3 服务的文件浏览器
+func newE2EFileBrowser(t *testing.T, pageSize int) (*FileBrowserModel, *r2test.Server) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	server := r2test.NewServer(t, e2eBucket)
+	cfg := &config.Config{
+		R2:      server.R2Config(e2eBucket),
+		General: config.GeneralConfig{DefaultTimeout: 10, MaxRetries: 3},
+		UI:      config.UIConfig{PageSize: pageSize},
+	}
+	client := server.Client(t, e2eBucket)
+	return NewFileBrowserModel(client, cfg, e2eBucket, ""), server
+}
+
+// runUntil 模拟 bubbletea 事件循环：异步执行命令，把消息交给 Update，
+// 直到 done 返回 true。spinner 的 tick 消息被丢弃，避免循环永不停止
+func runUntil(t *testing.T, model *FileBrowserModel, cmd tea.Cmd, done func(*FileBrowserModel) bool) *FileBrowserModel {
+	t.Helper()
+
+	msgs := make(chan tea.Msg, 64)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	var start func(tea.Cmd)
+	start = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		go func() {
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, c := range batch {
+					start(c)
+				}
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-stop:
+			}
+		}()
+	}
+	start(cmd)
+
+	timeout := time.After(10 * time.Second)
+	for !done(model) {
+		select {
+		case msg := <-msgs:
+			if _, isTick := msg.(spinner.TickMsg); isTick || msg == nil {
+				continue
+			}
+			updated, next := model.Update(msg)
+			model = updated.(*FileBrowserModel)
+			start(next)
+		case <-timeout:
+			t.Fatalf("timed out waiting for the file browser, files: %v", fileKeys(model))
+		}
+	}
+	return model
+}
+
+// pressKey 发送一个按键并执行返回的命令
+func pressKey(t *testing.T, model *FileBrowserModel, msg tea.KeyMsg, done func(*FileBrowserModel) bool) *FileBrowserModel {
+	t.Helper()
+	updated, cmd := model.Update(msg)
+	return runUntil(t, updated.(*FileBrowserModel), cmd, done)
+}
+
+func runeKey(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func fileKeys(model *FileBrowserModel) []string {
+	keys := make([]string, 0, len(model.files))
+	for _, file := range model.files {
+		keys = append(keys, file.Key)
+	}
+	return keys
+}
+
+func notLoading(model *FileBrowserModel) bool {
+	return !model.loading && !model.paginationLoading
+}
+
+// TestE2E_FileBrowserListing 测试从真实 S3 协议加载目录、分页和进入子目录
+func TestE2E_FileBrowserListing(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 2)
+	server.PutObject(e2eBucket, "a.txt", []byte("a"))
+	server.PutObject(e2eBucket, "b.txt", []byte("bb"))
+	server.PutObject(e2eBucket, "images/logo.png", []byte("png"))
+	server.PutObject(e2eBucket, "images/photo.jpg", []byte("jpg"))
+
+	// 第一页：按 key 排序，目录和文件一起计入页大小
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.NoError(t, model.error)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, fileKeys(model))
+	assert.True(t, model.hasNextPage)
+	assert.Equal(t, int64(2), model.files[1].Size)
+
+	// 下一页只剩目录
+	model = pressKey(t, model, runeKey("n"), notLoading)
+	assert.Equal(t, []string{"images/"}, fileKeys(model))
+	assert.True(t, model.files[0].IsDir)
+	assert.False(t, model.hasNextPage)
+
+	// 进入目录
+	model.cursor = 0
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyEnter}, func(m *FileBrowserModel) bool {
+		return m.prefix == "images/" && notLoading(m) && len(m.files) > 0
+	})
+	assert.Equal(t, []string{"images/logo.png", "images/photo.jpg"}, fileKeys(model))
+	assert.Equal(t, "image", model.files[0].Category)
+}
+
+// TestE2E_FileBrowserSearch 测试搜索会扫描所有分页并过滤结果
+func TestE2E_FileBrowserSearch(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 50)
+	for i := 0; i < 1200; i++ {
+		server.PutObject(e2eBucket, fmt.Sprintf("data/%04d.bin", i), []byte("x"))
+	}
+	server.PutObject(e2eBucket, "assets/logo.png", []byte("png"))
+	server.PutObject(e2eBucket, "z/logo-dark.svg", []byte("svg"))
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.NoError(t, model.error)
+
+	// 打开搜索输入框并输入查询
+	model = pressKey(t, model, runeKey("s"), func(*FileBrowserModel) bool { return true })
+	require.Equal(t, InputModeSearch, model.inputMode)
+	model = pressKey(t, model, runeKey("logo"), func(*FileBrowserModel) bool { return true })
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyEnter}, func(m *FileBrowserModel) bool {
+		return m.isSearchMode && !m.searchScanning
+	})
+
+	assert.Equal(t, []string{"assets/logo.png", "z/logo-dark.svg"}, fileKeys(model))
+	assert.Equal(t, 1202, model.searchScanned)
+	// 一次目录加载，加上超过 1000 个 key 的两页扫描
+	assert.Equal(t, 3, server.CountOperation("ListObjectsV2"))
+}
+
+// TestE2E_FileBrowserDelete 测试删除确认后对象被删除并重新加载列表
+func TestE2E_FileBrowserDelete(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 50)
+	server.PutObject(e2eBucket, "keep.txt", []byte("keep"))
+	server.PutObject(e2eBucket, "remove.txt", []byte("remove"))
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.Equal(t, []string{"keep.txt", "remove.txt"}, fileKeys(model))
+
+	model.cursor = 1
+	model.fileTable.SetCursor(1)
+	model = pressKey(t, model, runeKey("x"), func(*FileBrowserModel) bool { return true })
+	require.True(t, model.confirmDelete)
+	assert.Equal(t, "remove.txt", model.deleteTarget)
+
+	// 确认删除后等待重新加载完成
+	model = pressKey(t, model, runeKey("y"), func(m *FileBrowserModel) bool {
+		return !m.deleting && notLoading(m) && len(m.files) == 1
+	})
+
+	assert.Equal(t, []string{"keep.txt"}, fileKeys(model))
+	assert.Equal(t, []string{"keep.txt"}, server.Keys(e2eBucket))
+	assert.Equal(t, 1, server.CountOperation("DeleteObject"))
+}
+
+// countingStorage 是一个装饰器后端，统计列表请求次数
+type countingStorage struct {
+	r2.Storage
+	lists atomic.Int32
+}
+
+func (s *countingStorage) List(ctx context.Context, bucket string, options r2.ListOptions) (*r2.ListPage, error) {
+	s.lists.Add(1)
+	return s.Storage.List(ctx, bucket, options)
+}
+
+// TestE2E_FileBrowserCustomStorage 测试文件浏览器可以使用自定义存储后端
+func TestE2E_FileBrowserCustomStorage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := r2test.NewServer(t, e2eBucket)
+	server.PutObject(e2eBucket, "a.txt", []byte("a"))
+
+	storage := &countingStorage{Storage: server.Client(t, e2eBucket).Storage()}
+	cfg := &config.Config{R2: server.R2Config(e2eBucket), UI: config.UIConfig{PageSize: 50}}
+	client := r2.NewClientWithStorage(storage, &cfg.R2)
+
+	model := NewFileBrowserModel(client, cfg, e2eBucket, "")
+	model = runUntil(t, model, model.Init(), notLoading)
+
+	assert.Equal(t, []string{"a.txt"}, fileKeys(model))
+	assert.Equal(t, int32(1), storage.lists.Load())
+}
+
+// TestE2E_BucketSelectorDetails 测试存储桶选择器加载列表后按选中项加载详情
+func TestE2E_BucketSelectorDetails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := r2test.NewServer(t, e2eBucket, "archive")
+	cfg := &config.Config{R2: server.R2Config(e2eBucket)}
+	selector := NewBucketSelectorModel(server.Client(t, e2eBucket), cfg)
+
+	// 直接驱动选择器，直到选中项的详情加载完成
+	run := func(cmd tea.Cmd, bucket string) {
+		t.Helper()
+		for cmd != nil {
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				require.Len(t, batch, 1)
+				msg = batch[0]()
+			}
+			if _, isTick := msg.(spinner.TickMsg); isTick {
+				break
+			}
+			_, cmd = selector.Update(msg)
+			if _, ok := selector.details[bucket]; ok {
+				return
+			}
+		}
+		require.Contains(t, selector.details, bucket)
+	}
+
+	run(selector.loadBuckets(), "archive")
+	require.Len(t, selector.buckets, 2)
+	info := selector.details["archive"]
+	assert.Empty(t, info.Error)
+	assert.Contains(t, selector.View(), "Website:  disabled")
+
+	// 移动光标后加载下一个存储桶，已加载的详情被缓存
+	_, cmd := selector.Update(tea.KeyMsg{Type: tea.KeyDown})
+	run(cmd, e2eBucket)
+	assert.Equal(t, 2, server.CountOperation("HeadBucket"))
+
+	_, cmd = selector.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Nil(t, cmd)
+
+	// 创建时间来自已加载的列表，不会为每个存储桶重新列出
+	assert.Equal(t, 1, server.CountOperation("ListBuckets"))
+	assert.False(t, selector.details["archive"].CreationDate.IsZero())
+
+	// 刷新前开始加载的详情在刷新后被丢弃
+	stale := bucketDetailsMsg{bucket: "archive", generation: selector.detailsGeneration, info: info}
+	_, cmd = selector.Update(runeKey("r"))
+	require.NotNil(t, cmd)
+	assert.Empty(t, selector.loadingDetails)
+	selector.Update(stale)
+	assert.NotContains(t, selector.details, "archive")
+}
+
+// TestE2E_TextPreview 测试文本预览只用 Range 请求读取开头部分，并可以继续加载
+func TestE2E_TextPreview(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := r2test.NewServer(t, e2eBucket)
+	var content strings.Builder
+	for i := 0; content.Len() < 2500; i++ {
+		fmt.Fprintf(&content, "2024-05-01 12:00:%02d INFO line %d\n", i%60, i)
+	}
+	server.PutObject(e2eBucket, "app.log", []byte(content.String()))
+
+	// 每次加载 1KB
+	cfg := &config.Config{
+		R2: server.R2Config(e2eBucket),
+		UI: config.UIConfig{PageSize: 50, TextPreviewKB: 1},
+	}
+	model := NewFileBrowserModel(server.Client(t, e2eBucket), cfg, e2eBucket, "")
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.Equal(t, []string{"app.log"}, fileKeys(model))
+
+	previewLoaded := func(m *FileBrowserModel) bool {
+		return m.textPreview != nil && !m.textPreview.loading
+	}
+	model = pressKey(t, model, runeKey("p"), previewLoaded)
+	preview := model.textPreview
+	require.NoError(t, preview.err)
+	assert.Equal(t, int64(1024), preview.doc.Loaded())
+	assert.Equal(t, int64(content.Len()), preview.doc.Total())
+	assert.Contains(t, preview.View(), "first 1.0 KB")
+	assert.Equal(t, 1, server.CountOperation("GetObject"))
+
+	// 继续加载直到读完整个对象
+	model = pressKey(t, model, runeKey("m"), previewLoaded)
+	assert.Equal(t, int64(2048), model.textPreview.doc.Loaded())
+	model = pressKey(t, model, runeKey("m"), previewLoaded)
+	assert.True(t, model.textPreview.doc.Complete())
+	assert.Equal(t, content.String(), model.textPreview.doc.Text())
+	assert.Equal(t, 3, server.CountOperation("GetObject"))
+
+	// 加载完后不再发请求
+	model = pressKey(t, model, runeKey("m"), previewLoaded)
+	assert.Equal(t, 3, server.CountOperation("GetObject"))
+
+	model = pressKey(t, model, runeKey("q"), func(m *FileBrowserModel) bool { return m.textPreview == nil })
+	assert.Nil(t, model.textPreview)
+}
+
+// TestE2E_ArchivePreview 测试压缩包预览列出条目，并能解压单个条目到本地
+func TestE2E_ArchivePreview(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 50)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"docs/", "docs/readme.txt"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		if !strings.HasSuffix(name, "/") {
+			_, err = w.Write([]byte("read me"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, zw.Close())
+	server.PutObject(e2eBucket, "bundle.zip", buf.Bytes())
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.Equal(t, []string{"bundle.zip"}, fileKeys(model))
+
+	listed := func(m *FileBrowserModel) bool {
+		return m.archivePreview != nil && !m.archivePreview.loading
+	}
+	model = pressKey(t, model, runeKey("p"), listed)
+	preview := model.archivePreview
+	require.NoError(t, preview.err)
+	require.Len(t, preview.entries, 2)
+	assert.True(t, preview.entries[0].IsDir)
+	assert.Contains(t, preview.View(), "docs/readme.txt")
+	assert.Contains(t, preview.View(), "1 files")
+
+	// 目录不能解压
+	model = pressKey(t, model, runeKey("x"), listed)
+	assert.Contains(t, model.archivePreview.status, "Directories cannot be extracted")
+
+	preview.extractDir = t.TempDir()
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyDown}, listed)
+	extracted := func(m *FileBrowserModel) bool {
+		return m.archivePreview != nil && m.archivePreview.extracting == "" && m.archivePreview.status != ""
+	}
+	model = pressKey(t, model, runeKey("x"), extracted)
+	assert.Contains(t, model.archivePreview.status, "Extracted docs/readme.txt")
+	content, err := os.ReadFile(filepath.Join(preview.extractDir, "readme.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "read me", string(content))
+
+	model = pressKey(t, model, runeKey("q"), func(m *FileBrowserModel) bool { return m.archivePreview == nil })
+	assert.Nil(t, model.archivePreview)
+}
+
+// TestE2E_PDFPreviewWithoutTools 测试没有外部工具时 PDF 预览显示元数据卡片
+func TestE2E_PDFPreviewWithoutTools(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 50)
+	t.Setenv("PATH", t.TempDir())
+	server.PutObject(e2eBucket, "report.pdf", []byte(`%PDF-1.4
+1 0 obj << /Type /Pages /Kids [2 0 R 3 0 R] /Count 2 >> endobj
+2 0 obj << /Type /Page /Parent 1 0 R >> endobj
+3 0 obj << /Type /Page /Parent 1 0 R >> endobj
+%%EOF`))
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	require.Equal(t, []string{"report.pdf"}, fileKeys(model))
+	assert.NotEmpty(t, model.files[0].ETag)
+
+	model = pressKey(t, model, runeKey("p"), func(m *FileBrowserModel) bool {
+		return m.previewModal != nil && !m.previewModal.loading
+	})
+	preview := model.previewModal
+	require.NoError(t, preview.err)
+	assert.Contains(t, preview.card, "Pages:      2")
+	assert.Contains(t, preview.card, "Install pdftoppm")
+
+	view := preview.View()
+	assert.Contains(t, view, "📄 report.pdf")
+	assert.Contains(t, view, "no thumbnail  •  2 pages")
+}
+
+// TestE2E_Gallery 测试画廊模式：ANSI 缩略图网格、方向键导航、打开预览后返回画廊
+func TestE2E_Gallery(t *testing.T) {
+	// 使用 ANSI 半块字符渲染，避免依赖运行测试的终端
+	for _, env := range []string{"TERM", "TERM_PROGRAM", "KITTY_WINDOW_ID", "GHOSTTY"} {
+		t.Setenv(env, "")
+	}
+	model, server := newE2EFileBrowser(t, 50)
+
+	for i := 0; i < 5; i++ {
+		pic := image.NewRGBA(image.Rect(0, 0, 48, 32))
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 48; x++ {
+				pic.Set(x, y, color.RGBA{R: uint8(40 * i), G: 120, B: 200, A: 255})
+			}
+		}
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, pic))
+		server.PutObject(e2eBucket, fmt.Sprintf("gallery-%d.png", i), buf.Bytes())
+	}
+	server.PutObject(e2eBucket, "notes.txt", []byte("not an image"))
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model = updated.(*FileBrowserModel)
+
+	loaded := func(m *FileBrowserModel) bool {
+		return m.gallery != nil && len(m.gallery.pending) == 0 && len(m.gallery.thumbs) == 5
+	}
+	model = pressKey(t, model, runeKey("t"), loaded)
+	gallery := model.gallery
+	require.Len(t, gallery.items, 5, "only images are shown")
+	assert.False(t, gallery.graphics)
+	assert.Equal(t, 4, gallery.columns())
+
+	view := gallery.View()
+	assert.Contains(t, view, "Gallery  •  5 images")
+	assert.Contains(t, view, "▀")
+	assert.Contains(t, view, "gallery-4.png")
+
+	// 右移一格，再下移一行时停在最后一张
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyRight}, loaded)
+	assert.Equal(t, 1, model.gallery.cursor)
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyDown}, loaded)
+	assert.Equal(t, 4, model.gallery.cursor)
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyUp}, loaded)
+	assert.Equal(t, 0, model.gallery.cursor)
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyRight}, loaded)
+
+	// 打开大图预览，关闭后回到画廊
+	model = pressKey(t, model, tea.KeyMsg{Type: tea.KeyEnter}, func(m *FileBrowserModel) bool {
+		return m.previewModal != nil && !m.previewModal.loading
+	})
+	assert.Equal(t, "gallery-1.png", model.previewModal.file.Key)
+	model = pressKey(t, model, runeKey("q"), func(m *FileBrowserModel) bool { return m.previewModal == nil })
+	require.NotNil(t, model.gallery)
+	assert.Contains(t, model.View(), "Gallery  •  5 images")
+
+	model = pressKey(t, model, runeKey("q"), func(m *FileBrowserModel) bool { return m.gallery == nil })
+	assert.Equal(t, "gallery-1.png", model.files[model.cursor].Key)
+}
+
+// TestE2E_GalleryWithoutImages 测试当前页没有图片时不打开画廊
+func TestE2E_GalleryWithoutImages(t *testing.T) {
+	model, server := newE2EFileBrowser(t, 50)
+	server.PutObject(e2eBucket, "notes.txt", []byte("text"))
+
+	model = runUntil(t, model, model.Init(), notLoading)
+	updated, _ := model.Update(runeKey("t"))
+	model = updated.(*FileBrowserModel)
+	assert.Nil(t, model.gallery)
+	assert.Contains(t, model.View(), "No images on this page")
+}
@@ -9,8 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -28,6 +26,7 @@ import (
 	tuiconfig "github.com/HaiFongPan/r2s3-cli/internal/tui/config"
 	"github.com/HaiFongPan/r2s3-cli/internal/tui/image"
 	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/text"
 	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
 	"github.com/HaiFongPan/r2s3-cli/internal/utils"
 )
@@ -39,6 +38,8 @@ type FileItem struct {
 	LastModified time.Time
 	ContentType  string
 	Category     string
+	ETag         string
+	IsDir        bool // Common prefix shown as a folder row
 }
 
 // KeyMap defines keybindings for the file browser
@@ -67,6 +68,15 @@ type KeyMap struct {
 	Cancel       key.Binding
 	CopyCustom   key.Binding
 	CopyPresign  key.Binding
+	Open         key.Binding
+	Back         key.Binding
+	ToggleFlat   key.Binding
+	Mark         key.Binding
+	SelectAll    key.Binding
+	Invert       key.Binding
+	Rename       key.Binding
+	Details      key.Binding
+	Gallery      key.Binding
 }
 
 // DefaultKeyMap returns default keybindings
@@ -138,7 +148,7 @@ func DefaultKeyMap() KeyMap {
 		),
 		ToggleImage: key.NewBinding(
 			key.WithKeys("p"),
-			key.WithHelp("p", "preview image"),
+			key.WithHelp("p", "preview file"),
 		),
 		ForcePreview: key.NewBinding(
 			key.WithKeys("P"),
@@ -166,7 +176,43 @@ func DefaultKeyMap() KeyMap {
 		),
 		CopyPresign: key.NewBinding(
 			key.WithKeys("ctrl+y"),
-			key.WithHelp("ctrl+y", "copy presigned URL"),
+			key.WithHelp("ctrl+y", "copy presigned URL (pick expiry)"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open folder"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "parent folder"),
+		),
+		ToggleFlat: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "toggle flat view"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark file"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "mark all on page"),
+		),
+		Invert: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert marks on page"),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rename"),
+		),
+		Details: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "object details"),
+		),
+		Gallery: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "gallery"),
 		),
 	}
 }
@@ -181,11 +227,13 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown},
 		{k.Home, k.End, k.Refresh},
-		{k.Download, k.Preview, k.Delete},
+		{k.Open, k.Back, k.ToggleFlat},
+		{k.Mark, k.SelectAll, k.Invert},
+		{k.Download, k.Preview, k.Delete, k.Rename, k.Details},
 		{k.Search, k.Upload, k.ClearSearch},
 		{k.CopyCustom, k.CopyPresign},
 		{k.ChangeBucket},
-		{k.NextPage, k.PrevPage, k.ToggleImage, k.ForcePreview},
+		{k.NextPage, k.PrevPage, k.ToggleImage, k.ForcePreview, k.Gallery},
 		{k.Confirm, k.Cancel},
 		{k.Help, k.Quit},
 	}
@@ -199,6 +247,10 @@ const (
 	InputModeSearch
 	InputModeUpload
 	InputModeUploadTarget
+	InputModeDownloadDir
+	InputModeRename
+	InputModeEditMetadata
+	InputModePresignExpiry
 )
 
 // InputComponentMode represents different input component types
@@ -252,6 +304,10 @@ type FileBrowserModel struct {
 	paginationLoading   bool // Loading state for pagination (different from initial loading)
 	estimatedTotalPages int  // Estimated total pages (updated as we navigate)
 
+	// Folder navigation
+	flatMode     bool           // List every key below the prefix instead of folders
+	cursorMemory map[string]int // Cursor position per visited prefix
+
 	// Input states
 	showInput          bool
 	inputMode          InputMode
@@ -264,20 +320,46 @@ type FileBrowserModel struct {
 	messageManager messaging.StatusManager
 
 	// Search state
-	searchQuery  string
-	isSearchMode bool
+	searchQuery      string
+	isSearchMode     bool
+	searchSpec       *searchSpec
+	searchCtx        context.Context
+	searchCancel     context.CancelFunc
+	searchGeneration int  // Incremented per scan so stale pages are dropped
+	searchScanning   bool // A background scan is still running
+	searchScanned    int  // Objects scanned so far
 
 	// Upload state
-	uploadProgress progress.Model
-	uploading      bool
-	uploadingFile  string
-	fileUploader   utils.FileUploader
-	uploadFilePath string // Temporary storage for selected file path
+	uploadProgress   progress.Model
+	uploading        bool
+	uploadingFile    string
+	fileUploader     utils.FileUploader
+	uploadFilePath   string // Temporary storage for selected file path
 	uploadTargetPath string // Target path for upload
 
 	// Delete state
-	deleting     bool
-	deletingFile string
+	deleting        bool
+	deletingFile    string
+	batchDeleteKeys []string // Keys of a pending batch delete
+
+	// Multi-select state
+	selected map[string]FileItem
+
+	// Rename state
+	renaming     bool
+	renameSource string // Key being renamed
+
+	// Object details state
+	showDetails    bool
+	detailsFile    string
+	detailsInfo    *utils.ObjectInfo
+	detailsErr     error
+	detailsLoading bool
+	detailsCursor  int
+	detailsEdit    detailsField // Field edited through the input popup
+
+	// Last expiry picked for presigned URLs (zero means the default)
+	presignExpiry time.Duration
 
 	// Image preview state
 	imageManager        *image.ImageManager
@@ -294,6 +376,18 @@ type FileBrowserModel struct {
 	previewModal     *ImagePreviewModel
 	lastPreviewFile  *FileItem
 	lastPreviewForce bool
+
+	// Text preview modal state
+	textLoader  *text.Loader
+	textPreview *TextPreviewModel
+
+	// Archive preview modal state
+	archiveReader  *utils.ArchiveReader
+	archivePreview *ArchivePreviewModel
+
+	// Gallery mode state
+	thumbnailLoader *image.ThumbnailLoader
+	gallery         *GalleryModel
 }
 
 // createFilePicker creates a properly configured file picker
@@ -326,9 +420,9 @@ func createFilePicker() filepicker.Model {
 
 // NewFileBrowserModel creates a new file browser model
 func NewFileBrowserModel(client *r2.Client, cfg *config.Config, bucketName, prefix string) *FileBrowserModel {
-	urlGenerator := utils.NewURLGenerator(client.GetS3Client().(*s3.Client), cfg, bucketName)
-	fileDownloader := utils.NewFileDownloader(client.GetS3Client().(*s3.Client), bucketName)
-	fileUploader := utils.NewFileUploader(client, cfg, bucketName)
+	urlGenerator := utils.NewURLGenerator(client.Storage(), cfg, bucketName)
+	fileDownloader := utils.NewFileDownloader(client.Storage(), bucketName)
+	fileUploader := utils.NewFileUploader(client.Storage(), cfg, bucketName)
 
 	// Initialize table with proper column configuration
 	columns := []table.Column{
@@ -400,6 +494,7 @@ func NewFileBrowserModel(client *r2.Client, cfg *config.Config, bucketName, pref
 		currentPage:         1,
 		hasNextPage:         false,
 		estimatedTotalPages: 1,
+		cursorMemory:        make(map[string]int),
 
 		// Input states
 		showInput:          false,
@@ -425,7 +520,7 @@ func NewFileBrowserModel(client *r2.Client, cfg *config.Config, bucketName, pref
 		deletingFile: "",
 
 		// Image preview state
-		imageManager:        image.NewImageManager("/tmp/r2s3-cli-cache", 100*1024*1024), // 100MB cache
+		imageManager:        image.NewImageManager(cfg.Cache.Directory(), cfg.Cache.MaxSizeBytes()),
 		imagePreview:        nil,
 		isImagePreviewing:   false,
 		imageSpinner:        spinner.New(),
@@ -433,6 +528,12 @@ func NewFileBrowserModel(client *r2.Client, cfg *config.Config, bucketName, pref
 		currentPreviewForce: false,
 		lastPreviewFile:     nil,
 		lastPreviewForce:    false,
+
+		// Text preview state
+		textLoader: text.NewLoader(int64(cfg.UI.TextPreviewKB) * 1024),
+
+		// Archive preview state
+		archiveReader: utils.NewArchiveReader(client.Storage(), bucketName),
 	}
 
 	// Configure text input
@@ -451,13 +552,19 @@ func NewFileBrowserModel(client *r2.Client, cfg *config.Config, bucketName, pref
 	m.imageSpinner.Spinner = spinner.Dot
 	m.imageSpinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow))
 
-	// Configure image manager with S3 client
-	if s3Client, ok := client.GetS3Client().(*s3.Client); ok {
-		m.imageManager.SetDownloaderClient(s3Client)
-		m.imageManager.SetBucketName(bucketName)
-	}
+	// Configure image manager with the storage client
+	// The policy is validated with the config; unknown names fall back to LRU
+	cleanupPolicy, _ := image.ParseCleanupPolicy(cfg.Cache.CleanupPolicy)
+	m.imageManager.SetCleanupPolicy(cleanupPolicy, cfg.Cache.MaxAge())
+	m.imageManager.SetDownloaderClient(client.Storage())
+	m.imageManager.SetBucketName(bucketName)
 	// 在 TUI 中启用安全的文本模式渲染，避免控制序列破坏 UI
 	m.imageManager.SetUseTextRender(true)
+	// Gallery thumbnails share the image manager's cache and downloader
+	m.thumbnailLoader = m.imageManager.NewThumbnailLoader(image.DefaultThumbnailConcurrency)
+	// Configure text preview loader with the storage client
+	m.textLoader.SetClient(client.Storage())
+	m.textLoader.SetBucketName(bucketName)
 	// Set current directory to user's home directory
 	if homeDir, err := os.UserHomeDir(); err == nil {
 		m.filePicker.CurrentDirectory = homeDir
@@ -499,6 +606,15 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// If text preview is showing, route keys to it first
+		if m.textPreview != nil {
+			_, cmd := m.textPreview.Update(msg)
+			return m, cmd
+		}
+		if m.archivePreview != nil {
+			_, cmd := m.archivePreview.Update(msg)
+			return m, cmd
+		}
 		// If preview modal is showing, route keys to it first
 		if m.showingPreview && m.previewModal != nil {
 			// Let modal handle closure keys
@@ -508,6 +624,10 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, cmd
 		}
+		if m.gallery != nil {
+			_, cmd := m.gallery.Update(msg)
+			return m, cmd
+		}
 		// If bucket selector is showing, handle its keys
 		if m.showingBucketSelector && m.bucketSelector != nil {
 			// Check for ESC key to close bucket selector
@@ -526,6 +646,17 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleDeleteConfirmation(msg)
 		}
 
+		if m.showDetails && !m.showInput {
+			return m.handleDetailsKey(msg)
+		}
+
+		// Esc/q stops a running search scan before quitting
+		if m.searchScanning && !m.showInput && key.Matches(msg, m.keyMap.Quit) && msg.String() != "ctrl+c" {
+			m.cancelSearch()
+			m.setMessage(fmt.Sprintf("Search stopped: %d matches in %d objects", len(m.files), m.searchScanned), messaging.MessageInfo)
+			return m, nil
+		}
+
 		// Handle input popup
 		if m.showInput {
 			return m.handleInputPopup(msg)
@@ -541,6 +672,9 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.continuationToken = msg.nextToken
 		m.error = msg.err
 		m.clearInlinePreview()
+		if m.cursor >= len(m.files) {
+			m.cursor = max(0, len(m.files)-1)
+		}
 
 		// Update estimated total pages
 		if msg.hasNext {
@@ -555,6 +689,23 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if m.error == nil {
 			m.updateTable()
+			m.fileTable.SetCursor(m.cursor)
+		}
+		return m, nil
+
+	case searchPageMsg:
+		return m, m.handleSearchPage(msg)
+
+	case batchDownloadCompletedMsg:
+		m.downloading = false
+		m.downloadingFile = ""
+		m.downloadCancel = nil
+		if msg.err != nil {
+			m.setMessage(fmt.Sprintf("Downloaded %d of %d files: %v", msg.downloaded, len(m.selected), msg.err), messaging.MessageError)
+		} else {
+			m.setMessage(fmt.Sprintf("Downloaded %d files to %s", msg.downloaded, msg.dir), messaging.MessageSuccess)
+			m.clearSelection()
+			m.updateTable()
 		}
 		return m, nil
 
@@ -562,6 +713,17 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.confirmDelete = false
 		m.deleting = false
 		m.deletingFile = ""
+		if len(m.batchDeleteKeys) > 0 {
+			// Keep the keys that failed selected so the delete can be retried,
+			// and reload since the rest of the batch may be gone
+			m.batchDeleteKeys = nil
+			m.retainSelection(msg.failed)
+			if msg.err != nil {
+				m.setMessage(theme.FormatErrorMessage("Delete", msg.err), messaging.MessageError)
+				m.loading = true
+				return m, m.loadFiles()
+			}
+		}
 		if msg.err != nil {
 			m.setMessage(theme.FormatErrorMessage("Delete", msg.err), messaging.MessageError)
 		} else {
@@ -572,6 +734,20 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case objectDetailsMsg:
+		return m.handleObjectDetails(msg)
+
+	case renameCompletedMsg:
+		m.renaming = false
+		m.renameSource = ""
+		if msg.err != nil {
+			m.setMessage(theme.FormatErrorMessage("Rename", msg.err), messaging.MessageError)
+			return m, nil
+		}
+		m.setMessage(fmt.Sprintf("Renamed %s to %s", msg.from, msg.to), messaging.MessageSuccess)
+		m.loading = true
+		return m, m.loadFiles()
+
 	case previewURLGeneratedMsg:
 		if msg.err != nil {
 			m.infoMessage = fmt.Sprintf("Failed to generate preview URL: %v", msg.err)
@@ -610,9 +786,13 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle bucket switch from bucket selector
 		m.bucketName = msg.bucket
 
-		// Update URLGenerator and FileDownloader with new bucket
+		// Update URLGenerator, FileDownloader and previews with new bucket
 		m.urlGenerator.SetBucketName(msg.bucket)
 		m.fileDownloader.SetBucketName(msg.bucket)
+		m.textLoader.SetBucketName(msg.bucket)
+		m.archiveReader.SetBucketName(msg.bucket)
+		m.cursorMemory = make(map[string]int)
+		m.clearSelection()
 
 		m.showingBucketSelector = false
 		m.bucketSelector = nil
@@ -625,9 +805,13 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Switch to the main bucket and reload files
 			m.bucketName = msg.bucket
 
-			// Update URLGenerator and FileDownloader with new bucket
+			// Update URLGenerator, FileDownloader and previews with new bucket
 			m.urlGenerator.SetBucketName(msg.bucket)
 			m.fileDownloader.SetBucketName(msg.bucket)
+			m.textLoader.SetBucketName(msg.bucket)
+			m.archiveReader.SetBucketName(msg.bucket)
+			m.cursorMemory = make(map[string]int)
+			m.clearSelection()
 
 			m.showingBucketSelector = false
 			m.bucketSelector = nil
@@ -665,10 +849,27 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.previewModal.width = msg.Width
 			m.previewModal.height = msg.Height
 		}
+		if m.textPreview != nil {
+			m.textPreview.SetSize(msg.Width, msg.Height)
+		}
+		if m.archivePreview != nil {
+			m.archivePreview.SetSize(msg.Width, msg.Height)
+		}
+		if m.gallery != nil {
+			m.gallery.SetSize(msg.Width, msg.Height)
+		}
 		return m, nil
 
 	case spinner.TickMsg:
 		// Route spinner ticks to preview modal first if open
+		if m.textPreview != nil {
+			_, cmd := m.textPreview.Update(msg)
+			return m, cmd
+		}
+		if m.archivePreview != nil {
+			_, cmd := m.archivePreview.Update(msg)
+			return m, cmd
+		}
 		if m.showingPreview && m.previewModal != nil {
 			newModal, cmd := m.previewModal.Update(msg)
 			if im, ok := newModal.(*ImagePreviewModel); ok {
@@ -676,6 +877,10 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, cmd
 		}
+		if m.gallery != nil {
+			_, cmd := m.gallery.Update(msg)
+			return m, cmd
+		}
 		var cmds []tea.Cmd
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -739,7 +944,54 @@ func (m *FileBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.resetUploadState()
 		return m, nil
 
+	case textChunkLoadedMsg:
+		if m.textPreview != nil {
+			_, cmd := m.textPreview.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case previewReadyMsg:
+		if m.showingPreview && m.previewModal != nil {
+			_, cmd := m.previewModal.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case archiveListedMsg, archiveExtractedMsg:
+		if m.archivePreview != nil {
+			_, cmd := m.archivePreview.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case thumbnailLoadedMsg:
+		if m.gallery != nil {
+			_, cmd := m.gallery.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case galleryOpenMsg:
+		m.selectFileByKey(msg.key)
+		return m.startPreviewModal(false)
+
+	case galleryClosedMsg:
+		m.gallery = nil
+		m.selectFileByKey(msg.key)
+		m.clearInlinePreview()
+		m.updateRightPanel()
+		return m, nil
+
 	case modalClosedMsg:
+		if m.textPreview != nil {
+			m.textPreview.Close()
+			m.textPreview = nil
+		}
+		if m.archivePreview != nil {
+			m.archivePreview.Close()
+			m.archivePreview = nil
+		}
 		m.showingPreview = false
 		m.previewModal = nil
 		m.imageManager.SetUseTextRender(true)
@@ -802,8 +1054,20 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			m.infoMessage = "Download cancelled"
 			return m, nil
 		}
+		if len(m.selected) > 0 && msg.String() != "ctrl+c" {
+			m.clearSelection()
+			m.updateTable()
+			m.setMessage("Selection cleared", messaging.MessageInfo)
+			return m, nil
+		}
 		return m, tea.Quit
 
+	case key.Matches(msg, m.keyMap.Mark), key.Matches(msg, m.keyMap.SelectAll), key.Matches(msg, m.keyMap.Invert):
+		if m.downloading || m.deleting {
+			return m, nil
+		}
+		return m.handleSelectionKey(msg)
+
 	case key.Matches(msg, m.keyMap.Up):
 		if m.downloading || m.deleting {
 			return m, nil // Block navigation during download/delete
@@ -882,8 +1146,11 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		if m.downloading || m.deleting {
 			return m, nil // Block new download during current download/delete
 		}
-		if len(m.files) > 0 && m.cursor < len(m.files) {
-			file := m.files[m.cursor]
+		if len(m.selected) > 0 {
+			m.showDownloadDirInput()
+			return m, nil
+		}
+		if file, ok := m.selectedFile(); ok {
 			return m, m.downloadFileWithProgress(file.Key)
 		}
 
@@ -891,8 +1158,7 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		if m.downloading || m.deleting {
 			return m, nil
 		}
-		if len(m.files) > 0 && m.cursor < len(m.files) {
-			file := m.files[m.cursor]
+		if file, ok := m.selectedFile(); ok {
 			return m, m.generatePreviewURL(file.Key)
 		}
 
@@ -903,9 +1169,9 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		m.showInput = true
 		m.inputMode = InputModeSearch
 		m.inputComponentMode = InputComponentText
-		m.inputPrompt = "Search objects:"
+		m.inputPrompt = "Search objects below the current folder:"
 		m.textInput.SetValue("")
-		m.textInput.Placeholder = "Enter search query..."
+		m.textInput.Placeholder = "logo, *.png, /re/, size>1MB, type:image"
 		m.textInput.Focus()
 		return m, nil
 
@@ -941,10 +1207,21 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		if m.downloading || m.deleting {
 			return m, nil
 		}
-		if len(m.files) > 0 && m.cursor < len(m.files) {
+		if len(m.selected) > 0 {
+			m.startBatchDelete()
+		} else if file, ok := m.selectedFile(); ok {
 			m.confirmDelete = true
-			m.deleteTarget = m.files[m.cursor].Key
+			m.deleteTarget = file.Key
+		}
+
+	case key.Matches(msg, m.keyMap.Details):
+		return m, m.openDetails()
+
+	case key.Matches(msg, m.keyMap.Rename):
+		if m.downloading || m.deleting || m.renaming {
+			return m, nil
 		}
+		m.showRenameInput()
 
 	case key.Matches(msg, m.keyMap.ChangeBucket):
 		if m.downloading || m.deleting {
@@ -979,12 +1256,38 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			return m, m.loadFromPage(m.currentPage)
 		}
 
+	case key.Matches(msg, m.keyMap.Open):
+		if m.downloading || m.deleting || m.paginationLoading {
+			return m, nil
+		}
+		if len(m.files) > 0 && m.cursor < len(m.files) && m.files[m.cursor].IsDir {
+			return m, m.enterDirectory(m.files[m.cursor].Key)
+		}
+
+	case key.Matches(msg, m.keyMap.Back):
+		if m.downloading || m.deleting || m.paginationLoading {
+			return m, nil
+		}
+		if m.flatMode || m.prefix == "" {
+			return m, nil
+		}
+		return m, m.enterDirectory(parentPrefix(m.prefix))
+
+	case key.Matches(msg, m.keyMap.ToggleFlat):
+		if m.downloading || m.deleting || m.paginationLoading {
+			return m, nil
+		}
+		return m, m.toggleFlatMode()
+
 	case key.Matches(msg, m.keyMap.ToggleImage):
 		return m.startPreviewModal(false)
 
 	case key.Matches(msg, m.keyMap.ForcePreview):
 		return m.startPreviewModal(true)
 
+	case key.Matches(msg, m.keyMap.Gallery):
+		return m.startGallery()
+
 	case key.Matches(msg, m.keyMap.Refresh):
 		if m.downloading || m.deleting {
 			return m, nil
@@ -998,8 +1301,9 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		return m, m.loadFiles()
 
 	case key.Matches(msg, m.keyMap.CopyCustom):
-		if len(m.files) > 0 && m.fileTable.Cursor() < len(m.files) {
-			file := m.files[m.fileTable.Cursor()]
+		if len(m.selected) > 0 {
+			m.copySelectedURLs(false)
+		} else if file, ok := m.selectedFile(); ok {
 			customURL := m.urlGenerator.GenerateCustomDomainURL(file.Key)
 			if customURL != "" {
 				utils.CopyToClipboard(customURL)
@@ -1010,15 +1314,8 @@ func (m *FileBrowserModel) handleNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		}
 
 	case key.Matches(msg, m.keyMap.CopyPresign):
-		if len(m.files) > 0 && m.fileTable.Cursor() < len(m.files) {
-			file := m.files[m.fileTable.Cursor()]
-			_, presignedURL, err := m.urlGenerator.GenerateFileURL(file.Key)
-			if err != nil {
-				m.setMessage(fmt.Sprintf("Failed to generate presigned URL: %s", err), messaging.MessageError)
-			} else {
-				utils.CopyToClipboard(presignedURL)
-				m.setMessage("Presigned URL copied to clipboard", messaging.MessageInfo)
-			}
+		if _, ok := m.selectedFile(); ok || len(m.selected) > 0 {
+			m.showPresignExpiryInput()
 		}
 
 	case key.Matches(msg, m.keyMap.Help):
@@ -1042,8 +1339,21 @@ func (m *FileBrowserModel) startPreviewModal(force bool) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	file := m.files[m.cursor]
-	if !m.imageManager.IsImageFile(file.ContentType) {
-		m.setMessage("Not an image file", messaging.MessageInfo)
+	if file.IsDir {
+		return m, nil
+	}
+	if !m.imageManager.IsPreviewable(file.ContentType) {
+		if text.IsTextFile(file.Key, file.ContentType) {
+			return m.startTextPreview(file)
+		}
+		if _, err := utils.DetectArchiveFormat(file.Key); err == nil {
+			return m.startArchivePreview(file)
+		}
+		if file.Category == "archive" {
+			m.setMessage("Only .zip, .tar and .tar.gz archives can be previewed", messaging.MessageInfo)
+			return m, nil
+		}
+		m.setMessage("No preview available for this file type", messaging.MessageInfo)
 		return m, nil
 	}
 
@@ -1070,6 +1380,58 @@ func (m *FileBrowserModel) startPreviewModal(force bool) (tea.Model, tea.Cmd) {
 	return m, m.previewModal.Init()
 }
 
+// startGallery opens the thumbnail grid for the images on the current page
+func (m *FileBrowserModel) startGallery() (tea.Model, tea.Cmd) {
+	if m.downloading || m.deleting {
+		return m, nil
+	}
+
+	var images []FileItem
+	cursor := 0
+	for i, file := range m.files {
+		if file.IsDir || !m.imageManager.IsImageFile(file.ContentType) {
+			continue
+		}
+		if i == m.cursor {
+			cursor = len(images)
+		}
+		images = append(images, file)
+	}
+	if len(images) == 0 {
+		m.setMessage("No images on this page to show in the gallery", messaging.MessageInfo)
+		return m, nil
+	}
+
+	m.clearInlinePreview()
+	m.gallery = NewGalleryModel(m.thumbnailLoader, images, cursor, m.windowWidth, m.windowHeight)
+	return m, m.gallery.Init()
+}
+
+// selectFileByKey moves the cursor to the file with the given key
+func (m *FileBrowserModel) selectFileByKey(key string) {
+	for i, file := range m.files {
+		if file.Key == key {
+			m.cursor = i
+			m.fileTable.SetCursor(i)
+			return
+		}
+	}
+}
+
+// startTextPreview opens the text preview modal for a text or code file
+func (m *FileBrowserModel) startTextPreview(file FileItem) (tea.Model, tea.Cmd) {
+	logrus.WithField("file", file.Key).Info("opening text preview")
+	m.textPreview = NewTextPreviewModel(m.textLoader, file, m.windowWidth, m.windowHeight)
+	return m, m.textPreview.Init()
+}
+
+// startArchivePreview opens the archive listing modal for a zip or tar file
+func (m *FileBrowserModel) startArchivePreview(file FileItem) (tea.Model, tea.Cmd) {
+	logrus.WithField("file", file.Key).Info("opening archive preview")
+	m.archivePreview = NewArchivePreviewModel(m.archiveReader, file, m.windowWidth, m.windowHeight)
+	return m, m.archivePreview.Init()
+}
+
 func (m *FileBrowserModel) clearInlinePreview() {
 	m.isImagePreviewing = false
 	m.imagePreview = nil
@@ -1085,11 +1447,15 @@ func (m *FileBrowserModel) handleDeleteConfirmation(msg tea.KeyMsg) (tea.Model,
 		m.deleting = true
 		m.deletingFile = filepath.Base(m.deleteTarget)
 		m.setMessage(theme.FormatProgressMessage("Deleting", m.deletingFile, -1), messaging.MessageWarning)
+		if len(m.batchDeleteKeys) > 0 {
+			return m, m.deleteFiles(m.batchDeleteKeys)
+		}
 		return m, m.deleteFile(m.deleteTarget)
 
 	case key.Matches(msg, m.keyMap.Cancel) || key.Matches(msg, m.keyMap.Quit):
 		m.confirmDelete = false
 		m.deleteTarget = ""
+		m.batchDeleteKeys = nil
 	}
 
 	return m, nil
@@ -1146,6 +1512,18 @@ func (m *FileBrowserModel) renderCustomHelp() string {
 	lines = append(lines, format("pgdn", "page down"))
 	lines = append(lines, format("home/g", "go to start"))
 	lines = append(lines, format("end/G", "go to end"))
+	lines = append(lines, format("enter", "open folder"))
+	lines = append(lines, format("backspace", "parent folder"))
+	lines = append(lines, format("f", "toggle flat view"))
+	lines = append(lines, "")
+
+	// Section: Selection
+	lines = append(lines, formatSection("Selection"))
+	lines = append(lines, format("space", "mark/unmark file"))
+	lines = append(lines, format("a", "mark all on page"))
+	lines = append(lines, format("i", "invert marks on page"))
+	lines = append(lines, format("esc", "clear marks"))
+	lines = append(lines, format("d/x/ctrl+o/ctrl+y", "act on marked files"))
 	lines = append(lines, "")
 
 	// Section 2: Pagination
@@ -1158,9 +1536,12 @@ func (m *FileBrowserModel) renderCustomHelp() string {
 	lines = append(lines, formatSection("File Actions"))
 	lines = append(lines, format("d", "download"))
 	lines = append(lines, format("v", "preview URL"))
-	lines = append(lines, format("p", "preview image"))
+	lines = append(lines, format("p", "preview image, PDF, video, text or archive"))
 	lines = append(lines, format("P", "force preview"))
+	lines = append(lines, format("t", "image gallery"))
 	lines = append(lines, format("x", "delete"))
+	lines = append(lines, format("R", "rename"))
+	lines = append(lines, format("m", "object details & metadata"))
 	lines = append(lines, format("y", "confirm delete"))
 	lines = append(lines, format("N", "cancel delete"))
 	lines = append(lines, "")
@@ -1176,7 +1557,7 @@ func (m *FileBrowserModel) renderCustomHelp() string {
 	// Section 5: Sharing
 	lines = append(lines, formatSection("Sharing"))
 	lines = append(lines, format("ctrl+o", "copy custom URL"))
-	lines = append(lines, format("ctrl+y", "copy presigned URL"))
+	lines = append(lines, format("ctrl+y", "copy presigned URL (pick expiry)"))
 	lines = append(lines, "")
 
 	// Section 6: Misc
@@ -1197,12 +1578,15 @@ func (m *FileBrowserModel) View() string {
 	// Render header with consistent styling and left alignment with panel
 	headerStyle := theme.CreateHeaderStyle()
 
-	header := fmt.Sprintf("R2 File Browser - %s", m.bucketName)
-	if m.prefix != "" {
-		header += fmt.Sprintf("/%s", m.prefix)
+	header := fmt.Sprintf("R2 File Browser [%s] - %s", m.config.ActiveProfileName(), m.breadcrumb())
+	if m.flatMode {
+		header += " [flat]"
 	}
 	if m.isSearchMode && m.searchQuery != "" {
 		header += fmt.Sprintf(" [Search: '%s'] (l: clear)", m.searchQuery)
+		if m.searchScanning {
+			header += " (esc: stop)"
+		}
 	}
 	headerLine := headerStyle.Render(header)
 
@@ -1248,6 +1632,10 @@ func (m *FileBrowserModel) View() string {
 		m.help.ShowAll, len(helpLine), helpLine)
 
 	// Add status message if present
+	if len(m.selected) > 0 {
+		helpLine = lipgloss.JoinVertical(lipgloss.Left, helpLine, m.renderSelectionStatus())
+	}
+
 	var footerContent string
 	if m.messageManager.HasMessage() {
 		messageLine := m.messageManager.RenderMessage()
@@ -1281,6 +1669,18 @@ func (m *FileBrowserModel) View() string {
 		return m.previewModal.View()
 	}
 
+	if m.textPreview != nil {
+		return m.textPreview.View()
+	}
+
+	if m.archivePreview != nil {
+		return m.archivePreview.View()
+	}
+
+	if m.gallery != nil {
+		return m.gallery.View()
+	}
+
 	if m.showingBucketSelector && m.bucketSelector != nil {
 		return m.renderFloatingDialog(baseView, m.bucketSelector.View())
 	}
@@ -1289,6 +1689,10 @@ func (m *FileBrowserModel) View() string {
 		return m.renderFloatingDialog(baseView, m.renderInputPopup())
 	}
 
+	if m.showDetails {
+		return m.renderFloatingDialog(baseView, m.renderDetailsDialog())
+	}
+
 	return baseView
 }
 
@@ -1305,7 +1709,7 @@ func (m *FileBrowserModel) renderLeftPanel(width int) string {
 			Width(panelWidth - tuiconfig.DefaultViewportPadding).
 			Height(panelHeight - 4).
 			AlignVertical(lipgloss.Center).
-			Render("No files found")
+			Render(m.emptyListText())
 
 		return theme.CreateUnifiedPanelStyle(panelWidth, panelHeight).Render(emptyContent)
 	}
@@ -1321,6 +1725,14 @@ func (m *FileBrowserModel) renderLeftPanel(width int) string {
 		countStyle := theme.CreateSecondaryTextStyle().MarginTop(tuiconfig.DefaultMarginSize)
 
 		countInfo := fmt.Sprintf("Total: %d files", len(m.files))
+		if folders := m.folderCount(); folders > 0 {
+			countInfo = fmt.Sprintf("Total: %d folders, %d files", folders, len(m.files)-folders)
+		}
+		if m.isSearchMode {
+			countInfo = m.searchCounter()
+			tableView += "\n" + countStyle.Render(countInfo)
+			return theme.CreateUnifiedPanelStyle(panelWidth, panelHeight).Render(tableView)
+		}
 
 		// Add pagination info
 		var pageInfo string
@@ -1363,7 +1775,15 @@ func (m *FileBrowserModel) renderRightPanel(width int) string {
 	content.WriteString("\n")
 
 	// Show file info if a file is selected
-	if len(m.files) > 0 && m.cursor < len(m.files) {
+	if len(m.files) > 0 && m.cursor < len(m.files) && m.files[m.cursor].IsDir {
+		folder := m.files[m.cursor]
+		infoStyle := theme.CreateInfoTextStyle()
+
+		content.WriteString(infoStyle.Render(fmt.Sprintf("📁 Folder: %s", folder.Key)))
+		content.WriteString("\n\n")
+		content.WriteString(theme.CreateHintStyle().Render("💡 Press Enter to open, Backspace to go up"))
+		content.WriteString("\n")
+	} else if len(m.files) > 0 && m.cursor < len(m.files) {
 		file := m.files[m.cursor]
 
 		// Basic file information section
@@ -1534,6 +1954,10 @@ func (m *FileBrowserModel) renderDownloadProgress() string {
 func (m *FileBrowserModel) renderDeleteConfirmation() string {
 	dialogStyle := theme.CreateDialogStyle(tuiconfig.DialogDefaultWidth, theme.ColorBrightRed)
 
+	if len(m.batchDeleteKeys) > 0 {
+		return dialogStyle.Render(m.renderBatchDeleteConfirmation())
+	}
+
 	content := fmt.Sprintf("Delete file: %s\n\nThis action cannot be undone!\n\nPress 'y' to confirm, 'n' to cancel",
 		m.deleteTarget)
 
@@ -1599,6 +2023,14 @@ func (m *FileBrowserModel) renderInputPopup() string {
 		}
 	case InputModeUploadTarget:
 		title = titleStyle.Render("🎯 Set Target Path")
+	case InputModeDownloadDir:
+		title = titleStyle.Render("📥 Download Marked Files")
+	case InputModeRename:
+		title = titleStyle.Render("✏️ Rename File")
+	case InputModeEditMetadata:
+		title = titleStyle.Render("📋 Edit Metadata")
+	case InputModePresignExpiry:
+		title = titleStyle.Render("⏱️ Presigned URL Expiry")
 	default:
 		title = titleStyle.Render("Input")
 	}
@@ -1675,7 +2107,8 @@ type filesLoadedMsg struct {
 }
 
 type deleteCompletedMsg struct {
-	err error
+	err    error
+	failed []string // keys of a batch delete that were not deleted
 }
 
 type previewURLGeneratedMsg struct {
@@ -1709,6 +2142,7 @@ func (m *FileBrowserModel) previewImageCmd(file FileItem, force bool) tea.Cmd {
 			LastModified: file.LastModified,
 			ContentType:  file.ContentType,
 			Category:     file.Category,
+			ETag:         file.ETag,
 		}
 		var (
 			preview *image.ImagePreview
@@ -1725,6 +2159,9 @@ func (m *FileBrowserModel) previewImageCmd(file FileItem, force bool) tea.Cmd {
 
 // loadFiles loads files from R2
 func (m *FileBrowserModel) loadFiles() tea.Cmd {
+	if m.isSearchMode {
+		return m.startSearch()
+	}
 	return func() tea.Msg {
 		files, hasNext, nextToken, err := m.fetchFiles(m.continuationToken)
 		return filesLoadedMsg{files: files, hasNext: hasNext, nextToken: nextToken, err: err}
@@ -1762,65 +2199,63 @@ func (m *FileBrowserModel) loadFromPage(targetPage int) tea.Cmd {
 
 // fetchFiles fetches files from R2 bucket
 func (m *FileBrowserModel) fetchFiles(continuationToken string) ([]FileItem, bool, string, error) {
-	return m.fetchFilesWithQuery(continuationToken, m.searchQuery)
-}
-
-func (m *FileBrowserModel) fetchFilesWithQuery(continuationToken, searchQuery string) ([]FileItem, bool, string, error) {
-	s3Client := m.client.GetS3Client().(*s3.Client)
+	storage := m.client.Storage()
 
 	// Use configured page size
 	pageSize := int32(m.config.UI.PageSize)
 
 	// List objects
-	input := &s3.ListObjectsV2Input{
-		Bucket:  aws.String(m.bucketName),
-		MaxKeys: aws.Int32(pageSize),
+	prefix := m.prefix
+	options := r2.ListOptions{
+		Prefix:            prefix,
+		MaxKeys:           pageSize,
+		ContinuationToken: continuationToken,
 	}
 
-	// Combine original prefix with search query
-	var prefix string
-	if m.prefix != "" && searchQuery != "" {
-		// Combine original prefix with search query
-		prefix = m.prefix + searchQuery
-	} else if m.prefix != "" {
-		prefix = m.prefix
-	} else if searchQuery != "" {
-		prefix = searchQuery
+	// Group keys into folders at the next "/" unless flat mode is on
+	if !m.flatMode {
+		options.Delimiter = "/"
 	}
 
-	if prefix != "" {
-		input.Prefix = aws.String(prefix)
+	page, err := storage.List(context.TODO(), m.bucketName, options)
+	if err != nil {
+		return nil, false, "", err
 	}
 
-	if continuationToken != "" {
-		input.ContinuationToken = aws.String(continuationToken)
+	files := make([]FileItem, 0, len(page.Prefixes)+len(page.Objects))
+	for _, commonPrefix := range page.Prefixes {
+		files = append(files, FileItem{
+			Key:      commonPrefix,
+			Category: "folder",
+			IsDir:    true,
+		})
 	}
 
-	result, err := s3Client.ListObjectsV2(context.TODO(), input)
-	if err != nil {
-		return nil, false, "", err
-	}
+	for _, obj := range page.Objects {
+		// Skip the "folder/" marker object of the current directory
+		if !m.flatMode && obj.Key == prefix && strings.HasSuffix(prefix, "/") {
+			continue
+		}
 
-	files := make([]FileItem, 0, len(result.Contents))
-	for _, obj := range result.Contents {
-		contentType, err := utils.DetectContentType(aws.ToString(obj.Key), nil)
+		contentType, err := utils.DetectContentType(obj.Key, nil)
 		if err != nil {
-			logrus.Warnf("Failed to detect content type for %s: %v", aws.ToString(obj.Key), err)
+			logrus.Warnf("Failed to detect content type for %s: %v", obj.Key, err)
 			contentType = "application/octet-stream"
 		}
 
 		files = append(files, FileItem{
-			Key:          aws.ToString(obj.Key),
-			Size:         aws.ToInt64(obj.Size),
-			LastModified: aws.ToTime(obj.LastModified),
+			Key:          obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
 			ContentType:  contentType,
 			Category:     utils.GetFileCategory(contentType),
+			ETag:         obj.ETag,
 		})
 	}
 
 	// Check if there are more results
-	hasNext := aws.ToBool(result.IsTruncated)
-	nextToken := aws.ToString(result.NextContinuationToken)
+	hasNext := page.NextToken != ""
+	nextToken := page.NextToken
 
 	return files, hasNext, nextToken, nil
 }
@@ -1828,11 +2263,8 @@ func (m *FileBrowserModel) fetchFilesWithQuery(continuationToken, searchQuery st
 // deleteFile deletes a file from R2
 func (m *FileBrowserModel) deleteFile(key string) tea.Cmd {
 	return func() tea.Msg {
-		s3Client := m.client.GetS3Client().(*s3.Client)
-		_, err := s3Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-			Bucket: aws.String(m.bucketName),
-			Key:    aws.String(key),
-		})
+		storage := m.client.Storage()
+		err := storage.Delete(context.TODO(), m.bucketName, key)
 		return deleteCompletedMsg{err: err}
 	}
 }
@@ -1892,6 +2324,8 @@ func (m *FileBrowserModel) getFormattedCategory(category string) string {
 		return "Data"
 	case "font":
 		return "Font"
+	case "folder":
+		return "Folder"
 	default:
 		return "Other"
 	}
@@ -1922,6 +2356,8 @@ func (m *FileBrowserModel) getFormattedCategoryShort(category string) string {
 		return "DATA"
 	case "font":
 		return "FONT"
+	case "folder":
+		return "DIR"
 	default:
 		return "OTH"
 	}
@@ -2017,7 +2453,10 @@ func (m *FileBrowserModel) updateTable() {
 			maxNameLength = 3
 		}
 
-		name := file.Key
+		name := m.displayName(file)
+		if m.isSelected(file.Key) {
+			name = "✔ " + name
+		}
 		if len(name) > maxNameLength {
 			if maxNameLength <= 3 {
 				// For very small widths, just show first few characters
@@ -2039,6 +2478,14 @@ func (m *FileBrowserModel) updateTable() {
 		// Column 0: NAME
 		row[0] = coloredName
 
+		if file.IsDir {
+			row[1] = "-"
+			row[2] = m.getFormattedCategoryShort(file.Category)
+			row[3] = ""
+			rows[i] = row
+			continue
+		}
+
 		// Column 1: SIZE
 		if columns[1].Width >= 10 {
 			row[1] = formatFileSize(file.Size)
@@ -2152,13 +2599,15 @@ func (m *FileBrowserModel) generatePreviewURL(key string) tea.Cmd {
 
 // downloadFileWithProgress downloads a file with progress updates
 func (m *FileBrowserModel) downloadFileWithProgress(key string) tea.Cmd {
-	return func() tea.Msg {
-		// Create context for cancellation
-		ctx, cancel := context.WithCancel(context.Background())
-		m.downloadCancel = cancel
+	// Create the context here, in Update, so the command does not write to the model
+	ctx, cancel := context.WithCancel(context.Background())
+	m.downloadCancel = cancel
 
+	return func() tea.Msg {
 		// Start download with direct message sending
 		go func() {
+			defer cancel()
+
 			logrus.Info("downloadFileWithProgress: starting download with direct messaging")
 
 			// Start download with progress callback
@@ -2266,6 +2715,14 @@ func (m *FileBrowserModel) handleInputPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 				return m.processUploadFileSelection()
 			case InputModeUploadTarget:
 				return m.processUploadTargetInput()
+			case InputModeDownloadDir:
+				return m.processDownloadDirInput()
+			case InputModeRename:
+				return m.processRenameInput()
+			case InputModeEditMetadata:
+				return m.processMetadataInput()
+			case InputModePresignExpiry:
+				return m.processPresignExpiryInput()
 			}
 		}
 		m.showInput = false
@@ -2314,21 +2771,23 @@ func (m *FileBrowserModel) processSearchInput() (tea.Model, tea.Cmd) {
 		return m, m.loadFiles()
 	}
 
-	m.searchQuery = strings.TrimSpace(m.textInput.Value())
+	query := strings.TrimSpace(m.textInput.Value())
+	spec, err := parseSearchQuery(query)
+	if err != nil {
+		m.setMessage(theme.FormatErrorMessage("Search", err), messaging.MessageError)
+		return m, nil
+	}
+
+	m.searchQuery = query
+	m.searchSpec = spec
 	m.isSearchMode = true
 	m.textInput.SetValue("")
 	m.textInput.Blur()
+	m.clearInlinePreview()
 
-	// Reset pagination for new search
-	m.currentPage = 1
-	m.continuationToken = ""
-	m.estimatedTotalPages = 1
-	m.cursor = 0
-
-	// Start loading with search query
-	m.loading = true
+	// Scan the listing below the current prefix in the background
 	m.setMessage(fmt.Sprintf("Searching for '%s'...", m.searchQuery), messaging.MessageInfo)
-	return m, m.loadFiles()
+	return m, m.startSearch()
 }
 
 // processUploadFileSelection processes file selection for upload
@@ -2387,10 +2846,7 @@ func (m *FileBrowserModel) showTargetPathInput() (tea.Model, tea.Cmd) {
 	m.inputPrompt = fmt.Sprintf("Target path for '%s':", filepath.Base(m.uploadFilePath))
 
 	// Set default value to root directory (empty for root, or current prefix)
-	defaultPath := ""
-	if m.prefix != "" {
-		defaultPath = m.prefix + "/"
-	}
+	defaultPath := prefixDir(m.prefix)
 
 	m.textInput.SetValue(defaultPath)
 	m.textInput.Placeholder = "Enter target path... (end with '/' for folder, otherwise rename)"
@@ -2468,11 +2924,7 @@ func (m *FileBrowserModel) uploadFileWithTarget(filePath, remotePath string) tea
 		}
 
 		// Create upload options from config
-		options := &utils.UploadOptions{
-			Overwrite:    m.config.Upload.DefaultOverwrite,
-			PublicAccess: m.config.Upload.DefaultPublic,
-			ContentType:  "", // Auto-detect
-		}
+		options := utils.UploadOptionsFromConfig(&m.config.Upload)
 
 		// Create progress callback
 		progressCallback := func(uploaded, total int64, percentage float64) {
@@ -2529,8 +2981,10 @@ func (m *FileBrowserModel) processUploadWithPath(filePath string) (tea.Model, te
 
 // clearSearch clears search mode and reloads files without search
 func (m *FileBrowserModel) clearSearch() {
+	m.cancelSearch()
 	m.isSearchMode = false
 	m.searchQuery = ""
+	m.searchSpec = nil
 	m.currentPage = 1
 	m.continuationToken = ""
 	m.estimatedTotalPages = 1
@@ -2559,17 +3013,10 @@ func (m *FileBrowserModel) uploadFile(filePath string) tea.Cmd {
 		}
 
 		// Determine remote path (use filename)
-		remotePath := filepath.Base(filePath)
-		if m.prefix != "" {
-			remotePath = m.prefix + "/" + remotePath
-		}
+		remotePath := prefixDir(m.prefix) + filepath.Base(filePath)
 
 		// Create upload options from config
-		options := &utils.UploadOptions{
-			Overwrite:    m.config.Upload.DefaultOverwrite,
-			PublicAccess: m.config.Upload.DefaultPublic,
-			ContentType:  "", // Auto-detect
-		}
+		options := utils.UploadOptionsFromConfig(&m.config.Upload)
 
 		// Create progress callback
 		progressCallback := func(uploaded, total int64, percentage float64) {
@@ -5,6 +5,9 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/text"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
 )
 
 // Test 'p' opens modal preview safely
@@ -60,3 +63,26 @@ func TestFileBrowser_ForceImagePreview_KeyBinding(t *testing.T) {
 		assert.True(t, fb.previewModal.forceReload)
 	}
 }
+
+// Results of a closed text or archive preview must not reach the next one
+func TestPreviewModals_DropStaleResults(t *testing.T) {
+	textPreview := NewTextPreviewModel(text.NewLoader(0), FileItem{Key: "b.txt"}, 80, 24)
+	textPreview.Update(textChunkLoadedMsg{key: "a.txt", doc: text.NewDocument("a.txt")})
+	assert.True(t, textPreview.loading)
+	assert.Nil(t, textPreview.doc)
+
+	textPreview.Update(textChunkLoadedMsg{key: "b.txt", doc: text.NewDocument("b.txt")})
+	assert.False(t, textPreview.loading)
+	assert.Equal(t, "b.txt", textPreview.doc.Key)
+
+	archivePreview := NewArchivePreviewModel(nil, FileItem{Key: "b.zip"}, 80, 24)
+	archivePreview.Update(archiveListedMsg{key: "a.zip", entries: []utils.ArchiveEntry{{Name: "a.txt"}}})
+	assert.True(t, archivePreview.loading)
+	assert.Empty(t, archivePreview.entries)
+
+	// Closing cancels loads that are still running
+	textPreview.Close()
+	assert.Error(t, textPreview.ctx.Err())
+	archivePreview.Close()
+	assert.Error(t, archivePreview.ctx.Err())
+}
@@ -0,0 +1,370 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	img "github.com/HaiFongPan/r2s3-cli/internal/tui/image"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+)
+
+// GalleryModel is a fullscreen grid of image thumbnails
+type GalleryModel struct {
+	width  int
+	height int
+	items  []FileItem
+	loader *img.ThumbnailLoader
+
+	// graphics is true when thumbnails are drawn with Kitty/iTerm2/Sixel
+	// instead of ANSI half blocks
+	graphics bool
+
+	cursor int
+	offset int // First visible grid row
+
+	thumbs  map[string]*img.Thumbnail
+	errs    map[string]error
+	pending map[string]bool
+
+	// ctx is cancelled when the gallery closes so queued downloads give up
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	spin spinner.Model
+}
+
+// thumbnailLoadedMsg carries one rendered thumbnail
+type thumbnailLoadedMsg struct {
+	key   string
+	thumb *img.Thumbnail
+	err   error
+}
+
+type (
+	// galleryOpenMsg asks the file browser to preview an image from the gallery
+	galleryOpenMsg struct{ key string }
+	// galleryClosedMsg closes the gallery, leaving the browser on key
+	galleryClosedMsg struct{ key string }
+)
+
+const (
+	galleryThumbCols = 22
+	galleryThumbRows = 8
+	// Each cell is the thumbnail, its name and a blank line, with one column
+	// of padding on both sides
+	galleryCellWidth  = galleryThumbCols + 2
+	galleryCellHeight = galleryThumbRows + 2
+	// Header (name, status, hint, separator)
+	galleryChrome = 4
+)
+
+func NewGalleryModel(loader *img.ThumbnailLoader, items []FileItem, cursor, width, height int) *GalleryModel {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &GalleryModel{
+		width:    width,
+		height:   height,
+		items:    items,
+		loader:   loader,
+		graphics: loader.Graphics(),
+		cursor:   max(0, min(cursor, len(items)-1)),
+		thumbs:   make(map[string]*img.Thumbnail),
+		errs:     make(map[string]error),
+		pending:  make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	m.spin = spinner.New()
+	m.spin.Spinner = spinner.Line
+	m.spin.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow))
+	m.clampOffset()
+	return m
+}
+
+func (m *GalleryModel) Init() tea.Cmd {
+	return tea.Batch(m.prefetch(), m.spin.Tick)
+}
+
+// SetSize resizes the gallery
+func (m *GalleryModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clampOffset()
+}
+
+// columns is the number of thumbnails per grid row
+func (m *GalleryModel) columns() int {
+	return max(1, m.width/galleryCellWidth)
+}
+
+// visibleRows is the number of grid rows that fit on screen
+func (m *GalleryModel) visibleRows() int {
+	return max(1, (m.height-galleryChrome)/galleryCellHeight)
+}
+
+// clampOffset keeps the cursor's row inside the visible window
+func (m *GalleryModel) clampOffset() {
+	cols, rows := m.columns(), m.visibleRows()
+	row := m.cursor / cols
+	if row < m.offset {
+		m.offset = row
+	}
+	if row >= m.offset+rows {
+		m.offset = row - rows + 1
+	}
+	totalRows := (len(m.items) + cols - 1) / cols
+	m.offset = max(0, min(m.offset, totalRows-rows))
+}
+
+func (m *GalleryModel) moveCursor(delta int) {
+	if len(m.items) == 0 {
+		return
+	}
+	m.cursor = max(0, min(len(m.items)-1, m.cursor+delta))
+	m.clampOffset()
+}
+
+// prefetch loads the visible thumbnails and the row below them. Each load
+// waits for a download slot in the loader, so only a few run at once.
+func (m *GalleryModel) prefetch() tea.Cmd {
+	cols := m.columns()
+	start := m.offset * cols
+	end := min(len(m.items), (m.offset+m.visibleRows()+1)*cols)
+
+	var cmds []tea.Cmd
+	for i := start; i < end; i++ {
+		file := m.items[i]
+		if m.thumbs[file.Key] != nil || m.errs[file.Key] != nil || m.pending[file.Key] {
+			continue
+		}
+		m.pending[file.Key] = true
+		cmds = append(cmds, m.load(file))
+	}
+	return tea.Batch(cmds...)
+}
+
+// load renders one thumbnail in the background
+func (m *GalleryModel) load(file FileItem) tea.Cmd {
+	loader, parent := m.loader, m.ctx
+	item := img.FileItem{
+		Key:          file.Key,
+		Size:         file.Size,
+		LastModified: file.LastModified,
+		ContentType:  file.ContentType,
+		Category:     file.Category,
+		ETag:         file.ETag,
+	}
+	return func() tea.Msg {
+		// The loader times out each download once it has a slot, so queued
+		// thumbnails only wait on the gallery context
+		thumb, err := loader.Load(parent, item, galleryThumbCols, galleryThumbRows)
+		return thumbnailLoadedMsg{key: item.Key, thumb: thumb, err: err}
+	}
+}
+
+// close cancels pending loads and removes drawn images from the terminal
+func (m *GalleryModel) close() {
+	m.cancel()
+	if m.graphics {
+		_ = m.loader.Clear()
+	}
+}
+
+func (m *GalleryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case thumbnailLoadedMsg:
+		delete(m.pending, msg.key)
+		if msg.err != nil {
+			if !errors.Is(msg.err, context.Canceled) {
+				m.errs[msg.key] = msg.err
+			}
+			return m, nil
+		}
+		m.thumbs[msg.key] = msg.thumb
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "t":
+			m.close()
+			key := m.selectedKey()
+			return m, func() tea.Msg { return galleryClosedMsg{key: key} }
+		case "enter", "p":
+			if len(m.items) == 0 {
+				return m, nil
+			}
+			if m.graphics {
+				_ = m.loader.Clear()
+			}
+			key := m.selectedKey()
+			return m, func() tea.Msg { return galleryOpenMsg{key: key} }
+		case "left", "h":
+			m.moveCursor(-1)
+		case "right", "l":
+			m.moveCursor(1)
+		case "up", "k":
+			m.moveCursor(-m.columns())
+		case "down", "j":
+			m.moveCursor(m.columns())
+		case "pgup":
+			m.moveCursor(-m.columns() * m.visibleRows())
+		case "pgdown":
+			m.moveCursor(m.columns() * m.visibleRows())
+		case "g", "home":
+			m.moveCursor(-len(m.items))
+		case "G", "end":
+			m.moveCursor(len(m.items))
+		default:
+			return m, nil
+		}
+		idle := len(m.pending) == 0
+		cmd := m.prefetch()
+		if idle && len(m.pending) > 0 {
+			return m, tea.Batch(cmd, m.spin.Tick)
+		}
+		return m, cmd
+
+	case spinner.TickMsg:
+		if len(m.pending) > 0 {
+			var cmd tea.Cmd
+			m.spin, cmd = m.spin.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// selectedKey returns the key of the image under the cursor
+func (m *GalleryModel) selectedKey() string {
+	if len(m.items) == 0 {
+		return ""
+	}
+	return m.items[m.cursor].Key
+}
+
+func (m *GalleryModel) View() string {
+	nameLine := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Bold(true).
+		Foreground(lipgloss.Color(theme.ColorBrightCyan)).
+		Render(fmt.Sprintf("🖼 Gallery  •  %d images", len(m.items)))
+
+	var statusText string
+	if len(m.items) > 0 {
+		file := m.items[m.cursor]
+		statusText = fmt.Sprintf("%s  •  %s  •  %d/%d", filepath.Base(file.Key), formatFileSize(file.Size), m.cursor+1, len(m.items))
+		if len(m.pending) > 0 {
+			statusText = fmt.Sprintf("%s Loading %d thumbnails…  •  %s", m.spin.View(), len(m.pending), statusText)
+		}
+	}
+	statusLine := lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(statusText)
+
+	hint := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Foreground(lipgloss.Color(theme.ColorBrightBlack)).
+		Render("←/→/↑/↓ pgup/pgdn g/G move • enter/p preview • q/esc/t close")
+
+	separator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.ColorBrightBlue)).
+		Render(strings.Repeat("─", max(1, m.width)))
+
+	var b strings.Builder
+	b.WriteString(nameLine)
+	b.WriteString("\n")
+	b.WriteString(statusLine)
+	b.WriteString("\n")
+	b.WriteString(hint)
+	b.WriteString("\n")
+	b.WriteString(separator)
+	b.WriteString("\n")
+
+	// Graphics thumbnails are drawn after the text grid at absolute
+	// positions over the blank space left for them
+	var images strings.Builder
+	cols := m.columns()
+	start := m.offset * cols
+	end := min(len(m.items), (m.offset+m.visibleRows())*cols)
+	for rowStart := start; rowStart < end; rowStart += cols {
+		cells := make([][]string, 0, cols)
+		for i := rowStart; i < min(end, rowStart+cols); i++ {
+			cells = append(cells, m.renderCell(i))
+			if thumb := m.thumbs[m.items[i].Key]; thumb != nil && m.graphics {
+				gridRow := (rowStart - start) / cols
+				row := galleryChrome + 1 + gridRow*galleryCellHeight + (galleryThumbRows-thumb.Rows)/2
+				col := 1 + (i-rowStart)*galleryCellWidth + 1 + (galleryThumbCols-thumb.Cols)/2
+				fmt.Fprintf(&images, "\x1b[%d;%dH%s\x1b[0m", row, col, thumb.Data)
+			}
+		}
+		for line := 0; line < galleryCellHeight; line++ {
+			for _, cell := range cells {
+				b.WriteString(cell[line])
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if m.graphics {
+		b.WriteString(m.loader.ClearSequence())
+		b.WriteString(images.String())
+	}
+	return b.String()
+}
+
+// renderCell renders the lines of one grid cell, each galleryCellWidth wide
+func (m *GalleryModel) renderCell(i int) []string {
+	file := m.items[i]
+	lines := make([]string, 0, galleryCellHeight)
+
+	body := make([]string, galleryThumbRows)
+	thumb := m.thumbs[file.Key]
+	switch {
+	case thumb != nil && !m.graphics:
+		top := (galleryThumbRows - thumb.Rows) / 2
+		for j, line := range strings.Split(thumb.Data, "\n") {
+			if top+j < len(body) {
+				body[top+j] = line
+			}
+		}
+	case thumb != nil:
+		// Left blank for the image drawn on top
+	case m.errs[file.Key] != nil:
+		body[galleryThumbRows/2] = theme.CreateErrorStyle().Render("⚠ no preview")
+	default:
+		body[galleryThumbRows/2] = theme.CreateHintStyle().Render("loading…")
+	}
+	for _, line := range body {
+		lines = append(lines, " "+centerCell(line, galleryThumbCols)+" ")
+	}
+
+	name := ansi.Truncate(filepath.Base(file.Key), galleryThumbCols, "…")
+	name = centerCell(name, galleryThumbCols)
+	if i == m.cursor {
+		name = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(theme.ColorBrightCyan)).
+			Bold(true).
+			Reverse(true).
+			Render(name)
+	}
+	lines = append(lines, " "+name+" ")
+	lines = append(lines, strings.Repeat(" ", galleryCellWidth))
+	return lines
+}
+
+// centerCell pads s with spaces to exactly width columns
+func centerCell(s string, width int) string {
+	w := ansi.StringWidth(s)
+	if w >= width {
+		return s
+	}
+	left := (width - w) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-w-left)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,18 +12,25 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-// CacheManager 管理 /tmp 目录下的图片缓存
+// CacheManager 管理本地的预览缓存，目录和容量由 [cache] 配置决定
 type CacheManager struct {
 	cacheDir      string
 	maxSize       int64
 	cleanupPolicy CleanupPolicy
+	maxAge        time.Duration // CleanupPolicyAge 下条目的最长保留时间
 	index         map[string]*CacheEntry
 	mutex         sync.RWMutex
 
+	// 命中统计随索引一起保存，供 cache stats 计算命中率
+	hits        int64
+	misses      int64
+	lastCleanup time.Time
+
 	// 自动清理相关
 	cleanupTicker *time.Ticker
 	stopCleanup   chan struct{}
@@ -57,17 +65,76 @@ type CacheIndex struct {
 	Entries     map[string]*CacheEntry
 	TotalSize   int64
 	LastCleanup time.Time
+	Hits        int64
+	Misses      int64
 }
 
 // CleanupPolicy 清理策略
 type CleanupPolicy int
 
 const (
-	CleanupPolicyLRU CleanupPolicy = iota
-	CleanupPolicySize
-	CleanupPolicyAge
+	CleanupPolicyLRU  CleanupPolicy = iota // 超出容量时删除最久未访问的条目
+	CleanupPolicySize                      // 超出容量时优先删除最大的条目
+	CleanupPolicyAge                       // 删除超过最长保留时间的条目，仍超出容量时按 LRU 删除
 )
 
+// DefaultCacheMaxAge CleanupPolicyAge 的默认保留时间
+const DefaultCacheMaxAge = 24 * time.Hour
+
+// cacheIndexFile 缓存目录中的索引文件名
+const cacheIndexFile = ".cache_index.json"
+
+// ParseCleanupPolicy 解析配置中的清理策略名称：lru、size 或 age，空字符串表示 lru
+func ParseCleanupPolicy(name string) (CleanupPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "lru":
+		return CleanupPolicyLRU, nil
+	case "size":
+		return CleanupPolicySize, nil
+	case "age":
+		return CleanupPolicyAge, nil
+	}
+	return CleanupPolicyLRU, fmt.Errorf("invalid cleanup policy %q: must be lru, size or age", name)
+}
+
+// String 返回清理策略在配置中的名称
+func (p CleanupPolicy) String() string {
+	switch p {
+	case CleanupPolicySize:
+		return "size"
+	case CleanupPolicyAge:
+		return "age"
+	}
+	return "lru"
+}
+
+// objectCacheKey 生成对象某个版本的缓存键。键中包含存储桶和 ETag，
+// 对象被覆盖后 ETag 变化，旧的预览不再命中
+func objectCacheKey(bucket, fileKey, etag string) string {
+	return fmt.Sprintf("%s/%s@%s", bucket, fileKey, strings.Trim(etag, `"`))
+}
+
+// cacheFileName 返回缓存文件名：键的 MD5 加源文件扩展名
+func cacheFileName(key, sourcePath string) string {
+	hash := md5.Sum([]byte(key))
+	return hex.EncodeToString(hash[:]) + filepath.Ext(sourcePath)
+}
+
+// isCacheFileName 判断文件名是否由 cacheFileName 生成。缓存目录可以配置到任意位置，
+// 清理孤立文件时只删除这类文件，目录中的其他文件保持不动
+func isCacheFileName(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if len(base) != 2*md5.Size {
+		return false
+	}
+	for _, char := range base {
+		if !(char >= '0' && char <= '9') && !(char >= 'a' && char <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // NewCacheManager 创建新的缓存管理器
 func NewCacheManager(cacheDir string, maxSize int64) *CacheManager {
 	// 确保缓存目录存在
@@ -77,6 +144,7 @@ func NewCacheManager(cacheDir string, maxSize int64) *CacheManager {
 		cacheDir:      cacheDir,
 		maxSize:       maxSize,
 		cleanupPolicy: CleanupPolicyLRU,
+		maxAge:        DefaultCacheMaxAge,
 		index:         make(map[string]*CacheEntry),
 	}
 
@@ -91,11 +159,13 @@ func NewCacheManager(cacheDir string, maxSize int64) *CacheManager {
 
 // Get 从缓存中获取文件
 func (c *CacheManager) Get(key string) (string, bool, error) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	// 会更新访问时间并清理失效条目，需要写锁
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
 	entry, exists := c.index[key]
 	if !exists {
+		c.misses++
 		return "", false, nil
 	}
 
@@ -103,11 +173,13 @@ func (c *CacheManager) Get(key string) (string, bool, error) {
 	if _, err := os.Stat(entry.FilePath); os.IsNotExist(err) {
 		// 文件已被删除，从索引中移除
 		delete(c.index, key)
+		c.misses++
 		return "", false, nil
 	}
 
 	// 更新访问时间
 	entry.AccessTime = time.Now()
+	c.hits++
 	return entry.FilePath, true, nil
 }
 
@@ -116,11 +188,7 @@ func (c *CacheManager) Put(key, sourcePath string) (string, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// 生成缓存文件名
-	hash := md5.Sum([]byte(key))
-	ext := filepath.Ext(sourcePath)
-	filename := fmt.Sprintf("%x%s", hash, ext)
-	cachePath := filepath.Join(c.cacheDir, filename)
+	cachePath := filepath.Join(c.cacheDir, cacheFileName(key, sourcePath))
 
 	// 复制文件到缓存目录
 	sourceFile, err := os.Open(sourcePath)
@@ -168,8 +236,8 @@ func (c *CacheManager) Put(key, sourcePath string) (string, error) {
 
 	c.index[key] = entry
 
-	// 保存缓存索引
-	if err := c.saveIndex(); err != nil {
+	// 超出容量时按清理策略回收空间，刚放入的条目保留；清理过程会保存索引
+	if err := c.cleanup(key); err != nil {
 		// 记录错误但不影响主流程
 		// TODO: 添加日志记录
 	}
@@ -210,27 +278,32 @@ func (c *CacheManager) Cleanup() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	return c.cleanup("")
+}
+
+// cleanup 根据清理策略执行清理，keep 指定的条目不会被删除；调用方需持有写锁
+func (c *CacheManager) cleanup(keep string) error {
+	if c.cleanupPolicy == CleanupPolicyAge {
+		c.cleanupByAge(keep)
+	}
+
 	// 检查是否需要清理
 	currentSize := c.calculateTotalSize()
-	if currentSize <= c.maxSize {
-		return nil
+	if currentSize > c.maxSize {
+		switch c.cleanupPolicy {
+		case CleanupPolicySize:
+			c.cleanupBySize(currentSize, keep)
+		default:
+			c.cleanupByLRU(currentSize, keep)
+		}
+		c.lastCleanup = time.Now()
 	}
 
-	// 根据清理策略执行清理
-	switch c.cleanupPolicy {
-	case CleanupPolicyLRU:
-		return c.cleanupByLRU(currentSize)
-	case CleanupPolicySize:
-		return c.cleanupBySize(currentSize)
-	case CleanupPolicyAge:
-		return c.cleanupByAge()
-	default:
-		return c.cleanupByLRU(currentSize)
-	}
+	return c.saveIndex()
 }
 
 // cleanupByLRU 根据 LRU 策略清理缓存
-func (c *CacheManager) cleanupByLRU(currentSize int64) error {
+func (c *CacheManager) cleanupByLRU(currentSize int64, keep string) {
 	// 创建按访问时间排序的条目列表
 	entries := make([]*CacheEntry, 0, len(c.index))
 	for _, entry := range c.index {
@@ -242,27 +315,11 @@ func (c *CacheManager) cleanupByLRU(currentSize int64) error {
 		return entries[i].AccessTime.Before(entries[j].AccessTime)
 	})
 
-	// 删除最久未访问的条目，直到大小满足要求
-	sizeToRemove := currentSize - c.maxSize
-	var removedSize int64
-
-	for _, entry := range entries {
-		if removedSize >= sizeToRemove {
-			break
-		}
-
-		// 删除文件
-		if err := os.Remove(entry.FilePath); err == nil {
-			removedSize += entry.Size
-			delete(c.index, entry.Key)
-		}
-	}
-
-	return c.saveIndex()
+	c.evict(entries, currentSize-c.maxSize, keep)
 }
 
 // cleanupBySize 根据文件大小清理缓存
-func (c *CacheManager) cleanupBySize(currentSize int64) error {
+func (c *CacheManager) cleanupBySize(currentSize int64, keep string) {
 	// 创建按文件大小排序的条目列表 (大文件优先删除)
 	entries := make([]*CacheEntry, 0, len(c.index))
 	for _, entry := range c.index {
@@ -274,38 +331,41 @@ func (c *CacheManager) cleanupBySize(currentSize int64) error {
 		return entries[i].Size > entries[j].Size
 	})
 
-	// 删除大文件，直到大小满足要求
-	sizeToRemove := currentSize - c.maxSize
+	c.evict(entries, currentSize-c.maxSize, keep)
+}
+
+// evict 按顺序删除条目，直到释放 sizeToRemove 字节
+func (c *CacheManager) evict(entries []*CacheEntry, sizeToRemove int64, keep string) {
 	var removedSize int64
 
 	for _, entry := range entries {
 		if removedSize >= sizeToRemove {
 			break
 		}
+		if entry.Key == keep {
+			continue
+		}
 
 		// 删除文件
-		if err := os.Remove(entry.FilePath); err == nil {
+		if err := os.Remove(entry.FilePath); err == nil || os.IsNotExist(err) {
 			removedSize += entry.Size
 			delete(c.index, entry.Key)
 		}
 	}
-
-	return c.saveIndex()
 }
 
-// cleanupByAge 根据文件年龄清理缓存
-func (c *CacheManager) cleanupByAge() error {
-	cutoffTime := time.Now().Add(-24 * time.Hour) // 删除超过24小时的文件
+// cleanupByAge 删除超过最长保留时间的条目
+func (c *CacheManager) cleanupByAge(keep string) {
+	cutoffTime := time.Now().Add(-c.maxAge)
 
 	for key, entry := range c.index {
-		if entry.CreateTime.Before(cutoffTime) {
-			if err := os.Remove(entry.FilePath); err == nil {
+		if key != keep && entry.CreateTime.Before(cutoffTime) {
+			if err := os.Remove(entry.FilePath); err == nil || os.IsNotExist(err) {
 				delete(c.index, key)
 			}
 		}
 	}
-
-	return c.saveIndex()
+	c.lastCleanup = time.Now()
 }
 
 // calculateTotalSize 计算当前缓存总大小
@@ -352,12 +412,14 @@ func (c *CacheManager) detectContentType(filePath string) (string, error) {
 
 // saveIndex 保存缓存索引到磁盘
 func (c *CacheManager) saveIndex() error {
-	indexPath := filepath.Join(c.cacheDir, ".cache_index.json")
+	indexPath := filepath.Join(c.cacheDir, cacheIndexFile)
 
 	cacheIndex := &CacheIndex{
 		Entries:     c.index,
 		TotalSize:   c.calculateTotalSize(),
-		LastCleanup: time.Now(),
+		LastCleanup: c.lastCleanup,
+		Hits:        c.hits,
+		Misses:      c.misses,
 	}
 
 	data, err := json.MarshalIndent(cacheIndex, "", "  ")
@@ -370,7 +432,7 @@ func (c *CacheManager) saveIndex() error {
 
 // loadIndex 从磁盘加载缓存索引
 func (c *CacheManager) loadIndex() error {
-	indexPath := filepath.Join(c.cacheDir, ".cache_index.json")
+	indexPath := filepath.Join(c.cacheDir, cacheIndexFile)
 
 	data, err := os.ReadFile(indexPath)
 	if err != nil {
@@ -390,6 +452,8 @@ func (c *CacheManager) loadIndex() error {
 	if c.index == nil {
 		c.index = make(map[string]*CacheEntry)
 	}
+	c.hits, c.misses = cacheIndex.Hits, cacheIndex.Misses
+	c.lastCleanup = cacheIndex.LastCleanup
 
 	// 验证缓存文件是否仍然存在
 	c.validateCache()
@@ -449,6 +513,17 @@ func (c *CacheManager) SetMaxSize(size int64) {
 	c.maxSize = size
 }
 
+// SetCleanupPolicy 设置清理策略，maxAge 用于 CleanupPolicyAge，不大于 0 时使用默认值
+func (c *CacheManager) SetCleanupPolicy(policy CleanupPolicy, maxAge time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cleanupPolicy = policy
+	if maxAge <= 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+	c.maxAge = maxAge
+}
+
 // VerifyChecksum 验证缓存文件的完整性
 func (c *CacheManager) VerifyChecksum(key string) (bool, error) {
 	c.mutex.RLock()
@@ -504,7 +579,7 @@ func (c *CacheManager) startAutoCleanup() {
 	}()
 }
 
-// StopAutoCleanup 停止自动清理
+// StopAutoCleanup 停止自动清理，并保存索引以记录本次运行的命中统计
 func (c *CacheManager) StopAutoCleanup() {
 	if c.cleanupCancel != nil {
 		c.cleanupCancel()
@@ -512,6 +587,12 @@ func (c *CacheManager) StopAutoCleanup() {
 	if c.stopCleanup != nil {
 		close(c.stopCleanup)
 	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.hits > 0 || c.misses > 0 {
+		c.saveIndex()
+	}
 }
 
 // cleanupTempFiles 清理过期的临时文件
@@ -519,12 +600,20 @@ func (c *CacheManager) cleanupTempFiles() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// 扫描缓存目录，移除不在索引中的文件
+	c.removeOrphanFiles()
+}
+
+// removeOrphanFiles 扫描缓存目录，移除不在索引中的缓存文件，返回删除的文件数和字节数；
+// 不符合缓存文件命名的文件不会被删除。调用方需持有写锁
+func (c *CacheManager) removeOrphanFiles() (int, int64) {
 	entries, err := os.ReadDir(c.cacheDir)
 	if err != nil {
-		return
+		return 0, 0
 	}
 
+	var removed int
+	var removedSize int64
+
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -532,8 +621,8 @@ func (c *CacheManager) cleanupTempFiles() {
 
 		filePath := filepath.Join(c.cacheDir, entry.Name())
 
-		// 跳过索引文件
-		if entry.Name() == ".cache_index.json" {
+		// 只处理缓存自己生成的文件，跳过索引文件和用户文件
+		if !isCacheFileName(entry.Name()) {
 			continue
 		}
 
@@ -548,9 +637,14 @@ func (c *CacheManager) cleanupTempFiles() {
 
 		// 如果文件不在索引中，删除它
 		if !inIndex {
-			os.Remove(filePath)
+			info, err := entry.Info()
+			if err == nil && os.Remove(filePath) == nil {
+				removed++
+				removedSize += info.Size()
+			}
 		}
 	}
+	return removed, removedSize
 }
 
 // GetCacheMetrics 获取详细的缓存指标
@@ -559,7 +653,6 @@ func (c *CacheManager) GetCacheMetrics() CacheMetrics {
 	defer c.mutex.RUnlock()
 
 	var totalSize int64
-	var hitCount, missCount int64
 	accessTimes := make([]time.Time, 0, len(c.index))
 	createTimes := make([]time.Time, 0, len(c.index))
 
@@ -579,21 +672,33 @@ func (c *CacheManager) GetCacheMetrics() CacheMetrics {
 		avgAccessTime = time.Unix(0, totalNano/int64(len(accessTimes)))
 	}
 
-	return CacheMetrics{
+	metrics := CacheMetrics{
+		CacheDir:          c.cacheDir,
+		CleanupPolicy:     c.cleanupPolicy.String(),
 		TotalFiles:        len(c.index),
 		TotalSize:         totalSize,
 		MaxSize:           c.maxSize,
-		UsagePercent:      float64(totalSize) / float64(c.maxSize) * 100,
-		HitRate:           float64(hitCount) / float64(hitCount+missCount),
-		AverageFileSize:   float64(totalSize) / float64(len(c.index)),
+		Hits:              c.hits,
+		Misses:            c.misses,
 		AverageAccessTime: avgAccessTime,
 		OldestEntry:       c.findOldestEntry(),
 		NewestEntry:       c.findNewestEntry(),
-		LastCleanupTime:   time.Now(), // 这里应该从实际的清理记录中获取
+		LastCleanupTime:   c.lastCleanup,
+	}
+	if c.maxSize > 0 {
+		metrics.UsagePercent = float64(totalSize) / float64(c.maxSize) * 100
+	}
+	if c.hits+c.misses > 0 {
+		metrics.HitRate = float64(c.hits) / float64(c.hits+c.misses) * 100
+	}
+	if len(c.index) > 0 {
+		metrics.AverageFileSize = float64(totalSize) / float64(len(c.index))
 	}
+	return metrics
 }
 
-// CompactCache 压缩缓存索引，移除无效条目
+// CompactCache 压缩缓存：移除文件丢失或校验失败的条目、不在索引中的文件，
+// 再按清理策略把缓存收缩到容量以内
 func (c *CacheManager) CompactCache() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -614,7 +719,29 @@ func (c *CacheManager) CompactCache() error {
 	}
 
 	c.index = validEntries
-	return c.saveIndex()
+	c.removeOrphanFiles()
+	c.lastCleanup = time.Now()
+	return c.cleanup("")
+}
+
+// Clear 删除所有缓存文件并清空索引，返回删除的文件数和字节数
+func (c *CacheManager) Clear() (int, int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	removed := len(c.index)
+	removedSize := c.calculateTotalSize()
+	for key, entry := range c.index {
+		if err := os.Remove(entry.FilePath); err != nil && !os.IsNotExist(err) {
+			return 0, 0, &CacheError{Operation: "clear", Path: entry.FilePath, Err: err}
+		}
+		delete(c.index, key)
+	}
+
+	orphans, orphanSize := c.removeOrphanFiles()
+	c.hits, c.misses = 0, 0
+	c.lastCleanup = time.Now()
+	return removed + orphans, removedSize + orphanSize, c.saveIndex()
 }
 
 // verifyChecksum 验证单个文件的校验和
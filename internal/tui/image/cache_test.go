@@ -370,3 +370,168 @@ func TestCacheManager_GetLRUEntries(t *testing.T) {
 			entries[i-1].AccessTime.Equal(entries[i].AccessTime))
 	}
 }
+
+// putTestFile 在单独的目录中创建源文件并放入缓存
+func putTestFile(t *testing.T, cm *CacheManager, key string, size int) string {
+	t.Helper()
+	source := filepath.Join(t.TempDir(), key+".jpg")
+	require.NoError(t, os.WriteFile(source, make([]byte, size), 0644))
+	cachedPath, err := cm.Put(key, source)
+	require.NoError(t, err)
+	return cachedPath
+}
+
+func TestParseCleanupPolicy(t *testing.T) {
+	for name, want := range map[string]CleanupPolicy{
+		"":     CleanupPolicyLRU,
+		"lru":  CleanupPolicyLRU,
+		"Size": CleanupPolicySize,
+		"age":  CleanupPolicyAge,
+	} {
+		policy, err := ParseCleanupPolicy(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, policy, name)
+	}
+
+	_, err := ParseCleanupPolicy("fifo")
+	assert.Error(t, err)
+	assert.Equal(t, "size", CleanupPolicySize.String())
+}
+
+func TestObjectCacheKey(t *testing.T) {
+	assert.Equal(t, "media/photos/a.png@abc", objectCacheKey("media", "photos/a.png", `"abc"`))
+	assert.NotEqual(t, objectCacheKey("media", "a.png", "v1"), objectCacheKey("media", "a.png", "v2"))
+	assert.NotEqual(t, objectCacheKey("media", "a.png", "v1"), objectCacheKey("backup", "a.png", "v1"))
+}
+
+func TestCacheManager_PutEnforcesMaxSize(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), 100)
+	defer cm.StopAutoCleanup()
+	cm.SetCleanupPolicy(CleanupPolicySize, 0)
+
+	putTestFile(t, cm, "large", 60)
+	putTestFile(t, cm, "small", 30)
+	// 超出容量，按 size 策略删除最大的条目，刚放入的条目保留
+	putTestFile(t, cm, "new", 40)
+
+	assert.LessOrEqual(t, cm.GetSize(), int64(100))
+	_, hit, _ := cm.Get("large")
+	assert.False(t, hit)
+	_, hit, _ = cm.Get("new")
+	assert.True(t, hit)
+
+	// 比容量还大的文件也能放入，直到下次清理
+	putTestFile(t, cm, "huge", 150)
+	_, hit, _ = cm.Get("huge")
+	assert.True(t, hit)
+}
+
+func TestCacheManager_AgePolicy(t *testing.T) {
+	cm := NewCacheManager(t.TempDir(), 10*1024*1024)
+	defer cm.StopAutoCleanup()
+	cm.SetCleanupPolicy(CleanupPolicyAge, time.Hour)
+
+	oldPath := putTestFile(t, cm, "old", 10)
+	putTestFile(t, cm, "fresh", 10)
+	cm.index["old"].CreateTime = time.Now().Add(-2 * time.Hour)
+
+	require.NoError(t, cm.Cleanup())
+	_, hit, _ := cm.Get("old")
+	assert.False(t, hit)
+	_, hit, _ = cm.Get("fresh")
+	assert.True(t, hit)
+	_, err := os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheManager_Clear(t *testing.T) {
+	cacheDir := t.TempDir()
+	cm := NewCacheManager(cacheDir, 10*1024*1024)
+	defer cm.StopAutoCleanup()
+
+	putTestFile(t, cm, "a", 10)
+	putTestFile(t, cm, "b", 20)
+	// 不在索引中的残留缓存文件也会被删除，用户文件保留
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, cacheFileName("stray", "stray.png")), make([]byte, 5), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "notes.txt"), make([]byte, 7), 0644))
+
+	files, size, err := cm.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, 3, files)
+	assert.Equal(t, int64(35), size)
+	assert.Equal(t, int64(0), cm.GetSize())
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{cacheIndexFile, "notes.txt"}, names)
+}
+
+func TestIsCacheFileName(t *testing.T) {
+	assert.True(t, isCacheFileName(cacheFileName("media/cat.png@etag", "/tmp/cat.png")))
+	assert.True(t, isCacheFileName(cacheFileName("key", "noext")))
+	assert.False(t, isCacheFileName(cacheIndexFile))
+	assert.False(t, isCacheFileName("holiday.png"))
+	assert.False(t, isCacheFileName("0123456789abcdef0123456789abcdeg.png"))
+	assert.False(t, isCacheFileName("0123456789ABCDEF0123456789ABCDEF.png"))
+}
+
+func TestCacheManager_CompactRemovesOrphansAndShrinks(t *testing.T) {
+	cacheDir := t.TempDir()
+	cm := NewCacheManager(cacheDir, 10*1024*1024)
+	defer cm.StopAutoCleanup()
+
+	putTestFile(t, cm, "a", 40)
+	putTestFile(t, cm, "b", 40)
+	stray := filepath.Join(cacheDir, cacheFileName("stray", "stray.png"))
+	require.NoError(t, os.WriteFile(stray, []byte("x"), 0644))
+	userFile := filepath.Join(cacheDir, "holiday.png")
+	require.NoError(t, os.WriteFile(userFile, []byte("x"), 0644))
+
+	// 容量在运行中被调小，压缩时按策略收缩
+	cm.SetMaxSize(50)
+	require.NoError(t, cm.CompactCache())
+
+	_, err := os.Stat(stray)
+	assert.True(t, os.IsNotExist(err))
+	// 缓存目录中不是缓存文件的文件保持不动
+	_, err = os.Stat(userFile)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, cm.GetSize(), int64(50))
+	assert.False(t, cm.GetCacheMetrics().LastCleanupTime.IsZero())
+}
+
+func TestCacheManager_MetricsPersistHitRate(t *testing.T) {
+	cacheDir := t.TempDir()
+	cm := NewCacheManager(cacheDir, 1000)
+	putTestFile(t, cm, "a", 100)
+
+	// 空缓存的指标不应出现 NaN
+	empty := NewCacheManager(t.TempDir(), 1000)
+	defer empty.StopAutoCleanup()
+	metrics := empty.GetCacheMetrics()
+	assert.Zero(t, metrics.HitRate)
+	assert.Zero(t, metrics.AverageFileSize)
+
+	cm.Get("a")
+	cm.Get("a")
+	cm.Get("a")
+	cm.Get("missing")
+	cm.StopAutoCleanup()
+
+	// 重新打开同一目录，命中统计随索引保存
+	reopened := NewCacheManager(cacheDir, 1000)
+	defer reopened.StopAutoCleanup()
+	metrics = reopened.GetCacheMetrics()
+	assert.Equal(t, cacheDir, metrics.CacheDir)
+	assert.Equal(t, "lru", metrics.CleanupPolicy)
+	assert.Equal(t, 1, metrics.TotalFiles)
+	assert.Equal(t, int64(3), metrics.Hits)
+	assert.Equal(t, int64(1), metrics.Misses)
+	assert.InDelta(t, 75.0, metrics.HitRate, 0.01)
+	assert.InDelta(t, 10.0, metrics.UsagePercent, 0.01)
+	assert.InDelta(t, 100.0, metrics.AverageFileSize, 0.01)
+}
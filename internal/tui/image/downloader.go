@@ -7,19 +7,24 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 )
 
 // ProgressCallback 下载进度回调函数类型
 type ProgressCallback func(downloaded, total int64)
 
+// ObjectClient 定义下载图片所需的存储客户端接口
+type ObjectClient interface {
+	Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error)
+	Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error)
+}
+
 // ImageDownloader 处理图片文件的下载
 type ImageDownloader struct {
-	s3Client         *s3.Client
+	s3Client         ObjectClient
 	bucketName       string
 	httpClient       *http.Client
 	progressCallback ProgressCallback
@@ -36,7 +41,7 @@ type ImageDownloaderInterface interface {
 	DownloadImage(ctx context.Context, fileKey string) (string, error)
 	DownloadWithProgress(ctx context.Context, fileKey string, callback ProgressCallback) (string, error)
 	Cancel(ctx context.Context) error
-	SetS3Client(client *s3.Client)
+	SetS3Client(client ObjectClient)
 	SetBucketName(bucketName string)
 	GetDownloadState(fileKey string) (*DownloadState, bool)
 	CancelDownload(fileKey string) error
@@ -53,7 +58,7 @@ func NewImageDownloader() *ImageDownloader {
 }
 
 // SetS3Client 设置 S3 客户端
-func (d *ImageDownloader) SetS3Client(client *s3.Client) {
+func (d *ImageDownloader) SetS3Client(client ObjectClient) {
 	d.s3Client = client
 }
 
@@ -97,20 +102,11 @@ func (d *ImageDownloader) DownloadWithProgress(ctx context.Context, fileKey stri
 	downloadCtx, cancel := context.WithTimeout(ctx, d.timeout)
 	defer cancel()
 
-	// 创建临时文件
-	tempDir := os.TempDir()
-	fileName := filepath.Base(fileKey)
-	tempPath := filepath.Join(tempDir, "r2s3-cli-"+fileName)
-
 	// 更新状态为下载中
 	state.Status = DownloadStatusDownloading
-	state.TempPath = tempPath
 
 	// 从 S3 获取对象
-	result, err := d.s3Client.GetObject(downloadCtx, &s3.GetObjectInput{
-		Bucket: &d.bucketName,
-		Key:    &fileKey,
-	})
+	result, err := d.s3Client.Get(downloadCtx, d.bucketName, fileKey, r2.GetOptions{})
 	if err != nil {
 		state.Status = DownloadStatusFailed
 		state.Error = err
@@ -119,21 +115,21 @@ func (d *ImageDownloader) DownloadWithProgress(ctx context.Context, fileKey stri
 	defer result.Body.Close()
 
 	// 更新文件大小信息
-	if result.ContentLength != nil {
-		state.Progress.Total = *result.ContentLength
-	}
+	state.Progress.Total = result.ContentLength
 
-	// 创建本地文件
-	file, err := os.Create(tempPath)
+	// 创建临时文件；文件名随机，避免并发下载同名文件时互相覆盖，保留扩展名用于识别格式
+	file, err := os.CreateTemp("", "r2s3-cli-*-"+filepath.Base(fileKey))
 	if err != nil {
 		state.Status = DownloadStatusFailed
 		state.Error = err
 		return "", err
 	}
 	defer file.Close()
+	tempPath := file.Name()
+	state.TempPath = tempPath
 
 	// 使用进度跟踪复制
-	finalPath, err := d.copyWithProgressAndState(result.Body, file, result.ContentLength, callback, tempPath, state)
+	finalPath, err := d.copyWithProgressAndState(result.Body, file, &result.ContentLength, callback, tempPath, state)
 	if err != nil {
 		state.Status = DownloadStatusFailed
 		state.Error = err
@@ -322,8 +318,8 @@ func (d *ImageDownloader) DownloadWithRetry(ctx context.Context, fileKey string,
 
 		lastErr = err
 
-		// 检查是否为可重试的错误
-		if !d.isRetryableError(err) {
+		// 检查是否为可重试的错误，与 S3 客户端的重试策略使用同一判断
+		if !r2.IsRetryableError(err) {
 			break
 		}
 
@@ -336,35 +332,6 @@ func (d *ImageDownloader) DownloadWithRetry(ctx context.Context, fileKey string,
 	return "", fmt.Errorf("download failed after %d attempts: %w", maxRetries+1, lastErr)
 }
 
-// isRetryableError 判断错误是否可重试
-func (d *ImageDownloader) isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errorStr := strings.ToLower(err.Error())
-
-	// 网络相关的可重试错误
-	retryableErrors := []string{
-		"timeout",
-		"connection reset",
-		"connection refused",
-		"temporary failure",
-		"service unavailable",
-		"internal server error",
-		"bad gateway",
-		"gateway timeout",
-	}
-
-	for _, retryableError := range retryableErrors {
-		if strings.Contains(errorStr, retryableError) {
-			return true
-		}
-	}
-
-	return false
-}
-
 // GetDownloadProgress 获取下载进度信息
 type DownloadProgress struct {
 	FileKey     string
@@ -383,19 +350,12 @@ func (d *ImageDownloader) EstimateFileSize(ctx context.Context, fileKey string)
 		return 0, fmt.Errorf("S3 client not configured")
 	}
 
-	result, err := d.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: &d.bucketName,
-		Key:    &fileKey,
-	})
+	result, err := d.s3Client.Head(ctx, d.bucketName, fileKey)
 	if err != nil {
 		return 0, err
 	}
 
-	if result.ContentLength != nil {
-		return *result.ContentLength, nil
-	}
-
-	return 0, nil
+	return result.Size, nil
 }
 
 // ProgressTrackingReader 带进度跟踪的 Reader
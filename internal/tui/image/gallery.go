@@ -0,0 +1,130 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"time"
+)
+
+// DefaultThumbnailConcurrency 画廊同时下载的原图数量
+const DefaultThumbnailConcurrency = 4
+
+// maxThumbnailSourceSize 超过该大小的图片不为画廊下载
+const maxThumbnailSourceSize = 32 * 1024 * 1024
+
+// thumbnailDownloadTimeout 单张原图的下载超时，从取得下载槽位后开始计时
+const thumbnailDownloadTimeout = 60 * time.Second
+
+// errThumbnailTooLarge 原图过大，不生成缩略图
+var errThumbnailTooLarge = errors.New("image too large for a thumbnail")
+
+// ThumbnailLoader 为画廊模式加载缩略图：从缓存或 R2 取得原图，缩放后按单元格渲染。
+// 与 ImageManager 共享缓存和下载器，并限制同时进行的下载数量
+type ThumbnailLoader struct {
+	cacheManager *CacheManager
+	downloader   *ImageDownloader
+	renderer     *ImageRenderer
+
+	// slots 是下载信号量，容量即最大并发数
+	slots chan struct{}
+}
+
+// NewThumbnailLoader 创建缩略图加载器，concurrency 不大于 0 时使用默认并发数
+func (m *ImageManager) NewThumbnailLoader(concurrency int) *ThumbnailLoader {
+	if concurrency <= 0 {
+		concurrency = DefaultThumbnailConcurrency
+	}
+	return &ThumbnailLoader{
+		cacheManager: m.cacheManager,
+		downloader:   m.downloader,
+		renderer:     NewImageRenderer(),
+		slots:        make(chan struct{}, concurrency),
+	}
+}
+
+// Graphics 报告缩略图是否使用终端图形协议（Kitty/iTerm2/Sixel），否则为 ANSI 半块字符
+func (l *ThumbnailLoader) Graphics() bool {
+	return l.renderer.IsSupported()
+}
+
+// ClearSequence 返回删除已显示缩略图的控制序列
+func (l *ThumbnailLoader) ClearSequence() string {
+	return l.renderer.ClearSequence()
+}
+
+// Clear 清除终端上显示的缩略图
+func (l *ThumbnailLoader) Clear() error {
+	return l.renderer.ClearScreen()
+}
+
+// Load 返回缩放到 cols×rows 单元格以内的缩略图
+func (l *ThumbnailLoader) Load(ctx context.Context, fileInfo FileItem, cols, rows int) (*Thumbnail, error) {
+	localPath, temporary, err := l.fetch(ctx, fileInfo)
+	if err != nil {
+		return nil, err
+	}
+	if temporary {
+		// 写入缓存失败时使用的是下载的临时文件，解码后删除
+		defer os.Remove(localPath)
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, &FormatError{
+			Format:   fileInfo.ContentType,
+			FilePath: fileInfo.Key,
+			Reason:   fmt.Sprintf("cannot decode image: %v", err),
+		}
+	}
+	return l.renderer.RenderThumbnail(img, cols, rows)
+}
+
+// fetch 返回原图的本地路径，缓存未命中时占用一个下载槽位下载并写入缓存。
+// 写入缓存失败时返回下载的临时文件，temporary 为 true，由调用方删除
+func (l *ThumbnailLoader) fetch(ctx context.Context, fileInfo FileItem) (path string, temporary bool, err error) {
+	// 与预览窗口使用同一缓存键，两者共享下载的原图
+	key := objectCacheKey(l.downloader.bucketName, fileInfo.Key, fileInfo.ETag)
+	if cachedPath, hit, err := l.cacheManager.Get(key); hit && err == nil {
+		return cachedPath, false, nil
+	}
+	if fileInfo.Size > maxThumbnailSourceSize {
+		return "", false, errThumbnailTooLarge
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		defer func() { <-l.slots }()
+	case <-ctx.Done():
+		return "", false, ctx.Err()
+	}
+
+	// 等待槽位期间可能已被其他请求下载
+	if cachedPath, hit, err := l.cacheManager.Get(key); hit && err == nil {
+		return cachedPath, false, nil
+	}
+
+	// 排队等待的时间不计入超时
+	ctx, cancel := context.WithTimeout(ctx, thumbnailDownloadTimeout)
+	defer cancel()
+
+	downloadedPath, err := l.downloader.DownloadWithProgress(ctx, fileInfo.Key, nil)
+	if err != nil {
+		return "", false, &NetworkError{Op: "download", Err: err, Code: 0}
+	}
+
+	cachedPath, err := l.cacheManager.Put(key, downloadedPath)
+	if err != nil {
+		return downloadedPath, true, nil
+	}
+	os.Remove(downloadedPath)
+	return cachedPath, false, nil
+}
@@ -0,0 +1,207 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// slowObjectClient 每次 Get 都等待一段时间，并记录同时进行的请求数峰值
+type slowObjectClient struct {
+	body     []byte
+	delay    time.Duration
+	gets     atomic.Int32
+	inflight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (f *slowObjectClient) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	return &r2.ObjectInfo{Key: key, Size: int64(len(f.body))}, nil
+}
+
+func (f *slowObjectClient) Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error) {
+	f.gets.Add(1)
+	current := f.inflight.Add(1)
+	defer f.inflight.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if current <= peak || f.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+	return &r2.Object{
+		ObjectInfo:    r2.ObjectInfo{Key: key, Size: int64(len(f.body))},
+		Body:          io.NopCloser(bytes.NewReader(f.body)),
+		ContentLength: int64(len(f.body)),
+	}, nil
+}
+
+// testPNG 生成一张纯色 PNG
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 80, B: 40, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// newTestThumbnailLoader 创建使用 ANSI 渲染的缩略图加载器
+func newTestThumbnailLoader(t *testing.T, client ObjectClient, concurrency int) *ThumbnailLoader {
+	t.Helper()
+	manager := NewImageManager(t.TempDir(), 10*1024*1024)
+	t.Cleanup(func() { manager.Close() })
+	manager.SetDownloaderClient(client)
+	manager.SetBucketName("media")
+
+	loader := manager.NewThumbnailLoader(concurrency)
+	loader.renderer.SetTextMode(true)
+	return loader
+}
+
+func TestFitCells(t *testing.T) {
+	tests := []struct {
+		width, height int
+		cols, rows    int
+		wantCols      int
+		wantRows      int
+	}{
+		{200, 100, 20, 8, 20, 5}, // 横图受宽度限制
+		{100, 400, 20, 8, 4, 8},  // 竖图受高度限制
+		{16, 16, 20, 8, 16, 8},   // 小图放大到格子
+		{10000, 1, 20, 8, 20, 1}, // 极端比例至少占一行
+	}
+	for _, tt := range tests {
+		cols, rows := fitCells(tt.width, tt.height, tt.cols, tt.rows)
+		assert.Equal(t, tt.wantCols, cols, "%dx%d", tt.width, tt.height)
+		assert.Equal(t, tt.wantRows, rows, "%dx%d", tt.width, tt.height)
+	}
+}
+
+func TestRenderThumbnail_ANSIFitsCells(t *testing.T) {
+	renderer := NewImageRenderer()
+	renderer.SetTextMode(true)
+
+	img, err := png.Decode(bytes.NewReader(testPNG(t, 200, 100)))
+	require.NoError(t, err)
+
+	thumb, err := renderer.RenderThumbnail(img, 20, 8)
+	require.NoError(t, err)
+	assert.Equal(t, 20, thumb.Cols)
+	assert.Equal(t, 5, thumb.Rows)
+
+	lines := strings.Split(thumb.Data, "\n")
+	require.Len(t, lines, 5)
+	for _, line := range lines {
+		assert.Equal(t, 20, ansi.StringWidth(line))
+	}
+}
+
+func TestThumbnailLoader_BoundsConcurrentDownloads(t *testing.T) {
+	client := &slowObjectClient{body: testPNG(t, 32, 32), delay: 20 * time.Millisecond}
+	loader := newTestThumbnailLoader(t, client, 2)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			item := FileItem{Key: fmt.Sprintf("photos/%d.png", i), ContentType: "image/png", Size: int64(len(client.body))}
+			_, errs[i] = loader.Load(context.Background(), item, 10, 5)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(8), client.gets.Load())
+	assert.LessOrEqual(t, client.peak.Load(), int32(2))
+}
+
+func TestThumbnailLoader_UsesCache(t *testing.T) {
+	client := &fakeObjectClient{objects: map[string][]byte{"photos/cat.png": testPNG(t, 64, 32)}}
+	loader := newTestThumbnailLoader(t, client, 0)
+	item := FileItem{Key: "photos/cat.png", ContentType: "image/png"}
+
+	first, err := loader.Load(context.Background(), item, 10, 5)
+	require.NoError(t, err)
+	second, err := loader.Load(context.Background(), item, 10, 5)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, client.gets, "second thumbnail should come from the cache")
+	assert.Equal(t, first.Data, second.Data)
+}
+
+func TestThumbnailLoader_RemovesDownloadWhenCacheFails(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	client := &fakeObjectClient{objects: map[string][]byte{"photos/cat.png": testPNG(t, 64, 32)}}
+	loader := newTestThumbnailLoader(t, client, 0)
+
+	// 用普通文件替换缓存目录，使写入缓存失败
+	cacheDir := loader.cacheManager.cacheDir
+	require.NoError(t, os.RemoveAll(cacheDir))
+	require.NoError(t, os.WriteFile(cacheDir, nil, 0644))
+
+	thumb, err := loader.Load(context.Background(), FileItem{Key: "photos/cat.png", ContentType: "image/png"}, 10, 5)
+	require.NoError(t, err)
+	assert.NotEmpty(t, thumb.Data)
+
+	// 下载的临时文件在解码后被删除
+	leftovers, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestThumbnailLoader_SkipsLargeImages(t *testing.T) {
+	client := &fakeObjectClient{objects: map[string][]byte{}}
+	loader := newTestThumbnailLoader(t, client, 0)
+
+	item := FileItem{Key: "photos/huge.png", ContentType: "image/png", Size: maxThumbnailSourceSize + 1}
+	_, err := loader.Load(context.Background(), item, 10, 5)
+	assert.ErrorIs(t, err, errThumbnailTooLarge)
+	assert.Equal(t, 0, client.gets)
+}
+
+func TestThumbnailLoader_WaitingLoadHonoursContext(t *testing.T) {
+	client := &slowObjectClient{body: testPNG(t, 8, 8), delay: 200 * time.Millisecond}
+	loader := newTestThumbnailLoader(t, client, 1)
+
+	// 第一个请求占住唯一的下载槽位
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loader.Load(context.Background(), FileItem{Key: "a.png", ContentType: "image/png"}, 10, 5)
+	}()
+	defer func() { <-done }()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := loader.Load(ctx, FileItem{Key: "b.png", ContentType: "image/png"}, 10, 5)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -13,7 +13,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,6 +21,7 @@ type ImageManager struct {
 	cacheManager   *CacheManager
 	renderer       ImageRendererInterface
 	downloader     *ImageDownloader
+	thumbnailer    *thumbnailer
 	currentPreview *ImagePreview
 
 	// 配置和状态
@@ -51,6 +51,7 @@ type ImageManagerInterface interface {
 	PreviewImageAtForce(ctx context.Context, fileKey string, fileInfo FileItem, startCol, startRow int) (*ImagePreview, error)
 	ClearPreview() error
 	IsImageFile(contentType string) bool
+	IsPreviewable(contentType string) bool
 	GetSupportedFormats() []ImageFormat
 	GetCurrentPreview() *ImagePreview
 	GetStats() ImageManagerStats
@@ -65,6 +66,7 @@ type FileItem struct {
 	LastModified time.Time
 	ContentType  string
 	Category     string
+	ETag         string
 }
 
 // NewImageManager 创建新的图片管理器实例
@@ -73,6 +75,7 @@ func NewImageManager(cacheDir string, maxCacheSize int64) *ImageManager {
 		cacheManager:     NewCacheManager(cacheDir, maxCacheSize),
 		renderer:         NewImageRenderer(),
 		downloader:       NewImageDownloader(),
+		thumbnailer:      newThumbnailer(),
 		maxCacheSize:     maxCacheSize,
 		cleanupInterval:  time.Hour,
 		supportedFormats: []ImageFormat{FormatJPEG, FormatPNG, FormatGIF, FormatWebP, FormatBMP, FormatSVG},
@@ -142,7 +145,10 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 	// 增加预览计数
 	m.previewCount++
 
-	// 检查格式
+	// 检查格式，PDF 和视频走缩略图流程
+	if kind := DetectMediaKind(fileInfo.ContentType); kind == MediaPDF || kind == MediaVideo {
+		return m.generateMediaPreview(ctx, fileKey, fileInfo, kind, force, startTime)
+	}
 	if !m.IsImageFile(fileInfo.ContentType) {
 		return nil, &FormatError{
 			Format:   fileInfo.ContentType,
@@ -157,8 +163,10 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 		cacheHit  bool
 	)
 
+	// 缓存键包含存储桶和 ETag，对象被覆盖后自动重新下载
+	cacheKey := objectCacheKey(m.downloader.bucketName, fileKey, fileInfo.ETag)
 	if !force {
-		if cachedPath, hit, getErr := m.cacheManager.Get(fileKey); hit && getErr == nil {
+		if cachedPath, hit, getErr := m.cacheManager.Get(cacheKey); hit && getErr == nil {
 			cacheHit = true
 			m.cacheHitCount++
 			localPath = cachedPath
@@ -170,7 +178,7 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 	if localPath == "" {
 		m.cacheMissCount++
 		if force {
-			if err := m.cacheManager.Delete(fileKey); err != nil {
+			if err := m.cacheManager.Delete(cacheKey); err != nil {
 				logrus.WithError(err).WithField("file_key", fileKey).Warn("failed to invalidate cache before forced preview")
 			}
 		}
@@ -180,7 +188,7 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 			return nil, &NetworkError{Op: "download", Err: err, Code: 0}
 		}
 
-		cachedPath, err := m.cacheManager.Put(fileKey, downloadedPath)
+		cachedPath, err := m.cacheManager.Put(cacheKey, downloadedPath)
 		if err != nil {
 			localPath = downloadedPath
 		} else {
@@ -189,6 +197,27 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 		cacheHit = false
 	}
 
+	preview, err := m.renderLocalImage(fileKey, localPath)
+	if err != nil {
+		return nil, err
+	}
+	preview.Kind = MediaImage
+	preview.CacheHit = cacheHit
+	preview.LoadTime = time.Since(startTime)
+	m.currentPreview = preview
+
+	logrus.WithFields(logrus.Fields{
+		"file_key":  fileKey,
+		"cache_hit": cacheHit,
+		"force":     force,
+		"load_ms":   preview.LoadTime.Milliseconds(),
+	}).Info("image preview generated")
+
+	return preview, nil
+}
+
+// renderLocalImage 渲染本地图片文件，用于图片本身和 PDF/视频的缩略图
+func (m *ImageManager) renderLocalImage(fileKey, localPath string) (*ImagePreview, error) {
 	format, originalSize, err := m.getImageInfo(localPath)
 	if err != nil {
 		return nil, err
@@ -214,7 +243,7 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 	displaySize := m.calculateDisplaySize(originalSize)
 	renderCols, renderRows := m.estimateCellUsage(displaySize)
 
-	preview := &ImagePreview{
+	return &ImagePreview{
 		FileKey:      fileKey,
 		FilePath:     localPath,
 		OriginalSize: originalSize,
@@ -223,21 +252,8 @@ func (m *ImageManager) generatePreview(ctx context.Context, fileKey string, file
 		RenderedData: renderedData,
 		RenderCols:   renderCols,
 		RenderRows:   renderRows,
-		CacheHit:     cacheHit,
-		LoadTime:     time.Since(startTime),
 		CreateTime:   time.Now(),
-	}
-
-	m.currentPreview = preview
-
-	logrus.WithFields(logrus.Fields{
-		"file_key":  fileKey,
-		"cache_hit": cacheHit,
-		"force":     force,
-		"load_ms":   preview.LoadTime.Milliseconds(),
-	}).Info("image preview generated")
-
-	return preview, nil
+	}, nil
 }
 
 func (m *ImageManager) previewImageAt(ctx context.Context, fileKey string, fileInfo FileItem, startCol, startRow int, force bool) (*ImagePreview, error) {
@@ -245,6 +261,10 @@ func (m *ImageManager) previewImageAt(ctx context.Context, fileKey string, fileI
 	if err != nil {
 		return nil, err
 	}
+	// 元数据卡片是纯文本，不需要定位图片
+	if p.FilePath == "" {
+		return p, nil
+	}
 	cols, rows := m.estimateCellUsage(p.DisplaySize)
 	placed, err := m.renderer.RenderImageAtCells(p.FilePath, cols, rows, startCol, startRow)
 	if err != nil {
@@ -393,13 +413,14 @@ func (m *ImageManager) estimateCellUsage(displaySize ImageSize) (int, int) {
 	return cols, rows
 }
 
-// SetDownloaderClient 设置下载器的 S3 客户端
-func (m *ImageManager) SetDownloaderClient(client interface{}) error {
-	if s3Client, ok := client.(*s3.Client); ok {
-		m.downloader.SetS3Client(s3Client)
-		return nil
-	}
-	return fmt.Errorf("invalid client type")
+// SetCleanupPolicy 设置缓存的清理策略，maxAge 用于 CleanupPolicyAge
+func (m *ImageManager) SetCleanupPolicy(policy CleanupPolicy, maxAge time.Duration) {
+	m.cacheManager.SetCleanupPolicy(policy, maxAge)
+}
+
+// SetDownloaderClient 设置下载器的存储客户端
+func (m *ImageManager) SetDownloaderClient(client ObjectClient) {
+	m.downloader.SetS3Client(client)
 }
 
 // SetBucketName 设置存储桶名称
@@ -438,6 +459,15 @@ func (m *ImageManager) IsImageFile(contentType string) bool {
 	return supportedTypes[mainType]
 }
 
+// IsPreviewable 检查文件能否在预览窗口中显示：图片、PDF 和视频
+func (m *ImageManager) IsPreviewable(contentType string) bool {
+	switch DetectMediaKind(contentType) {
+	case MediaPDF, MediaVideo:
+		return true
+	}
+	return m.IsImageFile(contentType)
+}
+
 // GetSupportedFormats 返回支持的图片格式列表
 func (m *ImageManager) GetSupportedFormats() []ImageFormat {
 	return m.supportedFormats
@@ -0,0 +1,549 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// MediaKind 预览内容的种类
+type MediaKind string
+
+const (
+	MediaUnknown MediaKind = ""
+	MediaImage   MediaKind = "image"
+	MediaPDF     MediaKind = "pdf"
+	MediaVideo   MediaKind = "video"
+)
+
+// maxMediaDownloadSize 超过该大小的 PDF 和视频不下载，只显示元数据卡片
+const maxMediaDownloadSize = 256 * 1024 * 1024
+
+// errToolMissing 表示生成缩略图所需的外部工具没有安装
+var errToolMissing = errors.New("external tool not found")
+
+// MediaMetadata 是无法生成缩略图时展示的信息
+type MediaMetadata struct {
+	Kind     MediaKind     `json:"kind"`
+	Pages    int           `json:"pages,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Width    int           `json:"width,omitempty"`
+	Height   int           `json:"height,omitempty"`
+	Size     int64         `json:"size,omitempty"`
+	// Note 说明为什么没有缩略图，例如缺少的工具
+	Note string `json:"note,omitempty"`
+}
+
+// DetectMediaKind 根据内容类型判断预览方式
+func DetectMediaKind(contentType string) MediaKind {
+	mainType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return MediaUnknown
+	}
+	switch {
+	case strings.HasPrefix(mainType, "image/"):
+		return MediaImage
+	case mainType == "application/pdf":
+		return MediaPDF
+	case strings.HasPrefix(mainType, "video/"):
+		return MediaVideo
+	}
+	return MediaUnknown
+}
+
+// thumbnailer 调用外部工具生成 PDF 首页和视频封面帧
+type thumbnailer struct {
+	lookPath func(file string) (string, error)
+	run      func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func newThumbnailer() *thumbnailer {
+	return &thumbnailer{
+		lookPath: exec.LookPath,
+		run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, name, args...).Output()
+		},
+	}
+}
+
+// has 检查外部工具是否可用
+func (t *thumbnailer) has(tool string) bool {
+	_, err := t.lookPath(tool)
+	return err == nil
+}
+
+// pdfFirstPage 把 PDF 第一页渲染为 PNG，优先使用 poppler 的 pdftoppm，其次 mutool
+func (t *thumbnailer) pdfFirstPage(ctx context.Context, src, dst string) error {
+	switch {
+	case t.has("pdftoppm"):
+		// pdftoppm 会自动追加 .png 后缀
+		prefix := strings.TrimSuffix(dst, ".png")
+		_, err := t.run(ctx, "pdftoppm", "-png", "-f", "1", "-l", "1", "-singlefile", "-scale-to", "1024", src, prefix)
+		return err
+	case t.has("mutool"):
+		_, err := t.run(ctx, "mutool", "draw", "-q", "-r", "96", "-o", dst, src, "1")
+		return err
+	}
+	return fmt.Errorf("pdftoppm or mutool: %w", errToolMissing)
+}
+
+// videoPosterFrame 用 ffmpeg 截取一帧有代表性的画面作为封面
+func (t *thumbnailer) videoPosterFrame(ctx context.Context, src, dst string) error {
+	if !t.has("ffmpeg") {
+		return fmt.Errorf("ffmpeg: %w", errToolMissing)
+	}
+	_, err := t.run(ctx, "ffmpeg", "-v", "error", "-y", "-i", src,
+		"-vf", "thumbnail,scale='min(1280,iw)':-2", "-frames:v", "1", dst)
+	return err
+}
+
+// probe 读取媒体元数据，外部工具不可用时退回到纯 Go 的解析
+func (t *thumbnailer) probe(ctx context.Context, kind MediaKind, path string) MediaMetadata {
+	meta := MediaMetadata{Kind: kind}
+	if stat, err := os.Stat(path); err == nil {
+		meta.Size = stat.Size()
+	}
+
+	switch kind {
+	case MediaPDF:
+		if t.has("pdfinfo") {
+			if out, err := t.run(ctx, "pdfinfo", path); err == nil {
+				meta.Pages = parsePdfinfoPages(out)
+			}
+		}
+		if meta.Pages == 0 {
+			meta.Pages = countPDFPages(path)
+		}
+	case MediaVideo:
+		if t.has("ffprobe") {
+			out, err := t.run(ctx, "ffprobe", "-v", "error", "-show_entries",
+				"format=duration:stream=codec_type,width,height", "-of", "json", path)
+			if err == nil {
+				parseFFprobe(out, &meta)
+			}
+		}
+		if meta.Duration == 0 && meta.Width == 0 {
+			parseMP4(path, &meta)
+		}
+	}
+	return meta
+}
+
+// parsePdfinfoPages 从 pdfinfo 的输出中读取页数
+func parsePdfinfoPages(out []byte) int {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "Pages:") {
+			pages, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Pages:")))
+			return pages
+		}
+	}
+	return 0
+}
+
+var (
+	pdfCountPattern = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+	pdfPagePattern  = regexp.MustCompile(`/Type\s*/Page\b`)
+)
+
+// countPDFPages 在未压缩的对象中查找页树的 /Count，找不到时统计 /Type /Page。
+// 使用对象流压缩的 PDF 可能无法识别，此时返回 0
+func countPDFPages(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pages := 0
+	for _, m := range pdfCountPattern.FindAllSubmatch(data, -1) {
+		value := m[1]
+		if len(value) == 0 {
+			value = m[2]
+		}
+		if n, err := strconv.Atoi(string(value)); err == nil && n > pages {
+			pages = n
+		}
+	}
+	if pages == 0 {
+		pages = len(pdfPagePattern.FindAll(data, -1))
+	}
+	return pages
+}
+
+// parseFFprobe 解析 ffprobe 的 JSON 输出
+func parseFFprobe(out []byte, meta *MediaMetadata) {
+	var result struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return
+	}
+	for _, stream := range result.Streams {
+		if stream.CodecType == "video" && stream.Width > 0 {
+			meta.Width, meta.Height = stream.Width, stream.Height
+			break
+		}
+	}
+	if seconds, err := strconv.ParseFloat(result.Format.Duration, 64); err == nil {
+		meta.Duration = time.Duration(seconds * float64(time.Second))
+	}
+}
+
+// parseMP4 从 MP4/MOV 的 moov 盒子中读取时长 (mvhd) 和画面尺寸 (tkhd)
+func parseMP4(path string, meta *MediaMetadata) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return
+	}
+	walkMP4Boxes(io.NewSectionReader(file, 0, stat.Size()), meta)
+}
+
+// walkMP4Boxes 遍历一层盒子，进入 moov 和 trak 容器
+func walkMP4Boxes(r *io.SectionReader, meta *MediaMetadata) {
+	var offset int64
+	header := make([]byte, 16)
+	for offset+8 <= r.Size() {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return
+		}
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		headerLen := int64(8)
+		switch size {
+		case 0:
+			size = r.Size() - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerLen = 16
+		}
+		if size < headerLen || offset+size > r.Size() {
+			return
+		}
+
+		body := io.NewSectionReader(r, offset+headerLen, size-headerLen)
+		switch boxType {
+		case "moov", "trak":
+			walkMP4Boxes(body, meta)
+		case "mvhd":
+			parseMvhd(body, meta)
+		case "tkhd":
+			parseTkhd(body, meta)
+		}
+		offset += size
+	}
+}
+
+func parseMvhd(r *io.SectionReader, meta *MediaMetadata) {
+	data := make([]byte, min(r.Size(), 32))
+	if _, err := r.ReadAt(data, 0); err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+	var timescale, duration uint64
+	switch {
+	case len(data) >= 32 && data[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+		duration = binary.BigEndian.Uint64(data[24:32])
+	case len(data) >= 20 && data[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	default:
+		return
+	}
+	if timescale > 0 {
+		meta.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+}
+
+// parseTkhd 读取轨道尺寸，宽高是盒子末尾的两个 16.16 定点数；音轨的尺寸为 0
+func parseTkhd(r *io.SectionReader, meta *MediaMetadata) {
+	if meta.Width > 0 || r.Size() < 8 {
+		return
+	}
+	data := make([]byte, 8)
+	if _, err := r.ReadAt(data, r.Size()-8); err != nil {
+		return
+	}
+	width := int(binary.BigEndian.Uint32(data[:4]) >> 16)
+	height := int(binary.BigEndian.Uint32(data[4:]) >> 16)
+	if width > 0 && height > 0 {
+		meta.Width, meta.Height = width, height
+	}
+}
+
+// thumbnailPath 返回临时缩略图路径
+func thumbnailPath(fileKey string) string {
+	name := strings.TrimSuffix(filepath.Base(fileKey), filepath.Ext(fileKey))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("r2s3-cli-thumb-%s-%d.png", name, time.Now().UnixNano()))
+}
+
+// thumbnailCacheKey 生成缩略图的缓存键，对象被覆盖后 ETag 变化，旧缩略图自然失效
+func (m *ImageManager) thumbnailCacheKey(fileKey, etag string) string {
+	return "thumb:" + objectCacheKey(m.downloader.bucketName, fileKey, etag)
+}
+
+// generateMediaPreview 生成 PDF 首页或视频封面帧的预览。缩略图和元数据分别存入
+// CacheManager；缺少外部工具时只缓存元数据，显示为卡片
+func (m *ImageManager) generateMediaPreview(ctx context.Context, fileKey string, fileInfo FileItem, kind MediaKind, force bool, startTime time.Time) (*ImagePreview, error) {
+	thumbKey := m.thumbnailCacheKey(fileKey, fileInfo.ETag)
+	metaKey := thumbKey + "#meta"
+
+	var (
+		preview *ImagePreview
+		err     error
+	)
+	if force {
+		m.cacheManager.Delete(thumbKey)
+		m.cacheManager.Delete(metaKey)
+	} else {
+		preview, err = m.cachedMediaPreview(fileKey, thumbKey, metaKey)
+		if err != nil {
+			logrus.WithError(err).WithField("file_key", fileKey).Warn("ignoring unreadable cached thumbnail")
+		}
+	}
+
+	cacheHit := preview != nil
+	if cacheHit {
+		m.cacheHitCount++
+	} else {
+		m.cacheMissCount++
+		preview, err = m.createMediaPreview(ctx, fileKey, fileInfo, kind, thumbKey, metaKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	preview.CacheHit = cacheHit
+	preview.LoadTime = time.Since(startTime)
+	m.currentPreview = preview
+
+	logrus.WithFields(logrus.Fields{
+		"file_key":  fileKey,
+		"kind":      kind,
+		"thumbnail": preview.FilePath != "",
+		"cache_hit": cacheHit,
+		"force":     force,
+		"load_ms":   preview.LoadTime.Milliseconds(),
+	}).Info("media preview generated")
+
+	return preview, nil
+}
+
+// cachedMediaPreview 从缓存恢复预览。缩略图被清理掉但元数据还在时需要重新生成
+func (m *ImageManager) cachedMediaPreview(fileKey, thumbKey, metaKey string) (*ImagePreview, error) {
+	metaPath, hit, err := m.cacheManager.Get(metaKey)
+	if !hit || err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, err
+	}
+	var meta MediaMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	if meta.Note != "" {
+		return m.metadataPreview(fileKey, meta), nil
+	}
+	thumbPath, hit, err := m.cacheManager.Get(thumbKey)
+	if !hit || err != nil {
+		return nil, err
+	}
+	return m.thumbnailPreview(fileKey, thumbPath, meta)
+}
+
+// createMediaPreview 下载对象，读取元数据并尝试生成缩略图。只有生成成功
+// 或超过大小限制时才写入缓存；超时、取消或缺少工具的结果下次重新生成
+func (m *ImageManager) createMediaPreview(ctx context.Context, fileKey string, fileInfo FileItem, kind MediaKind, thumbKey, metaKey string) (*ImagePreview, error) {
+	meta := MediaMetadata{Kind: kind, Size: fileInfo.Size}
+	thumbPath := ""
+	tooLarge := fileInfo.Size > maxMediaDownloadSize
+
+	if tooLarge {
+		meta.Note = fmt.Sprintf("Larger than %s, thumbnail skipped", utils.FormatBytes(maxMediaDownloadSize))
+	} else {
+		sourcePath, err := m.downloader.DownloadWithProgress(ctx, fileKey, nil)
+		if err != nil {
+			return nil, &NetworkError{Op: "download", Err: err, Code: 0}
+		}
+		// 原始文件只用来生成缩略图，不进入缓存
+		defer os.Remove(sourcePath)
+
+		meta = m.thumbnailer.probe(ctx, kind, sourcePath)
+		thumbPath = thumbnailPath(fileKey)
+		if kind == MediaPDF {
+			err = m.thumbnailer.pdfFirstPage(ctx, sourcePath, thumbPath)
+		} else {
+			err = m.thumbnailer.videoPosterFrame(ctx, sourcePath, thumbPath)
+		}
+		if err == nil {
+			if _, statErr := os.Stat(thumbPath); statErr != nil {
+				err = fmt.Errorf("no thumbnail produced: %w", statErr)
+			}
+		}
+		if err != nil {
+			os.Remove(thumbPath)
+			thumbPath = ""
+			meta.Note = thumbnailNote(kind, err)
+			if !errors.Is(err, errToolMissing) {
+				logrus.WithError(err).WithField("file_key", fileKey).Warn("failed to generate thumbnail")
+			}
+		}
+	}
+
+	if thumbPath != "" {
+		// 缓存失败时继续使用临时缩略图
+		if cachedPath, err := m.cacheManager.Put(thumbKey, thumbPath); err == nil {
+			os.Remove(thumbPath)
+			thumbPath = cachedPath
+		} else {
+			logrus.WithError(err).WithField("file_key", fileKey).Warn("failed to cache thumbnail")
+		}
+	}
+	if thumbPath != "" || tooLarge {
+		m.cacheMetadata(metaKey, meta)
+	}
+
+	if thumbPath == "" {
+		return m.metadataPreview(fileKey, meta), nil
+	}
+	return m.thumbnailPreview(fileKey, thumbPath, meta)
+}
+
+// cacheMetadata 把元数据以 JSON 文件的形式存入缓存
+func (m *ImageManager) cacheMetadata(metaKey string, meta MediaMetadata) {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp("", "r2s3-cli-meta-*.json")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		_, err = m.cacheManager.Put(metaKey, tmp.Name())
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("cache_key", metaKey).Warn("failed to cache media metadata")
+	}
+}
+
+// thumbnailPreview 渲染缩略图
+func (m *ImageManager) thumbnailPreview(fileKey, thumbPath string, meta MediaMetadata) (*ImagePreview, error) {
+	preview, err := m.renderLocalImage(fileKey, thumbPath)
+	if err != nil {
+		return nil, err
+	}
+	preview.Kind = meta.Kind
+	preview.Metadata = &meta
+	return preview, nil
+}
+
+// metadataPreview 生成没有缩略图时的元数据卡片
+func (m *ImageManager) metadataPreview(fileKey string, meta MediaMetadata) *ImagePreview {
+	card := RenderMetadataCard(meta)
+	lines := strings.Split(card, "\n")
+	cols := 0
+	for _, line := range lines {
+		cols = max(cols, len([]rune(line)))
+	}
+	return &ImagePreview{
+		FileKey:      fileKey,
+		RenderedData: card,
+		RenderCols:   cols,
+		RenderRows:   len(lines),
+		CreateTime:   time.Now(),
+		Kind:         meta.Kind,
+		Metadata:     &meta,
+	}
+}
+
+// thumbnailNote 说明缩略图为什么没有生成
+func thumbnailNote(kind MediaKind, err error) string {
+	if errors.Is(err, errToolMissing) {
+		if kind == MediaPDF {
+			return "Install pdftoppm (poppler) or mutool to render the first page"
+		}
+		return "Install ffmpeg to show a poster frame"
+	}
+	return fmt.Sprintf("Thumbnail failed: %v", err)
+}
+
+// RenderMetadataCard 把元数据排成多行文本
+func RenderMetadataCard(meta MediaMetadata) string {
+	var lines []string
+	switch meta.Kind {
+	case MediaPDF:
+		lines = append(lines, "📄 PDF document")
+	case MediaVideo:
+		lines = append(lines, "🎬 Video")
+	}
+
+	field := func(name, value string) {
+		lines = append(lines, fmt.Sprintf("%-12s%s", name+":", value))
+	}
+	if meta.Pages > 0 {
+		field("Pages", strconv.Itoa(meta.Pages))
+	}
+	if meta.Duration > 0 {
+		field("Duration", FormatDuration(meta.Duration))
+	}
+	if meta.Width > 0 && meta.Height > 0 {
+		field("Resolution", fmt.Sprintf("%d×%d", meta.Width, meta.Height))
+	}
+	if meta.Size > 0 {
+		field("Size", utils.FormatBytes(meta.Size))
+	}
+	if meta.Note != "" {
+		lines = append(lines, "", meta.Note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatDuration 把时长格式化为 m:ss 或 h:mm:ss
+func FormatDuration(d time.Duration) string {
+	total := int(d.Round(time.Second) / time.Second)
+	hours, minutes, seconds := total/3600, total/60%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
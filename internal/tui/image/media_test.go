@@ -0,0 +1,309 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// fakeObjectClient 从内存返回对象，并记录 Get 次数
+type fakeObjectClient struct {
+	objects map[string][]byte
+	gets    int
+}
+
+func (f *fakeObjectClient) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	body, ok := f.objects[key]
+	if !ok {
+		return nil, r2.ErrNotFound
+	}
+	return &r2.ObjectInfo{Key: key, Size: int64(len(body))}, nil
+}
+
+func (f *fakeObjectClient) Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error) {
+	f.gets++
+	body, ok := f.objects[key]
+	if !ok {
+		return nil, r2.ErrNotFound
+	}
+	return &r2.Object{
+		ObjectInfo:    r2.ObjectInfo{Key: key, Size: int64(len(body))},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+// withTools 模拟已安装的外部工具；生成缩略图的工具会写出一张 PNG
+func withTools(t *testing.T, tools ...string) *thumbnailer {
+	t.Helper()
+	installed := map[string]bool{}
+	for _, tool := range tools {
+		installed[tool] = true
+	}
+	return &thumbnailer{
+		lookPath: func(file string) (string, error) {
+			if installed[file] {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("not found")
+		},
+		run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			dst := args[len(args)-1]
+			switch name {
+			case "pdftoppm":
+				dst += ".png"
+			case "ffprobe":
+				return []byte(`{"streams":[{"codec_type":"audio"},{"codec_type":"video","width":1920,"height":1080}],"format":{"duration":"83.5"}}`), nil
+			case "ffmpeg":
+			default:
+				return nil, errors.New("unexpected tool " + name)
+			}
+			img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+			img.Set(1, 1, color.RGBA{R: 255, A: 255})
+			file, err := os.Create(dst)
+			require.NoError(t, err)
+			defer file.Close()
+			return nil, png.Encode(file, img)
+		},
+	}
+}
+
+// testPDF 是一个未压缩的三页 PDF 片段
+const testPDF = `%PDF-1.4
+1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj
+2 0 obj << /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >> endobj
+3 0 obj << /Type /Page /Parent 2 0 R >> endobj
+4 0 obj << /Type /Page /Parent 2 0 R >> endobj
+5 0 obj << /Type /Page /Parent 2 0 R >> endobj
+%%EOF`
+
+// mp4Box 组装一个 MP4 盒子
+func mp4Box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	box := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(box, uint32(8+len(body)))
+	copy(box[4:], boxType)
+	return append(box, body...)
+}
+
+// testMP4 生成一个只有 moov 头信息的 MP4：时长 90 秒，一条音轨和一条 640x360 的视频轨
+func testMP4() []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)  // timescale
+	binary.BigEndian.PutUint32(mvhd[16:], 90000) // duration
+
+	audio := make([]byte, 84)
+	video := make([]byte, 84)
+	binary.BigEndian.PutUint32(video[76:], 640<<16)
+	binary.BigEndian.PutUint32(video[80:], 360<<16)
+
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom")),
+		mp4Box("moov",
+			mp4Box("mvhd", mvhd),
+			mp4Box("trak", mp4Box("tkhd", audio)),
+			mp4Box("trak", mp4Box("tkhd", video)),
+		),
+		mp4Box("mdat", make([]byte, 32)),
+	}, nil)
+}
+
+func newMediaManager(t *testing.T, tools *thumbnailer, objects map[string][]byte) (*ImageManager, *fakeObjectClient) {
+	t.Helper()
+	manager := NewImageManager(t.TempDir(), 10*1024*1024)
+	t.Cleanup(func() { manager.Close() })
+
+	client := &fakeObjectClient{objects: objects}
+	manager.SetDownloaderClient(client)
+	manager.SetBucketName("media")
+	manager.thumbnailer = tools
+	return manager, client
+}
+
+func TestDetectMediaKind(t *testing.T) {
+	assert.Equal(t, MediaImage, DetectMediaKind("image/png"))
+	assert.Equal(t, MediaPDF, DetectMediaKind("application/pdf"))
+	assert.Equal(t, MediaVideo, DetectMediaKind("video/mp4; codecs=avc1"))
+	assert.Equal(t, MediaUnknown, DetectMediaKind("text/plain"))
+	assert.Equal(t, MediaUnknown, DetectMediaKind(""))
+
+	manager := NewImageManager(t.TempDir(), 1024)
+	defer manager.Close()
+	assert.True(t, manager.IsPreviewable("video/quicktime"))
+	assert.True(t, manager.IsPreviewable("application/pdf"))
+	assert.False(t, manager.IsPreviewable("application/zip"))
+}
+
+func TestCountPDFPages(t *testing.T) {
+	path := t.TempDir() + "/doc.pdf"
+	require.NoError(t, os.WriteFile(path, []byte(testPDF), 0644))
+	assert.Equal(t, 3, countPDFPages(path))
+
+	// 没有页树时统计页面对象
+	noTree := strings.Replace(testPDF, "/Count 3", "", 1)
+	require.NoError(t, os.WriteFile(path, []byte(noTree), 0644))
+	assert.Equal(t, 3, countPDFPages(path))
+
+	assert.Equal(t, 12, parsePdfinfoPages([]byte("Title:  x\nPages:          12\n")))
+}
+
+func TestParseMP4(t *testing.T) {
+	path := t.TempDir() + "/clip.mp4"
+	require.NoError(t, os.WriteFile(path, testMP4(), 0644))
+
+	meta := MediaMetadata{}
+	parseMP4(path, &meta)
+	assert.Equal(t, 90*time.Second, meta.Duration)
+	assert.Equal(t, 640, meta.Width)
+	assert.Equal(t, 360, meta.Height)
+}
+
+func TestMediaPreview_MetadataCardWithoutTools(t *testing.T) {
+	manager, client := newMediaManager(t, withTools(t), map[string][]byte{
+		"docs/report.pdf": []byte(testPDF),
+		"clips/intro.mp4": testMP4(),
+	})
+	ctx := context.Background()
+
+	pdf := FileItem{Key: "docs/report.pdf", Size: int64(len(testPDF)), ContentType: "application/pdf", ETag: `"v1"`}
+	preview, err := manager.PreviewImageAt(ctx, pdf.Key, pdf, 1, 1)
+	require.NoError(t, err)
+	assert.Empty(t, preview.FilePath)
+	assert.Equal(t, MediaPDF, preview.Kind)
+	assert.Equal(t, 3, preview.Metadata.Pages)
+	assert.Contains(t, preview.RenderedData, "📄 PDF document")
+	assert.Contains(t, preview.RenderedData, "Pages:      3")
+	assert.Contains(t, preview.RenderedData, "Install pdftoppm")
+	assert.False(t, preview.CacheHit)
+
+	// 缺少工具的结果不写入缓存，安装工具后可以生成缩略图
+	manager.thumbnailer = withTools(t, "pdftoppm")
+	preview, err = manager.PreviewImage(ctx, pdf.Key, pdf)
+	require.NoError(t, err)
+	assert.False(t, preview.CacheHit)
+	assert.NotEmpty(t, preview.FilePath)
+	assert.Equal(t, 2, client.gets)
+	manager.thumbnailer = withTools(t)
+
+	video := FileItem{Key: "clips/intro.mp4", Size: int64(len(testMP4())), ContentType: "video/mp4", ETag: `"v1"`}
+	preview, err = manager.PreviewImage(ctx, video.Key, video)
+	require.NoError(t, err)
+	assert.Contains(t, preview.RenderedData, "Duration:   1:30")
+	assert.Contains(t, preview.RenderedData, "Resolution: 640×360")
+	assert.Contains(t, preview.RenderedData, "Install ffmpeg")
+}
+
+func TestMediaPreview_ThumbnailCachedByETag(t *testing.T) {
+	manager, client := newMediaManager(t, withTools(t, "ffmpeg", "ffprobe"), map[string][]byte{
+		"clips/intro.mp4": testMP4(),
+	})
+	ctx := context.Background()
+
+	video := FileItem{Key: "clips/intro.mp4", Size: int64(len(testMP4())), ContentType: "video/mp4", ETag: `"v1"`}
+	preview, err := manager.PreviewImage(ctx, video.Key, video)
+	require.NoError(t, err)
+	require.NotEmpty(t, preview.FilePath)
+	assert.Equal(t, MediaVideo, preview.Kind)
+	assert.Equal(t, ImageSize{Width: 64, Height: 48}, preview.OriginalSize)
+	assert.Equal(t, 1920, preview.Metadata.Width)
+	assert.Equal(t, 83500*time.Millisecond, preview.Metadata.Duration)
+
+	// 缩略图保存在 CacheManager 中，键包含存储桶、对象键和 ETag
+	_, hit, err := manager.cacheManager.Get(`thumb:media/clips/intro.mp4@v1`)
+	require.NoError(t, err)
+	assert.True(t, hit)
+
+	preview, err = manager.PreviewImage(ctx, video.Key, video)
+	require.NoError(t, err)
+	assert.True(t, preview.CacheHit)
+	assert.Equal(t, 1920, preview.Metadata.Width)
+	assert.Equal(t, 1, client.gets)
+
+	// 对象被覆盖后 ETag 变化，重新生成
+	video.ETag = `"v2"`
+	preview, err = manager.PreviewImage(ctx, video.Key, video)
+	require.NoError(t, err)
+	assert.False(t, preview.CacheHit)
+	assert.Equal(t, 2, client.gets)
+
+	// 强制刷新同样重新下载
+	_, err = manager.PreviewImageForce(ctx, video.Key, video)
+	require.NoError(t, err)
+	assert.Equal(t, 3, client.gets)
+}
+
+func TestMediaPreview_PDFThumbnail(t *testing.T) {
+	manager, _ := newMediaManager(t, withTools(t, "pdftoppm"), map[string][]byte{
+		"docs/report.pdf": []byte(testPDF),
+	})
+
+	pdf := FileItem{Key: "docs/report.pdf", Size: int64(len(testPDF)), ContentType: "application/pdf"}
+	preview, err := manager.PreviewImage(context.Background(), pdf.Key, pdf)
+	require.NoError(t, err)
+	assert.NotEmpty(t, preview.FilePath)
+	assert.Equal(t, FormatPNG, preview.Format)
+	assert.Equal(t, 3, preview.Metadata.Pages)
+}
+
+func TestMediaPreview_SkipsLargeObjects(t *testing.T) {
+	manager, client := newMediaManager(t, withTools(t, "ffmpeg"), map[string][]byte{})
+
+	video := FileItem{Key: "raw/footage.mov", Size: maxMediaDownloadSize + 1, ContentType: "video/quicktime"}
+	preview, err := manager.PreviewImage(context.Background(), video.Key, video)
+	require.NoError(t, err)
+	assert.Empty(t, preview.FilePath)
+	assert.Contains(t, preview.RenderedData, "thumbnail skipped")
+	assert.Equal(t, 0, client.gets)
+
+	// 超过大小限制的结果会缓存
+	preview, err = manager.PreviewImage(context.Background(), video.Key, video)
+	require.NoError(t, err)
+	assert.True(t, preview.CacheHit)
+	assert.Contains(t, preview.RenderedData, "thumbnail skipped")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "0:05", FormatDuration(5*time.Second))
+	assert.Equal(t, "1:30", FormatDuration(90*time.Second))
+	assert.Equal(t, "1:02:03", FormatDuration(time.Hour+2*time.Minute+3*time.Second))
+}
+
+func TestImagePreview_RefetchesWhenETagChanges(t *testing.T) {
+	manager, client := newMediaManager(t, withTools(t), map[string][]byte{"photos/cat.png": testPNG(t, 16, 16)})
+	item := FileItem{Key: "photos/cat.png", ContentType: "image/png", ETag: `"v1"`}
+
+	_, err := manager.PreviewImage(context.Background(), item.Key, item)
+	require.NoError(t, err)
+	preview, err := manager.PreviewImage(context.Background(), item.Key, item)
+	require.NoError(t, err)
+	assert.True(t, preview.CacheHit)
+	assert.Equal(t, 1, client.gets)
+
+	// 对象被覆盖后 ETag 变化，不再使用旧的缓存
+	item.ETag = `"v2"`
+	preview, err = manager.PreviewImage(context.Background(), item.Key, item)
+	require.NoError(t, err)
+	assert.False(t, preview.CacheHit)
+	assert.Equal(t, 2, client.gets)
+
+	// 同名对象在另一个存储桶中也单独缓存
+	manager.SetBucketName("archive")
+	preview, err = manager.PreviewImage(context.Background(), item.Key, item)
+	require.NoError(t, err)
+	assert.False(t, preview.CacheHit)
+	assert.Equal(t, 3, client.gets)
+}
@@ -244,6 +244,69 @@ func (r *ImageRenderer) RenderImageAtCells(imagePath string, cols, rows, startCo
 	return b.String(), nil
 }
 
+// RenderThumbnail 把已解码的图片缩放到 cols×rows 单元格以内（保持宽高比）并渲染；
+// 文本模式或终端不支持图形时使用 ANSI 半块字符
+func (r *ImageRenderer) RenderThumbnail(img image.Image, cols, rows int) (*Thumbnail, error) {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil, fmt.Errorf("invalid image size")
+	}
+	cols, rows = fitCells(bounds.Dx(), bounds.Dy(), cols, rows)
+
+	var output strings.Builder
+	var err error
+	switch {
+	case r.TextMode || !r.IsSupported():
+		var data string
+		data, err = ansiHalfBlocks(img, cols, rows)
+		output.WriteString(strings.TrimSuffix(data, "\n"))
+	case r.Protocol == ProtocolKitty:
+		// 先缩小再发送，减少写入终端的数据量
+		var small image.Image
+		if small, err = r.preprocessImage(img, cols*8, rows*16); err == nil {
+			err = rasterm.KittyWriteImage(&output, small, rasterm.KittyImgOpts{
+				DstCols: uint32(cols),
+				DstRows: uint32(rows),
+			})
+		}
+	case r.Protocol == ProtocolITerm:
+		var small image.Image
+		if small, err = r.preprocessImage(img, cols*8, rows*16); err == nil {
+			err = rasterm.ItermWriteImage(&output, small)
+		}
+	case r.Protocol == ProtocolSixel:
+		var small image.Image
+		if small, err = r.preprocessImage(img, cols*8, rows*16); err == nil {
+			err = rasterm.SixelWriteImage(&output, toPaletted(small))
+		}
+	}
+	if err != nil {
+		return nil, &RenderError{
+			Terminal: string(r.TerminalType),
+			Protocol: string(r.Protocol),
+			Err:      fmt.Errorf("failed to render thumbnail: %w", err),
+		}
+	}
+
+	return &Thumbnail{Data: output.String(), Cols: cols, Rows: rows}, nil
+}
+
+// fitCells 计算宽 width、高 height 像素的图片在 cols×rows 单元格内保持比例时占用的单元格
+func fitCells(width, height, cols, rows int) (int, int) {
+	// 近似每个字符 8x16 px
+	imgCols := float64(width) / 8.0
+	imgRows := float64(height) / 16.0
+
+	scale := float64(cols) / imgCols
+	if s := float64(rows) / imgRows; s < scale {
+		scale = s
+	}
+
+	fitCols := int(imgCols*scale + 0.5)
+	fitRows := int(imgRows*scale + 0.5)
+	return max(1, min(cols, fitCols)), max(1, min(rows, fitRows))
+}
+
 // renderWithKitty 使用 Kitty 协议渲染
 func (r *ImageRenderer) renderWithKitty(imagePath string, maxWidth, maxHeight int) (string, error) {
 	// 打开图片文件
@@ -329,7 +392,11 @@ func (r *ImageRenderer) renderAsANSI(imagePath string) (string, error) {
 	if rows <= 0 {
 		rows = 12
 	}
+	return ansiHalfBlocks(img, cols, rows)
+}
 
+// ansiHalfBlocks 把图片绘制到 cols×rows 的字符网格，每个字符上下各表示一个像素
+func ansiHalfBlocks(img image.Image, cols, rows int) (string, error) {
 	// 根据网格计算目标像素尺寸（行*2）
 	targetW := cols
 	targetH := rows * 2
@@ -472,16 +539,11 @@ func (r *ImageRenderer) renderWithSixel(imagePath string, maxWidth, maxHeight in
 		return "", err
 	}
 
-	// 转换为调色板图像（Sixel 需要）
-	bounds := processedImg.Bounds()
-	palettedImg := image.NewPaletted(bounds, palette.Plan9)
-	draw.FloydSteinberg.Draw(palettedImg, bounds, processedImg, image.ZP)
-
 	// 使用 strings.Builder 捕获输出
 	var output strings.Builder
 
 	// 使用 rasterm 编码为 Sixel 协议
-	if err := rasterm.SixelWriteImage(&output, palettedImg); err != nil {
+	if err := rasterm.SixelWriteImage(&output, toPaletted(processedImg)); err != nil {
 		return "", &RenderError{
 			Terminal: string(r.TerminalType),
 			Protocol: string(r.Protocol),
@@ -492,6 +554,14 @@ func (r *ImageRenderer) renderWithSixel(imagePath string, maxWidth, maxHeight in
 	return output.String(), nil
 }
 
+// toPaletted 转换为调色板图像（Sixel 需要）
+func toPaletted(img image.Image) *image.Paletted {
+	bounds := img.Bounds()
+	palettedImg := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(palettedImg, bounds, img, image.ZP)
+	return palettedImg
+}
+
 // renderFallback 降级处理，不支持图形时的文本描述
 func (r *ImageRenderer) renderFallback(imagePath string) (string, error) {
 	stat, err := os.Stat(imagePath)
@@ -559,17 +629,23 @@ func (r *ImageRenderer) SetCellSize(cols, rows int) {
 
 // ClearScreen 清除终端图片显示
 func (r *ImageRenderer) ClearScreen() error {
+	fmt.Print(r.ClearSequence())
+	return nil
+}
+
+// ClearSequence 返回删除已显示图片的控制序列，可以直接拼接在 TUI 输出中
+func (r *ImageRenderer) ClearSequence() string {
 	switch r.Protocol {
 	case ProtocolKitty:
 		// Kitty 清除命令
-		fmt.Print("\033_Ga=d\033\\")
+		return "\033_Ga=d\033\\"
 	case ProtocolITerm:
 		// iTerm2: 避免全屏清除以免破坏 TUI，这里不执行
 		// iTerm2 内联图像不易精确删除，选择不清除，由 Bubble Tea 后续重绘覆盖
 	case ProtocolSixel:
 		// Sixel: 避免全屏清除
 	}
-	return nil
+	return ""
 }
 
 // validateImageFile 验证图片文件格式
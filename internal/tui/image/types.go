@@ -36,6 +36,17 @@ type ImagePreview struct {
 	CacheHit     bool
 	LoadTime     time.Duration
 	CreateTime   time.Time
+
+	// PDF 和视频的预览信息；没有缩略图时 FilePath 为空，RenderedData 是元数据卡片
+	Kind     MediaKind
+	Metadata *MediaMetadata
+}
+
+// Thumbnail 画廊中的一张缩略图
+type Thumbnail struct {
+	Data string // 渲染后的终端输出数据，不含定位序列
+	Cols int    // 占用的列数（终端单元）
+	Rows int    // 占用的行数（终端单元）
 }
 
 // RenderError 渲染错误类型
@@ -94,11 +105,15 @@ type CacheStats struct {
 
 // CacheMetrics 详细的缓存指标
 type CacheMetrics struct {
+	CacheDir          string
+	CleanupPolicy     string
 	TotalFiles        int
 	TotalSize         int64
 	MaxSize           int64
 	UsagePercent      float64
-	HitRate           float64
+	Hits              int64
+	Misses            int64
+	HitRate           float64 // 百分比，没有访问记录时为 0
 	AverageFileSize   float64
 	AverageAccessTime time.Time
 	OldestEntry       *CacheEntry
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+)
+
+// parentPrefix returns the prefix one folder above p ("a/b/" -> "a/", "a/" -> "")
+func parentPrefix(p string) string {
+	trimmed := strings.TrimSuffix(p, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return ""
+	}
+	return trimmed[:idx+1]
+}
+
+// prefixDir returns p as a folder path ending with "/", or "" for the bucket root
+func prefixDir(p string) string {
+	if p == "" || strings.HasSuffix(p, "/") {
+		return p
+	}
+	return p + "/"
+}
+
+// selectedFile returns the object under the cursor, ignoring folder rows
+func (m *FileBrowserModel) selectedFile() (FileItem, bool) {
+	if len(m.files) == 0 || m.cursor >= len(m.files) {
+		return FileItem{}, false
+	}
+	file := m.files[m.cursor]
+	if file.IsDir {
+		return FileItem{}, false
+	}
+	return file, true
+}
+
+// folderCount returns the number of folder rows on the current page
+func (m *FileBrowserModel) folderCount() int {
+	count := 0
+	for _, file := range m.files {
+		if file.IsDir {
+			count++
+		}
+	}
+	return count
+}
+
+// displayName returns the name shown in the table: the full key in flat
+// and search mode, otherwise the key relative to the current folder
+func (m *FileBrowserModel) displayName(file FileItem) string {
+	if m.flatMode || m.isSearchMode {
+		return file.Key
+	}
+	base := m.prefix[:strings.LastIndex(m.prefix, "/")+1]
+	if name := strings.TrimPrefix(file.Key, base); name != "" {
+		return name
+	}
+	return file.Key
+}
+
+// breadcrumb renders the bucket and current folder path for the header
+func (m *FileBrowserModel) breadcrumb() string {
+	parts := []string{m.bucketName}
+	for _, segment := range strings.Split(m.prefix, "/") {
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, " › ")
+}
+
+// resetListing resets pagination and starts loading the first page
+func (m *FileBrowserModel) resetListing() {
+	m.currentPage = 1
+	m.continuationToken = ""
+	m.estimatedTotalPages = 1
+	m.hasNextPage = false
+	m.loading = true
+	m.error = nil
+	m.clearInlinePreview()
+	m.updateRightPanel()
+}
+
+// enterDirectory switches the browser to prefix, restoring the cursor
+// position last used there
+func (m *FileBrowserModel) enterDirectory(prefix string) tea.Cmd {
+	if m.cursorMemory == nil {
+		m.cursorMemory = make(map[string]int)
+	}
+	m.cursorMemory[m.prefix] = m.cursor
+
+	// A search is scoped to the folder it was started in
+	m.cancelSearch()
+	m.isSearchMode = false
+	m.searchQuery = ""
+	m.searchSpec = nil
+
+	m.prefix = prefix
+	m.cursor = m.cursorMemory[prefix]
+	m.clearMessage()
+	m.resetListing()
+	return m.loadFiles()
+}
+
+// toggleFlatMode switches between folder navigation and a flat key listing
+func (m *FileBrowserModel) toggleFlatMode() tea.Cmd {
+	m.flatMode = !m.flatMode
+	m.cursor = 0
+	if m.flatMode {
+		m.setMessage("Flat view: listing every key below the current folder", messaging.MessageInfo)
+	} else {
+		m.setMessage("Folder view", messaging.MessageInfo)
+	}
+	m.resetListing()
+	return m.loadFiles()
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentPrefix(t *testing.T) {
+	assert.Equal(t, "", parentPrefix(""))
+	assert.Equal(t, "", parentPrefix("photos/"))
+	assert.Equal(t, "photos/", parentPrefix("photos/2024/"))
+	assert.Equal(t, "", parentPrefix("photos"))
+}
+
+func TestPrefixDir(t *testing.T) {
+	assert.Equal(t, "", prefixDir(""))
+	assert.Equal(t, "photos/", prefixDir("photos"))
+	assert.Equal(t, "photos/", prefixDir("photos/"))
+}
+
+// TestFileBrowser_EnterAndLeaveFolder 测试进入和返回目录，并恢复光标位置
+func TestFileBrowser_EnterAndLeaveFolder(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = []FileItem{
+		{Key: "docs/", Category: "folder", IsDir: true},
+		{Key: "photos/", Category: "folder", IsDir: true},
+		{Key: "readme.txt", Size: 10, Category: "text"},
+	}
+	model.cursor = 1
+
+	// 在目录上按 Enter 进入目录
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	fb := updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "photos/", fb.prefix)
+	assert.Equal(t, 0, fb.cursor)
+	assert.True(t, fb.loading)
+	assert.Equal(t, "test-bucket › photos", fb.breadcrumb())
+
+	// 按 Backspace 返回上级目录，光标回到原位置
+	fb.files = []FileItem{{Key: "photos/cat.png", Category: "image"}}
+	updated, cmd = fb.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	fb = updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "", fb.prefix)
+	assert.Equal(t, 1, fb.cursor)
+
+	// 在根目录按 Backspace 不做任何事
+	_, cmd = fb.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Nil(t, cmd)
+}
+
+// TestFileBrowser_FolderRowsBlockFileActions 测试目录行不能执行文件操作
+func TestFileBrowser_FolderRowsBlockFileActions(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = []FileItem{{Key: "photos/", Category: "folder", IsDir: true}}
+	model.cursor = 0
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	fb := updated.(*FileBrowserModel)
+	assert.Nil(t, cmd)
+	assert.False(t, fb.confirmDelete)
+
+	// 文件行上按 Enter 不做任何事
+	fb.files = []FileItem{{Key: "readme.txt", Category: "text"}}
+	_, cmd = fb.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.Equal(t, "", fb.prefix)
+}
+
+// TestFileBrowser_ToggleFlatMode 测试平铺模式切换
+func TestFileBrowser_ToggleFlatMode(t *testing.T) {
+	model := createTestFileBrowser()
+	model.prefix = "photos/"
+	model.cursor = 3
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	fb := updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	assert.True(t, fb.flatMode)
+	assert.Equal(t, 0, fb.cursor)
+	assert.Equal(t, "photos/2024/cat.png", fb.displayName(FileItem{Key: "photos/2024/cat.png"}))
+
+	// 平铺模式下 Backspace 不切换目录
+	_, cmd = fb.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Nil(t, cmd)
+	assert.Equal(t, "photos/", fb.prefix)
+
+	fb.flatMode = false
+	assert.Equal(t, "2024/", fb.displayName(FileItem{Key: "photos/2024/", IsDir: true}))
+}
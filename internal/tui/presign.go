@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// presignExpiryOrDefault returns the expiry used for copied presigned URLs
+func (m *FileBrowserModel) presignExpiryOrDefault() time.Duration {
+	if m.presignExpiry == 0 {
+		return utils.DefaultPresignExpiry
+	}
+	return m.presignExpiry
+}
+
+// showPresignExpiryInput asks how long the copied presigned URL should be valid
+func (m *FileBrowserModel) showPresignExpiryInput() {
+	target := "the selected file"
+	if len(m.selected) > 0 {
+		target = fmt.Sprintf("%d marked files", len(m.selected))
+	} else if file, ok := m.selectedFile(); ok {
+		target = file.Key
+	}
+
+	m.showInput = true
+	m.inputMode = InputModePresignExpiry
+	m.inputComponentMode = InputComponentText
+	m.inputPrompt = fmt.Sprintf("Presigned URL expiry for %s (max 7d):", target)
+	m.textInput.SetValue(utils.FormatExpiry(m.presignExpiryOrDefault()))
+	m.textInput.Placeholder = "e.g. 15m, 12h, 7d"
+	m.textInput.Focus()
+}
+
+// processPresignExpiryInput parses the expiry and copies the presigned URL(s)
+func (m *FileBrowserModel) processPresignExpiryInput() (tea.Model, tea.Cmd) {
+	m.showInput = false
+	m.inputMode = InputModeNone
+
+	value := strings.TrimSpace(m.textInput.Value())
+	m.textInput.SetValue("")
+	m.textInput.Blur()
+
+	expiry, err := utils.ParseExpiry(value)
+	if err != nil {
+		m.setMessage(err.Error(), messaging.MessageError)
+		return m, nil
+	}
+	m.presignExpiry = expiry
+
+	if len(m.selected) > 0 {
+		m.copySelectedURLs(true)
+		return m, nil
+	}
+
+	file, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	presignedURL, err := m.urlGenerator.GeneratePresignedURLWithExpiry(file.Key, expiry)
+	if err != nil {
+		m.setMessage(fmt.Sprintf("Failed to generate presigned URL: %s", err), messaging.MessageError)
+		return m, nil
+	}
+	if err := utils.CopyToClipboard(presignedURL); err != nil {
+		m.setMessage(theme.FormatErrorMessage("Copy", err), messaging.MessageError)
+		return m, nil
+	}
+	m.setMessage(fmt.Sprintf("Presigned URL copied to clipboard (expires in %s)", utils.FormatExpiry(expiry)), messaging.MessageInfo)
+	return m, nil
+}
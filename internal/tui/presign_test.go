@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileBrowser_PresignExpiryPrompt 测试复制预签名 URL 前先选择有效期
+func TestFileBrowser_PresignExpiryPrompt(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.cursor = 1
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	fb := updated.(*FileBrowserModel)
+	require.True(t, fb.showInput)
+	assert.Equal(t, InputModePresignExpiry, fb.inputMode)
+	assert.Contains(t, fb.inputPrompt, "a.txt")
+	// 默认有效期为 1 小时
+	assert.Equal(t, "1h", fb.textInput.Value())
+
+	// 无效的有效期不会被保存
+	fb.textInput.SetValue("30d")
+	updated, _ = fb.processPresignExpiryInput()
+	fb = updated.(*FileBrowserModel)
+	assert.False(t, fb.showInput)
+	assert.Equal(t, time.Hour, fb.presignExpiryOrDefault())
+
+	// 目录行没有可复制的 URL
+	fb.cursor = 0
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	fb = updated.(*FileBrowserModel)
+	assert.False(t, fb.showInput)
+}
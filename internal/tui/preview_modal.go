@@ -38,11 +38,21 @@ type ImagePreviewModel struct {
 	// terminal cell footprint
 	imgCols int
 	imgRows int
+
+	// PDF and video previews; card holds the metadata card when no thumbnail
+	// could be generated
+	kind     img.MediaKind
+	metadata *img.MediaMetadata
+	card     string
 }
 
 type (
-	previewReadyMsg struct{}
-	modalClosedMsg  struct{}
+	// previewReadyMsg carries the rendered preview back to the modal
+	previewReadyMsg struct {
+		preview *img.ImagePreview
+		err     error
+	}
+	modalClosedMsg struct{}
 )
 
 func NewImagePreviewModel(manager *img.ImageManager, file FileItem, width, height int, force bool) *ImagePreviewModel {
@@ -78,6 +88,7 @@ func (m *ImagePreviewModel) Init() tea.Cmd {
 			LastModified: m.file.LastModified,
 			ContentType:  m.file.ContentType,
 			Category:     m.file.Category,
+			ETag:         m.file.ETag,
 		}
 
 		var (
@@ -90,51 +101,39 @@ func (m *ImagePreviewModel) Init() tea.Cmd {
 		} else {
 			preview, err = m.manager.PreviewImage(ctx, m.file.Key, item)
 		}
-		if err != nil {
-			m.err = err
-		} else if preview != nil {
-			m.cacheHit = preview.CacheHit
-			cols := preview.RenderCols
-			if cols < 1 {
-				cols = 1
-			}
-			if cols > m.width {
-				cols = m.width
-			}
-			gap := m.width - cols
-			col := 1 + gap/2
-			if col < 1 {
-				col = 1
-			}
-			if col > m.width {
-				col = m.width
-			}
-			// Calculate row position dynamically: name + status + hint + cache + separator + margin
-			row := 4 // base: name, status, hint lines
-			if m.forceReload || !m.forceReload { // cacheInfo will be shown
-				row++
-			}
-			row++ // separator line
-			row++ // extra margin for better spacing
+		if err != nil || preview == nil || preview.FilePath == "" {
+			// Metadata cards are plain text and need no placement
+			return previewReadyMsg{preview: preview, err: err}
+		}
+		cols := preview.RenderCols
+		if cols < 1 {
+			cols = 1
+		}
+		if cols > m.width {
+			cols = m.width
+		}
+		gap := m.width - cols
+		col := 1 + gap/2
+		if col < 1 {
+			col = 1
+		}
+		if col > m.width {
+			col = m.width
+		}
+		// Calculate row position dynamically: name + status + hint + cache + separator + margin
+		row := 4 // base: name, status, hint lines
+		if m.forceReload || !m.forceReload { // cacheInfo will be shown
+			row++
+		}
+		row++ // separator line
+		row++ // extra margin for better spacing
 
-			if m.forceReload {
-				preview, err = m.manager.PreviewImageAtForce(ctx, m.file.Key, item, col, row)
-			} else {
-				preview, err = m.manager.PreviewImageAt(ctx, m.file.Key, item, col, row)
-			}
-			if err != nil {
-				m.err = err
-			} else if preview != nil {
-				m.rendered = preview.RenderedData
-				m.imgPixelW = preview.DisplaySize.Width
-				m.imgPixelH = preview.DisplaySize.Height
-				m.imgCols = preview.RenderCols
-				m.imgRows = preview.RenderRows
-				m.cacheHit = preview.CacheHit
-			}
+		if m.forceReload {
+			preview, err = m.manager.PreviewImageAtForce(ctx, m.file.Key, item, col, row)
+		} else {
+			preview, err = m.manager.PreviewImageAt(ctx, m.file.Key, item, col, row)
 		}
-		m.loading = false
-		return previewReadyMsg{}
+		return previewReadyMsg{preview: preview, err: err}
 	}
 
 	return tea.Batch(load, m.spin.Tick)
@@ -142,6 +141,26 @@ func (m *ImagePreviewModel) Init() tea.Cmd {
 
 func (m *ImagePreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case previewReadyMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err != nil || msg.preview == nil {
+			return m, nil
+		}
+		preview := msg.preview
+		m.cacheHit = preview.CacheHit
+		m.kind, m.metadata = preview.Kind, preview.Metadata
+		if preview.FilePath == "" {
+			m.card = preview.RenderedData
+			return m, nil
+		}
+		m.rendered = preview.RenderedData
+		m.imgPixelW = preview.DisplaySize.Width
+		m.imgPixelH = preview.DisplaySize.Height
+		m.imgCols = preview.RenderCols
+		m.imgRows = preview.RenderRows
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "esc", "p", "P":
@@ -167,14 +186,16 @@ func (m *ImagePreviewModel) View() string {
 		Align(lipgloss.Center).
 		Bold(true).
 		Foreground(lipgloss.Color(theme.ColorBrightCyan)).
-		Render("🖼 " + filename)
+		Render(m.icon() + " " + filename)
 
 	// Second line: loading/meta (centered)
 	var statusText string
 	if m.loading {
-		statusText = fmt.Sprintf("%s Loading image preview…", m.spin.View())
+		statusText = fmt.Sprintf("%s Loading %s preview…", m.spin.View(), m.kindName())
 	} else if m.err != nil {
 		statusText = theme.CreateErrorStyle().Render(fmt.Sprintf("Failed to render: %v", m.err))
+	} else if m.metadata != nil {
+		statusText = m.mediaSummary()
 	} else if m.imgPixelW > 0 && m.imgPixelH > 0 && m.file.Size > 0 {
 		statusText = fmt.Sprintf("%dx%d  •  %s", m.imgPixelW, m.imgPixelH, formatFileSize(m.file.Size))
 	}
@@ -271,7 +292,60 @@ func (m *ImagePreviewModel) View() string {
 	if imageBlock != "" {
 		b.WriteString(imageBlock)
 	}
+	if m.card != "" && !m.loading && m.err == nil {
+		card := theme.CreateCardStyle(0).Render(m.card)
+		b.WriteString("\n")
+		b.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, card))
+	}
 	return b.String()
 }
 
+// icon returns the title icon for the previewed kind of file
+func (m *ImagePreviewModel) icon() string {
+	switch img.DetectMediaKind(m.file.ContentType) {
+	case img.MediaPDF:
+		return "📄"
+	case img.MediaVideo:
+		return "🎬"
+	}
+	return "🖼"
+}
+
+// kindName names the previewed kind of file in the loading line
+func (m *ImagePreviewModel) kindName() string {
+	switch img.DetectMediaKind(m.file.ContentType) {
+	case img.MediaPDF:
+		return "PDF"
+	case img.MediaVideo:
+		return "video"
+	}
+	return "image"
+}
+
+// mediaSummary describes a PDF or video in the status line
+func (m *ImagePreviewModel) mediaSummary() string {
+	parts := []string{}
+	switch {
+	case m.card != "":
+		parts = append(parts, "no thumbnail")
+	case m.kind == img.MediaPDF:
+		parts = append(parts, "first page")
+	case m.kind == img.MediaVideo:
+		parts = append(parts, "poster frame")
+	}
+	if m.metadata.Pages > 0 {
+		parts = append(parts, fmt.Sprintf("%d pages", m.metadata.Pages))
+	}
+	if m.metadata.Duration > 0 {
+		parts = append(parts, img.FormatDuration(m.metadata.Duration))
+	}
+	if m.metadata.Width > 0 && m.metadata.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%dx%d", m.metadata.Width, m.metadata.Height))
+	}
+	if m.file.Size > 0 {
+		parts = append(parts, formatFileSize(m.file.Size))
+	}
+	return strings.Join(parts, "  •  ")
+}
+
 // modalClosedMsg is sent to parent model when preview modal is closed
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// renameCompletedMsg is sent when a rename finishes
+type renameCompletedMsg struct {
+	from string
+	to   string
+	err  error
+}
+
+// showRenameInput opens the input popup prefilled with the selected key
+func (m *FileBrowserModel) showRenameInput() {
+	file, ok := m.selectedFile()
+	if !ok {
+		m.setMessage("Select a file to rename", messaging.MessageWarning)
+		return
+	}
+
+	m.renameSource = file.Key
+	m.showInput = true
+	m.inputMode = InputModeRename
+	m.inputComponentMode = InputComponentText
+	m.inputPrompt = fmt.Sprintf("Rename '%s' to:", file.Key)
+	m.textInput.SetValue(file.Key)
+	m.textInput.Placeholder = "Enter new key... (end with '/' to move into a folder)"
+	m.textInput.Focus()
+}
+
+// processRenameInput validates the new key and starts the rename
+func (m *FileBrowserModel) processRenameInput() (tea.Model, tea.Cmd) {
+	m.showInput = false
+	m.inputMode = InputModeNone
+
+	target := renameTarget(m.renameSource, strings.TrimSpace(m.textInput.Value()))
+	m.textInput.SetValue("")
+	m.textInput.Blur()
+
+	if target == "" || target == m.renameSource {
+		m.renameSource = ""
+		m.setMessage("Rename cancelled", messaging.MessageInfo)
+		return m, nil
+	}
+
+	m.renaming = true
+	m.setMessage(fmt.Sprintf("Renaming %s to %s...", m.renameSource, target), messaging.MessageWarning)
+	return m, m.renameFile(m.renameSource, target)
+}
+
+// renameTarget resolves the new key; a value ending in "/" keeps the file name
+func renameTarget(source, value string) string {
+	if value == "" {
+		return ""
+	}
+	if strings.HasSuffix(value, "/") {
+		return value + source[strings.LastIndex(source, "/")+1:]
+	}
+	return value
+}
+
+// renameFile copies from to to on the server side, then deletes from
+func (m *FileBrowserModel) renameFile(from, to string) tea.Cmd {
+	bucket := m.bucketName
+	return func() tea.Msg {
+		storage := m.client.Storage()
+		ctx := context.Background()
+
+		if _, err := utils.NewObjectCopier(storage).Copy(ctx, bucket, from, bucket, to, nil); err != nil {
+			return renameCompletedMsg{from: from, to: to, err: err}
+		}
+
+		if err := storage.Delete(ctx, bucket, from); err != nil {
+			logrus.Errorf("Copied %s to %s but failed to delete the original: %v", from, to, err)
+			return renameCompletedMsg{from: from, to: to, err: fmt.Errorf("copied to %s but failed to delete original: %w", to, err)}
+		}
+
+		return renameCompletedMsg{from: from, to: to}
+	}
+}
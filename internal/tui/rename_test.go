@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileBrowser_RenamePrompt 测试重命名输入框预填当前 key
+func TestFileBrowser_RenamePrompt(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.cursor = 2
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	fb := updated.(*FileBrowserModel)
+	require.True(t, fb.showInput)
+	assert.Equal(t, InputModeRename, fb.inputMode)
+	assert.Equal(t, "b.png", fb.renameSource)
+	assert.Equal(t, "b.png", fb.textInput.Value())
+
+	// 未修改名称时不发起请求
+	updated, cmd := fb.processRenameInput()
+	fb = updated.(*FileBrowserModel)
+	assert.Nil(t, cmd)
+	assert.False(t, fb.renaming)
+	assert.Empty(t, fb.renameSource)
+}
+
+// TestFileBrowser_RenameRejectsFolder 测试目录行不能重命名
+func TestFileBrowser_RenameRejectsFolder(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.cursor = 0
+
+	model.showRenameInput()
+	assert.False(t, model.showInput)
+	assert.Empty(t, model.renameSource)
+}
+
+// TestRenameTarget 测试目标 key 解析
+func TestRenameTarget(t *testing.T) {
+	assert.Equal(t, "photos/dog.png", renameTarget("photos/cat.png", "photos/dog.png"))
+	assert.Equal(t, "archive/cat.png", renameTarget("photos/cat.png", "archive/"))
+	assert.Equal(t, "archive/top.png", renameTarget("top.png", "archive/"))
+	assert.Equal(t, "", renameTarget("photos/cat.png", ""))
+}
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// searchPageSize is the List page size used while scanning
+const searchPageSize = 1000
+
+// searchSpec is a parsed search query. The query is a pattern followed or
+// preceded by optional filters:
+//
+//	logo                 substring match on the key (case-insensitive)
+//	*.png  img/**/*.jpg  glob match (see utils.MatchGlob)
+//	/^logs/.*\.gz$/      regular expression between slashes
+//	size>10MB size<1GB   size filters
+//	after:2024-01-01     modified on or after a date
+//	before:2024-06-30    modified before a date
+//	type:image           file category (image, video, text, ...)
+type searchSpec struct {
+	substring  string
+	glob       string
+	regex      *regexp.Regexp
+	minSize    int64
+	maxSize    int64
+	hasMaxSize bool
+	after      time.Time
+	before     time.Time
+	category   string
+}
+
+// parseSearchQuery parses a search query into a searchSpec
+func parseSearchQuery(query string) (*searchSpec, error) {
+	spec := &searchSpec{}
+	var pattern []string
+
+	for _, field := range strings.Fields(query) {
+		lower := strings.ToLower(field)
+		switch {
+		case strings.HasPrefix(lower, "size>"):
+			size, err := parseSize(field[len("size>"):])
+			if err != nil {
+				return nil, err
+			}
+			spec.minSize = size + 1
+		case strings.HasPrefix(lower, "size<"):
+			size, err := parseSize(field[len("size<"):])
+			if err != nil {
+				return nil, err
+			}
+			if size == 0 {
+				return nil, fmt.Errorf("invalid size filter: %s", field)
+			}
+			spec.maxSize = size - 1
+			spec.hasMaxSize = true
+		case strings.HasPrefix(lower, "after:"):
+			date, err := time.ParseInLocation("2006-01-02", field[len("after:"):], time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date in %s (use YYYY-MM-DD)", field)
+			}
+			spec.after = date
+		case strings.HasPrefix(lower, "before:"):
+			date, err := time.ParseInLocation("2006-01-02", field[len("before:"):], time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date in %s (use YYYY-MM-DD)", field)
+			}
+			spec.before = date
+		case strings.HasPrefix(lower, "type:"):
+			spec.category = lower[len("type:"):]
+		default:
+			pattern = append(pattern, field)
+		}
+	}
+
+	text := strings.Join(pattern, " ")
+	switch {
+	case len(text) >= 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/"):
+		re, err := regexp.Compile(text[1 : len(text)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		spec.regex = re
+	case strings.ContainsAny(text, "*?["):
+		if err := utils.ValidateGlob(text); err != nil {
+			return nil, err
+		}
+		spec.glob = text
+	default:
+		spec.substring = strings.ToLower(text)
+	}
+
+	return spec, nil
+}
+
+// parseSize parses sizes like 512, 10KB, 1.5MB or 2G (1024-based)
+func parseSize(value string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := float64(1)
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(upper, unit.suffix) {
+			upper = strings.TrimSuffix(upper, unit.suffix)
+			multiplier = unit.factor
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(upper, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(number * multiplier), nil
+}
+
+// Match reports whether file satisfies the pattern and all filters
+func (s *searchSpec) Match(file FileItem) bool {
+	switch {
+	case s.regex != nil:
+		if !s.regex.MatchString(file.Key) {
+			return false
+		}
+	case s.glob != "":
+		if !utils.MatchGlob(s.glob, file.Key) {
+			return false
+		}
+	case s.substring != "":
+		if !strings.Contains(strings.ToLower(file.Key), s.substring) {
+			return false
+		}
+	}
+
+	if file.Size < s.minSize {
+		return false
+	}
+	if s.hasMaxSize && file.Size > s.maxSize {
+		return false
+	}
+	if !s.after.IsZero() && file.LastModified.Before(s.after) {
+		return false
+	}
+	if !s.before.IsZero() && !file.LastModified.Before(s.before) {
+		return false
+	}
+	if s.category != "" && file.Category != s.category {
+		return false
+	}
+	return true
+}
+
+// searchPageMsg carries the matches found in one scanned page
+type searchPageMsg struct {
+	generation int
+	matches    []FileItem
+	scanned    int
+	nextToken  string
+	done       bool
+	err        error
+}
+
+// startSearch cancels any running scan and starts scanning from the first page
+func (m *FileBrowserModel) startSearch() tea.Cmd {
+	m.cancelSearch()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.searchCtx = ctx
+	m.searchCancel = cancel
+	m.searchGeneration++
+	m.searchScanning = true
+	m.searchScanned = 0
+	m.files = nil
+	m.cursor = 0
+	m.currentPage = 1
+	m.estimatedTotalPages = 1
+	m.hasNextPage = false
+	m.continuationToken = ""
+	m.loading = false
+	m.error = nil
+	m.updateTable()
+
+	return m.searchPageCmd(ctx, m.searchGeneration, m.searchSpec, "")
+}
+
+// cancelSearch stops a running scan, keeping the matches found so far
+func (m *FileBrowserModel) cancelSearch() {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+	}
+	m.searchScanning = false
+}
+
+// searchPageCmd scans one page below the current prefix and filters it
+func (m *FileBrowserModel) searchPageCmd(ctx context.Context, generation int, spec *searchSpec, token string) tea.Cmd {
+	bucket := m.bucketName
+	prefix := m.prefix
+	return func() tea.Msg {
+		page, err := m.client.Storage().List(ctx, bucket, r2.ListOptions{
+			Prefix:            prefix,
+			MaxKeys:           searchPageSize,
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return searchPageMsg{generation: generation, done: true, err: err}
+		}
+
+		var matches []FileItem
+		for _, obj := range page.Objects {
+			key := obj.Key
+			contentType, err := utils.DetectContentType(key, nil)
+			if err != nil {
+				contentType = "application/octet-stream"
+			}
+			file := FileItem{
+				Key:          key,
+				Size:         obj.Size,
+				LastModified: obj.LastModified,
+				ContentType:  contentType,
+				Category:     utils.GetFileCategory(contentType),
+				ETag:         obj.ETag,
+			}
+			if spec.Match(file) {
+				matches = append(matches, file)
+			}
+		}
+
+		return searchPageMsg{
+			generation: generation,
+			matches:    matches,
+			scanned:    len(page.Objects),
+			nextToken:  page.NextToken,
+			done:       page.NextToken == "",
+		}
+	}
+}
+
+// handleSearchPage appends matches from a scanned page and requests the next one
+func (m *FileBrowserModel) handleSearchPage(msg searchPageMsg) tea.Cmd {
+	// Results from a cancelled or replaced scan
+	if msg.generation != m.searchGeneration || !m.searchScanning {
+		return nil
+	}
+
+	if msg.err != nil {
+		m.cancelSearch()
+		if errors.Is(msg.err, context.Canceled) {
+			return nil
+		}
+		logrus.Errorf("Search scan failed: %v", msg.err)
+		m.setMessage(theme.FormatErrorMessage("Search", msg.err), messaging.MessageError)
+		return nil
+	}
+
+	m.files = append(m.files, msg.matches...)
+	m.searchScanned += msg.scanned
+	if len(msg.matches) > 0 {
+		m.updateTable()
+		m.fileTable.SetCursor(m.cursor)
+	}
+
+	if msg.done {
+		m.cancelSearch()
+		m.setMessage(fmt.Sprintf("Found %d matches in %d objects", len(m.files), m.searchScanned), messaging.MessageInfo)
+		return nil
+	}
+
+	return m.searchPageCmd(m.searchCtx, msg.generation, m.searchSpec, msg.nextToken)
+}
+
+// searchCounter renders the live match counter shown below the table
+func (m *FileBrowserModel) searchCounter() string {
+	counter := fmt.Sprintf("Matches: %d | Scanned: %d objects", len(m.files), m.searchScanned)
+	if m.searchScanning {
+		counter += fmt.Sprintf(" %s (esc: stop)", m.spinner.View())
+	}
+	return counter
+}
+
+// emptyListText returns the placeholder shown when the table has no rows
+func (m *FileBrowserModel) emptyListText() string {
+	if m.isSearchMode && m.searchScanning {
+		return "Searching...\n" + m.searchCounter()
+	}
+	if m.isSearchMode {
+		return "No matches\n" + m.searchCounter()
+	}
+	return "No files found"
+}
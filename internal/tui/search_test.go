@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{"512", 512, false},
+		{"10KB", 10 * 1024, false},
+		{"1.5MB", 1536 * 1024, false},
+		{"2g", 2 << 30, false},
+		{"abc", 0, true},
+		{"-1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := parseSize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestSearchSpec_Match(t *testing.T) {
+	logo := FileItem{
+		Key:          "assets/img/Logo.png",
+		Size:         2 << 20,
+		LastModified: time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local),
+		Category:     "image",
+	}
+	notes := FileItem{
+		Key:          "docs/notes.txt",
+		Size:         100,
+		LastModified: time.Date(2023, 5, 1, 12, 0, 0, 0, time.Local),
+		Category:     "text",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		logo  bool
+		notes bool
+	}{
+		{"子串不区分大小写", "logo", true, false},
+		{"glob 匹配文件名", "*.png", true, false},
+		{"glob 匹配路径", "docs/**", false, true},
+		{"正则", `/^docs/.*\.txt$/`, false, true},
+		{"大小过滤", "size>1MB", true, false},
+		{"大小上限", "size<1KB", false, true},
+		{"只匹配空文件", "size<1", false, false},
+		{"日期过滤", "after:2024-01-01", true, false},
+		{"日期上限", "before:2024-01-01", false, true},
+		{"类型过滤", "type:text", false, true},
+		{"组合条件", "img type:image size<1MB", false, false},
+		{"空查询匹配全部", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := parseSearchQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.logo, spec.Match(logo))
+			assert.Equal(t, tt.notes, spec.Match(notes))
+		})
+	}
+	spec, err := parseSearchQuery("size<1")
+	require.NoError(t, err)
+	assert.True(t, spec.Match(FileItem{Key: "empty.txt"}))
+}
+
+func TestParseSearchQuery_Invalid(t *testing.T) {
+	for _, query := range []string{"/[a-/", "size>big", "after:yesterday", "[a-"} {
+		_, err := parseSearchQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+// TestFileBrowser_SearchPages 测试扫描结果累积和过期结果丢弃
+func TestFileBrowser_SearchPages(t *testing.T) {
+	model := createTestFileBrowser()
+	model.isSearchMode = true
+	model.searchSpec, _ = parseSearchQuery("logo")
+	model.searchCtx, model.searchCancel = context.WithCancel(context.Background())
+	model.searchGeneration = 2
+	model.searchScanning = true
+
+	// 来自旧扫描的结果被丢弃
+	cmd := model.handleSearchPage(searchPageMsg{generation: 1, matches: []FileItem{{Key: "old/logo.png"}}, scanned: 10})
+	assert.Nil(t, cmd)
+	assert.Empty(t, model.files)
+
+	// 未完成的页面继续请求下一页
+	cmd = model.handleSearchPage(searchPageMsg{generation: 2, matches: []FileItem{{Key: "a/logo.png"}}, scanned: 1000, nextToken: "next"})
+	assert.NotNil(t, cmd)
+	assert.Len(t, model.files, 1)
+	assert.Equal(t, 1000, model.searchScanned)
+	assert.True(t, model.searchScanning)
+
+	// 最后一页结束扫描
+	cmd = model.handleSearchPage(searchPageMsg{generation: 2, matches: []FileItem{{Key: "b/logo.svg"}}, scanned: 5, done: true})
+	assert.Nil(t, cmd)
+	assert.Len(t, model.files, 2)
+	assert.Equal(t, 1005, model.searchScanned)
+	assert.False(t, model.searchScanning)
+	assert.Contains(t, model.searchCounter(), "Matches: 2")
+}
@@ -0,0 +1,316 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/messaging"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+	"github.com/HaiFongPan/r2s3-cli/internal/utils"
+)
+
+// maxDeleteBatchSize is the DeleteObjects limit per request
+const maxDeleteBatchSize = 1000
+
+// deleteBatchTimeout bounds each DeleteMany request
+const deleteBatchTimeout = 60 * time.Second
+
+// maxConfirmListItems is the number of keys listed in the batch delete dialog
+const maxConfirmListItems = 8
+
+// batchDownloadCompletedMsg is sent when a batch download finishes
+type batchDownloadCompletedMsg struct {
+	downloaded int
+	dir        string
+	err        error
+}
+
+// isSelected reports whether key is marked
+func (m *FileBrowserModel) isSelected(key string) bool {
+	_, ok := m.selected[key]
+	return ok
+}
+
+// toggleSelection marks or unmarks a file; folder rows cannot be marked
+func (m *FileBrowserModel) toggleSelection(file FileItem) {
+	if file.IsDir {
+		return
+	}
+	if m.selected == nil {
+		m.selected = make(map[string]FileItem)
+	}
+	if m.isSelected(file.Key) {
+		delete(m.selected, file.Key)
+	} else {
+		m.selected[file.Key] = file
+	}
+}
+
+// selectAllOnPage marks every file on the current page
+func (m *FileBrowserModel) selectAllOnPage() {
+	for _, file := range m.files {
+		if !file.IsDir && !m.isSelected(file.Key) {
+			m.toggleSelection(file)
+		}
+	}
+}
+
+// invertSelectionOnPage flips the mark of every file on the current page
+func (m *FileBrowserModel) invertSelectionOnPage() {
+	for _, file := range m.files {
+		m.toggleSelection(file)
+	}
+}
+
+// clearSelection unmarks all files
+func (m *FileBrowserModel) clearSelection() {
+	m.selected = nil
+}
+
+// retainSelection keeps only the given keys marked
+func (m *FileBrowserModel) retainSelection(keys []string) {
+	kept := make(map[string]FileItem, len(keys))
+	for _, objectKey := range keys {
+		if file, ok := m.selected[objectKey]; ok {
+			kept[objectKey] = file
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	m.selected = kept
+}
+
+// selectedFiles returns the marked files sorted by key
+func (m *FileBrowserModel) selectedFiles() []FileItem {
+	files := make([]FileItem, 0, len(m.selected))
+	for _, file := range m.selected {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Key < files[j].Key })
+	return files
+}
+
+// selectedSize returns the total size of the marked files
+func (m *FileBrowserModel) selectedSize() int64 {
+	var total int64
+	for _, file := range m.selected {
+		total += file.Size
+	}
+	return total
+}
+
+// renderSelectionStatus renders the selection counter shown in the footer
+func (m *FileBrowserModel) renderSelectionStatus() string {
+	status := fmt.Sprintf("✔ Selected: %d files (%s) • space: toggle • a: all • i: invert • esc: clear",
+		len(m.selected), formatFileSize(m.selectedSize()))
+	return theme.CreateHintStyle().Render(status)
+}
+
+// handleSelectionKey handles the mark/select-all/invert keys
+func (m *FileBrowserModel) handleSelectionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Mark):
+		if len(m.files) == 0 || m.cursor >= len(m.files) {
+			return m, nil
+		}
+		m.toggleSelection(m.files[m.cursor])
+		m.fileTable.MoveDown(1)
+		m.cursor = m.fileTable.Cursor()
+		m.updateRightPanel()
+	case key.Matches(msg, m.keyMap.SelectAll):
+		m.selectAllOnPage()
+	case key.Matches(msg, m.keyMap.Invert):
+		m.invertSelectionOnPage()
+	}
+	m.updateTable()
+	return m, nil
+}
+
+// startBatchDelete opens the confirmation dialog for the marked files
+func (m *FileBrowserModel) startBatchDelete() {
+	m.batchDeleteKeys = m.batchDeleteKeys[:0]
+	for _, file := range m.selectedFiles() {
+		m.batchDeleteKeys = append(m.batchDeleteKeys, file.Key)
+	}
+	m.confirmDelete = true
+	m.deleteTarget = fmt.Sprintf("%d files", len(m.batchDeleteKeys))
+}
+
+// renderBatchDeleteConfirmation lists the keys that will be deleted
+func (m *FileBrowserModel) renderBatchDeleteConfirmation() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Delete %d files (%s):\n\n", len(m.batchDeleteKeys), formatFileSize(m.selectedSize())))
+	for i, objectKey := range m.batchDeleteKeys {
+		if i == maxConfirmListItems {
+			b.WriteString(fmt.Sprintf("  ... and %d more\n", len(m.batchDeleteKeys)-maxConfirmListItems))
+			break
+		}
+		b.WriteString(fmt.Sprintf("  %s\n", objectKey))
+	}
+	b.WriteString("\nThis action cannot be undone!\n\nPress 'y' to confirm, 'n' to cancel")
+	return b.String()
+}
+
+// deleteFiles deletes keys with DeleteMany in batches of up to 1000. Keys
+// that could not be deleted are reported so they stay selected.
+func (m *FileBrowserModel) deleteFiles(keys []string) tea.Cmd {
+	bucket := m.bucketName
+	return func() tea.Msg {
+		storage := m.client.Storage()
+
+		var (
+			errs       []error
+			failedKeys []string
+		)
+		for start := 0; start < len(keys); start += maxDeleteBatchSize {
+			batch := keys[start:min(start+maxDeleteBatchSize, len(keys))]
+
+			ctx, cancel := context.WithTimeout(context.Background(), deleteBatchTimeout)
+			failed, err := storage.DeleteMany(ctx, bucket, batch)
+			cancel()
+			if err != nil {
+				errs = append(errs, err)
+				failedKeys = append(failedKeys, batch...)
+				continue
+			}
+			for _, deleteError := range failed {
+				errs = append(errs, fmt.Errorf("%s: %s", deleteError.Key, deleteError.Message))
+				failedKeys = append(failedKeys, deleteError.Key)
+			}
+		}
+
+		return deleteCompletedMsg{err: errors.Join(errs...), failed: failedKeys}
+	}
+}
+
+// showDownloadDirInput asks where the marked files should be downloaded
+func (m *FileBrowserModel) showDownloadDirInput() {
+	defaultDir := ""
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		defaultDir = filepath.Join(homeDir, "Downloads")
+	}
+
+	m.showInput = true
+	m.inputMode = InputModeDownloadDir
+	m.inputComponentMode = InputComponentText
+	m.inputPrompt = fmt.Sprintf("Download %d files (%s) to directory:", len(m.selected), formatFileSize(m.selectedSize()))
+	m.textInput.SetValue(defaultDir)
+	m.textInput.Placeholder = "Enter local directory..."
+	m.textInput.Focus()
+}
+
+// processDownloadDirInput validates the directory and starts the batch download
+func (m *FileBrowserModel) processDownloadDirInput() (tea.Model, tea.Cmd) {
+	m.showInput = false
+	m.inputMode = InputModeNone
+
+	dir := strings.TrimSpace(m.textInput.Value())
+	m.textInput.SetValue("")
+	m.textInput.Blur()
+
+	if strings.HasPrefix(dir, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(homeDir, dir[2:])
+		}
+	}
+	if dir == "" {
+		m.setMessage("No download directory provided", messaging.MessageError)
+		return m, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		m.setMessage(theme.FormatErrorMessage("Download", err), messaging.MessageError)
+		return m, nil
+	}
+
+	return m, m.downloadFilesWithProgress(m.selectedFiles(), dir)
+}
+
+// downloadFilesWithProgress downloads files one after another into dir,
+// reusing the single-file progress dialog. It must be called from Update: the
+// cancel func is stored on the model before the command runs.
+func (m *FileBrowserModel) downloadFilesWithProgress(files []FileItem, dir string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.downloadCancel = cancel
+
+	return func() tea.Msg {
+		go func() {
+			defer cancel()
+
+			var errs []error
+			downloaded := 0
+			for i, file := range files {
+				if ctx.Err() != nil {
+					errs = append(errs, ctx.Err())
+					break
+				}
+				if i > 0 && m.program != nil {
+					m.program.Send(utils.DownloadStartedMsg{Filename: batchDownloadLabel(file.Key, i, len(files))})
+				}
+
+				localPath := filepath.Join(dir, filepath.Base(file.Key))
+				_, _, err := m.fileDownloader.DownloadToFile(ctx, file.Key, localPath, utils.ConflictRename, func(downloaded, total int64, percentage float64) {
+					if m.program != nil {
+						m.program.Send(utils.DownloadProgressMsg{Progress: percentage / 100})
+					}
+				})
+				if err != nil {
+					logrus.Errorf("Batch download of %s failed: %v", file.Key, err)
+					errs = append(errs, fmt.Errorf("%s: %w", file.Key, err))
+					continue
+				}
+				downloaded++
+			}
+
+			if m.program != nil {
+				m.program.Send(batchDownloadCompletedMsg{downloaded: downloaded, dir: dir, err: errors.Join(errs...)})
+			}
+		}()
+
+		return utils.DownloadStartedMsg{Filename: batchDownloadLabel(files[0].Key, 0, len(files))}
+	}
+}
+
+// batchDownloadLabel returns the progress dialog label for file i of total
+func batchDownloadLabel(key string, i, total int) string {
+	return fmt.Sprintf("%s (%d/%d)", filepath.Base(key), i+1, total)
+}
+
+// copySelectedURLs copies one URL per marked file to the clipboard
+func (m *FileBrowserModel) copySelectedURLs(presigned bool) {
+	var urls []string
+	for _, file := range m.selectedFiles() {
+		if presigned {
+			presignedURL, err := m.urlGenerator.GeneratePresignedURLWithExpiry(file.Key, m.presignExpiryOrDefault())
+			if err != nil {
+				m.setMessage(fmt.Sprintf("Failed to generate presigned URL: %s", err), messaging.MessageError)
+				return
+			}
+			urls = append(urls, presignedURL)
+			continue
+		}
+
+		customURL := m.urlGenerator.GenerateCustomDomainURL(file.Key)
+		if customURL == "" {
+			m.setMessage("No custom domain configured for this bucket", messaging.MessageError)
+			return
+		}
+		urls = append(urls, customURL)
+	}
+
+	if err := utils.CopyToClipboard(strings.Join(urls, "\n")); err != nil {
+		m.setMessage(theme.FormatErrorMessage("Copy", err), messaging.MessageError)
+		return
+	}
+	m.setMessage(fmt.Sprintf("%d URLs copied to clipboard", len(urls)), messaging.MessageInfo)
+}
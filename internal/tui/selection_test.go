@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectionTestFiles() []FileItem {
+	return []FileItem{
+		{Key: "photos/", Category: "folder", IsDir: true},
+		{Key: "a.txt", Size: 100, Category: "text"},
+		{Key: "b.png", Size: 200, Category: "image"},
+		{Key: "c.mp4", Size: 300, Category: "video"},
+	}
+}
+
+// TestFileBrowser_MarkFiles 测试空格标记文件并移动光标
+func TestFileBrowser_MarkFiles(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.updateTableSize(80, 20)
+	model.cursor = 1
+	model.fileTable.SetCursor(1)
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	fb := updated.(*FileBrowserModel)
+	assert.True(t, fb.isSelected("a.txt"))
+	assert.Equal(t, 2, fb.cursor)
+
+	// 目录行不能被标记
+	fb.toggleSelection(fb.files[0])
+	assert.False(t, fb.isSelected("photos/"))
+
+	// 再次标记取消选择
+	fb.toggleSelection(fb.files[1])
+	assert.Empty(t, fb.selected)
+}
+
+// TestFileBrowser_SelectAllAndInvert 测试全选和反选当前页
+func TestFileBrowser_SelectAllAndInvert(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.toggleSelection(model.files[1])
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	fb := updated.(*FileBrowserModel)
+	assert.False(t, fb.isSelected("a.txt"))
+	assert.True(t, fb.isSelected("b.png"))
+	assert.True(t, fb.isSelected("c.mp4"))
+	assert.Equal(t, int64(500), fb.selectedSize())
+
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	fb = updated.(*FileBrowserModel)
+	assert.Len(t, fb.selected, 3)
+	assert.Contains(t, fb.renderSelectionStatus(), "Selected: 3 files")
+
+	// Esc 先清除选择而不是退出
+	updated, cmd := fb.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	fb = updated.(*FileBrowserModel)
+	assert.Nil(t, cmd)
+	assert.Empty(t, fb.selected)
+}
+
+// TestFileBrowser_BatchDeleteConfirmation 测试批量删除确认对话框
+func TestFileBrowser_BatchDeleteConfirmation(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.selectAllOnPage()
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	fb := updated.(*FileBrowserModel)
+	assert.Nil(t, cmd)
+	require.True(t, fb.confirmDelete)
+	assert.Equal(t, []string{"a.txt", "b.png", "c.mp4"}, fb.batchDeleteKeys)
+
+	dialog := fb.renderBatchDeleteConfirmation()
+	assert.Contains(t, dialog, "Delete 3 files")
+	assert.Contains(t, dialog, "b.png")
+
+	// 取消后清空待删除列表但保留选择
+	updated, _ = fb.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	fb = updated.(*FileBrowserModel)
+	assert.False(t, fb.confirmDelete)
+	assert.Empty(t, fb.batchDeleteKeys)
+	assert.Len(t, fb.selected, 3)
+
+	// 确认删除后清空选择
+	fb.startBatchDelete()
+	updated, cmd = fb.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	fb = updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	assert.True(t, fb.deleting)
+
+	// 部分失败时只保留失败的文件并重新加载列表
+	updated, cmd = fb.Update(deleteCompletedMsg{err: assert.AnError, failed: []string{"b.png"}})
+	fb = updated.(*FileBrowserModel)
+	assert.NotNil(t, cmd)
+	assert.True(t, fb.loading)
+	assert.Len(t, fb.selected, 1)
+	assert.Contains(t, fb.selected, "b.png")
+	assert.Empty(t, fb.batchDeleteKeys)
+
+	// 全部成功后清空选择
+	fb.loading = false
+	fb.startBatchDelete()
+	updated, _ = fb.Update(deleteCompletedMsg{})
+	fb = updated.(*FileBrowserModel)
+	assert.Empty(t, fb.selected)
+	assert.Empty(t, fb.batchDeleteKeys)
+}
+
+// TestFileBrowser_BatchDownloadPrompt 测试批量下载目录输入
+func TestFileBrowser_BatchDownloadPrompt(t *testing.T) {
+	model := createTestFileBrowser()
+	model.files = selectionTestFiles()
+	model.selectAllOnPage()
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	fb := updated.(*FileBrowserModel)
+	assert.True(t, fb.showInput)
+	assert.Equal(t, InputModeDownloadDir, fb.inputMode)
+	assert.Contains(t, fb.inputPrompt, "Download 3 files")
+	assert.Equal(t, "a.txt (1/3)", batchDownloadLabel("a.txt", 0, 3))
+}
@@ -0,0 +1,136 @@
+package text
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// 二进制检测只看开头的这一段
+const sniffLen = 8 * 1024
+
+// DetectEncoding 根据开头的字节判断编码。有 BOM 时以 BOM 为准；
+// 含 NUL 或大量控制字符的内容视为二进制；合法 UTF-8 优先，
+// 否则尝试 GB18030，最后退回 Windows-1252
+func DetectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+
+	sample := data
+	if len(sample) > sniffLen {
+		sample = sample[:sniffLen]
+	}
+	if isBinary(sample) {
+		return EncodingBinary
+	}
+
+	// 截断处可能有半个字符，去掉后再校验
+	if utf8.Valid(trimPartialUTF8(sample)) {
+		return EncodingUTF8
+	}
+	if looksLikeGB18030(sample) {
+		return EncodingGB18030
+	}
+	return EncodingLatin1
+}
+
+// isBinary 判断内容是否为二进制：出现 NUL，或控制字符超过 10%
+func isBinary(sample []byte) bool {
+	if len(sample) == 0 {
+		return false
+	}
+	control := 0
+	for _, b := range sample {
+		switch {
+		case b == 0:
+			return true
+		case b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' && b != 0x1b:
+			control++
+		}
+	}
+	return control*10 > len(sample)
+}
+
+// looksLikeGB18030 判断非 UTF-8 内容是否是 GB18030 编码的中文：
+// 必须能完整解码并包含汉字，只允许末尾有一个被截断的字符
+func looksLikeGB18030(sample []byte) bool {
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(sample)
+	if err != nil {
+		return false
+	}
+	text := strings.TrimSuffix(string(decoded), string(utf8.RuneError))
+	if strings.ContainsRune(text, utf8.RuneError) {
+		return false
+	}
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimPartialUTF8 去掉末尾不完整的 UTF-8 字符
+func trimPartialUTF8(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if !utf8.RuneStart(data[len(data)-i]) {
+			continue
+		}
+		if !utf8.FullRune(data[len(data)-i:]) {
+			return data[:len(data)-i]
+		}
+		break
+	}
+	return data
+}
+
+// Decode 把字节按编码转换为 UTF-8 文本。partial 为 true 表示后面还有内容，
+// 此时末尾被截断的字符会被丢弃，等加载更多后再显示
+func Decode(data []byte, enc Encoding, partial bool) string {
+	var decoder encoding.Encoding
+	switch enc {
+	case EncodingUTF8:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		if partial {
+			data = trimPartialUTF8(data)
+		}
+		return strings.ToValidUTF8(string(data), "�")
+	case EncodingUTF16LE:
+		decoder = textunicode.UTF16(textunicode.LittleEndian, textunicode.ExpectBOM)
+	case EncodingUTF16BE:
+		decoder = textunicode.UTF16(textunicode.BigEndian, textunicode.ExpectBOM)
+	case EncodingGB18030:
+		decoder = simplifiedchinese.GB18030
+	case EncodingLatin1:
+		decoder = charmap.Windows1252
+	default:
+		return ""
+	}
+
+	if partial && (enc == EncodingUTF16LE || enc == EncodingUTF16BE) && len(data)%2 == 1 {
+		data = data[:len(data)-1]
+	}
+	decoded, err := decoder.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "�")
+	}
+	return string(decoded)
+}
@@ -0,0 +1,207 @@
+package text
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// 十六进制视图最多显示的字节数
+const maxHexDumpBytes = 4 * 1024
+
+// Document 是已经加载的对象开头部分
+type Document struct {
+	Key      string
+	Language Language
+
+	data  []byte
+	total int64
+}
+
+// NewDocument 创建一个还没有内容的文档，总大小在第一次加载后才知道
+func NewDocument(key string) *Document {
+	return &Document{
+		Key:      key,
+		Language: DetectLanguage(key),
+		total:    -1,
+	}
+}
+
+// withChunk 返回追加了一段内容的新文档
+func (d *Document) withChunk(chunk []byte, total int64) *Document {
+	next := *d
+	next.data = append(d.data[:len(d.data):len(d.data)], chunk...)
+	next.total = total
+	// 对象比声明的短时以实际读到的为准，避免反复请求
+	if len(chunk) == 0 && total > next.Loaded() {
+		next.total = next.Loaded()
+	}
+	return &next
+}
+
+// Loaded 返回已加载的字节数
+func (d *Document) Loaded() int64 {
+	return int64(len(d.data))
+}
+
+// Total 返回对象总大小，未知时为 -1
+func (d *Document) Total() int64 {
+	return d.total
+}
+
+// Complete 报告整个对象是否都已加载
+func (d *Document) Complete() bool {
+	return d.total >= 0 && d.Loaded() >= d.total
+}
+
+// Encoding 返回检测到的编码
+func (d *Document) Encoding() Encoding {
+	return DetectEncoding(d.data)
+}
+
+// Text 返回解码后的文本，二进制内容返回空字符串
+func (d *Document) Text() string {
+	return Decode(d.data, d.Encoding(), !d.Complete())
+}
+
+// RenderOptions 控制渲染方式
+type RenderOptions struct {
+	Width int
+	// Raw 关闭 JSON 格式化和 Markdown 渲染，只保留语法高亮
+	Raw bool
+	// Wrap 折行显示长行，否则截断
+	Wrap bool
+}
+
+// Rendered 是渲染结果
+type Rendered struct {
+	Lines []string
+	// Format 描述实际使用的显示方式，例如 "json (formatted)"
+	Format string
+}
+
+// Render 按语言渲染已加载的内容：JSON 格式化、Markdown 渲染、代码高亮，
+// 二进制内容显示为十六进制
+func (d *Document) Render(opts RenderOptions) Rendered {
+	enc := d.Encoding()
+	if enc == EncodingBinary {
+		return Rendered{Lines: fitLines(hexDump(d.data), opts), Format: "binary (hex)"}
+	}
+
+	content := expandTabs(d.Text())
+	lines := splitLines(content)
+	format := string(d.Language)
+
+	switch {
+	case d.Language == LangJSON && !opts.Raw:
+		if pretty, ok := prettyJSON(content, d.Complete()); ok {
+			lines = splitLines(pretty)
+			format = "json (formatted)"
+		}
+		lines = Highlight(lines, LangJSON)
+	case d.Language == LangMarkdown && !opts.Raw:
+		lines = RenderMarkdown(lines)
+		format = "markdown (rendered)"
+	default:
+		lines = Highlight(lines, d.Language)
+	}
+
+	if enc != EncodingUTF8 {
+		format += ", " + string(enc)
+	}
+	return Rendered{Lines: fitLines(lines, opts), Format: format}
+}
+
+// prettyJSON 格式化完整的 JSON 文档。只加载了一部分时无法解析，保持原样
+func prettyJSON(content string, complete bool) (string, bool) {
+	if !complete {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(content), "", "  "); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// fitLines 按宽度折行或截断
+func fitLines(lines []string, opts RenderOptions) []string {
+	if opts.Width <= 0 {
+		return lines
+	}
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if ansi.StringWidth(line) <= opts.Width {
+			out = append(out, line)
+			continue
+		}
+		if opts.Wrap {
+			out = append(out, strings.Split(ansi.Wrap(line, opts.Width, ""), "\n")...)
+		} else {
+			out = append(out, ansi.Truncate(line, opts.Width, "…"))
+		}
+	}
+	return out
+}
+
+func splitLines(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	return strings.Split(content, "\n")
+}
+
+// expandTabs 把制表符展开成空格，避免终端宽度计算出错
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			spaces := 4 - col%4
+			b.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+		case '\n':
+			b.WriteRune(r)
+			col = 0
+		default:
+			b.WriteRune(r)
+			col++
+		}
+	}
+	return b.String()
+}
+
+// hexDump 生成 "偏移  十六进制  ASCII" 格式的视图
+func hexDump(data []byte) []string {
+	if len(data) > maxHexDumpBytes {
+		data = data[:maxHexDumpBytes]
+	}
+	lines := make([]string, 0, len(data)/16+1)
+	for offset := 0; offset < len(data); offset += 16 {
+		row := data[offset:min(offset+16, len(data))]
+		var hex, ascii strings.Builder
+		for i := 0; i < 16; i++ {
+			if i < len(row) {
+				fmt.Fprintf(&hex, "%02x ", row[i])
+				if row[i] >= 0x20 && row[i] < 0x7f {
+					ascii.WriteByte(row[i])
+				} else {
+					ascii.WriteByte('.')
+				}
+			} else {
+				hex.WriteString("   ")
+			}
+			if i == 7 {
+				hex.WriteByte(' ')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%08x  %s |%s|", offset, hex.String(), ascii.String()))
+	}
+	return lines
+}
@@ -0,0 +1,144 @@
+package text
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// plainLines 去掉 ANSI 颜色，方便比较文本内容
+func plainLines(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = ansi.Strip(line)
+	}
+	return out
+}
+
+func TestDetectLanguage(t *testing.T) {
+	assert.Equal(t, LangGo, DetectLanguage("src/main.go"))
+	assert.Equal(t, LangJSON, DetectLanguage("data/config.JSON"))
+	assert.Equal(t, LangMarkdown, DetectLanguage("README.md"))
+	assert.Equal(t, LangLog, DetectLanguage("logs/app.log"))
+	assert.Equal(t, LangShell, DetectLanguage("build/Dockerfile"))
+	assert.Equal(t, LangPlain, DetectLanguage("notes"))
+}
+
+func TestIsTextFile(t *testing.T) {
+	assert.True(t, IsTextFile("a.txt", "text/plain; charset=utf-8"))
+	assert.True(t, IsTextFile("data.bin", "application/json"))
+	assert.True(t, IsTextFile("main.go", ""))
+	assert.True(t, IsTextFile("app.log", "application/octet-stream"))
+	assert.False(t, IsTextFile("video.mp4", "video/mp4"))
+	assert.False(t, IsTextFile("archive.zip", "application/zip"))
+}
+
+func TestDetectEncoding(t *testing.T) {
+	gbk, err := simplifiedchinese.GB18030.NewEncoder().String("你好，世界")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+		want Encoding
+	}{
+		{"ASCII", []byte("hello\n"), EncodingUTF8},
+		{"UTF-8 中文", []byte("你好"), EncodingUTF8},
+		{"UTF-8 BOM", append([]byte{0xEF, 0xBB, 0xBF}, "hi"...), EncodingUTF8},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, EncodingUTF16LE},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, EncodingUTF16BE},
+		{"GB18030", []byte(gbk), EncodingGB18030},
+		{"Latin-1", []byte("caf\xe9 cr\xe8me"), EncodingLatin1},
+		{"NUL 字节", []byte("PK\x03\x04\x00\x00"), EncodingBinary},
+		{"空内容", nil, EncodingUTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectEncoding(tt.data))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	assert.Equal(t, "hi", Decode([]byte{0xFF, 0xFE, 'h', 0, 'i', 0}, EncodingUTF16LE, false))
+	assert.Equal(t, "café", Decode([]byte("caf\xe9"), EncodingLatin1, false))
+
+	// 分段加载时末尾被截断的字符要等下一段
+	data := []byte("好")
+	assert.Equal(t, "a", Decode(append([]byte("a"), data[:2]...), EncodingUTF8, true))
+	assert.Equal(t, "a"+"�", Decode(append([]byte("a"), data[:2]...), EncodingUTF8, false))
+}
+
+func TestDocument_RenderJSON(t *testing.T) {
+	doc := NewDocument("data.json").withChunk([]byte(`{"name":"r2","tags":["a","b"]}`), 30)
+	require.True(t, doc.Complete())
+
+	rendered := doc.Render(RenderOptions{})
+	assert.Equal(t, "json (formatted)", rendered.Format)
+	assert.Equal(t, []string{
+		`{`,
+		`  "name": "r2",`,
+		`  "tags": [`,
+		`    "a",`,
+		`    "b"`,
+		`  ]`,
+		`}`,
+	}, plainLines(rendered.Lines))
+
+	// Raw 模式和未加载完的文档都保持原样
+	assert.Equal(t, []string{`{"name":"r2","tags":["a","b"]}`}, plainLines(doc.Render(RenderOptions{Raw: true}).Lines))
+	partial := NewDocument("data.json").withChunk([]byte(`{"name":`), 100)
+	assert.Equal(t, "json", partial.Render(RenderOptions{}).Format)
+}
+
+func TestDocument_RenderWrapAndTruncate(t *testing.T) {
+	doc := NewDocument("notes.txt").withChunk([]byte("0123456789\nshort\n"), 17)
+
+	assert.Equal(t, []string{"012345", "6789", "short"}, plainLines(doc.Render(RenderOptions{Width: 6, Wrap: true}).Lines))
+	assert.Equal(t, []string{"01234…", "short"}, plainLines(doc.Render(RenderOptions{Width: 6}).Lines))
+}
+
+func TestDocument_RenderBinary(t *testing.T) {
+	doc := NewDocument("data.txt").withChunk([]byte("\x00\x01ABC"), 5)
+
+	rendered := doc.Render(RenderOptions{})
+	assert.Equal(t, "binary (hex)", rendered.Format)
+	require.Len(t, rendered.Lines, 1)
+	assert.True(t, strings.HasPrefix(rendered.Lines[0], "00000000  00 01 41 42 43"))
+	assert.True(t, strings.HasSuffix(rendered.Lines[0], "|..ABC|"))
+}
+
+func TestDocument_RenderTabsAndCRLF(t *testing.T) {
+	doc := NewDocument("a.txt").withChunk([]byte("a\tb\r\nc"), 6)
+	assert.Equal(t, []string{"a   b", "c"}, plainLines(doc.Render(RenderOptions{}).Lines))
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	lines := plainLines(RenderMarkdown([]string{
+		"# Title",
+		"Some **bold**, *em* and `code_with_underscores`.",
+		"- item",
+		"- [x] done",
+		"> quoted",
+		"---",
+		"```go",
+		"func main() {}",
+		"```",
+		"See [docs](https://example.com).",
+	}))
+
+	assert.Equal(t, "Title", lines[0])
+	assert.Equal(t, "Some bold, em and code_with_underscores.", lines[1])
+	assert.Equal(t, "• item", lines[2])
+	assert.Equal(t, "☑ done", lines[3])
+	assert.Equal(t, "┃ quoted", lines[4])
+	assert.True(t, strings.HasPrefix(lines[5], "───"))
+	assert.True(t, strings.HasPrefix(lines[6], "┌─ go"))
+	assert.Equal(t, "│ func main() {}", lines[7])
+	assert.True(t, strings.HasPrefix(lines[8], "└"))
+	assert.Equal(t, "See docs (https://example.com).", lines[9])
+}
@@ -0,0 +1,431 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+)
+
+// tokenKind 是高亮后的片段类型
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenComment
+	tokenString
+	tokenNumber
+	tokenKeyword
+	tokenConstant
+	tokenKey
+	tokenTag
+	tokenError
+	tokenWarning
+	tokenInfo
+	tokenMuted
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var tokenStyles = map[tokenKind]lipgloss.Style{
+	tokenComment:  lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightBlack)).Italic(true),
+	tokenString:   lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightGreen)),
+	tokenNumber:   lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow)),
+	tokenKeyword:  lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightBlue)).Bold(true),
+	tokenConstant: lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow)),
+	tokenKey:      lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightCyan)),
+	tokenTag:      lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightCyan)),
+	tokenError:    lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightRed)).Bold(true),
+	tokenWarning:  lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow)).Bold(true),
+	tokenInfo:     lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightGreen)),
+	tokenMuted:    lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorHint)),
+}
+
+// syntax 描述一种语言的词法规则
+type syntax struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	keywords     map[string]bool
+	constants    map[string]bool
+	// JSON 中冒号前的字符串是键
+	stringKeys bool
+}
+
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+var syntaxes = map[Language]*syntax{
+	LangJSON: {
+		quotes:     `"`,
+		constants:  words("true false null"),
+		stringKeys: true,
+	},
+	LangGo: {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var`),
+		constants: words("true false nil iota"),
+	},
+	LangPython: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield`),
+		constants: words("True False None self"),
+	},
+	LangJavaScript: {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		keywords: words(`async await break case catch class const continue default delete do else export
+			extends finally for from function if import in instanceof interface let new of return static
+			switch throw try type typeof var void while yield`),
+		constants: words("true false null undefined this NaN"),
+	},
+	LangShell: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		keywords: words(`if then else elif fi for while until do done case esac function in return export
+			local set unset source FROM RUN CMD COPY ADD ENV ARG WORKDIR EXPOSE ENTRYPOINT USER LABEL`),
+		constants: words("true false"),
+	},
+	LangYAML: {
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		constants:    words("true false null yes no on off ~"),
+	},
+	LangTOML: {
+		lineComments: []string{"#", ";"},
+		quotes:       `"'`,
+		constants:    words("true false"),
+	},
+	LangCSS: {
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keywords:     words("@media @import @font-face @keyframes @supports !important"),
+	},
+	LangSQL: {
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `'"`,
+		keywords: words(`select from where insert into values update set delete create table drop alter
+			index join left right inner outer on group by order having limit offset as and or not in is
+			primary key foreign references default union all distinct case when then else end
+			SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER INDEX JOIN LEFT
+			RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AS AND OR NOT IN IS PRIMARY KEY FOREIGN
+			REFERENCES DEFAULT UNION ALL DISTINCT CASE WHEN THEN ELSE END`),
+		constants: words("null true false NULL TRUE FALSE"),
+	},
+	LangCLike: {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+		keywords: words(`auto break case catch char class const continue default do double else enum
+			extends extern final float for fun if implements import int long namespace new override
+			package private protected public return short signed sizeof static struct switch template
+			this throw try typedef union unsigned val var virtual void volatile while #include #define`),
+		constants: words("true false null nullptr NULL"),
+	},
+	LangRust: {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		keywords: words(`as async await break const continue crate dyn else enum extern fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait type unsafe use
+			where while`),
+		constants: words("true false None Some Ok Err"),
+	},
+}
+
+// highlighter 逐行高亮，并在行之间保留块注释状态
+type highlighter struct {
+	lang    Language
+	syntax  *syntax
+	inBlock bool
+}
+
+func newHighlighter(lang Language) *highlighter {
+	return &highlighter{lang: lang, syntax: syntaxes[lang]}
+}
+
+// Highlight 按语言给每一行加上 ANSI 颜色
+func Highlight(lines []string, lang Language) []string {
+	h := newHighlighter(lang)
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = renderTokens(h.tokenize(line))
+	}
+	return out
+}
+
+func renderTokens(tokens []token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		if style, ok := tokenStyles[t.kind]; ok {
+			b.WriteString(style.Render(t.text))
+		} else {
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+var (
+	yamlKeyPattern    = regexp.MustCompile(`^(\s*(?:-\s+)?)([^\s#'"\-][^#:]*?|"[^"]*"|'[^']*')(:)(\s|$)`)
+	tomlKeyPattern    = regexp.MustCompile(`^(\s*)([A-Za-z0-9_.\-"]+)(\s*=)`)
+	tomlTablePattern  = regexp.MustCompile(`^\s*\[.*\]\s*$`)
+	logLevelPattern   = regexp.MustCompile(`\b(FATAL|PANIC|ERROR|ERR|WARNING|WARN|INFO|DEBUG|TRACE|fatal|panic|error|warning|warn|info|debug|trace)\b`)
+	logTimePattern    = regexp.MustCompile(`^\[?\d{4}[-/]\d{2}[-/]\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?\]?`)
+	markupTagPattern  = regexp.MustCompile(`</?[A-Za-z!?][^>]*>?`)
+	markupAttrPattern = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+)
+
+func (h *highlighter) tokenize(line string) []token {
+	switch h.lang {
+	case LangLog:
+		return tokenizeLog(line)
+	case LangMarkup:
+		return h.tokenizeMarkup(line)
+	case LangYAML:
+		if m := yamlKeyPattern.FindStringSubmatchIndex(line); m != nil && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			tokens := []token{{tokenText, line[:m[3]]}, {tokenKey, line[m[4]:m[5]]}, {tokenText, line[m[6]:m[7]]}}
+			return append(tokens, h.tokenizeCode(line[m[7]:])...)
+		}
+	case LangTOML:
+		if tomlTablePattern.MatchString(line) {
+			return []token{{tokenKeyword, line}}
+		}
+		if m := tomlKeyPattern.FindStringSubmatchIndex(line); m != nil {
+			tokens := []token{{tokenText, line[:m[3]]}, {tokenKey, line[m[4]:m[5]]}, {tokenText, line[m[6]:m[7]]}}
+			return append(tokens, h.tokenizeCode(line[m[7]:])...)
+		}
+	}
+	if h.syntax == nil {
+		return []token{{tokenText, line}}
+	}
+	return h.tokenizeCode(line)
+}
+
+// tokenizeCode 是通用的词法扫描：注释、字符串、数字、关键字和常量
+func (h *highlighter) tokenizeCode(line string) []token {
+	s := h.syntax
+	if s == nil {
+		return []token{{tokenText, line}}
+	}
+
+	var tokens []token
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			tokens = append(tokens, token{tokenText, plain.String()})
+			plain.Reset()
+		}
+	}
+	emit := func(kind tokenKind, text string) {
+		flush()
+		tokens = append(tokens, token{kind, text})
+	}
+
+	i := 0
+	for i < len(line) {
+		rest := line[i:]
+
+		if h.inBlock {
+			end := strings.Index(rest, s.blockComment[1])
+			if end < 0 {
+				emit(tokenComment, rest)
+				return tokens
+			}
+			end += len(s.blockComment[1])
+			emit(tokenComment, rest[:end])
+			h.inBlock = false
+			i += end
+			continue
+		}
+
+		if s.blockComment[0] != "" && strings.HasPrefix(rest, s.blockComment[0]) {
+			emit(tokenComment, s.blockComment[0])
+			h.inBlock = true
+			i += len(s.blockComment[0])
+			continue
+		}
+
+		if isLineComment(s, line, i) {
+			emit(tokenComment, rest)
+			return tokens
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(s.quotes, c) >= 0:
+			end := scanString(rest, c)
+			kind := tokenString
+			if s.stringKeys && strings.HasPrefix(strings.TrimLeft(rest[end:], " \t"), ":") {
+				kind = tokenKey
+			}
+			emit(kind, rest[:end])
+			i += end
+		case isDigit(c) && (i == 0 || !isIdentByte(line[i-1])):
+			end := scanNumber(rest)
+			emit(tokenNumber, rest[:end])
+			i += end
+		case isIdentStart(c):
+			end := scanIdent(rest)
+			word := rest[:end]
+			switch {
+			case s.keywords[word]:
+				emit(tokenKeyword, word)
+			case s.constants[word]:
+				emit(tokenConstant, word)
+			default:
+				plain.WriteString(word)
+			}
+			i += end
+		default:
+			plain.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isLineComment 判断位置 i 是否开始一个行注释。Shell 和 YAML 里的 # 只有在
+// 行首或空白之后才是注释
+func isLineComment(s *syntax, line string, i int) bool {
+	for _, prefix := range s.lineComments {
+		if !strings.HasPrefix(line[i:], prefix) {
+			continue
+		}
+		if prefix == "#" && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// scanString 返回字符串字面量的长度；未闭合的字符串延续到行尾
+func scanString(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func scanNumber(s string) int {
+	i := 0
+	for i < len(s) && (isIdentByte(s[i]) || s[i] == '.') {
+		i++
+	}
+	return i
+}
+
+// scanIdent 返回标识符的长度；@ 开头的 CSS 规则可以包含连字符
+func scanIdent(s string) int {
+	i := 1
+	for i < len(s) && (isIdentByte(s[i]) || s[0] == '@' && s[i] == '-') {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '@' || c == '#' || c == '!' || c >= 0x80 || unicode.IsLetter(rune(c))
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 0x80 || unicode.IsLetter(rune(c))
+}
+
+// tokenizeMarkup 高亮 HTML/XML 的标签、属性值和注释
+func (h *highlighter) tokenizeMarkup(line string) []token {
+	var tokens []token
+	for line != "" {
+		if h.inBlock {
+			end := strings.Index(line, "-->")
+			if end < 0 {
+				return append(tokens, token{tokenComment, line})
+			}
+			tokens = append(tokens, token{tokenComment, line[:end+3]})
+			line = line[end+3:]
+			h.inBlock = false
+			continue
+		}
+		if start := strings.Index(line, "<!--"); start >= 0 && start == strings.Index(line, "<") {
+			tokens = append(tokens, token{tokenText, line[:start]})
+			line = line[start:]
+			h.inBlock = true
+			continue
+		}
+		loc := markupTagPattern.FindStringIndex(line)
+		if loc == nil {
+			return append(tokens, token{tokenText, line})
+		}
+		tokens = append(tokens, token{tokenText, line[:loc[0]]})
+		tag := line[loc[0]:loc[1]]
+		last := 0
+		for _, attr := range markupAttrPattern.FindAllStringIndex(tag, -1) {
+			tokens = append(tokens, token{tokenTag, tag[last:attr[0]]}, token{tokenString, tag[attr[0]:attr[1]]})
+			last = attr[1]
+		}
+		tokens = append(tokens, token{tokenTag, tag[last:]})
+		line = line[loc[1]:]
+	}
+	return tokens
+}
+
+// tokenizeLog 高亮日志行开头的时间戳和日志级别
+func tokenizeLog(line string) []token {
+	var tokens []token
+	if loc := logTimePattern.FindStringIndex(line); loc != nil {
+		tokens = append(tokens, token{tokenMuted, line[:loc[1]]})
+		line = line[loc[1]:]
+	}
+	loc := logLevelPattern.FindStringIndex(line)
+	if loc == nil {
+		return append(tokens, token{tokenText, line})
+	}
+
+	var kind tokenKind
+	switch strings.ToUpper(line[loc[0]:loc[1]]) {
+	case "FATAL", "PANIC", "ERROR", "ERR":
+		kind = tokenError
+	case "WARNING", "WARN":
+		kind = tokenWarning
+	case "INFO":
+		kind = tokenInfo
+	default:
+		kind = tokenMuted
+	}
+	return append(tokens,
+		token{tokenText, line[:loc[0]]},
+		token{kind, line[loc[0]:loc[1]]},
+		token{tokenText, line[loc[1]:]},
+	)
+}
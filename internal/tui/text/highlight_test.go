@@ -0,0 +1,79 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kinds 返回某种类型的所有片段
+func kinds(tokens []token, kind tokenKind) []string {
+	var out []string
+	for _, t := range tokens {
+		if t.kind == kind {
+			out = append(out, t.text)
+		}
+	}
+	return out
+}
+
+func TestHighlighter_Go(t *testing.T) {
+	h := newHighlighter(LangGo)
+	tokens := h.tokenize(`func main() { x := "a // b" + 42 // done`)
+
+	assert.Equal(t, []string{"func"}, kinds(tokens, tokenKeyword))
+	assert.Equal(t, []string{`"a // b"`}, kinds(tokens, tokenString))
+	assert.Equal(t, []string{"42"}, kinds(tokens, tokenNumber))
+	assert.Equal(t, []string{"// done"}, kinds(tokens, tokenComment))
+}
+
+func TestHighlighter_BlockCommentSpansLines(t *testing.T) {
+	h := newHighlighter(LangJavaScript)
+
+	first := h.tokenize("const a = 1 /* start")
+	second := h.tokenize("still comment */ let b")
+
+	assert.Equal(t, []string{"/*", " start"}, kinds(first, tokenComment))
+	assert.Equal(t, []string{"still comment */"}, kinds(second, tokenComment))
+	assert.Equal(t, []string{"let"}, kinds(second, tokenKeyword))
+}
+
+func TestHighlighter_JSONKeys(t *testing.T) {
+	tokens := newHighlighter(LangJSON).tokenize(`  "enabled": true, "name": "r2"`)
+
+	assert.Equal(t, []string{`"enabled"`, `"name"`}, kinds(tokens, tokenKey))
+	assert.Equal(t, []string{`"r2"`}, kinds(tokens, tokenString))
+	assert.Equal(t, []string{"true"}, kinds(tokens, tokenConstant))
+}
+
+func TestHighlighter_ShellHashInsideWord(t *testing.T) {
+	tokens := newHighlighter(LangShell).tokenize(`echo a#b # comment`)
+	assert.Equal(t, []string{"# comment"}, kinds(tokens, tokenComment))
+}
+
+func TestHighlighter_YAMLAndTOML(t *testing.T) {
+	yaml := newHighlighter(LangYAML).tokenize("  - name: web # service")
+	assert.Equal(t, []string{"name"}, kinds(yaml, tokenKey))
+	assert.Equal(t, []string{"# service"}, kinds(yaml, tokenComment))
+
+	toml := newHighlighter(LangTOML)
+	assert.Equal(t, []string{"[r2]"}, kinds(toml.tokenize("[r2]"), tokenKeyword))
+	assert.Equal(t, []string{"page_size"}, kinds(toml.tokenize("page_size = 50"), tokenKey))
+}
+
+func TestHighlighter_Markup(t *testing.T) {
+	h := newHighlighter(LangMarkup)
+	tokens := h.tokenize(`<a href="/x">link</a> <!-- note`)
+
+	assert.Equal(t, []string{`"/x"`}, kinds(tokens, tokenString))
+	assert.Equal(t, []string{"<!-- note"}, kinds(tokens, tokenComment))
+	assert.Equal(t, []string{"end -->"}, kinds(h.tokenize("end -->"), tokenComment))
+}
+
+func TestHighlighter_Log(t *testing.T) {
+	tokens := tokenizeLog("2024-05-01 12:00:00 ERROR upload failed")
+	assert.Equal(t, []string{"ERROR"}, kinds(tokens, tokenError))
+	assert.Equal(t, []string{"2024-05-01 12:00:00"}, kinds(tokens, tokenMuted))
+
+	assert.Equal(t, []string{"WARN"}, kinds(tokenizeLog("[main] WARN slow request"), tokenWarning))
+}
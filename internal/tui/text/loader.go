@@ -0,0 +1,93 @@
+package text
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// DefaultChunkSize 是每次加载的字节数
+const DefaultChunkSize = 64 * 1024
+
+// ObjectClient 是加载文本需要的存储操作
+type ObjectClient interface {
+	Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error)
+}
+
+// Loader 通过 Range 请求分段读取对象内容
+type Loader struct {
+	client     ObjectClient
+	bucketName string
+	chunkSize  int64
+}
+
+// NewLoader 创建分段加载器，chunkSize 不大于 0 时使用默认值
+func NewLoader(chunkSize int64) *Loader {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &Loader{chunkSize: chunkSize}
+}
+
+// SetClient 设置 S3 客户端
+func (l *Loader) SetClient(client ObjectClient) {
+	l.client = client
+}
+
+// SetBucketName 设置存储桶名称
+func (l *Loader) SetBucketName(bucketName string) {
+	l.bucketName = bucketName
+}
+
+// ChunkSize 返回每次加载的字节数
+func (l *Loader) ChunkSize() int64 {
+	return l.chunkSize
+}
+
+// Open 加载对象的第一段内容
+func (l *Loader) Open(ctx context.Context, key string) (*Document, error) {
+	return l.LoadMore(ctx, NewDocument(key))
+}
+
+// LoadMore 加载下一段内容，返回新的文档，原文档不变，
+// 所以界面可以在加载期间继续显示它。已经加载完时直接返回原文档
+func (l *Loader) LoadMore(ctx context.Context, doc *Document) (*Document, error) {
+	if doc.Complete() {
+		return doc, nil
+	}
+	if l.client == nil {
+		return nil, fmt.Errorf("S3 client not set")
+	}
+
+	offset := doc.Loaded()
+	output, err := l.client.Get(ctx, l.bucketName, doc.Key, r2.GetOptions{Offset: offset, Length: l.chunkSize})
+	if err != nil {
+		// 空对象或已经读到末尾时服务端返回 InvalidRange
+		if errors.Is(err, r2.ErrInvalidRange) {
+			return doc.withChunk(nil, offset), nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", doc.Key, err)
+	}
+	defer output.Body.Close()
+
+	total := output.Size
+
+	chunk, err := io.ReadAll(io.LimitReader(output.Body, l.chunkSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", doc.Key, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"key":    doc.Key,
+		"offset": offset,
+		"bytes":  len(chunk),
+		"total":  total,
+	}).Debug("loaded text preview chunk")
+
+	return doc.withChunk(chunk, total), nil
+}
@@ -0,0 +1,166 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+)
+
+var (
+	mdHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListPattern    = regexp.MustCompile(`^(\s*)([-*+])\s+(.*)$`)
+	mdOrderedPattern = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdRulePattern    = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdFencePattern   = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+#-]*)")
+	mdTaskPattern    = regexp.MustCompile(`^\[([ xX])\]\s+`)
+
+	mdInlineCode = regexp.MustCompile("`[^`]+`")
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic     = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*|(^|[^_\w])_([^_\s][^_]*)_`)
+	mdLink       = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+)
+
+var (
+	mdH1Style    = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightBlue)).Bold(true).Underline(true)
+	mdH2Style    = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightCyan)).Bold(true)
+	mdHStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorText)).Bold(true)
+	mdQuoteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorHint)).Italic(true)
+	mdMutedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightBlack))
+	mdBullet     = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow))
+	mdCodeStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightGreen))
+	mdBoldStyle  = lipgloss.NewStyle().Bold(true)
+	mdEmStyle    = lipgloss.NewStyle().Italic(true)
+	mdLinkStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.URLColor)).Underline(true)
+)
+
+// 代码块的语言别名
+var fenceLanguages = map[string]Language{
+	"golang":     LangGo,
+	"javascript": LangJavaScript,
+	"typescript": LangJavaScript,
+	"bash":       LangShell,
+	"shell":      LangShell,
+	"console":    LangShell,
+	"python":     LangPython,
+	"html":       LangMarkup,
+	"xml":        LangMarkup,
+	"rust":       LangRust,
+	"java":       LangCLike,
+	"cpp":        LangCLike,
+}
+
+// fenceLanguage 识别代码块标注的语言，例如 ```go
+func fenceLanguage(info string) Language {
+	info = strings.ToLower(info)
+	if lang, ok := fenceLanguages[info]; ok {
+		return lang
+	}
+	return DetectLanguage("fence." + info)
+}
+
+// RenderMarkdown 把 Markdown 渲染为终端文本：标题、列表、引用、分隔线、
+// 带高亮的代码块，以及行内的代码、粗体、斜体和链接
+func RenderMarkdown(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	var code *highlighter
+	fence := ""
+
+	for _, line := range lines {
+		if code != nil {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				out = append(out, mdMutedStyle.Render("└"+strings.Repeat("─", 20)))
+				code = nil
+				continue
+			}
+			out = append(out, mdMutedStyle.Render("│ ")+renderTokens(code.tokenize(line)))
+			continue
+		}
+
+		if m := mdFencePattern.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			code = newHighlighter(fenceLanguage(m[2]))
+			label := "┌" + strings.Repeat("─", 20)
+			if m[2] != "" {
+				label = "┌─ " + m[2] + " " + strings.Repeat("─", max(1, 17-len(m[2])))
+			}
+			out = append(out, mdMutedStyle.Render(label))
+			continue
+		}
+
+		out = append(out, renderMarkdownLine(line))
+	}
+	return out
+}
+
+func renderMarkdownLine(line string) string {
+	if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+		switch len(m[1]) {
+		case 1:
+			return mdH1Style.Render(m[2])
+		case 2:
+			return mdH2Style.Render(m[2])
+		default:
+			return mdHStyle.Render(m[1] + " " + m[2])
+		}
+	}
+	if mdRulePattern.MatchString(line) {
+		return mdMutedStyle.Render(strings.Repeat("─", 40))
+	}
+	if trimmed := strings.TrimLeft(line, " "); strings.HasPrefix(trimmed, ">") {
+		indent := line[:len(line)-len(trimmed)]
+		quote := strings.TrimPrefix(strings.TrimPrefix(trimmed, ">"), " ")
+		return indent + mdMutedStyle.Render("┃ ") + mdQuoteStyle.Render(quote)
+	}
+	if m := mdListPattern.FindStringSubmatch(line); m != nil {
+		item := m[3]
+		bullet := "•"
+		if t := mdTaskPattern.FindStringSubmatch(item); t != nil {
+			bullet = "☐"
+			if t[1] != " " {
+				bullet = "☑"
+			}
+			item = item[len(t[0]):]
+		}
+		return m[1] + mdBullet.Render(bullet) + " " + renderInline(item)
+	}
+	if m := mdOrderedPattern.FindStringSubmatch(line); m != nil {
+		return m[1] + mdBullet.Render(m[2]) + " " + renderInline(m[3])
+	}
+	return renderInline(line)
+}
+
+// renderInline 渲染行内标记。行内代码先被替换成占位符，避免其中的 * 和 _ 被当作强调
+func renderInline(s string) string {
+	var codes []string
+	s = mdInlineCode.ReplaceAllStringFunc(s, func(code string) string {
+		codes = append(codes, mdCodeStyle.Render(strings.Trim(code, "`")))
+		return "\x00" + string(rune('0'+len(codes)-1)) + "\x00"
+	})
+
+	s = mdLink.ReplaceAllStringFunc(s, func(link string) string {
+		m := mdLink.FindStringSubmatch(link)
+		label := m[1]
+		if label == "" {
+			label = m[2]
+		}
+		return mdLinkStyle.Render(label) + mdMutedStyle.Render(" ("+m[2]+")")
+	})
+	s = mdBold.ReplaceAllStringFunc(s, func(bold string) string {
+		return mdBoldStyle.Render(bold[2 : len(bold)-2])
+	})
+	s = mdItalic.ReplaceAllStringFunc(s, func(em string) string {
+		m := mdItalic.FindStringSubmatch(em)
+		if m[2] != "" {
+			return m[1] + mdEmStyle.Render(m[2])
+		}
+		return m[3] + mdEmStyle.Render(m[4])
+	})
+
+	for i, code := range codes {
+		s = strings.Replace(s, "\x00"+string(rune('0'+i))+"\x00", code, 1)
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+package text
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Language 决定高亮和渲染方式
+type Language string
+
+const (
+	LangPlain      Language = "plain"
+	LangJSON       Language = "json"
+	LangMarkdown   Language = "markdown"
+	LangGo         Language = "go"
+	LangPython     Language = "python"
+	LangJavaScript Language = "javascript"
+	LangShell      Language = "shell"
+	LangYAML       Language = "yaml"
+	LangTOML       Language = "toml"
+	LangMarkup     Language = "markup"
+	LangCSS        Language = "css"
+	LangSQL        Language = "sql"
+	LangCLike      Language = "c"
+	LangRust       Language = "rust"
+	LangLog        Language = "log"
+)
+
+// Encoding 是检测到的文本编码
+type Encoding string
+
+const (
+	EncodingUTF8    Encoding = "UTF-8"
+	EncodingUTF16LE Encoding = "UTF-16LE"
+	EncodingUTF16BE Encoding = "UTF-16BE"
+	EncodingGB18030 Encoding = "GB18030"
+	EncodingLatin1  Encoding = "Windows-1252"
+	EncodingBinary  Encoding = "binary"
+)
+
+// 按扩展名识别语言
+var languageByExt = map[string]Language{
+	".txt":        LangPlain,
+	".text":       LangPlain,
+	".csv":        LangPlain,
+	".tsv":        LangPlain,
+	".json":       LangJSON,
+	".jsonl":      LangJSON,
+	".ndjson":     LangJSON,
+	".geojson":    LangJSON,
+	".md":         LangMarkdown,
+	".markdown":   LangMarkdown,
+	".go":         LangGo,
+	".py":         LangPython,
+	".js":         LangJavaScript,
+	".mjs":        LangJavaScript,
+	".cjs":        LangJavaScript,
+	".jsx":        LangJavaScript,
+	".ts":         LangJavaScript,
+	".tsx":        LangJavaScript,
+	".sh":         LangShell,
+	".bash":       LangShell,
+	".zsh":        LangShell,
+	".env":        LangShell,
+	".yaml":       LangYAML,
+	".yml":        LangYAML,
+	".toml":       LangTOML,
+	".ini":        LangTOML,
+	".conf":       LangTOML,
+	".cfg":        LangTOML,
+	".html":       LangMarkup,
+	".htm":        LangMarkup,
+	".xml":        LangMarkup,
+	".svg":        LangMarkup,
+	".css":        LangCSS,
+	".scss":       LangCSS,
+	".sql":        LangSQL,
+	".c":          LangCLike,
+	".h":          LangCLike,
+	".cc":         LangCLike,
+	".cpp":        LangCLike,
+	".hpp":        LangCLike,
+	".java":       LangCLike,
+	".kt":         LangCLike,
+	".cs":         LangCLike,
+	".swift":      LangCLike,
+	".rs":         LangRust,
+	".log":        LangLog,
+	".out":        LangLog,
+	".dockerfile": LangShell,
+}
+
+// 没有扩展名但常见的文本文件
+var languageByName = map[string]Language{
+	"dockerfile": LangShell,
+	"makefile":   LangShell,
+	"readme":     LangMarkdown,
+	"license":    LangPlain,
+	".gitignore": LangShell,
+}
+
+// DetectLanguage 根据对象 key 的扩展名识别语言
+func DetectLanguage(key string) Language {
+	name := strings.ToLower(filepath.Base(key))
+	if lang, ok := languageByName[name]; ok {
+		return lang
+	}
+	if lang, ok := languageByExt[filepath.Ext(name)]; ok {
+		return lang
+	}
+	return LangPlain
+}
+
+// IsTextFile 判断对象是否适合文本预览：text/* 或常见的文本类 Content-Type，
+// 以及已知的源码、配置和日志扩展名
+func IsTextFile(key, contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "text/"):
+		return true
+	case strings.Contains(contentType, "json"),
+		strings.Contains(contentType, "xml"),
+		strings.Contains(contentType, "javascript"),
+		strings.Contains(contentType, "yaml"),
+		strings.Contains(contentType, "toml"),
+		strings.Contains(contentType, "x-sh"):
+		return true
+	}
+
+	name := strings.ToLower(filepath.Base(key))
+	if _, ok := languageByName[name]; ok {
+		return true
+	}
+	_, ok := languageByExt[filepath.Ext(name)]
+	return ok
+}
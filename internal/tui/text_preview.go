@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/text"
+	"github.com/HaiFongPan/r2s3-cli/internal/tui/theme"
+)
+
+// TextPreviewModel is a fullscreen modal showing the beginning of a text object
+type TextPreviewModel struct {
+	width  int
+	height int
+	file   FileItem
+	loader *text.Loader
+
+	doc      *text.Document
+	rendered text.Rendered
+	loading  bool
+	err      error
+
+	raw  bool
+	wrap bool
+
+	viewport viewport.Model
+	spin     spinner.Model
+
+	// ctx is cancelled by Close so loads stop once the modal is gone
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// textChunkLoadedMsg carries the document after a chunk of key was loaded
+type textChunkLoadedMsg struct {
+	key string
+	doc *text.Document
+	err error
+}
+
+// Header (name, status, hint, separator) and the footer line
+const textPreviewChrome = 5
+
+func NewTextPreviewModel(loader *text.Loader, file FileItem, width, height int) *TextPreviewModel {
+	m := &TextPreviewModel{
+		width:   width,
+		height:  height,
+		file:    file,
+		loader:  loader,
+		loading: true,
+		wrap:    true,
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.viewport = viewport.New(width, max(1, height-textPreviewChrome))
+	m.spin = spinner.New()
+	m.spin.Spinner = spinner.Line
+	m.spin.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ColorBrightYellow))
+	return m
+}
+
+func (m *TextPreviewModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.spin.Tick)
+}
+
+// load fetches the first chunk, or the next one once a document is shown
+func (m *TextPreviewModel) load() tea.Cmd {
+	loader, key, doc, parent := m.loader, m.file.Key, m.doc, m.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, 20*time.Second)
+		defer cancel()
+
+		var err error
+		if doc == nil {
+			doc, err = loader.Open(ctx, key)
+		} else {
+			doc, err = loader.LoadMore(ctx, doc)
+		}
+		return textChunkLoadedMsg{key: key, doc: doc, err: err}
+	}
+}
+
+// Close cancels a load that is still running
+func (m *TextPreviewModel) Close() {
+	m.cancel()
+}
+
+// SetSize resizes the modal and re-renders the content for the new width
+func (m *TextPreviewModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(1, height-textPreviewChrome)
+	if !m.loading {
+		m.render()
+	}
+}
+
+// render lays out the loaded content, keeping the scroll position
+func (m *TextPreviewModel) render() {
+	if m.doc == nil {
+		return
+	}
+	m.rendered = m.doc.Render(text.RenderOptions{Width: m.width, Raw: m.raw, Wrap: m.wrap})
+	lines := m.rendered.Lines
+	if !m.doc.Complete() {
+		more := theme.CreateHintStyle().Render(fmt.Sprintf("── %s of %s loaded, press m to load more ──",
+			formatFileSize(m.doc.Loaded()), formatFileSize(m.doc.Total())))
+		lines = append(lines, "", more)
+	}
+	offset := m.viewport.YOffset
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	m.viewport.SetYOffset(offset)
+}
+
+func (m *TextPreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case textChunkLoadedMsg:
+		// Drop results for a file previewed earlier
+		if msg.key != m.file.Key {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.err = nil
+		m.doc = msg.doc
+		m.render()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "p", "P":
+			return m, func() tea.Msg { return modalClosedMsg{} }
+		case "m":
+			if m.loading || m.doc == nil || m.doc.Complete() {
+				return m, nil
+			}
+			m.loading = true
+			return m, tea.Batch(m.load(), m.spin.Tick)
+		case "w":
+			if !m.loading {
+				m.wrap = !m.wrap
+				m.render()
+			}
+			return m, nil
+		case "r":
+			if !m.loading {
+				m.raw = !m.raw
+				m.render()
+			}
+			return m, nil
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spin, cmd = m.spin.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m *TextPreviewModel) View() string {
+	nameLine := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Bold(true).
+		Foreground(lipgloss.Color(theme.ColorBrightCyan)).
+		Render("📄 " + filepath.Base(m.file.Key))
+
+	var statusText string
+	switch {
+	case m.err != nil:
+		statusText = theme.CreateErrorStyle().Render(fmt.Sprintf("Failed to load: %v", m.err))
+	case m.loading && m.doc == nil:
+		statusText = fmt.Sprintf("%s Loading text preview…", m.spin.View())
+	case m.doc != nil:
+		statusText = fmt.Sprintf("%s  •  %s", m.rendered.Format, m.sizeInfo())
+		if m.loading {
+			statusText = fmt.Sprintf("%s Loading more…  •  %s", m.spin.View(), statusText)
+		}
+	}
+	statusLine := lipgloss.NewStyle().Width(m.width).Align(lipgloss.Center).Render(statusText)
+
+	hint := lipgloss.NewStyle().
+		Width(m.width).
+		Align(lipgloss.Center).
+		Foreground(lipgloss.Color(theme.ColorBrightBlack)).
+		Render("↑/↓ pgup/pgdn g/G scroll • m load more • w wrap • r raw • q/esc close")
+
+	separator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.ColorBrightBlue)).
+		Render(strings.Repeat("─", max(1, m.width)))
+
+	var b strings.Builder
+	b.WriteString(nameLine)
+	b.WriteString("\n")
+	b.WriteString(statusLine)
+	b.WriteString("\n")
+	b.WriteString(hint)
+	b.WriteString("\n")
+	b.WriteString(separator)
+	b.WriteString("\n")
+	if m.doc != nil {
+		b.WriteString(m.viewport.View())
+		b.WriteString("\n")
+		b.WriteString(theme.CreateHintStyle().Render(fmt.Sprintf("%3.0f%%", m.viewport.ScrollPercent()*100)))
+	}
+	return b.String()
+}
+
+// sizeInfo describes how much of the object is shown
+func (m *TextPreviewModel) sizeInfo() string {
+	if m.doc.Complete() {
+		return formatFileSize(m.doc.Total())
+	}
+	return fmt.Sprintf("first %s of %s", formatFileSize(m.doc.Loaded()), formatFileSize(m.doc.Total()))
+}
@@ -26,6 +26,7 @@ const (
 	ColorFileCode         = "#7D56F4" // Signature purple for code
 	ColorFileData         = "#3B82F6" // Bright blue for data
 	ColorFileFont         = "#EC4899" // Pink for fonts
+	ColorFileFolder       = "#F59E0B" // Warm amber for folders
 )
 
 // GetFileColor returns the color for a given file category
@@ -53,6 +54,8 @@ func GetFileColor(category string) string {
 		return ColorFileData
 	case "font":
 		return ColorFileFont
+	case "folder":
+		return ColorFileFolder
 	default:
 		return ColorText
 	}
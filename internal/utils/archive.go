@@ -0,0 +1,390 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// ArchiveFormat identifies how an archive is read
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTar   ArchiveFormat = "tar"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// archiveBlockSize is the size of the ranged reads used for zip archives
+const archiveBlockSize = 64 * 1024
+
+// archiveMaxBlocks bounds the memory used by the ranged read cache
+const archiveMaxBlocks = 32
+
+// ErrArchiveEntryNotFound is returned when an entry to extract does not exist
+var ErrArchiveEntryNotFound = errors.New("entry not found in archive")
+
+// ArchiveEntry is one file or directory inside an archive
+type ArchiveEntry struct {
+	Name           string    `json:"name"`
+	Size           int64     `json:"size"`
+	CompressedSize int64     `json:"compressed_size,omitempty"`
+	Modified       time.Time `json:"modified"`
+	IsDir          bool      `json:"is_dir,omitempty"`
+}
+
+// DetectArchiveFormat returns the archive format for key based on its extension
+func DetectArchiveFormat(key string) (ArchiveFormat, error) {
+	name := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".jar"):
+		return ArchiveZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveTar, nil
+	default:
+		return "", fmt.Errorf("unsupported archive format for %s (supported: .zip, .tar, .tar.gz, .tgz)", key)
+	}
+}
+
+// ArchiveReader lists and extracts archive entries without downloading the
+// whole archive when the format allows it. Zip archives are read with ranged
+// Get requests for the central directory and the requested entry; tar
+// archives have no index, so their headers are streamed.
+type ArchiveReader struct {
+	client     DownloadAPI
+	bucketName string
+}
+
+// NewArchiveReader creates an archive reader for bucketName
+func NewArchiveReader(client DownloadAPI, bucketName string) *ArchiveReader {
+	return &ArchiveReader{client: client, bucketName: bucketName}
+}
+
+// SetBucketName updates the bucket the archives are read from
+func (r *ArchiveReader) SetBucketName(bucketName string) {
+	r.bucketName = bucketName
+}
+
+// List returns the entries of the archive stored at key
+func (r *ArchiveReader) List(ctx context.Context, key string) ([]ArchiveEntry, error) {
+	format, err := DetectArchiveFormat(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ArchiveEntry
+	if format == ArchiveZip {
+		zr, err := r.openZip(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			entries = append(entries, ArchiveEntry{
+				Name:           f.Name,
+				Size:           int64(f.UncompressedSize64),
+				CompressedSize: int64(f.CompressedSize64),
+				Modified:       f.Modified,
+				IsDir:          f.FileInfo().IsDir(),
+			})
+		}
+		return entries, nil
+	}
+
+	err = r.walkTar(ctx, key, format, func(header *tar.Header, _ io.Reader) (bool, error) {
+		entries = append(entries, ArchiveEntry{
+			Name:     header.Name,
+			Size:     header.Size,
+			Modified: header.ModTime,
+			IsDir:    header.Typeflag == tar.TypeDir,
+		})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Extract writes the content of one archive entry to w and returns the number
+// of bytes written
+func (r *ArchiveReader) Extract(ctx context.Context, key, entry string, w io.Writer) (int64, error) {
+	format, err := DetectArchiveFormat(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if format == ArchiveZip {
+		zr, err := r.openZip(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range zr.File {
+			if f.Name == entry && !f.FileInfo().IsDir() {
+				return r.extractZipFile(ctx, key, f, w)
+			}
+		}
+		return 0, fmt.Errorf("%s: %w", entry, ErrArchiveEntryNotFound)
+	}
+
+	var written int64
+	found := false
+	err = r.walkTar(ctx, key, format, func(header *tar.Header, content io.Reader) (bool, error) {
+		if header.Name != entry || header.Typeflag == tar.TypeDir {
+			return false, nil
+		}
+		found = true
+		n, err := io.Copy(w, content)
+		written = n
+		return true, err
+	})
+	if err != nil {
+		return written, err
+	}
+	if !found {
+		return 0, fmt.Errorf("%s: %w", entry, ErrArchiveEntryNotFound)
+	}
+	return written, nil
+}
+
+// ExtractToFile extracts one archive entry to localPath, applying policy when
+// the file already exists, and returns the path actually written. ConflictSkip
+// fails with an error matching os.ErrExist. The entry is written to a
+// temporary file first so a failed extraction never leaves a partial file.
+func (r *ArchiveReader) ExtractToFile(ctx context.Context, key, entry, localPath string, policy ConflictPolicy) (string, int64, error) {
+	if _, err := os.Stat(localPath); err == nil {
+		switch policy {
+		case ConflictSkip:
+			return localPath, 0, fmt.Errorf("%s: %w", localPath, os.ErrExist)
+		case ConflictRename:
+			localPath = resolveFileNameConflict(localPath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create directory for %s: %w", localPath, err)
+	}
+
+	tmpPath := localPath + ".r2s3-part"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	written, err := r.Extract(ctx, key, entry, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", 0, err
+	}
+
+	if err := os.Rename(tmpPath, localPath); err != nil {
+		os.Remove(tmpPath)
+		return "", 0, fmt.Errorf("failed to move extracted file into place: %w", err)
+	}
+
+	logrus.Infof("Extracted %s from %s to %s", entry, key, localPath)
+	return localPath, written, nil
+}
+
+// openZip reads the central directory of a zip archive with ranged requests
+func (r *ArchiveReader) openZip(ctx context.Context, key string) (*zip.Reader, error) {
+	head, err := r.client.Head(ctx, r.bucketName, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object info for %s: %w", key, err)
+	}
+
+	readerAt := newRangeReaderAt(ctx, r.client, r.bucketName, key, head.Size)
+	zr, err := zip.NewReader(readerAt, readerAt.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip directory of %s: %w", key, err)
+	}
+	logrus.Debugf("Read zip directory of %s (%d entries) with %d ranged requests", key, len(zr.File), readerAt.requests)
+	return zr, nil
+}
+
+// extractZipFile streams the compressed data of f with a single ranged
+// request and checks the CRC-32 stored in the directory
+func (r *ArchiveReader) extractZipFile(ctx context.Context, key string, f *zip.File, w io.Writer) (int64, error) {
+	offset, err := f.DataOffset()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate %s in %s: %w", f.Name, key, err)
+	}
+
+	var body io.ReadCloser = io.NopCloser(strings.NewReader(""))
+	if f.CompressedSize64 > 0 {
+		result, err := r.client.Get(ctx, r.bucketName, key, r2.GetOptions{
+			Offset: offset,
+			Length: int64(f.CompressedSize64),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s from %s: %w", f.Name, key, err)
+		}
+		body = result.Body
+	}
+	defer body.Close()
+
+	var content io.Reader
+	switch f.Method {
+	case zip.Store:
+		content = body
+	case zip.Deflate:
+		inflater := flate.NewReader(body)
+		defer inflater.Close()
+		content = inflater
+	default:
+		return 0, fmt.Errorf("%s uses unsupported compression method %d", f.Name, f.Method)
+	}
+
+	hash := crc32.NewIEEE()
+	written, err := io.Copy(io.MultiWriter(w, hash), content)
+	if err != nil {
+		return written, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if f.CRC32 != 0 && hash.Sum32() != f.CRC32 {
+		return written, fmt.Errorf("checksum mismatch for %s: the archive may be corrupted", f.Name)
+	}
+	return written, nil
+}
+
+// walkTar streams a tar archive and calls visit for every header until visit
+// returns true. The entry content must be read inside visit.
+func (r *ArchiveReader) walkTar(ctx context.Context, key string, format ArchiveFormat, visit func(*tar.Header, io.Reader) (bool, error)) error {
+	result, err := r.client.Get(ctx, r.bucketName, key, r2.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer result.Body.Close()
+
+	var stream io.Reader = result.Body
+	if format == ArchiveTarGz {
+		gz, err := gzip.NewReader(result.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream of %s: %w", key, err)
+		}
+		defer gz.Close()
+		stream = gz
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header in %s: %w", key, err)
+		}
+		// Skip PAX global headers and other metadata records
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+		done, err := visit(header, tr)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// rangeReaderAt is an io.ReaderAt over an object that fetches aligned blocks
+// with ranged Get requests and keeps a bounded number of them cached
+type rangeReaderAt struct {
+	ctx        context.Context
+	client     DownloadAPI
+	bucketName string
+	key        string
+	size       int64
+
+	blocks   map[int64][]byte
+	order    []int64
+	requests int
+}
+
+func newRangeReaderAt(ctx context.Context, client DownloadAPI, bucketName, key string, size int64) *rangeReaderAt {
+	return &rangeReaderAt{
+		ctx:        ctx,
+		client:     client,
+		bucketName: bucketName,
+		key:        key,
+		size:       size,
+		blocks:     make(map[int64][]byte),
+	}
+}
+
+// ReadAt implements io.ReaderAt
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && off < r.size {
+		index := off / archiveBlockSize
+		block, err := r.block(index)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], block[off-index*archiveBlockSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// block returns block index, fetching it when it is not cached
+func (r *rangeReaderAt) block(index int64) ([]byte, error) {
+	if block, ok := r.blocks[index]; ok {
+		return block, nil
+	}
+
+	start := index * archiveBlockSize
+	end := min(start+archiveBlockSize, r.size) - 1
+	result, err := r.client.Get(r.ctx, r.bucketName, r.key, r2.GetOptions{
+		Offset: start,
+		Length: end - start + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bytes %d-%d of %s: %w", start, end, r.key, err)
+	}
+	defer result.Body.Close()
+
+	block, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bytes %d-%d of %s: %w", start, end, r.key, err)
+	}
+	if int64(len(block)) != end-start+1 {
+		return nil, fmt.Errorf("short read of %s: got %d bytes at offset %d, expected %d", r.key, len(block), start, end-start+1)
+	}
+	r.requests++
+
+	if len(r.order) >= archiveMaxBlocks {
+		delete(r.blocks, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.blocks[index] = block
+	r.order = append(r.order, index)
+	return block, nil
+}
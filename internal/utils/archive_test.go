@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// fakeRangeClient serves one object and honours ranged reads
+type fakeRangeClient struct {
+	body        []byte
+	gets        int
+	bytesServed int
+}
+
+func (f *fakeRangeClient) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	return &r2.ObjectInfo{Key: key, Size: int64(len(f.body))}, nil
+}
+
+func (f *fakeRangeClient) Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error) {
+	f.gets++
+	body := f.body[options.Offset:]
+	if options.Length > 0 {
+		body = body[:options.Length]
+	}
+	f.bytesServed += len(body)
+	return &r2.Object{
+		ObjectInfo:    r2.ObjectInfo{Key: key, Size: int64(len(f.body))},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+var archiveModTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// buildZip creates a zip with a large incompressible entry followed by small ones
+func buildZip(t *testing.T, large int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	noise := make([]byte, large)
+	rand.New(rand.NewSource(1)).Read(noise)
+	files := []struct {
+		name   string
+		body   []byte
+		method uint16
+	}{
+		{"data/noise.bin", noise, zip.Store},
+		{"docs/", nil, zip.Store},
+		{"docs/readme.txt", []byte(strings.Repeat("hello zip\n", 100)), zip.Deflate},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: f.method, Modified: archiveModTime})
+		require.NoError(t, err)
+		_, err = w.Write(f.body)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func buildTar(t *testing.T, gzipped bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var gz *gzip.Writer
+	if gzipped {
+		gz = gzip.NewWriter(&buf)
+		out = gz
+	}
+
+	tw := tar.NewWriter(out)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "site/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: archiveModTime}))
+	for i, body := range []string{"<html></html>", "body {}"} {
+		name := []string{"site/index.html", "site/style.css"}[i]
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(body)), Mode: 0644, ModTime: archiveModTime}))
+		_, err := tw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+func TestDetectArchiveFormat(t *testing.T) {
+	for key, want := range map[string]ArchiveFormat{
+		"a.zip":       ArchiveZip,
+		"lib/app.JAR": ArchiveZip,
+		"backup.tar":  ArchiveTar,
+		"logs.tar.gz": ArchiveTarGz,
+		"release.tgz": ArchiveTarGz,
+	} {
+		format, err := DetectArchiveFormat(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, want, format, key)
+	}
+
+	_, err := DetectArchiveFormat("data.rar")
+	assert.Error(t, err)
+}
+
+func TestArchiveReader_ZipListUsesRangedReads(t *testing.T) {
+	client := &fakeRangeClient{body: buildZip(t, 2*1024*1024)}
+	reader := NewArchiveReader(client, "bucket")
+
+	entries, err := reader.List(context.Background(), "bundle.zip")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "data/noise.bin", entries[0].Name)
+	assert.Equal(t, int64(2*1024*1024), entries[0].Size)
+	assert.True(t, entries[1].IsDir)
+	assert.Equal(t, int64(1000), entries[2].Size)
+	assert.Less(t, entries[2].CompressedSize, entries[2].Size)
+	assert.True(t, entries[2].Modified.Equal(archiveModTime))
+
+	// Only the blocks holding the central directory are fetched
+	assert.LessOrEqual(t, client.gets, 2)
+	assert.LessOrEqual(t, client.bytesServed, 2*archiveBlockSize)
+}
+
+func TestArchiveReader_ZipExtract(t *testing.T) {
+	client := &fakeRangeClient{body: buildZip(t, 512*1024)}
+	reader := NewArchiveReader(client, "bucket")
+
+	var out bytes.Buffer
+	n, err := reader.Extract(context.Background(), "bundle.zip", "docs/readme.txt", &out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), n)
+	assert.Equal(t, strings.Repeat("hello zip\n", 100), out.String())
+	assert.Less(t, client.bytesServed, len(client.body)/2, "the large entry should not be downloaded")
+
+	_, err = reader.Extract(context.Background(), "bundle.zip", "missing.txt", io.Discard)
+	assert.ErrorIs(t, err, ErrArchiveEntryNotFound)
+	_, err = reader.Extract(context.Background(), "bundle.zip", "docs/", io.Discard)
+	assert.ErrorIs(t, err, ErrArchiveEntryNotFound)
+}
+
+func TestArchiveReader_ZipDetectsCorruption(t *testing.T) {
+	body := buildZip(t, 1024)
+	// Flip a byte inside the stored entry's data
+	index := bytes.Index(body, []byte("data/noise.bin")) + len("data/noise.bin") + 10
+	body[index] ^= 0xff
+
+	reader := NewArchiveReader(&fakeRangeClient{body: body}, "bucket")
+	_, err := reader.Extract(context.Background(), "bundle.zip", "data/noise.bin", io.Discard)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestArchiveReader_Tar(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		t.Run("gzip="+strconv.FormatBool(gzipped), func(t *testing.T) {
+			key := "site.tar"
+			if gzipped {
+				key = "site.tar.gz"
+			}
+			reader := NewArchiveReader(&fakeRangeClient{body: buildTar(t, gzipped)}, "bucket")
+
+			entries, err := reader.List(context.Background(), key)
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+			assert.True(t, entries[0].IsDir)
+			assert.Equal(t, "site/style.css", entries[2].Name)
+			assert.Equal(t, int64(7), entries[2].Size)
+
+			var out bytes.Buffer
+			_, err = reader.Extract(context.Background(), key, "site/index.html", &out)
+			require.NoError(t, err)
+			assert.Equal(t, "<html></html>", out.String())
+		})
+	}
+}
+
+func TestArchiveReader_ExtractToFile(t *testing.T) {
+	reader := NewArchiveReader(&fakeRangeClient{body: buildTar(t, true)}, "bucket")
+	target := filepath.Join(t.TempDir(), "out", "style.css")
+
+	written, n, err := reader.ExtractToFile(context.Background(), "site.tgz", "site/style.css", target, ConflictRename)
+	require.NoError(t, err)
+	assert.Equal(t, target, written)
+	assert.Equal(t, int64(7), n)
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "body {}", string(content))
+
+	// An existing file is renamed around, kept, or replaced depending on the policy
+	written, _, err = reader.ExtractToFile(context.Background(), "site.tgz", "site/index.html", target, ConflictRename)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(target), "style (1).css"), written)
+	content, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "body {}", string(content))
+
+	_, _, err = reader.ExtractToFile(context.Background(), "site.tgz", "site/index.html", target, ConflictSkip)
+	assert.ErrorIs(t, err, os.ErrExist)
+
+	_, _, err = reader.ExtractToFile(context.Background(), "site.tgz", "site/index.html", target, ConflictOverwrite)
+	require.NoError(t, err)
+	content, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "<html></html>", string(content))
+
+	// A failed extraction leaves nothing behind
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	_, _, err = reader.ExtractToFile(context.Background(), "site.tgz", "nope", missing, ConflictRename)
+	assert.ErrorIs(t, err, ErrArchiveEntryNotFound)
+	_, statErr := os.Stat(missing)
+	assert.True(t, os.IsNotExist(statErr))
+	_, statErr = os.Stat(missing + ".r2s3-part")
+	assert.True(t, os.IsNotExist(statErr))
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// Checksums holds the MD5 and SHA-256 digests of a piece of content
+type Checksums struct {
+	MD5    []byte
+	SHA256 []byte
+}
+
+// MD5Hex returns the MD5 digest in the hex form S3 uses for single-part ETags
+func (c Checksums) MD5Hex() string {
+	return hex.EncodeToString(c.MD5)
+}
+
+// ContentMD5 returns the MD5 digest in the base64 form of the Content-MD5 header
+func (c Checksums) ContentMD5() string {
+	return base64.StdEncoding.EncodeToString(c.MD5)
+}
+
+// SHA256Base64 returns the SHA-256 digest in the base64 form of x-amz-checksum-sha256
+func (c Checksums) SHA256Base64() string {
+	return base64.StdEncoding.EncodeToString(c.SHA256)
+}
+
+// ApplyToPut sends both digests with the upload so the server rejects a body
+// that was corrupted in transit
+func (c Checksums) ApplyToPut(options *r2.PutOptions) {
+	if len(c.MD5) > 0 {
+		options.ContentMD5 = c.ContentMD5()
+	}
+	if len(c.SHA256) > 0 {
+		options.ChecksumSHA256 = c.SHA256Base64()
+	}
+}
+
+// HashingReader computes MD5 and SHA-256 digests of everything read through it
+type HashingReader struct {
+	reader io.Reader
+	md5    hash.Hash
+	sha256 hash.Hash
+}
+
+// NewHashingReader wraps reader so its content is hashed while streaming
+func NewHashingReader(reader io.Reader) *HashingReader {
+	return &HashingReader{
+		reader: reader,
+		md5:    md5.New(),
+		sha256: sha256.New(),
+	}
+}
+
+func (h *HashingReader) Read(p []byte) (int, error) {
+	n, err := h.reader.Read(p)
+	if n > 0 {
+		h.md5.Write(p[:n])
+		h.sha256.Write(p[:n])
+	}
+	return n, err
+}
+
+// Checksums returns the digests of the content read so far
+func (h *HashingReader) Checksums() Checksums {
+	return Checksums{
+		MD5:    h.md5.Sum(nil),
+		SHA256: h.sha256.Sum(nil),
+	}
+}
+
+// ComputeChecksums hashes reader from the start and rewinds it afterwards so
+// it can be used as an upload body
+func ComputeChecksums(reader io.ReadSeeker) (Checksums, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return Checksums{}, err
+	}
+
+	hashing := NewHashingReader(reader)
+	if _, err := io.Copy(io.Discard, hashing); err != nil {
+		return Checksums{}, err
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return Checksums{}, err
+	}
+	return hashing.Checksums(), nil
+}
+
+// FileMD5 computes the hex MD5 of a local file
+func FileMD5(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FileMultipartETag computes the ETag S3 assigns to a multipart upload of a
+// local file split into partSize parts: the MD5 of the concatenated part MD5s
+// followed by "-<part count>"
+func FileMultipartETag(path string, partSize int64) (string, error) {
+	if partSize <= 0 {
+		return "", fmt.Errorf("invalid part size %d", partSize)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	combined := md5.New()
+	parts := 0
+	for {
+		part := md5.New()
+		n, err := io.CopyN(part, file, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n == 0 && parts > 0 {
+			break
+		}
+		combined.Write(part.Sum(nil))
+		parts++
+		if n < partSize {
+			break
+		}
+	}
+
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(combined.Sum(nil)), parts), nil
+}
+
+// NormalizeETag strips the quotes S3 puts around ETag values
+func NormalizeETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// IsMD5ETag reports whether an ETag is a plain hex MD5 (not a multipart ETag)
+func IsMD5ETag(etag string) bool {
+	etag = NormalizeETag(etag)
+	if len(etag) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
+
+// MultipartETagParts returns the part count encoded in a multipart ETag, or 0
+// when the ETag is not in the "<md5>-<parts>" form
+func MultipartETagParts(etag string) int {
+	sum, count, ok := strings.Cut(NormalizeETag(etag), "-")
+	if !ok || !IsMD5ETag(sum) {
+		return 0
+	}
+	parts, err := strconv.Atoi(count)
+	if err != nil || parts < 1 {
+		return 0
+	}
+	return parts
+}
+
+// ChecksumMismatchError reports content that does not match the object's ETag
+type ChecksumMismatchError struct {
+	Key      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected MD5 %s, got %s", e.Key, e.Expected, e.Actual)
+}
+
+// VerifyETag compares downloaded content with the object's ETag. ETags that
+// are not a plain MD5 (multipart uploads) cannot be checked and are accepted.
+func VerifyETag(key, etag string, sums Checksums) error {
+	if !IsMD5ETag(etag) {
+		return nil
+	}
+	expected := strings.ToLower(NormalizeETag(etag))
+	if actual := sums.MD5Hex(); actual != expected {
+		return &ChecksumMismatchError{Key: key, Expected: expected, Actual: actual}
+	}
+	return nil
+}
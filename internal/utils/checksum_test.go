@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+func TestComputeChecksums(t *testing.T) {
+	reader := strings.NewReader("Hello, World!")
+	reader.Seek(5, 0)
+
+	sums, err := ComputeChecksums(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "65a8e27d8879283831b664bd8b7f0ad4", sums.MD5Hex())
+	assert.Equal(t, "ZajifYh5KDgxtmS9i38K1A==", sums.ContentMD5())
+	assert.Equal(t, "3/1gIbsr1bCvZ2KQgJ7DpTGR3YHH9wpLKGiKNiGCmG8=", sums.SHA256Base64())
+
+	// The reader is rewound so it can be uploaded afterwards
+	offset, _ := reader.Seek(0, 1)
+	assert.Equal(t, int64(0), offset)
+
+	options := &r2.PutOptions{}
+	sums.ApplyToPut(options)
+	assert.Equal(t, sums.ContentMD5(), options.ContentMD5)
+	assert.Equal(t, sums.SHA256Base64(), options.ChecksumSHA256)
+
+	// Empty checksums leave the request untouched
+	options = &r2.PutOptions{}
+	Checksums{}.ApplyToPut(options)
+	assert.Empty(t, options.ContentMD5)
+	assert.Empty(t, options.ChecksumSHA256)
+}
+
+func TestVerifyETag(t *testing.T) {
+	sums, err := ComputeChecksums(strings.NewReader("Hello, World!"))
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifyETag("a.txt", `"65a8e27d8879283831b664bd8b7f0ad4"`, sums))
+	assert.NoError(t, VerifyETag("a.txt", "65A8E27D8879283831B664BD8B7F0AD4", sums))
+
+	// Multipart ETags are not content hashes and cannot be checked
+	assert.NoError(t, VerifyETag("a.txt", "d41d8cd98f00b204e9800998ecf8427e-3", sums))
+	assert.NoError(t, VerifyETag("a.txt", "", sums))
+
+	err = VerifyETag("a.txt", "d41d8cd98f00b204e9800998ecf8427e", sums)
+	var mismatch *ChecksumMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, "a.txt", mismatch.Key)
+	assert.Equal(t, "65a8e27d8879283831b664bd8b7f0ad4", mismatch.Actual)
+}
+
+func TestETagForms(t *testing.T) {
+	assert.True(t, IsMD5ETag(`"65a8e27d8879283831b664bd8b7f0ad4"`))
+	assert.False(t, IsMD5ETag("65a8e27d8879283831b664bd8b7f0ad4-2"))
+	assert.False(t, IsMD5ETag("not-an-etag"))
+
+	assert.Equal(t, 12, MultipartETagParts(`"65a8e27d8879283831b664bd8b7f0ad4-12"`))
+	assert.Equal(t, 0, MultipartETagParts("65a8e27d8879283831b664bd8b7f0ad4"))
+	assert.Equal(t, 0, MultipartETagParts("65a8e27d8879283831b664bd8b7f0ad4-x"))
+}
+
+func TestFileMultipartETag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	content := []byte(strings.Repeat("abcdefghij", 25))
+	require.NoError(t, os.WriteFile(path, content, 0644))
+
+	// Expected value built the way S3 does: MD5 of the concatenated part MD5s
+	partSize := 100
+	var joined []byte
+	for offset := 0; offset < len(content); offset += partSize {
+		sum := md5.Sum(content[offset:min(offset+partSize, len(content))])
+		joined = append(joined, sum[:]...)
+	}
+	combined := md5.Sum(joined)
+
+	etag, err := FileMultipartETag(path, int64(partSize))
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(combined[:])+"-3", etag)
+
+	// A file that divides evenly into parts has no trailing empty part
+	etag, err = FileMultipartETag(path, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 5, MultipartETagParts(etag))
+
+	sum, err := FileMD5(path)
+	require.NoError(t, err)
+	assert.True(t, IsMD5ETag(sum))
+}
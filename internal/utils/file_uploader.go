@@ -6,14 +6,11 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/sirupsen/logrus"
 
 	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 )
 
 // ProgressCallback 定义进度回调类型
@@ -37,17 +34,24 @@ type UploadOptions struct {
 	PublicAccess     bool
 	ContentType      string
 	CompressionLevel string
-}
 
-// S3ClientInterface 定义 S3 客户端接口，便于测试
-type S3ClientInterface interface {
-	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
-	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	// 写入每个对象的请求头和用户元数据
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Metadata           map[string]string
+
+	// HeaderRules 按 glob 匹配远程路径，覆盖上面的通用值
+	HeaderRules []config.HeaderRule
+
+	// Overrides 是命令行显式指定的值，在规则之后应用
+	Overrides HeaderOverrides
 }
 
-// R2ClientInterface 定义 R2 客户端接口，便于测试
-type R2ClientInterface interface {
-	GetS3Client() interface{}
+// UploadAPI 定义上传所需的存储接口，便于测试
+type UploadAPI interface {
+	Put(ctx context.Context, bucket, key string, body io.Reader, options r2.PutOptions) error
+	Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error)
 }
 
 // uploadError 包装上传相关的错误
@@ -67,47 +71,21 @@ func (e *uploadError) Unwrap() error {
 
 // fileUploader 是 FileUploader 接口的具体实现
 type fileUploader struct {
-	r2Client   R2ClientInterface
-	s3Client   S3ClientInterface
+	client     UploadAPI
 	config     *config.Config
 	bucketName string
 }
 
-// NewFileUploader 创建新的文件上传器
-func NewFileUploader(client R2ClientInterface, cfg *config.Config, bucketName string) FileUploader {
-	s3Client := extractS3Client(client)
-
+// NewFileUploader 创建新的文件上传器。client 同时实现 MultipartUploadAPI 时，
+// 大文件使用分片上传
+func NewFileUploader(client UploadAPI, cfg *config.Config, bucketName string) FileUploader {
 	return &fileUploader{
-		r2Client:   client,
-		s3Client:   s3Client,
+		client:     client,
 		config:     cfg,
 		bucketName: bucketName,
 	}
 }
 
-// extractS3Client 从 R2 客户端中提取 S3 客户端
-func extractS3Client(client R2ClientInterface) S3ClientInterface {
-	if client == nil {
-		return nil
-	}
-
-	s3ClientRaw := client.GetS3Client()
-	if s3ClientRaw == nil {
-		return nil
-	}
-
-	// 尝试类型断言
-	if s3c, ok := s3ClientRaw.(S3ClientInterface); ok {
-		return s3c
-	}
-
-	if s3c, ok := s3ClientRaw.(*s3.Client); ok {
-		return s3c
-	}
-
-	return nil
-}
-
 // UploadFile 实现 FileUploader 接口
 func (fu *fileUploader) UploadFile(ctx context.Context, localPath, remotePath string, options *UploadOptions) error {
 	return fu.UploadFileWithProgress(ctx, localPath, remotePath, options, nil)
@@ -133,8 +111,28 @@ func (fu *fileUploader) UploadFileWithProgress(ctx context.Context, localPath, r
 
 	fileSize := fileInfo.Size()
 
-	// 确定内容类型
-	contentType := fu.determineContentType(localPath, file, options.ContentType)
+	// 确定请求头和内容类型
+	headers := options.HeadersFor(remotePath)
+	headers.ContentType = fu.determineContentType(localPath, file, headers.ContentType)
+
+	// 大文件使用分片上传，支持断点续传
+	if multipartClient, ok := fu.client.(MultipartUploadAPI); ok {
+		multipartOptions := fu.multipartOptions()
+		if multipartOptions.ShouldUseMultipart(fileSize) {
+			uploader := NewMultipartUploader(multipartClient, fu.bucketName, multipartOptions)
+			return uploader.Upload(ctx, localPath, remotePath, headers, options.PublicAccess, callback)
+		}
+	}
+
+	// 计算校验和，由服务端校验上传内容是否完整
+	checksums, err := ComputeChecksums(file)
+	if err != nil {
+		return &uploadError{
+			operation: "compute checksum",
+			path:      localPath,
+			err:       err,
+		}
+	}
 
 	// 准备上传体
 	uploadBody, err := fu.prepareUploadBody(file, fileSize, callback)
@@ -143,36 +141,30 @@ func (fu *fileUploader) UploadFileWithProgress(ctx context.Context, localPath, r
 	}
 
 	// 执行上传
-	return fu.performUpload(ctx, localPath, remotePath, uploadBody, contentType, options.PublicAccess)
+	return fu.performUpload(ctx, localPath, remotePath, uploadBody, headers, checksums, options.PublicAccess)
 }
 
 // CheckFileExists 检查远程文件是否存在
 func (fu *fileUploader) CheckFileExists(ctx context.Context, remotePath string) (bool, error) {
-	_, err := fu.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(fu.bucketName),
-		Key:    aws.String(remotePath),
-	})
-
+	_, err := fu.client.Head(ctx, fu.bucketName, remotePath)
 	if err != nil {
-		// 检查是否是"未找到"类型的错误
-		var nsk *types.NoSuchKey
-		var nf *types.NotFound
-		if errors.As(err, &nsk) || errors.As(err, &nf) {
-			return false, nil
-		}
-
-		// 也检查错误消息中是否包含 404 或 NotFound
-		if strings.Contains(err.Error(), "StatusCode: 404") ||
-			strings.Contains(err.Error(), "NotFound") {
+		if errors.Is(err, r2.ErrNotFound) {
 			return false, nil
 		}
-
 		return false, err
 	}
 
 	return true, nil
 }
 
+// multipartOptions 从配置中读取分片上传选项
+func (fu *fileUploader) multipartOptions() *MultipartOptions {
+	if fu.config == nil {
+		return MultipartOptionsFromConfig(nil)
+	}
+	return MultipartOptionsFromConfig(&fu.config.Upload)
+}
+
 // openAndValidateFile 打开文件并获取文件信息
 func (fu *fileUploader) openAndValidateFile(localPath string) (*os.File, os.FileInfo, error) {
 	file, err := os.Open(localPath)
@@ -264,28 +256,21 @@ func (fu *fileUploader) determineContentType(localPath string, file *os.File, ex
 }
 
 // performUpload 执行实际的上传操作
-func (fu *fileUploader) performUpload(ctx context.Context, localPath, remotePath string, uploadBody io.Reader, contentType string, publicAccess bool) error {
-	// 准备上传参数
-	input := &s3.PutObjectInput{
-		Bucket: aws.String(fu.bucketName),
-		Key:    aws.String(remotePath),
-		Body:   uploadBody,
-	}
+func (fu *fileUploader) performUpload(ctx context.Context, localPath, remotePath string, uploadBody io.Reader, headers ObjectHeaders, checksums Checksums, publicAccess bool) error {
+	// 设置请求头和用户元数据
+	options := r2.PutOptions{Public: publicAccess}
+	headers.ApplyToPut(&options)
+	logrus.Debugf("Setting headers for %s: %+v", remotePath, headers)
 
-	// 设置内容类型
-	if contentType != "" {
-		input.ContentType = aws.String(contentType)
-		logrus.Debugf("Setting content type: %s", contentType)
-	}
+	// 发送 Content-MD5 和 x-amz-checksum-sha256
+	checksums.ApplyToPut(&options)
 
-	// 设置公共访问权限
 	if publicAccess {
-		input.ACL = "public-read"
 		logrus.Debugf("Setting public access")
 	}
 
 	// 执行上传
-	_, err := fu.s3Client.PutObject(ctx, input)
+	err := fu.client.Put(ctx, fu.bucketName, remotePath, uploadBody, options)
 	if err != nil {
 		return &uploadError{
 			operation: "upload to S3",
@@ -298,6 +283,15 @@ func (fu *fileUploader) performUpload(ctx context.Context, localPath, remotePath
 	return nil
 }
 
+// NewProgressCallbackReader 包装 io.Reader，读取时通过 callback 报告进度
+func NewProgressCallbackReader(reader io.Reader, total int64, callback ProgressCallback) io.Reader {
+	return &progressReader{
+		reader:   reader,
+		total:    total,
+		callback: callback,
+	}
+}
+
 // progressReader 包装 io.Reader 并提供进度回调
 type progressReader struct {
 	reader   io.Reader
@@ -7,51 +7,32 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 )
 
-// MockS3Client 用于模拟 S3 客户端
-type MockS3Client struct {
+// MockStorage 用于模拟存储后端
+type MockStorage struct {
 	mock.Mock
 }
 
-func (m *MockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+func (m *MockStorage) Put(ctx context.Context, bucket, key string, body io.Reader, options r2.PutOptions) error {
 	// 模拟读取 Body 内容以触发进度回调
-	if params.Body != nil {
-		buffer := make([]byte, 1024)
-		for {
-			n, err := params.Body.Read(buffer)
-			if err == io.EOF || (err != nil && n == 0) {
-				break
-			}
-			if err != nil && err != io.EOF {
-				break
-			}
-		}
+	if body != nil {
+		io.Copy(io.Discard, body)
 	}
 
-	args := m.Called(ctx, params)
-	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+	args := m.Called(ctx, key, options)
+	return args.Error(0)
 }
 
-func (m *MockS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
-	args := m.Called(ctx, params)
-	return args.Get(0).(*s3.HeadObjectOutput), args.Error(1)
-}
-
-// MockR2Client 用于模拟 R2 客户端
-type MockR2Client struct {
-	mock.Mock
-	s3Client *MockS3Client
-}
-
-func (m *MockR2Client) GetS3Client() interface{} {
-	return m.s3Client
+func (m *MockStorage) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(*r2.ObjectInfo), args.Error(1)
 }
 
 // 测试用例开始
@@ -66,14 +47,13 @@ func TestFileUploader_UploadFile_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望
 	// 先检查文件是否存在（返回不存在）
-	mockS3Client.On("HeadObject", mock.Anything, mock.Anything).Return((*s3.HeadObjectOutput)(nil), &MockNotFoundError{})
+	mockStorage.On("Head", mock.Anything, "remote/test.txt").Return((*r2.ObjectInfo)(nil), r2.ErrNotFound)
 	// 然后执行上传
-	mockS3Client.On("PutObject", mock.Anything, mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+	mockStorage.On("Put", mock.Anything, "remote/test.txt", mock.Anything).Return(nil)
 
 	// 创建配置
 	cfg := &config.Config{
@@ -85,7 +65,7 @@ func TestFileUploader_UploadFile_Success(t *testing.T) {
 	}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 执行上传
 	ctx := context.Background()
@@ -98,19 +78,18 @@ func TestFileUploader_UploadFile_Success(t *testing.T) {
 
 	// 验证结果
 	assert.NoError(t, err)
-	mockS3Client.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
 }
 
 func TestFileUploader_UploadFile_FileNotExists(t *testing.T) {
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 执行上传（文件不存在）
 	ctx := context.Background()
@@ -125,17 +104,16 @@ func TestFileUploader_UploadFile_FileNotExists(t *testing.T) {
 
 func TestFileUploader_CheckFileExists_FileExists(t *testing.T) {
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望 - 文件存在
-	mockS3Client.On("HeadObject", mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{}, nil)
+	mockStorage.On("Head", mock.Anything, "remote/test.txt").Return(&r2.ObjectInfo{Key: "remote/test.txt"}, nil)
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 检查文件是否存在
 	ctx := context.Background()
@@ -144,22 +122,21 @@ func TestFileUploader_CheckFileExists_FileExists(t *testing.T) {
 	// 验证结果
 	assert.NoError(t, err)
 	assert.True(t, exists)
-	mockS3Client.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
 }
 
 func TestFileUploader_CheckFileExists_FileNotExists(t *testing.T) {
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望 - 文件不存在（404 错误）
-	mockS3Client.On("HeadObject", mock.Anything, mock.Anything).Return((*s3.HeadObjectOutput)(nil), &MockNotFoundError{})
+	mockStorage.On("Head", mock.Anything, "remote/test.txt").Return((*r2.ObjectInfo)(nil), r2.ErrNotFound)
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 检查文件是否存在
 	ctx := context.Background()
@@ -168,7 +145,7 @@ func TestFileUploader_CheckFileExists_FileNotExists(t *testing.T) {
 	// 验证结果
 	assert.NoError(t, err)
 	assert.False(t, exists)
-	mockS3Client.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
 }
 
 func TestFileUploader_UploadFileWithProgress_Success(t *testing.T) {
@@ -181,20 +158,19 @@ func TestFileUploader_UploadFileWithProgress_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望
 	// 先检查文件是否存在（返回不存在）
-	mockS3Client.On("HeadObject", mock.Anything, mock.Anything).Return((*s3.HeadObjectOutput)(nil), &MockNotFoundError{})
+	mockStorage.On("Head", mock.Anything, "remote/test.txt").Return((*r2.ObjectInfo)(nil), r2.ErrNotFound)
 	// 然后执行上传
-	mockS3Client.On("PutObject", mock.Anything, mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+	mockStorage.On("Put", mock.Anything, "remote/test.txt", mock.Anything).Return(nil)
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 进度回调变量
 	var progressCalls []float64
@@ -212,7 +188,7 @@ func TestFileUploader_UploadFileWithProgress_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, progressCalls, "Progress callback should have been called")
 	assert.Equal(t, 100.0, progressCalls[len(progressCalls)-1], "Final progress should be 100%")
-	mockS3Client.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
 }
 
 func TestFileUploader_UploadFile_WithOverwrite(t *testing.T) {
@@ -225,18 +201,17 @@ func TestFileUploader_UploadFile_WithOverwrite(t *testing.T) {
 	require.NoError(t, err)
 
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望
 	// 由于设置了 overwrite=true，不会检查文件是否存在，直接执行上传
-	mockS3Client.On("PutObject", mock.Anything, mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+	mockStorage.On("Put", mock.Anything, "remote/test.txt", mock.Anything).Return(nil)
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 执行上传（设置覆盖选项）
 	ctx := context.Background()
@@ -248,7 +223,27 @@ func TestFileUploader_UploadFile_WithOverwrite(t *testing.T) {
 
 	// 验证结果
 	assert.NoError(t, err)
-	mockS3Client.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestFileUploader_UploadFile_SendsChecksums(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	require.NoError(t, os.WriteFile(testFile, []byte("Hello, World!"), 0644))
+
+	mockStorage := &MockStorage{}
+
+	// 上传请求应携带内容的 MD5 和 SHA-256
+	mockStorage.On("Put", mock.Anything, "remote/test.txt", mock.MatchedBy(func(options r2.PutOptions) bool {
+		return options.ContentMD5 == "ZajifYh5KDgxtmS9i38K1A==" &&
+			options.ChecksumSHA256 == "3/1gIbsr1bCvZ2KQgJ7DpTGR3YHH9wpLKGiKNiGCmG8="
+	})).Return(nil)
+
+	uploader := NewFileUploader(mockStorage, &config.Config{}, "test-bucket")
+	err := uploader.UploadFile(context.Background(), testFile, "remote/test.txt", &UploadOptions{Overwrite: true})
+
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
 }
 
 func TestFileUploader_UploadFile_FileExistsNoOverwrite(t *testing.T) {
@@ -261,18 +256,17 @@ func TestFileUploader_UploadFile_FileExistsNoOverwrite(t *testing.T) {
 	require.NoError(t, err)
 
 	// 创建 mock 客户端
-	mockS3Client := &MockS3Client{}
-	mockR2Client := &MockR2Client{s3Client: mockS3Client}
+	mockStorage := &MockStorage{}
 
 	// 设置 mock 期望 - 文件存在
-	mockS3Client.On("HeadObject", mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{}, nil)
-	// 不应该调用 PutObject，因为文件已存在且未设置覆盖
+	mockStorage.On("Head", mock.Anything, "remote/test.txt").Return(&r2.ObjectInfo{Key: "remote/test.txt"}, nil)
+	// 不应该调用 Put，因为文件已存在且未设置覆盖
 
 	// 创建配置
 	cfg := &config.Config{}
 
 	// 创建上传器
-	uploader := NewFileUploader(mockR2Client, cfg, "test-bucket")
+	uploader := NewFileUploader(mockStorage, cfg, "test-bucket")
 
 	// 执行上传（不设置覆盖选项）
 	ctx := context.Background()
@@ -285,12 +279,5 @@ func TestFileUploader_UploadFile_FileExistsNoOverwrite(t *testing.T) {
 	// 验证结果 - 应该返回错误
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "file conflict failed")
-	mockS3Client.AssertExpectations(t)
-}
-
-// Mock 错误类型用于模拟 404 错误
-type MockNotFoundError struct{}
-
-func (e *MockNotFoundError) Error() string {
-	return "NotFound: The specified key does not exist."
+	mockStorage.AssertExpectations(t)
 }
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether name (a slash-separated key or relative path)
+// matches pattern. Patterns use path.Match syntax per segment, plus "**"
+// which matches any number of segments. A pattern without "/" is matched
+// against the last segment only, so "*.jpg" matches "photos/2024/cat.jpg".
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches pattern segments against name segments, expanding "**"
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// "**" consumes zero or more name segments
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// ValidateGlob checks that pattern is a well-formed glob
+func ValidateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// KeyFilter selects keys by include and exclude glob patterns
+type KeyFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// NewKeyFilter creates a filter after validating every pattern
+func NewKeyFilter(include, exclude []string) (*KeyFilter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if err := ValidateGlob(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return &KeyFilter{Include: include, Exclude: exclude}, nil
+}
+
+// Match reports whether name matches at least one include pattern (or there
+// are none) and no exclude pattern
+func (f *KeyFilter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, pattern := range f.Exclude {
+		if MatchGlob(pattern, name) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
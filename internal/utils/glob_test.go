@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.jpg", "cat.jpg", true},
+		{"*.jpg", "photos/2024/cat.jpg", true},
+		{"*.jpg", "photos/cat.png", false},
+		{"photos/*.jpg", "photos/cat.jpg", true},
+		{"photos/*.jpg", "photos/2024/cat.jpg", false},
+		{"photos/**/*.jpg", "photos/cat.jpg", true},
+		{"photos/**/*.jpg", "photos/2024/01/cat.jpg", true},
+		{"**/thumbs/*", "a/b/thumbs/x.png", true},
+		{"**/thumbs/*", "a/b/other/x.png", false},
+		{"logs/**", "logs/2024/app.log", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	filter, err := NewKeyFilter([]string{"*.jpg", "*.png"}, []string{"**/thumbs/*"})
+	require.NoError(t, err)
+
+	assert.True(t, filter.Match("photos/cat.jpg"))
+	assert.True(t, filter.Match("dog.png"))
+	assert.False(t, filter.Match("notes.txt"))
+	assert.False(t, filter.Match("photos/thumbs/cat.jpg"))
+
+	var noFilter *KeyFilter
+	assert.True(t, noFilter.Match("anything"))
+
+	_, err = NewKeyFilter([]string{"[abc"}, nil)
+	assert.Error(t, err)
+}
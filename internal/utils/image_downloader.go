@@ -7,20 +7,26 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 )
 
+// DownloadAPI is the subset of the storage client needed to download objects
+type DownloadAPI interface {
+	Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error)
+	Get(ctx context.Context, bucket, key string, options r2.GetOptions) (*r2.Object, error)
+}
+
 type FileDownloader struct {
-	s3Client   *s3.Client
+	client     DownloadAPI
 	bucketName string
 }
 
-func NewFileDownloader(s3Client *s3.Client, bucketName string) *FileDownloader {
+func NewFileDownloader(client DownloadAPI, bucketName string) *FileDownloader {
 	return &FileDownloader{
-		s3Client:   s3Client,
+		client:     client,
 		bucketName: bucketName,
 	}
 }
@@ -30,7 +36,35 @@ func (d *FileDownloader) SetBucketName(bucketName string) {
 	d.bucketName = bucketName
 }
 
-func (d *FileDownloader) resolveFileNameConflict(originalPath string) string {
+// ConflictPolicy controls what happens when a download target already exists locally
+type ConflictPolicy int
+
+const (
+	// ConflictRename keeps the existing file and writes to "name (N).ext"
+	ConflictRename ConflictPolicy = iota
+	// ConflictSkip leaves the existing file untouched and skips the download
+	ConflictSkip
+	// ConflictOverwrite replaces the existing file
+	ConflictOverwrite
+)
+
+// ParseConflictPolicy converts a policy name (rename, skip, overwrite) to a ConflictPolicy
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch name {
+	case "rename", "":
+		return ConflictRename, nil
+	case "skip":
+		return ConflictSkip, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	default:
+		return ConflictRename, fmt.Errorf("invalid conflict policy: %s (use: rename, skip, overwrite)", name)
+	}
+}
+
+// resolveFileNameConflict returns originalPath, or "name (N).ext" when a file
+// with that name already exists
+func resolveFileNameConflict(originalPath string) string {
 	if _, err := os.Stat(originalPath); os.IsNotExist(err) {
 		// File doesn't exist, use original path
 		return originalPath
@@ -104,32 +138,27 @@ func (d *FileDownloader) DownloadFileWithProgressCallback(ctx context.Context, k
 	localPath := filepath.Join(downloadsDir, filename)
 
 	// Handle file name conflicts
-	localPath = d.resolveFileNameConflict(localPath)
+	localPath = resolveFileNameConflict(localPath)
 
 	// Get object info first to get content length
-	headResult, err := d.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(d.bucketName),
-		Key:    aws.String(key),
-	})
+	headResult, err := d.client.Head(ctx, d.bucketName, key)
 	if err != nil {
 		return fmt.Errorf("failed to get object info: %w", err)
 	}
 
 	// Download the file from S3
-	result, err := d.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(d.bucketName),
-		Key:    aws.String(key),
-	})
+	result, err := d.client.Get(ctx, d.bucketName, key, r2.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to get object from S3: %w", err)
 	}
 	defer result.Body.Close()
 
-	// Create progress reader with callback
-	contentLength := aws.ToInt64(headResult.ContentLength)
+	// Create progress reader with callback, hashing the content as it streams
+	contentLength := headResult.Size
 	// logrus.Infof("DownloadFileWithProgressCallback: content length: %d bytes", contentLength)
+	hashing := NewHashingReader(result.Body)
 	progressReader := &CallbackProgressReader{
-		Reader:   result.Body,
+		Reader:   hashing,
 		total:    contentLength,
 		callback: callback,
 	}
@@ -147,6 +176,13 @@ func (d *FileDownloader) DownloadFileWithProgressCallback(ctx context.Context, k
 		return fmt.Errorf("failed to write file content: %w", err)
 	}
 
+	// Never leave a corrupted file behind
+	if err := VerifyETag(key, result.ETag, hashing.Checksums()); err != nil {
+		file.Close()
+		os.Remove(localPath)
+		return err
+	}
+
 	logrus.Infof("File downloaded successfully to: %s", localPath)
 	return nil
 }
@@ -185,3 +221,70 @@ func (pr *CallbackProgressReader) Read(p []byte) (n int, err error) {
 
 	return n, err
 }
+
+// DownloadToFile downloads a single object to localPath, creating parent directories
+// as needed and applying the given conflict policy. It returns the path that was
+// actually written and whether the download was skipped because the file existed.
+func (d *FileDownloader) DownloadToFile(ctx context.Context, key, localPath string, policy ConflictPolicy, callback ProgressCallback) (string, bool, error) {
+	if _, err := os.Stat(localPath); err == nil {
+		switch policy {
+		case ConflictSkip:
+			logrus.Infof("Skipping %s: %s already exists", key, localPath)
+			return localPath, true, nil
+		case ConflictRename:
+			localPath = resolveFileNameConflict(localPath)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create directory for %s: %w", localPath, err)
+	}
+
+	result, err := d.client.Get(ctx, d.bucketName, key, r2.GetOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer result.Body.Close()
+
+	hashing := NewHashingReader(result.Body)
+	var body io.Reader = hashing
+	if callback != nil {
+		body = &progressReader{
+			reader:   hashing,
+			total:    result.ContentLength,
+			callback: callback,
+		}
+	}
+
+	// Write to a temporary file first so an interrupted download never leaves a
+	// truncated file under the final name
+	tmpPath := localPath + ".r2s3-part"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create local file: %w", err)
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to write file content: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to close local file: %w", err)
+	}
+
+	// Single-part ETags are the content MD5, so a corrupted transfer is caught here
+	if err := VerifyETag(key, result.ETag, hashing.Checksums()); err != nil {
+		os.Remove(tmpPath)
+		return "", false, err
+	}
+
+	if err := os.Rename(tmpPath, localPath); err != nil {
+		os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to move downloaded file into place: %w", err)
+	}
+
+	logrus.Infof("Downloaded %s to %s", key, localPath)
+	return localPath, false, nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ConflictPolicy
+		wantErr  bool
+	}{
+		{"默认为重命名", "", ConflictRename, false},
+		{"重命名", "rename", ConflictRename, false},
+		{"跳过", "skip", ConflictSkip, false},
+		{"覆盖", "overwrite", ConflictOverwrite, false},
+		{"无效策略", "replace", ConflictRename, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseConflictPolicy(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestDownloadToFile_SkipExisting(t *testing.T) {
+	tempDir := t.TempDir()
+	localPath := filepath.Join(tempDir, "existing.txt")
+	require.NoError(t, os.WriteFile(localPath, []byte("original"), 0644))
+
+	// 跳过策略不应访问远端，因此可以使用空客户端
+	downloader := NewFileDownloader(nil, "test-bucket")
+	path, skipped, err := downloader.DownloadToFile(context.Background(), "existing.txt", localPath, ConflictSkip, nil)
+
+	require.NoError(t, err)
+	assert.True(t, skipped)
+	assert.Equal(t, localPath, path)
+
+	content, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+}
+
+func TestResolveFileNameConflict(t *testing.T) {
+	tempDir := t.TempDir()
+	localPath := filepath.Join(tempDir, "photo.jpg")
+	require.NoError(t, os.WriteFile(localPath, []byte("a"), 0644))
+
+	resolved := resolveFileNameConflict(localPath)
+
+	assert.Equal(t, filepath.Join(tempDir, "photo (1).jpg"), resolved)
+}
+
+// newETagTestDownloader 创建一个连接到返回固定内容和 ETag 的测试服务器的下载器
+func newETagTestDownloader(t *testing.T, body, etag string) *FileDownloader {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+etag+`"`)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "auto",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		UsePathStyle: true,
+	})
+	return NewFileDownloader(r2.NewS3Storage(client), "test-bucket")
+}
+
+func TestDownloadToFile_VerifiesETag(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// ETag 与内容的 MD5 一致时正常写入
+	downloader := newETagTestDownloader(t, "Hello, World!", "65a8e27d8879283831b664bd8b7f0ad4")
+	localPath := filepath.Join(tempDir, "ok.txt")
+	_, _, err := downloader.DownloadToFile(context.Background(), "ok.txt", localPath, ConflictOverwrite, nil)
+	require.NoError(t, err)
+	assert.FileExists(t, localPath)
+
+	// 内容损坏时返回校验错误，且不留下任何文件
+	downloader = newETagTestDownloader(t, "Hello, World?", "65a8e27d8879283831b664bd8b7f0ad4")
+	localPath = filepath.Join(tempDir, "bad.txt")
+	_, _, err = downloader.DownloadToFile(context.Background(), "bad.txt", localPath, ConflictOverwrite, nil)
+	var mismatch *ChecksumMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.NoFileExists(t, localPath)
+	assert.NoFileExists(t, localPath+".r2s3-part")
+
+	// 分片上传的 ETag 无法校验，直接接受
+	downloader = newETagTestDownloader(t, "Hello, World?", "65a8e27d8879283831b664bd8b7f0ad4-2")
+	localPath = filepath.Join(tempDir, "multipart.txt")
+	_, _, err = downloader.DownloadToFile(context.Background(), "multipart.txt", localPath, ConflictOverwrite, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,543 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+const (
+	// MinPartSize 是 S3 允许的最小分片大小（最后一个分片除外）
+	MinPartSize int64 = 5 * 1024 * 1024
+	// MaxParts 是单次分片上传允许的最大分片数
+	MaxParts = 10000
+
+	// DefaultMultipartThreshold 默认分片上传阈值
+	DefaultMultipartThreshold int64 = 100 * 1024 * 1024
+	// DefaultPartSize 默认分片大小
+	DefaultPartSize int64 = 16 * 1024 * 1024
+	// DefaultPartConcurrency 默认并发上传的分片数
+	DefaultPartConcurrency = 4
+
+	// abortTimeout 中止分片上传的超时时间
+	abortTimeout = 30 * time.Second
+)
+
+// MultipartUploadAPI 定义分片上传所需的存储接口，便于测试
+type MultipartUploadAPI interface {
+	CreateMultipart(ctx context.Context, bucket, key string, options r2.PutOptions) (r2.MultipartUpload, error)
+	UploadPart(ctx context.Context, upload r2.MultipartUpload, number int32, body io.Reader, size int64, contentMD5 string) (string, error)
+	CompleteMultipart(ctx context.Context, upload r2.MultipartUpload, parts []r2.Part) error
+	AbortMultipart(ctx context.Context, upload r2.MultipartUpload) error
+	ListParts(ctx context.Context, upload r2.MultipartUpload) ([]r2.Part, error)
+}
+
+// MultipartOptions 分片上传选项
+type MultipartOptions struct {
+	Threshold   int64  // 达到该大小的文件使用分片上传
+	PartSize    int64  // 分片大小
+	Concurrency int    // 并发上传的分片数
+	JournalDir  string // 断点续传记录目录，默认 ~/.r2s3-cli/multipart
+}
+
+// MultipartOptionsFromConfig 根据上传配置生成分片上传选项，未设置的字段使用默认值
+func MultipartOptionsFromConfig(cfg *config.UploadConfig) *MultipartOptions {
+	options := &MultipartOptions{}
+	if cfg != nil {
+		options.Threshold = int64(cfg.MultipartThreshold) * 1024 * 1024
+		options.PartSize = int64(cfg.MultipartPartSize) * 1024 * 1024
+		options.Concurrency = cfg.MultipartConcurrency
+	}
+	options.applyDefaults()
+	return options
+}
+
+// applyDefaults 为未设置的字段填充默认值
+func (o *MultipartOptions) applyDefaults() {
+	if o.Threshold <= 0 {
+		o.Threshold = DefaultMultipartThreshold
+	}
+	if o.PartSize <= 0 {
+		o.PartSize = DefaultPartSize
+	}
+	if o.PartSize < MinPartSize {
+		o.PartSize = MinPartSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultPartConcurrency
+	}
+}
+
+// ShouldUseMultipart 判断给定大小的文件是否应使用分片上传
+func (o *MultipartOptions) ShouldUseMultipart(fileSize int64) bool {
+	return fileSize >= o.Threshold
+}
+
+// PartSizeFor 返回上传给定大小的文件时实际使用的分片大小
+func (o *MultipartOptions) PartSizeFor(fileSize int64) int64 {
+	return effectivePartSize(fileSize, o.PartSize)
+}
+
+// effectivePartSize 计算实际分片大小，保证分片数不超过 MaxParts
+func effectivePartSize(fileSize, partSize int64) int64 {
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+	for (fileSize+partSize-1)/partSize > MaxParts {
+		partSize *= 2
+	}
+	return partSize
+}
+
+// MultipartUploader 使用 CreateMultipart/UploadPart/CompleteMultipart 上传大文件，
+// 并在本地记录已完成的分片，以便中断后续传
+type MultipartUploader struct {
+	client     MultipartUploadAPI
+	bucketName string
+	options    MultipartOptions
+}
+
+// NewMultipartUploader 创建新的分片上传器
+func NewMultipartUploader(client MultipartUploadAPI, bucketName string, options *MultipartOptions) *MultipartUploader {
+	var opts MultipartOptions
+	if options != nil {
+		opts = *options
+	}
+	opts.applyDefaults()
+
+	return &MultipartUploader{
+		client:     client,
+		bucketName: bucketName,
+		options:    opts,
+	}
+}
+
+// multipartJournal 记录一次分片上传的状态，用于断点续传
+type multipartJournal struct {
+	Bucket    string          `json:"bucket"`
+	Key       string          `json:"key"`
+	UploadID  string          `json:"upload_id"`
+	FileSize  int64           `json:"file_size"`
+	ModTime   time.Time       `json:"mod_time"`
+	PartSize  int64           `json:"part_size"`
+	Parts     []completedPart `json:"parts"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// upload 返回续传记录对应的远端分片上传
+func (j *multipartJournal) upload() r2.MultipartUpload {
+	return r2.MultipartUpload{Bucket: j.Bucket, Key: j.Key, UploadID: j.UploadID}
+}
+
+// completedPart 已上传完成的分片
+type completedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+// Upload 分片上传本地文件。若存在匹配的续传记录且远端上传仍有效，则只上传剩余分片。
+// ctx 被取消时会调用 AbortMultipart 并删除续传记录；其他错误保留记录以便下次续传。
+func (mu *MultipartUploader) Upload(ctx context.Context, localPath, remotePath string, headers ObjectHeaders, publicAccess bool, callback ProgressCallback) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return &uploadError{operation: "open file", path: localPath, err: err}
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return &uploadError{operation: "get file info", path: localPath, err: err}
+	}
+
+	fileSize := fileInfo.Size()
+	partSize := effectivePartSize(fileSize, mu.options.PartSize)
+	journalPath := mu.journalPath(remotePath, fileInfo)
+
+	// 尝试恢复之前中断的上传
+	journal := mu.resumeJournal(ctx, journalPath, remotePath, fileInfo, partSize)
+	if journal == nil {
+		journal, err = mu.createUpload(ctx, remotePath, fileInfo, partSize, headers, publicAccess)
+		if err != nil {
+			return &uploadError{operation: "create multipart upload", path: localPath, err: err}
+		}
+		if err := saveJournal(journalPath, journal); err != nil {
+			logrus.Warnf("Failed to save multipart journal: %v", err)
+		}
+	} else {
+		logrus.Infof("Resuming multipart upload of %s (%d parts already uploaded)", localPath, len(journal.Parts))
+	}
+
+	err = mu.uploadParts(ctx, file, journal, journalPath, callback)
+	if err != nil {
+		if ctx.Err() != nil {
+			// 用户取消：中止远端上传，避免残留未完成的分片
+			mu.abort(journal)
+			removeJournal(journalPath)
+			return &uploadError{operation: "upload parts", path: localPath, err: ctx.Err()}
+		}
+		return &uploadError{operation: "upload parts", path: localPath, err: err}
+	}
+
+	if err := mu.complete(ctx, journal); err != nil {
+		return &uploadError{operation: "complete multipart upload", path: localPath, err: err}
+	}
+
+	removeJournal(journalPath)
+	logrus.Infof("Successfully uploaded %s to %s in %d parts", localPath, remotePath, len(journal.Parts))
+	return nil
+}
+
+// createUpload 创建新的分片上传
+func (mu *MultipartUploader) createUpload(ctx context.Context, remotePath string, fileInfo os.FileInfo, partSize int64, headers ObjectHeaders, publicAccess bool) (*multipartJournal, error) {
+	options := r2.PutOptions{Public: publicAccess}
+	headers.ApplyToPut(&options)
+
+	upload, err := mu.client.CreateMultipart(ctx, mu.bucketName, remotePath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("Created multipart upload %s for %s", upload.UploadID, remotePath)
+	return &multipartJournal{
+		Bucket:    mu.bucketName,
+		Key:       remotePath,
+		UploadID:  upload.UploadID,
+		FileSize:  fileInfo.Size(),
+		ModTime:   fileInfo.ModTime(),
+		PartSize:  partSize,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// resumeJournal 加载续传记录并与远端已上传分片核对，无法续传时返回 nil。
+// 记录与文件不符时中止旧的分片上传，避免远端残留分片
+func (mu *MultipartUploader) resumeJournal(ctx context.Context, journalPath, remotePath string, fileInfo os.FileInfo, partSize int64) *multipartJournal {
+	journal, err := loadJournal(journalPath)
+	if err != nil || journal == nil {
+		return nil
+	}
+
+	if journal.Bucket != mu.bucketName || journal.Key != remotePath ||
+		journal.FileSize != fileInfo.Size() || !journal.ModTime.Equal(fileInfo.ModTime()) ||
+		journal.PartSize != partSize {
+		logrus.Infof("Multipart journal for %s does not match the file, starting over", remotePath)
+		mu.abort(journal)
+		removeJournal(journalPath)
+		return nil
+	}
+
+	// 以远端记录为准，过滤掉本地记录中不存在或大小不符的分片
+	remoteParts, err := mu.listParts(ctx, journal)
+	if err != nil {
+		logrus.Warnf("Cannot resume multipart upload %s, starting over: %v", journal.UploadID, err)
+		removeJournal(journalPath)
+		return nil
+	}
+
+	var verified []completedPart
+	for _, part := range journal.Parts {
+		if remote, ok := remoteParts[part.PartNumber]; ok && remote.Size == part.Size {
+			verified = append(verified, remote)
+		}
+	}
+	journal.Parts = verified
+
+	return journal
+}
+
+// listParts 列出远端已上传的分片
+func (mu *MultipartUploader) listParts(ctx context.Context, journal *multipartJournal) (map[int32]completedPart, error) {
+	remoteParts, err := mu.client.ListParts(ctx, journal.upload())
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make(map[int32]completedPart, len(remoteParts))
+	for _, part := range remoteParts {
+		parts[part.Number] = completedPart{
+			PartNumber: part.Number,
+			ETag:       part.ETag,
+			Size:       part.Size,
+		}
+	}
+	return parts, nil
+}
+
+// partJob 待上传的分片
+type partJob struct {
+	number int32
+	offset int64
+	size   int64
+}
+
+// uploadParts 并发上传尚未完成的分片，每完成一个分片即更新续传记录
+func (mu *MultipartUploader) uploadParts(ctx context.Context, file *os.File, journal *multipartJournal, journalPath string, callback ProgressCallback) error {
+	done := make(map[int32]bool, len(journal.Parts))
+	var uploadedBytes int64
+	for _, part := range journal.Parts {
+		done[part.PartNumber] = true
+		uploadedBytes += part.Size
+	}
+
+	var jobs []partJob
+	for offset, number := int64(0), int32(1); offset < journal.FileSize; offset, number = offset+journal.PartSize, number+1 {
+		if done[number] {
+			continue
+		}
+		jobs = append(jobs, partJob{
+			number: number,
+			offset: offset,
+			size:   min(journal.PartSize, journal.FileSize-offset),
+		})
+	}
+
+	progress := &partProgress{total: journal.FileSize, transferred: uploadedBytes, callback: callback}
+	progress.report(0)
+
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobCh := make(chan partJob)
+	var (
+		wg       sync.WaitGroup
+		partsMu  sync.Mutex
+		firstErr error
+	)
+
+	for i := 0; i < min(mu.options.Concurrency, len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				part, err := mu.uploadPart(uploadCtx, file, journal, job, progress)
+				partsMu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("part %d: %w", job.number, err)
+						cancel()
+					}
+				} else {
+					journal.Parts = append(journal.Parts, part)
+					if err := saveJournal(journalPath, journal); err != nil {
+						logrus.Warnf("Failed to update multipart journal: %v", err)
+					}
+				}
+				partsMu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		select {
+		case jobCh <- job:
+		case <-uploadCtx.Done():
+		}
+		if uploadCtx.Err() != nil {
+			break
+		}
+	}
+	close(jobCh)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// uploadPart 上传单个分片
+func (mu *MultipartUploader) uploadPart(ctx context.Context, file *os.File, journal *multipartJournal, job partJob, progress *partProgress) (completedPart, error) {
+	section := io.NewSectionReader(file, job.offset, job.size)
+
+	// 先计算分片的 MD5，服务端据此拒绝传输中损坏的分片
+	checksums, err := ComputeChecksums(section)
+	if err != nil {
+		return completedPart{}, fmt.Errorf("failed to checksum part %d: %w", job.number, err)
+	}
+
+	body := &partReader{
+		reader:   section,
+		progress: progress,
+	}
+
+	etag, err := mu.client.UploadPart(ctx, journal.upload(), job.number, body, job.size, checksums.ContentMD5())
+	if err != nil {
+		// 回退该分片已计入的进度
+		progress.report(-body.read)
+		return completedPart{}, err
+	}
+
+	logrus.Debugf("Uploaded part %d (%d bytes) of %s", job.number, job.size, journal.Key)
+	return completedPart{
+		PartNumber: job.number,
+		ETag:       etag,
+		Size:       job.size,
+	}, nil
+}
+
+// complete 按分片序号提交分片列表，完成上传
+func (mu *MultipartUploader) complete(ctx context.Context, journal *multipartJournal) error {
+	sort.Slice(journal.Parts, func(i, j int) bool {
+		return journal.Parts[i].PartNumber < journal.Parts[j].PartNumber
+	})
+
+	parts := make([]r2.Part, 0, len(journal.Parts))
+	for _, part := range journal.Parts {
+		parts = append(parts, r2.Part{
+			Number: part.PartNumber,
+			ETag:   part.ETag,
+			Size:   part.Size,
+		})
+	}
+
+	return mu.client.CompleteMultipart(ctx, journal.upload(), parts)
+}
+
+// abort 中止远端分片上传。使用独立的 context，确保取消后仍能发出请求
+func (mu *MultipartUploader) abort(journal *multipartJournal) {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	if err := mu.client.AbortMultipart(ctx, journal.upload()); err != nil {
+		logrus.Warnf("Failed to abort multipart upload %s: %v", journal.UploadID, err)
+		return
+	}
+	logrus.Infof("Aborted multipart upload %s for %s", journal.UploadID, journal.Key)
+}
+
+// journalPath 根据 bucket、key 以及文件大小和修改时间计算续传记录路径
+func (mu *MultipartUploader) journalPath(remotePath string, fileInfo os.FileInfo) string {
+	dir := mu.options.JournalDir
+	if dir == "" {
+		dir = defaultJournalDir()
+	}
+
+	id := strings.Join([]string{
+		mu.bucketName,
+		remotePath,
+		fmt.Sprintf("%d", fileInfo.Size()),
+		fmt.Sprintf("%d", fileInfo.ModTime().UnixNano()),
+	}, "\x00")
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// defaultJournalDir 返回默认续传记录目录
+func defaultJournalDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "r2s3-cli", "multipart")
+	}
+	return filepath.Join(homeDir, ".r2s3-cli", "multipart")
+}
+
+// loadJournal 读取续传记录，文件不存在时返回 nil
+func loadJournal(path string) (*multipartJournal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var journal multipartJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse multipart journal: %w", err)
+	}
+	return &journal, nil
+}
+
+// saveJournal 原子写入续传记录
+func saveJournal(path string, journal *multipartJournal) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// removeJournal 删除续传记录
+func removeJournal(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logrus.Warnf("Failed to remove multipart journal %s: %v", path, err)
+	}
+}
+
+// partProgress 汇总所有分片的上传进度并转发给 ProgressCallback
+type partProgress struct {
+	mu          sync.Mutex
+	total       int64
+	transferred int64
+	callback    ProgressCallback
+}
+
+// report 累加已传输字节数（可为负数，用于重试回退）
+func (p *partProgress) report(delta int64) {
+	if p.callback == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.transferred += delta
+	percentage := float64(0)
+	if p.total > 0 {
+		percentage = float64(p.transferred) / float64(p.total) * 100
+	}
+	p.callback(p.transferred, p.total, percentage)
+}
+
+// partReader 包装分片的 SectionReader，读取时上报进度，Seek 时回退进度
+type partReader struct {
+	reader   *io.SectionReader
+	read     int64
+	progress *partProgress
+}
+
+// Read 实现 io.Reader 接口
+func (pr *partReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 {
+		pr.read += int64(n)
+		pr.progress.report(int64(n))
+	}
+	return n, err
+}
+
+// Seek 实现 io.Seeker 接口，供 SDK 重试时重置分片
+func (pr *partReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := pr.reader.Seek(offset, whence)
+	if err == nil {
+		pr.progress.report(pos - pr.read)
+		pr.read = pos
+	}
+	return pos, err
+}
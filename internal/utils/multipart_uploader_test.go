@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// fakeMultipartClient 在内存中模拟分片上传
+type fakeMultipartClient struct {
+	mu        sync.Mutex
+	uploads   map[string]map[int32][]byte
+	completed map[string][]byte
+	aborted   []string
+	nextID    int
+	// failPart 不为 0 时，上传该分片返回错误
+	failPart int32
+	// onPart 在每个分片上传时调用
+	onPart func(partNumber int32)
+	// uploadedParts 记录实际上传的分片号
+	uploadedParts []int32
+}
+
+func newFakeMultipartClient() *fakeMultipartClient {
+	return &fakeMultipartClient{
+		uploads:   make(map[string]map[int32][]byte),
+		completed: make(map[string][]byte),
+	}
+}
+
+func (f *fakeMultipartClient) CreateMultipart(ctx context.Context, bucket, key string, options r2.PutOptions) (r2.MultipartUpload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	id := fmt.Sprintf("upload-%d", f.nextID)
+	f.uploads[id] = make(map[int32][]byte)
+	return r2.MultipartUpload{Bucket: bucket, Key: key, UploadID: id}, nil
+}
+
+func (f *fakeMultipartClient) UploadPart(ctx context.Context, upload r2.MultipartUpload, number int32, body io.Reader, size int64, contentMD5 string) (string, error) {
+	if f.onPart != nil {
+		f.onPart(number)
+	}
+	if number == f.failPart {
+		return "", errors.New("connection reset")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts, ok := f.uploads[upload.UploadID]
+	if !ok {
+		return "", r2.ErrNotFound
+	}
+	parts[number] = data
+	f.uploadedParts = append(f.uploadedParts, number)
+	return fmt.Sprintf("\"etag-%d\"", number), nil
+}
+
+func (f *fakeMultipartClient) CompleteMultipart(ctx context.Context, upload r2.MultipartUpload, completed []r2.Part) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts, ok := f.uploads[upload.UploadID]
+	if !ok {
+		return r2.ErrNotFound
+	}
+
+	var buf bytes.Buffer
+	for _, part := range completed {
+		buf.Write(parts[part.Number])
+	}
+	f.completed[upload.Key] = buf.Bytes()
+	delete(f.uploads, upload.UploadID)
+	return nil
+}
+
+func (f *fakeMultipartClient) AbortMultipart(ctx context.Context, upload r2.MultipartUpload) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.uploads, upload.UploadID)
+	f.aborted = append(f.aborted, upload.UploadID)
+	return nil
+}
+
+func (f *fakeMultipartClient) ListParts(ctx context.Context, upload r2.MultipartUpload) ([]r2.Part, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts, ok := f.uploads[upload.UploadID]
+	if !ok {
+		return nil, r2.ErrNotFound
+	}
+
+	var result []r2.Part
+	for number, data := range parts {
+		result = append(result, r2.Part{
+			Number: number,
+			ETag:   fmt.Sprintf("\"etag-%d\"", number),
+			Size:   int64(len(data)),
+		})
+	}
+	return result, nil
+}
+
+// createMultipartTestFile 创建指定大小的测试文件
+func createMultipartTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	path := filepath.Join(t.TempDir(), "large.bin")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path, data
+}
+
+// journalFiles 返回续传记录目录中的文件
+func journalFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestMultipartUploader_Upload(t *testing.T) {
+	localPath, data := createMultipartTestFile(t, int(MinPartSize)*2+1024)
+	journalDir := t.TempDir()
+	client := newFakeMultipartClient()
+
+	uploader := NewMultipartUploader(client, "test-bucket", &MultipartOptions{
+		PartSize:    MinPartSize,
+		Concurrency: 3,
+		JournalDir:  journalDir,
+	})
+
+	var lastUploaded, lastTotal int64
+	var callbackMu sync.Mutex
+	callback := func(uploaded, total int64, percentage float64) {
+		callbackMu.Lock()
+		defer callbackMu.Unlock()
+		lastUploaded, lastTotal = uploaded, total
+	}
+
+	err := uploader.Upload(context.Background(), localPath, "large.bin", ObjectHeaders{ContentType: "application/octet-stream"}, false, callback)
+	require.NoError(t, err)
+
+	assert.Equal(t, data, client.completed["large.bin"])
+	assert.Len(t, client.uploadedParts, 3)
+	assert.Equal(t, int64(len(data)), lastTotal)
+	assert.Equal(t, int64(len(data)), lastUploaded)
+	assert.Empty(t, journalFiles(t, journalDir), "续传记录应在完成后删除")
+}
+
+func TestMultipartUploader_ResumeAfterFailure(t *testing.T) {
+	localPath, data := createMultipartTestFile(t, int(MinPartSize)*3)
+	journalDir := t.TempDir()
+	client := newFakeMultipartClient()
+	client.failPart = 3
+
+	options := &MultipartOptions{PartSize: MinPartSize, Concurrency: 1, JournalDir: journalDir}
+
+	// 第一次上传在第 3 个分片失败，保留续传记录
+	err := NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.Error(t, err)
+	assert.Len(t, journalFiles(t, journalDir), 1)
+	assert.Empty(t, client.aborted)
+
+	// 第二次上传只需上传剩余分片
+	client.failPart = 0
+	client.uploadedParts = nil
+	err = NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.NoError(t, err)
+
+	sort.Slice(client.uploadedParts, func(i, j int) bool { return client.uploadedParts[i] < client.uploadedParts[j] })
+	assert.Equal(t, []int32{3}, client.uploadedParts)
+	assert.Equal(t, data, client.completed["large.bin"])
+	assert.Empty(t, journalFiles(t, journalDir))
+}
+
+func TestMultipartUploader_CancelAborts(t *testing.T) {
+	localPath, _ := createMultipartTestFile(t, int(MinPartSize)*3)
+	journalDir := t.TempDir()
+	client := newFakeMultipartClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.onPart = func(partNumber int32) {
+		if partNumber == 2 {
+			cancel()
+		}
+	}
+
+	uploader := NewMultipartUploader(client, "test-bucket", &MultipartOptions{
+		PartSize:    MinPartSize,
+		Concurrency: 1,
+		JournalDir:  journalDir,
+	})
+
+	err := uploader.Upload(ctx, localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"upload-1"}, client.aborted)
+	assert.Empty(t, journalFiles(t, journalDir), "取消后应删除续传记录")
+	assert.NotContains(t, client.completed, "large.bin")
+}
+
+func TestMultipartUploader_StaleJournalStartsOver(t *testing.T) {
+	localPath, data := createMultipartTestFile(t, int(MinPartSize)*2)
+	journalDir := t.TempDir()
+	client := newFakeMultipartClient()
+	client.failPart = 2
+
+	options := &MultipartOptions{PartSize: MinPartSize, Concurrency: 1, JournalDir: journalDir}
+	err := NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.Error(t, err)
+
+	// 远端上传已失效（例如被生命周期规则清理），应重新开始
+	client.uploads = make(map[string]map[int32][]byte)
+	client.failPart = 0
+	client.uploadedParts = nil
+
+	err = NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.NoError(t, err)
+	assert.Len(t, client.uploadedParts, 2)
+	assert.Equal(t, data, client.completed["large.bin"])
+}
+
+func TestMultipartUploader_MismatchedJournalAborts(t *testing.T) {
+	localPath, data := createMultipartTestFile(t, int(MinPartSize)*2)
+	journalDir := t.TempDir()
+	client := newFakeMultipartClient()
+	client.failPart = 2
+
+	options := &MultipartOptions{PartSize: MinPartSize, Concurrency: 1, JournalDir: journalDir}
+	err := NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.Error(t, err)
+	require.Len(t, journalFiles(t, journalDir), 1)
+
+	// 分片大小改变后记录不再适用，中止旧上传再重新开始
+	client.failPart = 0
+	options.PartSize = MinPartSize * 2
+	err = NewMultipartUploader(client, "test-bucket", options).Upload(context.Background(), localPath, "large.bin", ObjectHeaders{}, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upload-1"}, client.aborted)
+	assert.Empty(t, client.uploads)
+	assert.Equal(t, data, client.completed["large.bin"])
+}
+
+func TestEffectivePartSize(t *testing.T) {
+	assert.Equal(t, MinPartSize, effectivePartSize(1024, 1024))
+	assert.Equal(t, DefaultPartSize, effectivePartSize(DefaultPartSize*10, DefaultPartSize))
+
+	// 超过 MaxParts 时分片大小翻倍
+	fileSize := MinPartSize * (MaxParts + 1)
+	partSize := effectivePartSize(fileSize, MinPartSize)
+	assert.Equal(t, MinPartSize*2, partSize)
+	assert.LessOrEqual(t, (fileSize+partSize-1)/partSize, int64(MaxParts))
+}
+
+func TestMultipartOptions_ShouldUseMultipart(t *testing.T) {
+	options := MultipartOptionsFromConfig(nil)
+	assert.False(t, options.ShouldUseMultipart(DefaultMultipartThreshold-1))
+	assert.True(t, options.ShouldUseMultipart(DefaultMultipartThreshold))
+}
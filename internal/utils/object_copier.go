@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+const (
+	// MaxCopyObjectSize 是单次复制允许的最大对象大小，超过时使用 UploadPartCopy
+	MaxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
+	// DefaultCopyPartSize 默认分片复制大小
+	DefaultCopyPartSize int64 = 512 * 1024 * 1024
+)
+
+// CopyAPI 定义服务端复制所需的存储接口，便于测试
+type CopyAPI interface {
+	Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error)
+	Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, headers *r2.Headers) error
+	CreateMultipart(ctx context.Context, bucket, key string, options r2.PutOptions) (r2.MultipartUpload, error)
+	UploadPartCopy(ctx context.Context, upload r2.MultipartUpload, number int32, srcBucket, srcKey string, start, end int64) (string, error)
+	CompleteMultipart(ctx context.Context, upload r2.MultipartUpload, parts []r2.Part) error
+	AbortMultipart(ctx context.Context, upload r2.MultipartUpload) error
+}
+
+// CopyOptions 服务端复制选项
+type CopyOptions struct {
+	// Metadata 不为 nil 时替换目标对象的用户元数据，否则保留源对象元数据
+	Metadata map[string]string
+	// ContentType 不为空时替换目标对象的 Content-Type
+	ContentType string
+	// CacheControl 不为 nil 时替换 Cache-Control，空字符串表示清除
+	CacheControl *string
+	// ContentDisposition 不为 nil 时替换 Content-Disposition，空字符串表示清除
+	ContentDisposition *string
+	// PartSize 大对象分片复制的分片大小
+	PartSize int64
+	// Concurrency 并发复制的分片数
+	Concurrency int
+}
+
+// replacesMetadata 判断是否需要替换元数据
+func (o *CopyOptions) replacesMetadata() bool {
+	return o.Metadata != nil || o.ContentType != "" || o.CacheControl != nil || o.ContentDisposition != nil
+}
+
+// ObjectCopier 在服务端复制对象，不经过本地下载和上传
+type ObjectCopier struct {
+	client CopyAPI
+}
+
+// NewObjectCopier 创建新的对象复制器
+func NewObjectCopier(client CopyAPI) *ObjectCopier {
+	return &ObjectCopier{client: client}
+}
+
+// Copy 将 srcBucket/srcKey 复制到 dstBucket/dstKey，返回复制的字节数。
+// 小于等于 5GB 的对象单次复制，更大的对象使用 UploadPartCopy 分片复制。
+func (c *ObjectCopier) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, options *CopyOptions) (int64, error) {
+	if options == nil {
+		options = &CopyOptions{}
+	}
+
+	head, err := c.client.Head(ctx, srcBucket, srcKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get source object %s/%s: %w", srcBucket, srcKey, err)
+	}
+	size := head.Size
+
+	if size <= MaxCopyObjectSize {
+		return size, c.copyObject(ctx, srcBucket, srcKey, dstBucket, dstKey, head, options)
+	}
+	return size, c.copyMultipart(ctx, srcBucket, srcKey, dstBucket, dstKey, head, options)
+}
+
+// UpdateMetadata 通过复制对象到自身并替换请求头，修改请求头和用户元数据
+func (c *ObjectCopier) UpdateMetadata(ctx context.Context, bucket, key string, options *CopyOptions) error {
+	if options == nil || !options.replacesMetadata() {
+		return fmt.Errorf("no metadata changes for %s", key)
+	}
+	_, err := c.Copy(ctx, bucket, key, bucket, key, options)
+	return err
+}
+
+// targetHeaders 返回目标对象的请求头和元数据：以源对象为准，再应用选项中的覆盖值
+func targetHeaders(head *r2.ObjectInfo, options *CopyOptions) r2.Headers {
+	headers := head.Headers
+	if options.Metadata != nil {
+		headers.Metadata = options.Metadata
+	}
+	if options.ContentType != "" {
+		headers.ContentType = options.ContentType
+	}
+	if options.CacheControl != nil {
+		headers.CacheControl = *options.CacheControl
+	}
+	if options.ContentDisposition != nil {
+		headers.ContentDisposition = *options.ContentDisposition
+	}
+	return headers
+}
+
+// copyObject 单次复制对象
+func (c *ObjectCopier) copyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, head *r2.ObjectInfo, options *CopyOptions) error {
+	// 替换元数据时需要同时提供完整的元数据和 Content-Type，否则会被清空
+	var headers *r2.Headers
+	if options.replacesMetadata() {
+		target := targetHeaders(head, options)
+		headers = &target
+	}
+
+	if err := c.client.Copy(ctx, srcBucket, srcKey, dstBucket, dstKey, headers); err != nil {
+		return fmt.Errorf("failed to copy %s/%s to %s/%s: %w", srcBucket, srcKey, dstBucket, dstKey, err)
+	}
+
+	logrus.Infof("Copied %s/%s to %s/%s", srcBucket, srcKey, dstBucket, dstKey)
+	return nil
+}
+
+// copyMultipart 使用 UploadPartCopy 分片复制大对象，失败时中止分片上传
+func (c *ObjectCopier) copyMultipart(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, head *r2.ObjectInfo, options *CopyOptions) error {
+	size := head.Size
+	partSize := options.PartSize
+	if partSize <= 0 {
+		partSize = DefaultCopyPartSize
+	}
+	partSize = min(effectivePartSize(size, partSize), MaxCopyObjectSize)
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultPartConcurrency
+	}
+
+	// 分片复制总是由 CreateMultipart 决定元数据
+	upload, err := c.client.CreateMultipart(ctx, dstBucket, dstKey, r2.PutOptions{Headers: targetHeaders(head, options)})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart copy: %w", err)
+	}
+
+	abort := func() {
+		// 使用独立的 context，确保取消后仍能中止
+		abortCtx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+		defer cancel()
+		if abortErr := c.client.AbortMultipart(abortCtx, upload); abortErr != nil {
+			logrus.Warnf("Failed to abort multipart copy of %s: %v", dstKey, abortErr)
+		}
+	}
+
+	copyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type copyJob struct {
+		number     int32
+		start, end int64
+	}
+	jobs := make(chan copyJob)
+
+	var (
+		wg       sync.WaitGroup
+		partsMu  sync.Mutex
+		parts    []r2.Part
+		firstErr error
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				etag, err := c.client.UploadPartCopy(copyCtx, upload, job.number, srcBucket, srcKey, job.start, job.end)
+
+				partsMu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("part %d: %w", job.number, err)
+						cancel()
+					}
+				} else {
+					parts = append(parts, r2.Part{
+						Number: job.number,
+						ETag:   etag,
+						Size:   job.end - job.start + 1,
+					})
+				}
+				partsMu.Unlock()
+			}
+		}()
+	}
+
+	for offset, number := int64(0), int32(1); offset < size; offset, number = offset+partSize, number+1 {
+		job := copyJob{number: number, start: offset, end: min(offset+partSize, size) - 1}
+		select {
+		case jobs <- job:
+		case <-copyCtx.Done():
+		}
+		if copyCtx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		abort()
+		return fmt.Errorf("failed to copy %s/%s to %s/%s: %w", srcBucket, srcKey, dstBucket, dstKey, firstErr)
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Number < parts[j].Number
+	})
+	if err := c.client.CompleteMultipart(ctx, upload, parts); err != nil {
+		abort()
+		return fmt.Errorf("failed to complete multipart copy of %s: %w", dstKey, err)
+	}
+
+	logrus.Infof("Copied %s/%s to %s/%s in %d parts", srcBucket, srcKey, dstBucket, dstKey, len(parts))
+	return nil
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// copyRequest 记录一次 Copy 调用
+type copyRequest struct {
+	srcBucket, srcKey string
+	dstBucket, dstKey string
+	headers           *r2.Headers
+}
+
+// fakeCopyClient 记录服务端复制请求
+type fakeCopyClient struct {
+	mu          sync.Mutex
+	size        int64
+	metadata    map[string]string
+	copies      []copyRequest
+	partRanges  map[int32][2]int64
+	completed   []r2.Part
+	aborted     bool
+	failPartNum int32
+}
+
+func (f *fakeCopyClient) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	return &r2.ObjectInfo{
+		Key:  key,
+		Size: f.size,
+		Headers: r2.Headers{
+			ContentType: "image/png",
+			Metadata:    f.metadata,
+		},
+	}, nil
+}
+
+func (f *fakeCopyClient) Copy(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, headers *r2.Headers) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.copies = append(f.copies, copyRequest{srcBucket, srcKey, dstBucket, dstKey, headers})
+	return nil
+}
+
+func (f *fakeCopyClient) CreateMultipart(ctx context.Context, bucket, key string, options r2.PutOptions) (r2.MultipartUpload, error) {
+	return r2.MultipartUpload{Bucket: bucket, Key: key, UploadID: "copy-1"}, nil
+}
+
+func (f *fakeCopyClient) UploadPartCopy(ctx context.Context, upload r2.MultipartUpload, number int32, srcBucket, srcKey string, start, end int64) (string, error) {
+	if number == f.failPartNum {
+		return "", errors.New("internal error")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.partRanges == nil {
+		f.partRanges = make(map[int32][2]int64)
+	}
+	f.partRanges[number] = [2]int64{start, end}
+	return fmt.Sprintf("\"etag-%d\"", number), nil
+}
+
+func (f *fakeCopyClient) CompleteMultipart(ctx context.Context, upload r2.MultipartUpload, parts []r2.Part) error {
+	f.completed = parts
+	return nil
+}
+
+func (f *fakeCopyClient) AbortMultipart(ctx context.Context, upload r2.MultipartUpload) error {
+	f.aborted = true
+	return nil
+}
+
+func TestObjectCopier_CopyPreservesMetadata(t *testing.T) {
+	client := &fakeCopyClient{size: 1024, metadata: map[string]string{"owner": "alice"}}
+
+	size, err := NewObjectCopier(client).Copy(context.Background(), "src", "photos/my cat.png", "dst", "archive/cat.png", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), size)
+
+	require.Len(t, client.copies, 1)
+	request := client.copies[0]
+	assert.Equal(t, copyRequest{"src", "photos/my cat.png", "dst", "archive/cat.png", nil}, request)
+}
+
+func TestObjectCopier_CopyReplacesMetadata(t *testing.T) {
+	client := &fakeCopyClient{size: 1024, metadata: map[string]string{"owner": "alice"}}
+
+	options := &CopyOptions{Metadata: map[string]string{"owner": "bob"}}
+	_, err := NewObjectCopier(client).Copy(context.Background(), "src", "a.png", "src", "b.png", options)
+	require.NoError(t, err)
+
+	headers := client.copies[0].headers
+	require.NotNil(t, headers)
+	assert.Equal(t, map[string]string{"owner": "bob"}, headers.Metadata)
+	// 未指定时保留原 Content-Type
+	assert.Equal(t, "image/png", headers.ContentType)
+}
+
+func TestObjectCopier_LargeObjectUsesPartCopy(t *testing.T) {
+	client := &fakeCopyClient{size: MaxCopyObjectSize + 1}
+
+	_, err := NewObjectCopier(client).Copy(context.Background(), "src", "big.bin", "dst", "big.bin", &CopyOptions{
+		PartSize:    2 * 1024 * 1024 * 1024,
+		Concurrency: 2,
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, client.copies)
+	require.Len(t, client.partRanges, 3)
+	assert.Equal(t, [2]int64{0, 2147483647}, client.partRanges[1])
+	assert.Equal(t, [2]int64{4294967296, MaxCopyObjectSize}, client.partRanges[3])
+
+	parts := client.completed
+	require.Len(t, parts, 3)
+	for i, part := range parts {
+		assert.Equal(t, int32(i+1), part.Number, "分片应按序号提交")
+	}
+	assert.False(t, client.aborted)
+}
+
+func TestObjectCopier_PartFailureAborts(t *testing.T) {
+	client := &fakeCopyClient{size: MaxCopyObjectSize + 1, failPartNum: 2}
+
+	_, err := NewObjectCopier(client).Copy(context.Background(), "src", "big.bin", "dst", "big.bin", &CopyOptions{
+		PartSize:    2 * 1024 * 1024 * 1024,
+		Concurrency: 1,
+	})
+	require.Error(t, err)
+	assert.True(t, client.aborted)
+	assert.Nil(t, client.completed)
+}
+
+func TestObjectCopier_UpdateMetadataOverridesHeaders(t *testing.T) {
+	client := &fakeCopyClient{size: 1024, metadata: map[string]string{"owner": "alice"}}
+	copier := NewObjectCopier(client)
+
+	// 没有任何修改时拒绝复制
+	require.Error(t, copier.UpdateMetadata(context.Background(), "src", "index.html", &CopyOptions{}))
+
+	err := copier.UpdateMetadata(context.Background(), "src", "index.html", &CopyOptions{
+		CacheControl:       aws.String("no-cache"),
+		ContentDisposition: aws.String(""),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, client.copies, 1)
+	request := client.copies[0]
+	assert.Equal(t, "index.html", request.dstKey)
+	require.NotNil(t, request.headers)
+	assert.Equal(t, "no-cache", request.headers.CacheControl)
+	assert.Equal(t, "", request.headers.ContentDisposition)
+	// 未修改的元数据保持不变
+	assert.Equal(t, map[string]string{"owner": "alice"}, request.headers.Metadata)
+}
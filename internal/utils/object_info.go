@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// HeadObjectAPI is the subset of the storage needed to inspect an object
+type HeadObjectAPI interface {
+	Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error)
+}
+
+// ObjectInfo holds the headers and user metadata stored on an object
+type ObjectInfo struct {
+	Key                string            `json:"key"`
+	Size               int64             `json:"size"`
+	LastModified       time.Time         `json:"last_modified"`
+	ETag               string            `json:"etag"`
+	ContentType        string            `json:"content_type"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	ContentEncoding    string            `json:"content_encoding,omitempty"`
+	StorageClass       string            `json:"storage_class,omitempty"`
+	Metadata           map[string]string `json:"metadata"`
+}
+
+// StatObject fetches the object's headers with Head
+func StatObject(ctx context.Context, client HeadObjectAPI, bucket, key string) (*ObjectInfo, error) {
+	head, err := client.Head(ctx, bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	metadata := head.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	return &ObjectInfo{
+		Key:                key,
+		Size:               head.Size,
+		LastModified:       head.LastModified,
+		ETag:               head.ETag,
+		ContentType:        head.ContentType,
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		ContentEncoding:    head.ContentEncoding,
+		StorageClass:       head.StorageClass,
+		Metadata:           metadata,
+	}, nil
+}
+
+// MetadataKeys returns the user metadata keys in sorted order
+func (i *ObjectInfo) MetadataKeys() []string {
+	keys := make([]string, 0, len(i.Metadata))
+	for k := range i.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CopyMetadata returns a copy of the user metadata that can be modified safely
+func (i *ObjectInfo) CopyMetadata() map[string]string {
+	metadata := make(map[string]string, len(i.Metadata))
+	for k, v := range i.Metadata {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// NormalizeMetadataKey lowercases a user metadata key and strips an optional
+// "x-amz-meta-" prefix, matching how S3 returns metadata keys
+func NormalizeMetadataKey(key string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(key)), "x-amz-meta-")
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+type fakeHeadClient struct {
+	output *r2.ObjectInfo
+}
+
+func (f *fakeHeadClient) Head(ctx context.Context, bucket, key string) (*r2.ObjectInfo, error) {
+	return f.output, nil
+}
+
+func TestStatObject(t *testing.T) {
+	client := &fakeHeadClient{output: &r2.ObjectInfo{
+		Key:  "index.html",
+		Size: 42,
+		ETag: "abc123",
+		Headers: r2.Headers{
+			ContentType:  "text/html",
+			CacheControl: "no-cache",
+			Metadata:     map[string]string{"owner": "alice", "build": "7"},
+		},
+	}}
+
+	info, err := StatObject(context.Background(), client, "bucket", "index.html")
+	require.NoError(t, err)
+	assert.Equal(t, "index.html", info.Key)
+	assert.Equal(t, int64(42), info.Size)
+	assert.Equal(t, "abc123", info.ETag)
+	assert.Equal(t, "no-cache", info.CacheControl)
+	assert.Equal(t, []string{"build", "owner"}, info.MetadataKeys())
+
+	// Copies must not alias the original map
+	metadata := info.CopyMetadata()
+	metadata["owner"] = "bob"
+	assert.Equal(t, "alice", info.Metadata["owner"])
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -82,7 +83,7 @@ func (pr *ProgressReader) printProgress() {
 	var speed string
 	if elapsed.Seconds() > 0.1 {
 		bytesPerSec := float64(pr.read) / elapsed.Seconds()
-		speed = fmt.Sprintf(" %s/s", formatBytes(int64(bytesPerSec)))
+		speed = fmt.Sprintf(" %s/s", FormatBytes(int64(bytesPerSec)))
 	}
 
 	// Create progress bar (40 characters wide)
@@ -98,8 +99,8 @@ func (pr *ProgressReader) printProgress() {
 	line := fmt.Sprintf("[1/1] %s %.1f%% (%s/%s)%s - %s",
 		bar,
 		percentage,
-		formatBytes(pr.read),
-		formatBytes(pr.total),
+		FormatBytes(pr.read),
+		FormatBytes(pr.total),
 		speed,
 		pr.description)
 
@@ -124,8 +125,8 @@ func (pr *ProgressReader) Close() error {
 	return nil
 }
 
-// formatBytes formats bytes in human readable format
-func formatBytes(bytes int64) string {
+// FormatBytes formats bytes in human readable format (e.g. 1.5MB)
+func FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%dB", bytes)
@@ -138,13 +139,17 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// MultiFileProgress tracks progress for multiple file uploads
+// MultiFileProgress tracks progress for multiple file uploads.
+// It is safe for concurrent use by several workers.
 type MultiFileProgress struct {
+	mu              sync.Mutex
 	totalFiles      int
 	currentFile     int
 	totalBytes      int64
 	processedBytes  int64
+	inFlight        map[int]int64 // bytes transferred so far per worker
 	currentFileName string
+	action          string
 	startTime       time.Time
 	lastPrint       time.Time
 	lastLineLen     int
@@ -155,21 +160,69 @@ func NewMultiFileProgress(totalFiles int, totalBytes int64) *MultiFileProgress {
 	return &MultiFileProgress{
 		totalFiles: totalFiles,
 		totalBytes: totalBytes,
+		inFlight:   make(map[int]int64),
+		action:     "Uploading",
 		startTime:  time.Now(),
 		lastPrint:  time.Now(),
 	}
 }
 
+// SetAction sets the verb shown before the current file name (default "Uploading")
+func (mfp *MultiFileProgress) SetAction(action string) {
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+	mfp.action = action
+}
+
 // StartFile marks the start of a new file upload
 func (mfp *MultiFileProgress) StartFile(fileName string, fileSize int64) {
+	mfp.StartWorkerFile(0, fileName, fileSize)
+}
+
+// UpdateFile reports bytes transferred so far for the current file
+func (mfp *MultiFileProgress) UpdateFile(transferred int64) {
+	mfp.UpdateWorkerFile(0, transferred)
+}
+
+// FinishFile marks the completion of a file upload
+func (mfp *MultiFileProgress) FinishFile(fileSize int64) {
+	mfp.FinishWorkerFile(0, fileSize)
+}
+
+// StartWorkerFile marks the start of a new file on the given worker
+func (mfp *MultiFileProgress) StartWorkerFile(worker int, fileName string, fileSize int64) {
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+
 	mfp.currentFile++
 	mfp.currentFileName = fileName
+	mfp.inFlight[worker] = 0
 	mfp.printProgress()
 }
 
-// FinishFile marks the completion of a file upload
-func (mfp *MultiFileProgress) FinishFile(fileSize int64) {
+// UpdateWorkerFile reports bytes transferred so far for the file on the given worker
+func (mfp *MultiFileProgress) UpdateWorkerFile(worker int, transferred int64) {
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+
+	mfp.inFlight[worker] = transferred
+
+	// Throttle redraws to every 200ms
+	now := time.Now()
+	if now.Sub(mfp.lastPrint) > 200*time.Millisecond {
+		mfp.printProgress()
+		mfp.lastPrint = now
+	}
+}
+
+// FinishWorkerFile marks the file on the given worker as done, counting fileSize
+// bytes as processed (pass 0 for a failed file)
+func (mfp *MultiFileProgress) FinishWorkerFile(worker int, fileSize int64) {
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+
 	mfp.processedBytes += fileSize
+	delete(mfp.inFlight, worker)
 	mfp.printProgress()
 }
 
@@ -183,17 +236,21 @@ func (mfp *MultiFileProgress) printProgress() {
 	filePercentage := float64(mfp.currentFile) / float64(mfp.totalFiles) * 100
 
 	// Calculate byte progress percentage (if we have total bytes)
+	doneBytes := mfp.processedBytes
+	for _, transferred := range mfp.inFlight {
+		doneBytes += transferred
+	}
 	var bytePercentage float64
 	if mfp.totalBytes > 0 {
-		bytePercentage = float64(mfp.processedBytes) / float64(mfp.totalBytes) * 100
+		bytePercentage = float64(doneBytes) / float64(mfp.totalBytes) * 100
 	}
 
 	// Calculate transfer speed
 	elapsed := time.Since(mfp.startTime)
 	var speed string
-	if elapsed.Seconds() > 1 && mfp.processedBytes > 0 {
-		bytesPerSec := float64(mfp.processedBytes) / elapsed.Seconds()
-		speed = fmt.Sprintf(" %s/s", formatBytes(int64(bytesPerSec)))
+	if elapsed.Seconds() > 1 && doneBytes > 0 {
+		bytesPerSec := float64(doneBytes) / elapsed.Seconds()
+		speed = fmt.Sprintf(" %s/s", FormatBytes(int64(bytesPerSec)))
 	}
 
 	// Create progress bar (40 characters wide)
@@ -213,21 +270,23 @@ func (mfp *MultiFileProgress) printProgress() {
 	// Build the progress line
 	var line string
 	if mfp.totalBytes > 0 {
-		line = fmt.Sprintf("[%d/%d] %s %.1f%% (%s/%s)%s - Uploading %s",
+		line = fmt.Sprintf("[%d/%d] %s %.1f%% (%s/%s)%s - %s %s",
 			mfp.currentFile,
 			mfp.totalFiles,
 			bar,
 			bytePercentage,
-			formatBytes(mfp.processedBytes),
-			formatBytes(mfp.totalBytes),
+			FormatBytes(doneBytes),
+			FormatBytes(mfp.totalBytes),
 			speed,
+			mfp.action,
 			mfp.currentFileName)
 	} else {
-		line = fmt.Sprintf("[%d/%d] %s %.1f%% - Uploading %s",
+		line = fmt.Sprintf("[%d/%d] %s %.1f%% - %s %s",
 			mfp.currentFile,
 			mfp.totalFiles,
 			bar,
 			filePercentage,
+			mfp.action,
 			mfp.currentFileName)
 	}
 
@@ -243,6 +302,9 @@ func (mfp *MultiFileProgress) printProgress() {
 
 // Finish completes the multi-file progress display
 func (mfp *MultiFileProgress) Finish() {
+	mfp.mu.Lock()
+	defer mfp.mu.Unlock()
+
 	mfp.printProgress()
 	fmt.Fprintln(os.Stderr) // New line after completion
 }
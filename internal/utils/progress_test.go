@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiFileProgress_ConcurrentWorkers(t *testing.T) {
+	progress := NewMultiFileProgress(8, 8*1024)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 2; i++ {
+				progress.StartWorkerFile(worker, "file.txt", 1024)
+				for transferred := int64(256); transferred <= 1024; transferred += 256 {
+					progress.UpdateWorkerFile(worker, transferred)
+				}
+				progress.FinishWorkerFile(worker, 1024)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	progress.Finish()
+
+	assert.Equal(t, 8, progress.currentFile)
+	assert.Equal(t, int64(8*1024), progress.processedBytes)
+	assert.Empty(t, progress.inFlight)
+}
+
+func TestMultiFileProgress_FailedFileDropsInFlightBytes(t *testing.T) {
+	progress := NewMultiFileProgress(1, 1024)
+
+	progress.StartWorkerFile(0, "broken.txt", 1024)
+	progress.UpdateWorkerFile(0, 512)
+	progress.FinishWorkerFile(0, 0)
+
+	assert.Equal(t, int64(0), progress.processedBytes)
+	assert.Empty(t, progress.inFlight)
+}
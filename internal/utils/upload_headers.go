@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+// ObjectHeaders 上传时写入对象的请求头和用户元数据
+type ObjectHeaders struct {
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Metadata           map[string]string
+}
+
+// HeaderOverrides 是显式指定的请求头，nil 表示未指定，空字符串会清除该值
+type HeaderOverrides struct {
+	ContentType        *string
+	CacheControl       *string
+	ContentDisposition *string
+	ContentEncoding    *string
+	Metadata           map[string]string
+}
+
+// UploadOptionsFromConfig 根据 [upload] 配置创建上传选项，内容类型留空以自动检测
+func UploadOptionsFromConfig(cfg *config.UploadConfig) *UploadOptions {
+	if cfg == nil {
+		return &UploadOptions{}
+	}
+	return &UploadOptions{
+		Overwrite:          cfg.DefaultOverwrite,
+		PublicAccess:       cfg.DefaultPublic,
+		CacheControl:       cfg.CacheControl,
+		ContentDisposition: cfg.ContentDisposition,
+		ContentEncoding:    cfg.ContentEncoding,
+		Metadata:           cfg.Metadata,
+		HeaderRules:        cfg.HeaderRules,
+	}
+}
+
+// HeadersFor 返回上传到 remotePath 时使用的请求头：先取选项中的通用值，
+// 再按顺序应用与 remotePath 匹配的规则，后面的规则覆盖前面的值，
+// 最后应用 Overrides，所以命令行指定的值优先于任何规则
+func (o *UploadOptions) HeadersFor(remotePath string) ObjectHeaders {
+	headers := ObjectHeaders{
+		ContentType:        o.ContentType,
+		CacheControl:       o.CacheControl,
+		ContentDisposition: o.ContentDisposition,
+		ContentEncoding:    o.ContentEncoding,
+	}
+	headers.mergeMetadata(o.Metadata)
+
+	for _, rule := range o.HeaderRules {
+		if !MatchGlob(rule.Pattern, remotePath) {
+			continue
+		}
+		headers.applyRule(rule)
+	}
+	headers.applyOverrides(o.Overrides)
+	return headers
+}
+
+// applyRule 用规则中设置的值覆盖请求头
+func (h *ObjectHeaders) applyRule(rule config.HeaderRule) {
+	if rule.ContentType != "" {
+		h.ContentType = rule.ContentType
+	}
+	if rule.CacheControl != "" {
+		h.CacheControl = rule.CacheControl
+	}
+	if rule.ContentDisposition != "" {
+		h.ContentDisposition = rule.ContentDisposition
+	}
+	if rule.ContentEncoding != "" {
+		h.ContentEncoding = rule.ContentEncoding
+	}
+	h.mergeMetadata(rule.Metadata)
+}
+
+// applyOverrides 用显式指定的值覆盖请求头
+func (h *ObjectHeaders) applyOverrides(overrides HeaderOverrides) {
+	if overrides.ContentType != nil {
+		h.ContentType = *overrides.ContentType
+	}
+	if overrides.CacheControl != nil {
+		h.CacheControl = *overrides.CacheControl
+	}
+	if overrides.ContentDisposition != nil {
+		h.ContentDisposition = *overrides.ContentDisposition
+	}
+	if overrides.ContentEncoding != nil {
+		h.ContentEncoding = *overrides.ContentEncoding
+	}
+	h.mergeMetadata(overrides.Metadata)
+}
+
+// mergeMetadata 合并用户元数据，键名统一为小写
+func (h *ObjectHeaders) mergeMetadata(metadata map[string]string) {
+	if len(metadata) == 0 {
+		return
+	}
+	if h.Metadata == nil {
+		h.Metadata = make(map[string]string, len(metadata))
+	}
+	for key, value := range metadata {
+		h.Metadata[NormalizeMetadataKey(key)] = value
+	}
+}
+
+// ApplyToPut 将请求头和用户元数据写入上传选项，同样用于创建分片上传
+func (h ObjectHeaders) ApplyToPut(options *r2.PutOptions) {
+	options.ContentType = h.ContentType
+	options.CacheControl = h.CacheControl
+	options.ContentDisposition = h.ContentDisposition
+	options.ContentEncoding = h.ContentEncoding
+	options.Metadata = h.Metadata
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+func TestUploadOptions_HeadersFor(t *testing.T) {
+	options := &UploadOptions{
+		CacheControl: "public, max-age=3600",
+		Metadata:     map[string]string{"Owner": "alice"},
+		HeaderRules: []config.HeaderRule{
+			{Pattern: "*.html", CacheControl: "no-cache"},
+			{Pattern: "assets/**/*.woff2", CacheControl: "public, max-age=31536000, immutable", ContentType: "font/woff2"},
+			{Pattern: "*.html", Metadata: map[string]string{"page": "true"}},
+		},
+	}
+
+	// 没有匹配的规则时使用通用值
+	headers := options.HeadersFor("images/cat.png")
+	assert.Equal(t, "public, max-age=3600", headers.CacheControl)
+	assert.Equal(t, map[string]string{"owner": "alice"}, headers.Metadata)
+
+	// 匹配的规则按顺序覆盖，元数据合并
+	headers = options.HeadersFor("docs/index.html")
+	assert.Equal(t, "no-cache", headers.CacheControl)
+	assert.Equal(t, map[string]string{"owner": "alice", "page": "true"}, headers.Metadata)
+
+	headers = options.HeadersFor("assets/fonts/inter.woff2")
+	assert.Equal(t, "font/woff2", headers.ContentType)
+	assert.Contains(t, headers.CacheControl, "immutable")
+
+	// 合并元数据时不修改选项中的原始 map
+	assert.Equal(t, map[string]string{"Owner": "alice"}, options.Metadata)
+}
+
+func TestUploadOptions_HeadersForOverrides(t *testing.T) {
+	cacheControl := "max-age=60"
+	empty := ""
+	options := &UploadOptions{
+		ContentDisposition: "inline",
+		HeaderRules: []config.HeaderRule{
+			{Pattern: "*.html", CacheControl: "no-cache", ContentType: "text/html", Metadata: map[string]string{"page": "rule"}},
+		},
+		Overrides: HeaderOverrides{
+			CacheControl:       &cacheControl,
+			ContentDisposition: &empty,
+			Metadata:           map[string]string{"Page": "flag"},
+		},
+	}
+
+	// 命令行的值优先于规则，空字符串清除配置的值，未指定的值仍取自规则
+	headers := options.HeadersFor("index.html")
+	assert.Equal(t, "max-age=60", headers.CacheControl)
+	assert.Equal(t, "", headers.ContentDisposition)
+	assert.Equal(t, "text/html", headers.ContentType)
+	assert.Equal(t, map[string]string{"page": "flag"}, headers.Metadata)
+}
+
+func TestObjectHeaders_ApplyToPut(t *testing.T) {
+	options := &r2.PutOptions{Public: true}
+	ObjectHeaders{ContentType: "text/html", ContentDisposition: "attachment", Metadata: map[string]string{"page": "true"}}.ApplyToPut(options)
+
+	assert.Equal(t, "text/html", options.ContentType)
+	assert.Equal(t, "attachment", options.ContentDisposition)
+	assert.Equal(t, map[string]string{"page": "true"}, options.Metadata)
+	assert.Empty(t, options.CacheControl)
+	// 其他选项保持不变
+	assert.True(t, options.Public)
+}
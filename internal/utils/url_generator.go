@@ -3,27 +3,56 @@ package utils
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/sirupsen/logrus"
 
 	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
 )
 
+const (
+	// DefaultPresignExpiry is the lifetime of presigned URLs unless configured otherwise
+	DefaultPresignExpiry = time.Hour
+	// MaxPresignExpiry is the longest lifetime SigV4 presigned URLs support
+	MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// PresignOptions controls the URL created by Presign
+type PresignOptions struct {
+	Method             string        // GET (default) or PUT
+	Expires            time.Duration // Defaults to DefaultPresignExpiry
+	ContentDisposition string        // GET only: response-content-disposition override
+}
+
+// PresignedURL is a presigned request and when it stops working
+type PresignedURL struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PresignAPI is the subset of the storage client needed to presign requests
+type PresignAPI interface {
+	Presign(ctx context.Context, request r2.PresignRequest) (string, error)
+}
+
 type URLGenerator struct {
-	s3Client   *s3.Client
+	presigner  PresignAPI
 	config     *config.Config
 	bucketName string
 }
 
-func NewURLGenerator(s3Client *s3.Client, cfg *config.Config, bucketName string) *URLGenerator {
+func NewURLGenerator(presigner PresignAPI, cfg *config.Config, bucketName string) *URLGenerator {
 	return &URLGenerator{
-		s3Client:   s3Client,
+		presigner:  presigner,
 		config:     cfg,
 		bucketName: bucketName,
 	}
@@ -72,20 +101,112 @@ func (g *URLGenerator) GenerateCustomDomainURL(key string) string {
 }
 
 func (g *URLGenerator) GeneratePresignedURL(key string) (string, error) {
-	presignClient := s3.NewPresignClient(g.s3Client)
-
-	// Create presigned URL valid for 1 hour
-	request, err := presignClient.PresignGetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: aws.String(g.bucketName),
-		Key:    aws.String(key),
-	}, func(opts *s3.PresignOptions) {
-		opts.Expires = time.Hour
-	})
+	return g.GeneratePresignedURLWithExpiry(key, DefaultPresignExpiry)
+}
+
+// GeneratePresignedURLWithExpiry creates a GET URL valid for expires
+func (g *URLGenerator) GeneratePresignedURLWithExpiry(key string, expires time.Duration) (string, error) {
+	presigned, err := g.Presign(context.TODO(), key, PresignOptions{Expires: expires})
 	if err != nil {
-		return "", fmt.Errorf("failed to presign request: %w", err)
+		return "", err
+	}
+	return presigned.URL, nil
+}
+
+// Presign creates a presigned GET or PUT URL for key
+func (g *URLGenerator) Presign(ctx context.Context, key string, options PresignOptions) (*PresignedURL, error) {
+	if options.Expires == 0 {
+		options.Expires = DefaultPresignExpiry
+	}
+	if options.Expires < time.Second || options.Expires > MaxPresignExpiry {
+		return nil, fmt.Errorf("expiry must be between 1s and 7d, got %s", options.Expires)
+	}
+
+	method := strings.ToUpper(options.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if method != http.MethodGet && method != http.MethodPut {
+		return nil, fmt.Errorf("unsupported method %q: must be GET or PUT", options.Method)
 	}
 
-	return request.URL, nil
+	request := r2.PresignRequest{
+		Method:  method,
+		Bucket:  g.bucketName,
+		Key:     key,
+		Expires: options.Expires,
+	}
+	if method == http.MethodGet {
+		request.ContentDisposition = options.ContentDisposition
+	}
+
+	presignedURL, err := g.presigner.Presign(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign request: %w", err)
+	}
+
+	return &PresignedURL{
+		URL:       presignedURL,
+		Method:    method,
+		Bucket:    g.bucketName,
+		Key:       key,
+		ExpiresAt: time.Now().Add(options.Expires).UTC(),
+	}, nil
+}
+
+// ParseExpiry parses a presign lifetime such as "15m", "12h", "7d" or "1d12h"
+func ParseExpiry(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var days time.Duration
+	if i := strings.Index(value, "d"); i > 0 {
+		n, err := strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q", value)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		value = value[i+1:]
+	}
+
+	var rest time.Duration
+	if value != "" {
+		var err error
+		rest, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q: use values like 15m, 12h or 7d", value)
+		}
+	}
+
+	expiry := days + rest
+	if expiry < time.Second || expiry > MaxPresignExpiry {
+		return 0, fmt.Errorf("expiry must be between 1s and 7d, got %s", expiry)
+	}
+	return expiry, nil
+}
+
+// FormatExpiry formats a presign lifetime the way ParseExpiry accepts it, e.g. "1d12h"
+func FormatExpiry(expiry time.Duration) string {
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var b strings.Builder
+	for _, unit := range units {
+		if n := expiry / unit.size; n > 0 {
+			fmt.Fprintf(&b, "%d%s", n, unit.suffix)
+			expiry -= n * unit.size
+		}
+	}
+	if b.Len() == 0 {
+		return "0s"
+	}
+	return b.String()
 }
 
 func (g *URLGenerator) GetPreferredURL(key string) (string, error) {
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HaiFongPan/r2s3-cli/internal/config"
+	"github.com/HaiFongPan/r2s3-cli/internal/r2"
+)
+
+func newTestURLGenerator() *URLGenerator {
+	client := s3.New(s3.Options{
+		Region:       "auto",
+		BaseEndpoint: aws.String("https://account.r2.cloudflarestorage.com"),
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		UsePathStyle: true,
+	})
+	return NewURLGenerator(r2.NewS3Storage(client), &config.Config{}, "bucket")
+}
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"15m", 15 * time.Minute},
+		{"12h", 12 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"90s", 90 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := ParseExpiry(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+		assert.Equal(t, tt.want, mustParseExpiry(t, FormatExpiry(got)), "round trip of %s", tt.value)
+	}
+
+	for _, invalid := range []string{"", "8d", "0s", "soon", "xd"} {
+		_, err := ParseExpiry(invalid)
+		assert.Error(t, err, invalid)
+	}
+	assert.Equal(t, "1d12h", FormatExpiry(36*time.Hour))
+	assert.Equal(t, "1h30m", FormatExpiry(90*time.Minute))
+}
+
+func mustParseExpiry(t *testing.T, value string) time.Duration {
+	t.Helper()
+	d, err := ParseExpiry(value)
+	require.NoError(t, err)
+	return d
+}
+
+func TestURLGenerator_Presign(t *testing.T) {
+	generator := newTestURLGenerator()
+
+	presigned, err := generator.Presign(context.Background(), "docs/report.pdf", PresignOptions{
+		Expires:            30 * time.Minute,
+		ContentDisposition: "attachment",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "GET", presigned.Method)
+
+	parsed, err := url.Parse(presigned.URL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "1800", query.Get("X-Amz-Expires"))
+	assert.Equal(t, "attachment", query.Get("response-content-disposition"))
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), presigned.ExpiresAt, time.Minute)
+
+	presigned, err = generator.Presign(context.Background(), "inbox/photo.jpg", PresignOptions{Method: "put"})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT", presigned.Method)
+	assert.Contains(t, presigned.URL, "/bucket/inbox/photo.jpg?")
+
+	_, err = generator.Presign(context.Background(), "a", PresignOptions{Method: "DELETE"})
+	assert.Error(t, err)
+	_, err = generator.Presign(context.Background(), "a", PresignOptions{Expires: 8 * 24 * time.Hour})
+	assert.Error(t, err)
+}